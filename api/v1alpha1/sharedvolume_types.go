@@ -0,0 +1,97 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SharedVolumeSpec defines the desired state of SharedVolume
+type SharedVolumeSpec struct {
+	// Size is the size of the shared PVC, which must be greater than zero.
+	// The PVC is expanded when the size grows, it is never shrunk.
+	Size resource.Quantity `json:"size"`
+
+	// StorageClassName is the storage class of the shared PVC, which must support ReadWriteMany
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="storage class name is immutable"
+	// +optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+
+	// MountPath is where the volume is mounted in the workspace containers
+	// +kubebuilder:validation:Pattern=`^/.*`
+	// +kubebuilder:validation:MinLength=1
+	MountPath string `json:"mountPath"`
+
+	// Groups restricts the volume to the workspaces created by members of one of the groups.
+	// The volume is mounted in all the workspaces of the namespace when empty.
+	// +listType=set
+	// +optional
+	Groups []string `json:"groups,omitempty"`
+
+	// ReadWriteGroups are the groups whose members mount the volume read-write,
+	// the workspaces of other users mount it read-only.
+	// All the workspaces mount the volume read-write when empty.
+	// +listType=set
+	// +optional
+	ReadWriteGroups []string `json:"readWriteGroups,omitempty"`
+}
+
+// SharedVolumeStatus defines the observed state of SharedVolume
+type SharedVolumeStatus struct {
+	// ObservedGeneration is the most recent generation observed by the controller
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// ClaimName is the name of the PVC provisioned for the shared volume
+	// +optional
+	ClaimName string `json:"claimName,omitempty"`
+
+	// Conditions represent the latest available observations of the shared volume's state
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:validation:XValidation:rule="size(self.metadata.name) <= 56",message="name must be at most 56 characters"
+// +kubebuilder:printcolumn:name="Claim",type="string",JSONPath=".status.claimName"
+// +kubebuilder:printcolumn:name="Size",type="string",JSONPath=".spec.size"
+// +kubebuilder:printcolumn:name="Mount Path",type="string",JSONPath=".spec.mountPath"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// SharedVolume is the Schema for the sharedvolumes API.
+// The controller provisions a ReadWriteMany PVC for each SharedVolume and mounts it
+// in the matching workspaces of its namespace.
+type SharedVolume struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec defines the desired state of SharedVolume
+	Spec SharedVolumeSpec `json:"spec"`
+
+	// Status defines the observed state of SharedVolume
+	// +optional
+	Status SharedVolumeStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// SharedVolumeList contains a list of SharedVolume
+type SharedVolumeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SharedVolume `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SharedVolume{}, &SharedVolumeList{})
+}
@@ -9,21 +9,80 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
-// VolumeSpec defines a volume to mount from an existing PVC
+// VolumeSpec defines a volume to mount from an existing PVC, ConfigMap or Secret, or from a CSI driver.
+// Exactly one source must be set.
 type VolumeSpec struct {
 	// Name is a unique identifier for this volume within the pod (maps to pod.spec.volumes[].name)
 	Name string `json:"name"`
 
 	// PersistentVolumeClaimName is the name of the existing PVC to mount
-	PersistentVolumeClaimName string `json:"persistentVolumeClaimName"`
+	// +optional
+	PersistentVolumeClaimName string `json:"persistentVolumeClaimName,omitempty"`
+
+	// ConfigMap mounts a ConfigMap of the workspace namespace
+	// +optional
+	ConfigMap *corev1.ConfigMapVolumeSource `json:"configMap,omitempty"`
+
+	// Secret mounts a Secret of the workspace namespace
+	// +optional
+	Secret *corev1.SecretVolumeSource `json:"secret,omitempty"`
+
+	// CSI mounts an ephemeral volume provided by a CSI driver
+	// +optional
+	CSI *corev1.CSIVolumeSource `json:"csi,omitempty"`
 
 	// MountPath is the path where the volume should be mounted (Unix-style path, e.g. /data)
 	MountPath string `json:"mountPath"`
+
+	// ReadOnly mounts the volume read-only
+	// +optional
+	ReadOnly bool `json:"readOnly,omitempty"`
+
+	// SubPath mounts a relative path within the volume instead of its root
+	// +optional
+	SubPath string `json:"subPath,omitempty"`
+}
+
+// VolumeSourceType defines the kind of source of an additional volume
+// +kubebuilder:validation:Enum=PersistentVolumeClaim;ConfigMap;Secret;CSI
+type VolumeSourceType string
+
+const (
+	// VolumeSourceTypePersistentVolumeClaim mounts an existing PVC
+	VolumeSourceTypePersistentVolumeClaim VolumeSourceType = "PersistentVolumeClaim"
+	// VolumeSourceTypeConfigMap mounts a ConfigMap
+	VolumeSourceTypeConfigMap VolumeSourceType = "ConfigMap"
+	// VolumeSourceTypeSecret mounts a Secret
+	VolumeSourceTypeSecret VolumeSourceType = "Secret"
+	// VolumeSourceTypeCSI mounts an ephemeral volume of a CSI driver
+	VolumeSourceTypeCSI VolumeSourceType = "CSI"
+)
+
+// SecondaryStorageSpec defines an additional PVC that the controller provisions for the workspace
+// and deletes with it
+type SecondaryStorageSpec struct {
+	// Name identifies the secondary storage within the workspace
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=40
+	Name string `json:"name"`
+
+	// Size specifies the size of the volume, it can only be expanded
+	Size resource.Quantity `json:"size"`
+
+	// StorageClassName specifies the storage class of the volume
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="storage class name is immutable"
+	// +optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+
+	// MountPath is the path where the volume is mounted (Unix-style path, e.g. /scratch)
+	// +kubebuilder:validation:Pattern=`^/.*`
+	MountPath string `json:"mountPath"`
 }
 
 // ContainerConfig defines container command and args configuration
@@ -36,12 +95,20 @@ type ContainerConfig struct {
 }
 
 // StorageSpec defines the storage configuration for Workspace
+// +kubebuilder:validation:XValidation:rule="(has(self.type) ? self.type : 'Persistent') == (has(oldSelf.type) ? oldSelf.type : 'Persistent')",message="storage type is immutable"
 type StorageSpec struct {
+	// Type specifies the kind of volume backing the workspace storage, Persistent when empty.
+	// EmptyDir and EphemeralVolume storages are deleted with the workspace pod and create no PVC.
+	// The type cannot be changed once set, an empty type counting as Persistent.
+	// +kubebuilder:validation:Enum=Persistent;EmptyDir;EphemeralVolume
+	// +optional
+	Type StorageType `json:"type,omitempty"`
+
 	// StorageClassName specifies the storage class to use for persistent storage
 	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="storage class name is immutable"
 	StorageClassName *string `json:"storageClassName,omitempty"`
 
-	// Size specifies the size of the persistent volume
+	// Size specifies the size of the persistent volume, or the size limit of an ephemeral storage
 	// Supports standard Kubernetes resource quantities (e.g., "10Gi", "500Mi", "1Ti")
 	// Integer values without units are interpreted as bytes
 	// +kubebuilder:default="10Gi"
@@ -51,6 +118,91 @@ type StorageSpec struct {
 	// Default is /home/jovyan (jovyan is the standard user in Jupyter images)
 	// +kubebuilder:default="/home/jovyan"
 	MountPath string `json:"mountPath,omitempty"`
+
+	// Snapshot configures CSI VolumeSnapshots of the workspace storage
+	// +optional
+	Snapshot *SnapshotPolicy `json:"snapshot,omitempty"`
+
+	// RestoreFromSnapshot is the name of a VolumeSnapshot in the workspace namespace
+	// used as the dataSource when the workspace PVC is created
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="restoreFromSnapshot is immutable"
+	// +optional
+	RestoreFromSnapshot string `json:"restoreFromSnapshot,omitempty"`
+
+	// RestartOnResize restarts the workspace pod when an expansion of its volume waits for a filesystem
+	// resize that the storage driver only performs when the volume is mounted again
+	// +optional
+	RestartOnResize bool `json:"restartOnResize,omitempty"`
+
+	// PersistentVolumeClaimRetentionPolicy defines what happens to the workspace PVC when the workspace is deleted
+	// +optional
+	PersistentVolumeClaimRetentionPolicy *PersistentVolumeClaimRetentionPolicy `json:"persistentVolumeClaimRetentionPolicy,omitempty"`
+
+	// ExistingClaimName is the name of a PVC retained from a deleted workspace, adopted as the workspace storage
+	// instead of creating a new PVC
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="existingClaimName is immutable"
+	// +kubebuilder:validation:MaxLength=253
+	// +optional
+	ExistingClaimName string `json:"existingClaimName,omitempty"`
+}
+
+// StorageType defines the kind of volume backing the workspace storage
+type StorageType string
+
+const (
+	// StorageTypePersistent backs the storage with a PVC owned by the workspace
+	StorageTypePersistent StorageType = "Persistent"
+	// StorageTypeEmptyDir backs the storage with an emptyDir volume limited to the storage size
+	StorageTypeEmptyDir StorageType = "EmptyDir"
+	// StorageTypeEphemeralVolume backs the storage with a generic ephemeral volume of the storage size and class
+	StorageTypeEphemeralVolume StorageType = "EphemeralVolume"
+)
+
+// PersistentVolumeClaimRetentionPolicyType defines what happens to the workspace PVC when the workspace is deleted
+type PersistentVolumeClaimRetentionPolicyType string
+
+const (
+	// PersistentVolumeClaimRetentionPolicyDelete deletes the PVC with the workspace
+	PersistentVolumeClaimRetentionPolicyDelete PersistentVolumeClaimRetentionPolicyType = "Delete"
+	// PersistentVolumeClaimRetentionPolicyRetain keeps the PVC until it is deleted manually or adopted
+	PersistentVolumeClaimRetentionPolicyRetain PersistentVolumeClaimRetentionPolicyType = "Retain"
+	// PersistentVolumeClaimRetentionPolicyRetainFor keeps the PVC for a duration after the workspace deletion
+	PersistentVolumeClaimRetentionPolicyRetainFor PersistentVolumeClaimRetentionPolicyType = "RetainFor"
+)
+
+// PersistentVolumeClaimRetentionPolicy defines what happens to the workspace PVC when the workspace is deleted.
+// Retained PVCs are released from the workspace and can be adopted by a new workspace through
+// spec.storage.existingClaimName.
+// +kubebuilder:validation:XValidation:rule="self.whenDeleted != 'RetainFor' || has(self.retainDuration)",message="retainDuration is required when whenDeleted is RetainFor"
+type PersistentVolumeClaimRetentionPolicy struct {
+	// WhenDeleted defines what happens to the PVC when the workspace is deleted
+	// +kubebuilder:validation:Enum=Delete;Retain;RetainFor
+	// +kubebuilder:default=Delete
+	// +optional
+	WhenDeleted PersistentVolumeClaimRetentionPolicyType `json:"whenDeleted,omitempty"`
+
+	// RetainDuration is how long the PVC is kept after the workspace deletion with the RetainFor policy (e.g. "168h"),
+	// at most one year
+	// +optional
+	RetainDuration *metav1.Duration `json:"retainDuration,omitempty"`
+}
+
+// SnapshotPolicy defines when VolumeSnapshots of the workspace storage are taken and how many are kept
+type SnapshotPolicy struct {
+	// OnStop takes a VolumeSnapshot of the workspace storage every time the workspace transitions to Stopped
+	// +optional
+	OnStop bool `json:"onStop,omitempty"`
+
+	// VolumeSnapshotClassName specifies the VolumeSnapshotClass to use
+	// If not set, the cluster default VolumeSnapshotClass is used
+	// +optional
+	VolumeSnapshotClassName *string `json:"volumeSnapshotClassName,omitempty"`
+
+	// Retain is the number of snapshots to keep for the workspace, oldest snapshots are deleted first
+	// +kubebuilder:default=3
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Retain int32 `json:"retain,omitempty"`
 }
 
 // AccessStrategyRef defines a reference to a WorkspaceAccessStrategy
@@ -83,15 +235,296 @@ type IdleShutdownSpec struct {
 	// +kubebuilder:validation:Minimum=1
 	IdleTimeoutInMinutes int `json:"idleTimeoutInMinutes"`
 
+	// GracePeriodInMinutes specifies how long an idle workspace is warned before it is stopped
+	// During the grace period the IdleShutdownPending condition is set and activity cancels the shutdown
+	// When 0, the workspace is stopped as soon as it is detected idle
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	GracePeriodInMinutes int `json:"gracePeriodInMinutes,omitempty"`
+
 	// Detection specifies how to detect idle state
 	Detection IdleDetectionSpec `json:"detection"`
 }
 
 // IdleDetectionSpec defines idle detection methods
+// +kubebuilder:validation:XValidation:rule="[has(self.httpGet), has(self.jupyterAPI), has(self.metrics), has(self.proxyActivity)].filter(x, x).size() <= 1",message="only one idle detection method may be set"
 type IdleDetectionSpec struct {
 	// HTTPGet specifies the HTTP request to perform for idle detection
 	// +optional
 	HTTPGet *corev1.HTTPGetAction `json:"httpGet,omitempty"`
+
+	// JupyterAPI detects activity through the Jupyter Server REST API
+	// Busy kernels and open terminals count as activity
+	// +optional
+	JupyterAPI *JupyterAPIIdleDetection `json:"jupyterAPI,omitempty"`
+
+	// Metrics detects activity from the resource usage of the workspace container
+	// Useful for images that expose no activity endpoint
+	// +optional
+	Metrics *MetricsIdleDetection `json:"metrics,omitempty"`
+
+	// ProxyActivity detects activity from the authenticated requests seen by the auth middleware
+	// It requires no cooperation from the workspace and works for any app type
+	// +optional
+	ProxyActivity *ProxyActivityIdleDetection `json:"proxyActivity,omitempty"`
+
+	// Probe configures how the controller reaches the idle endpoint
+	// When omitted, the controller runs curl inside the workspace container
+	// +optional
+	Probe *IdleProbeSpec `json:"probe,omitempty"`
+}
+
+// IdleProbeMode defines how the controller reaches the idle endpoint of a workspace
+type IdleProbeMode string
+
+const (
+	// IdleProbeModeExec runs curl inside the workspace container through pods/exec
+	IdleProbeModeExec IdleProbeMode = "Exec"
+	// IdleProbeModeService calls the idle endpoint through the workspace Service
+	IdleProbeModeService IdleProbeMode = "Service"
+	// IdleProbeModePodIP calls the idle endpoint on the workspace pod IP
+	IdleProbeModePodIP IdleProbeMode = "PodIP"
+)
+
+// IdleProbeSpec defines how the controller reaches the idle endpoint
+type IdleProbeSpec struct {
+	// Mode selects how the idle endpoint is reached
+	// Exec runs curl inside the workspace container and requires curl in the image.
+	// Service and PodIP call the endpoint directly from the controller without pods/exec;
+	// Service only reaches ports exposed by the workspace Service.
+	// +kubebuilder:validation:Enum=Exec;Service;PodIP
+	// +kubebuilder:default=Exec
+	// +optional
+	Mode IdleProbeMode `json:"mode,omitempty"`
+
+	// TimeoutSeconds is the timeout of each request made by the controller
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=60
+	// +kubebuilder:default=5
+	// +optional
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+
+	// TLS configures certificate verification when the scheme is HTTPS
+	// +optional
+	TLS *IdleProbeTLSConfig `json:"tls,omitempty"`
+}
+
+// IdleProbeTLSConfig defines TLS options for direct idle probes
+type IdleProbeTLSConfig struct {
+	// InsecureSkipVerify disables verification of the workspace server certificate
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+
+	// ServerName overrides the server name used to verify the certificate
+	// +optional
+	ServerName string `json:"serverName,omitempty"`
+
+	// CABundle is a PEM encoded CA bundle used to verify the server certificate
+	// When omitted, the system roots are used
+	// +optional
+	CABundle []byte `json:"caBundle,omitempty"`
+}
+
+// JupyterAPIIdleDetection configures idle detection through the Jupyter Server REST API
+// (/api/status, /api/kernels and /api/terminals)
+type JupyterAPIIdleDetection struct {
+	// Port is the port of the Jupyter server
+	// +kubebuilder:default=8888
+	// +optional
+	Port intstr.IntOrString `json:"port,omitempty"`
+
+	// Scheme to use for connecting to the Jupyter server
+	// +kubebuilder:validation:Enum=HTTP;HTTPS
+	// +kubebuilder:default=HTTP
+	// +optional
+	Scheme corev1.URIScheme `json:"scheme,omitempty"`
+
+	// BasePath is the base URL of the Jupyter server (ServerApp.base_url)
+	// +optional
+	BasePath string `json:"basePath,omitempty"`
+
+	// CountTerminals controls whether open terminals count as activity
+	// +kubebuilder:default=true
+	// +optional
+	CountTerminals *bool `json:"countTerminals,omitempty"`
+}
+
+// MetricsIdleSource defines where container resource usage is read from
+type MetricsIdleSource string
+
+const (
+	// MetricsIdleSourceMetricsServer reads CPU usage from the metrics.k8s.io API
+	MetricsIdleSourceMetricsServer MetricsIdleSource = "MetricsServer"
+	// MetricsIdleSourcePrometheus reads CPU and network usage from a Prometheus-compatible API
+	MetricsIdleSourcePrometheus MetricsIdleSource = "Prometheus"
+)
+
+// MetricsIdleDetection configures idle detection from container resource usage
+// The workspace is idle once its usage stayed below the thresholds for idleTimeoutInMinutes
+// +kubebuilder:validation:XValidation:rule="!has(self.source) || self.source != 'Prometheus' || has(self.prometheus)",message="prometheus is required when source is Prometheus"
+// +kubebuilder:validation:XValidation:rule="!has(self.networkThresholdBytesPerSecond) || (has(self.source) && self.source == 'Prometheus')",message="networkThresholdBytesPerSecond requires the Prometheus source"
+type MetricsIdleDetection struct {
+	// Source selects where usage is read from
+	// +kubebuilder:validation:Enum=MetricsServer;Prometheus
+	// +kubebuilder:default=MetricsServer
+	// +optional
+	Source MetricsIdleSource `json:"source,omitempty"`
+
+	// CPUThreshold is the CPU usage of the workspace container below which it counts as idle
+	// +kubebuilder:default="50m"
+	// +optional
+	CPUThreshold resource.Quantity `json:"cpuThreshold,omitempty"`
+
+	// NetworkThresholdBytesPerSecond is the network traffic (received and transmitted)
+	// of the workspace pod below which it counts as idle
+	// When omitted, network usage is ignored
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	NetworkThresholdBytesPerSecond *int64 `json:"networkThresholdBytesPerSecond,omitempty"`
+
+	// Prometheus configures the Prometheus-compatible API used by the Prometheus source
+	// +optional
+	Prometheus *PrometheusMetricsSource `json:"prometheus,omitempty"`
+}
+
+// PrometheusMetricsSource defines a Prometheus-compatible HTTP API exposing cAdvisor metrics
+type PrometheusMetricsSource struct {
+	// URL is the base URL of the API, e.g. http://prometheus.monitoring.svc:9090
+	// It must be listed by the operator in the IDLE_PROMETHEUS_ALLOWED_URLS setting of the controller
+	// +kubebuilder:validation:MinLength=1
+	URL string `json:"url"`
+
+	// RateWindowSeconds is the window over which usage rates are computed
+	// +kubebuilder:validation:Minimum=30
+	// +kubebuilder:validation:Maximum=3600
+	// +kubebuilder:default=300
+	// +optional
+	RateWindowSeconds int32 `json:"rateWindowSeconds,omitempty"`
+}
+
+// ProxyActivityIdleDetection configures idle detection from status.lastActivityTime,
+// which the auth middleware records for authenticated requests to the workspace
+// Activity before the workspace pod started is ignored
+type ProxyActivityIdleDetection struct{}
+
+// CloneSource defines a reference to the Workspace to clone
+type CloneSource struct {
+	// Name of the source Workspace, which must be in the same namespace
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// ScheduleSpec defines cron-style windows at which the workspace is started and stopped
+type ScheduleSpec struct {
+	// StartSchedule is a cron expression in standard 5-field format (e.g. "0 8 * * 1-5")
+	// When the schedule fires, the workspace desiredStatus is set to Running
+	// +optional
+	StartSchedule string `json:"startSchedule,omitempty"`
+
+	// StopSchedule is a cron expression in standard 5-field format (e.g. "0 20 * * *")
+	// When the schedule fires, the workspace desiredStatus is set to Stopped
+	// +optional
+	StopSchedule string `json:"stopSchedule,omitempty"`
+
+	// TimeZone is the IANA time zone name used to interpret the schedules (e.g. "Europe/Paris")
+	// Defaults to UTC when omitted
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// LifecyclePolicySpec defines when a workspace is deleted automatically
+type LifecyclePolicySpec struct {
+	// DeleteAfterStoppedInDays deletes the workspace once it has been stopped for this many days
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	DeleteAfterStoppedInDays *int32 `json:"deleteAfterStoppedInDays,omitempty"`
+
+	// MaxLifetimeInDays deletes the workspace this many days after its creation, whether it is running or not
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxLifetimeInDays *int32 `json:"maxLifetimeInDays,omitempty"`
+
+	// DeletionWarningInHours specifies how long before the deletion the DeletionScheduled condition
+	// is set and a warning event is emitted
+	// +kubebuilder:default=24
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	DeletionWarningInHours int32 `json:"deletionWarningInHours,omitempty"`
+
+	// RetainStorage keeps the workspace storage PVC when the workspace is deleted by this policy,
+	// whatever the PVC retention policy of the storage
+	// +optional
+	RetainStorage bool `json:"retainStorage,omitempty"`
+}
+
+// ComputeMode defines the kind of resource running the workspace pod
+type ComputeMode string
+
+const (
+	// ComputeModeDeployment runs the workspace pod with a single replica Deployment
+	ComputeModeDeployment ComputeMode = "Deployment"
+	// ComputeModeStatefulSet runs the workspace pod with a single replica StatefulSet, which never runs
+	// two pods of the workspace at once and gives the pod a stable name
+	ComputeModeStatefulSet ComputeMode = "StatefulSet"
+	// ComputeModePod runs a bare pod with a stable name, recreated by the controller once the previous one is gone
+	ComputeModePod ComputeMode = "Pod"
+)
+
+// StartupFailurePolicy defines what happens when a workspace does not start within its startup timeout
+type StartupFailurePolicy string
+
+const (
+	// StartupFailurePolicyRetry restarts the workspace pod after an exponential backoff
+	StartupFailurePolicyRetry StartupFailurePolicy = "Retry"
+	// StartupFailurePolicyStop sets the workspace desiredStatus to Stopped so it does not hold resources
+	StartupFailurePolicyStop StartupFailurePolicy = "Stop"
+)
+
+// StartupTimeoutSpec defines how long a workspace may take to become available
+type StartupTimeoutSpec struct {
+	// TimeoutInSeconds is how long the workspace may take to become available once it is started
+	// +kubebuilder:validation:Minimum=30
+	TimeoutInSeconds int32 `json:"timeoutInSeconds"`
+
+	// FailurePolicy decides what happens when the timeout is reached
+	// +kubebuilder:validation:Enum=Retry;Stop
+	// +kubebuilder:default=Retry
+	// +optional
+	FailurePolicy StartupFailurePolicy `json:"failurePolicy,omitempty"`
+}
+
+// PreemptionRestartPolicy defines how a preempted workspace is started again automatically
+type PreemptionRestartPolicy struct {
+	// Enabled turns on the automatic restart of the workspace after it is preempted
+	// A pending restart is canceled when a user updates the workspace while keeping it stopped
+	Enabled bool `json:"enabled"`
+
+	// MaxAttempts is the maximum number of consecutive automatic restarts
+	// The count is reset when the workspace ran for at least 10 minutes before being preempted,
+	// or when it is stopped other than by a preemption
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=3
+	// +optional
+	MaxAttempts int32 `json:"maxAttempts,omitempty"`
+
+	// BackoffSeconds is the delay between the preemption and the first restart,
+	// doubled for each further consecutive attempt
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=30
+	// +optional
+	BackoffSeconds int32 `json:"backoffSeconds,omitempty"`
+
+	// MaxBackoffSeconds caps the delay before a restart
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=600
+	// +optional
+	MaxBackoffSeconds int32 `json:"maxBackoffSeconds,omitempty"`
+
+	// PriorityClassName is applied to the workspace pods once the workspace was restarted
+	// after a preemption, to make further preemptions less likely
+	// It must be allowed by the template, workspaces without a template cannot set it
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
 }
 
 // WorkspaceSpec defines the desired state of Workspace
@@ -131,10 +564,20 @@ type WorkspaceSpec struct {
 	// Storage specifies the storage configuration
 	Storage *StorageSpec `json:"storage,omitempty"`
 
-	// Volumes specifies additional volumes to mount from existing PersistantVolumeClaims
+	// Volumes specifies additional volumes to mount from existing PersistantVolumeClaims, ConfigMaps or Secrets,
+	// or from CSI drivers
 	// +kubebuilder:validation:XValidation:rule="!self.exists(v, v.name == 'workspace-storage')",message="volume name 'workspace-storage' is reserved"
 	Volumes []VolumeSpec `json:"volumes,omitempty"`
 
+	// SecondaryStorages specifies additional volumes provisioned by the controller next to the primary storage.
+	// Removed storages are retained and adopted again when added back; on workspace deletion they follow
+	// spec.storage.persistentVolumeClaimRetentionPolicy
+	// +listType=map
+	// +listMapKey=name
+	// +kubebuilder:validation:MaxItems=16
+	// +optional
+	SecondaryStorages []SecondaryStorageSpec `json:"secondaryStorages,omitempty"`
+
 	// ContainerConfig specifies container command and args configuration
 	ContainerConfig *ContainerConfig `json:"containerConfig,omitempty"`
 
@@ -152,10 +595,21 @@ type WorkspaceSpec struct {
 	// Tolerations specifies tolerations for the workspace pod to schedule on nodes with matching taints
 	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
 
+	// PriorityClassName specifies the PriorityClass of the workspace pod
+	// It must be one of the template allowed priority classes, workspaces without a template cannot set it
+	// +kubebuilder:validation:MaxLength=253
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
 	// Lifecycle specifies actions that the management system should take
 	// in response to container lifecycle events (for instance, lifecycle hooks)
 	Lifecycle *corev1.Lifecycle `json:"lifecycle,omitempty"`
 
+	// Probes specifies the readiness, liveness and startup probes of the workspace container
+	// Probes that are not set use the defaults of the workspace AppType
+	// +optional
+	Probes *ProbesSpec `json:"probes,omitempty"`
+
 	// AccessStrategy specifies the WorkspaceAccessStrategy to use
 	// +optional
 	AccessStrategy *AccessStrategyRef `json:"accessStrategy,omitempty"`
@@ -165,10 +619,36 @@ type WorkspaceSpec struct {
 	// +optional
 	TemplateRef *TemplateRef `json:"templateRef,omitempty"`
 
+	// CloneFrom references an existing Workspace in the same namespace to clone
+	// The source spec is copied into unset fields on creation and the primary storage
+	// is provisioned as a CSI clone of the source workspace PVC
+	// Only the creator of the source may clone it, unless the source is annotated
+	// with workspace.jupyter.org/allow-clone=true
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="cloneFrom is immutable"
+	// +optional
+	CloneFrom *CloneSource `json:"cloneFrom,omitempty"`
+
 	// IdleShutdown specifies idle shutdown configuration
 	// +optional
 	IdleShutdown *IdleShutdownSpec `json:"idleShutdown,omitempty"`
 
+	// Schedule specifies cron-style start and stop times for the workspace
+	// +optional
+	Schedule *ScheduleSpec `json:"schedule,omitempty"`
+
+	// LifecyclePolicy specifies when the workspace is deleted automatically
+	// Workspaces with the workspace.jupyter.org/deletion-protection annotation set to "true" are never deleted by it
+	// +optional
+	LifecyclePolicy *LifecyclePolicySpec `json:"lifecyclePolicy,omitempty"`
+
+	// StartupTimeout specifies how long the workspace may take to start before it is marked StartupFailed
+	// +optional
+	StartupTimeout *StartupTimeoutSpec `json:"startupTimeout,omitempty"`
+
+	// PreemptionRestart specifies whether and how the workspace is restarted after its pod is preempted
+	// +optional
+	PreemptionRestart *PreemptionRestartPolicy `json:"preemptionRestart,omitempty"`
+
 	// AppType specifies the application type for this workspace
 	// +optional
 	AppType string `json:"appType,omitempty"`
@@ -187,6 +667,32 @@ type WorkspaceSpec struct {
 	// Overrides template defaults when specified
 	// +optional
 	ContainerSecurityContext *corev1.SecurityContext `json:"containerSecurityContext,omitempty"`
+
+	// ComputeMode specifies the kind of resource running the workspace pod, Deployment when empty
+	// It can only be changed while the workspace is stopped
+	// +kubebuilder:validation:Enum=Deployment;StatefulSet;Pod
+	// +optional
+	ComputeMode ComputeMode `json:"computeMode,omitempty"`
+}
+
+// ProbesSpec defines the probes of the workspace container
+type ProbesSpec struct {
+	// DisableDefaults skips the default probes of the workspace AppType,
+	// so that only the probes set here are applied
+	// +optional
+	DisableDefaults bool `json:"disableDefaults,omitempty"`
+
+	// ReadinessProbe decides when the workspace receives traffic and becomes Available
+	// +optional
+	ReadinessProbe *corev1.Probe `json:"readinessProbe,omitempty"`
+
+	// LivenessProbe decides when the workspace container is restarted
+	// +optional
+	LivenessProbe *corev1.Probe `json:"livenessProbe,omitempty"`
+
+	// StartupProbe holds off the other probes until the application has started
+	// +optional
+	StartupProbe *corev1.Probe `json:"startupProbe,omitempty"`
 }
 
 // AccessResourceStatus defines the status of a resource created from a template
@@ -204,6 +710,58 @@ type AccessResourceStatus struct {
 	Namespace string `json:"namespace"`
 }
 
+// ContainerTerminationStatus records how the workspace container last terminated
+type ContainerTerminationStatus struct {
+	// ContainerName is the name of the container that terminated
+	ContainerName string `json:"containerName"`
+
+	// ExitCode is the exit code of the terminated container
+	ExitCode int32 `json:"exitCode"`
+
+	// Reason is a brief reason for the termination, e.g. OOMKilled or Error
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// Message is the termination message reported by the kubelet
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// FinishedAt is the time at which the container terminated
+	// +optional
+	FinishedAt *metav1.Time `json:"finishedAt,omitempty"`
+
+	// RestartCount is the number of times the container had restarted when the termination was recorded
+	// +optional
+	RestartCount int32 `json:"restartCount,omitempty"`
+}
+
+// WorkspacePhase is a summary of the lifecycle state of a workspace
+type WorkspacePhase string
+
+const (
+	// WorkspacePhaseStarting means the workspace resources are being created or are not ready yet
+	WorkspacePhaseStarting WorkspacePhase = "Starting"
+	// WorkspacePhaseRunning means the workspace is available
+	WorkspacePhaseRunning WorkspacePhase = "Running"
+	// WorkspacePhaseStopping means the workspace resources are being removed
+	WorkspacePhaseStopping WorkspacePhase = "Stopping"
+	// WorkspacePhaseStopped means the workspace compute resources are removed
+	WorkspacePhaseStopped WorkspacePhase = "Stopped"
+	// WorkspacePhaseDeleting means the workspace is being deleted
+	WorkspacePhaseDeleting WorkspacePhase = "Deleting"
+)
+
+// PreemptionRecord describes a preemption of the workspace
+type PreemptionRecord struct {
+	// PreemptedAt is the time at which the workspace pod was preempted
+	PreemptedAt metav1.Time `json:"preemptedAt"`
+
+	// RestartedAt is the time at which the workspace was restarted automatically
+	// It is unset when the workspace was not restarted
+	// +optional
+	RestartedAt *metav1.Time `json:"restartedAt,omitempty"`
+}
+
 // WorkspaceStatus defines the observed state of Workspace.
 type WorkspaceStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
@@ -212,7 +770,20 @@ type WorkspaceStatus struct {
 	// For Kubernetes API conventions, see:
 	// https://github.com/kubernetes/community/blob/master/contributors/devel/sig-architecture/api-conventions.md#typical-status-properties
 
-	// DeploymentName is the name of the deployment managing the Workspace pods
+	// ObservedGeneration is the most recent generation of the workspace spec reconciled by the controller
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Phase is a summary of the lifecycle state of the workspace
+	// The conditions hold the detailed state
+	// +optional
+	Phase WorkspacePhase `json:"phase,omitempty"`
+
+	// PhaseTransitionTime is the time at which the workspace entered its current phase
+	// +optional
+	PhaseTransitionTime *metav1.Time `json:"phaseTransitionTime,omitempty"`
+
+	// DeploymentName is the name of the deployment, statefulset or pod running the Workspace, depending on its compute mode
 	// +optional
 	DeploymentName string `json:"deploymentName,omitempty"`
 
@@ -234,6 +805,70 @@ type WorkspaceStatus struct {
 	// +optional
 	AccessResources []AccessResourceStatus `json:"accessResources,omitempty"`
 
+	// LastScheduleTime is the most recent start or stop schedule boundary applied by the controller
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+
+	// LastSnapshotName is the name of the most recent VolumeSnapshot taken of the workspace storage
+	// +optional
+	LastSnapshotName string `json:"lastSnapshotName,omitempty"`
+
+	// LastSnapshotTime is the time at which the most recent VolumeSnapshot was requested
+	// +optional
+	LastSnapshotTime *metav1.Time `json:"lastSnapshotTime,omitempty"`
+
+	// StorageCapacity is the capacity of the workspace PVC as reported by its status,
+	// which lags behind spec.storage.size while the volume is being expanded
+	// +optional
+	StorageCapacity *resource.Quantity `json:"storageCapacity,omitempty"`
+
+	// StartedAt is the time at which the workspace last reached the Running state
+	// +optional
+	StartedAt *metav1.Time `json:"startedAt,omitempty"`
+
+	// StoppedAt is the time at which the workspace last reached the Stopped state
+	// It is cleared when the workspace starts again
+	// +optional
+	StoppedAt *metav1.Time `json:"stoppedAt,omitempty"`
+
+	// LastActivityTime is the time of the last authenticated request to the workspace
+	// recorded by the auth middleware, updated at most once per update interval
+	// +optional
+	LastActivityTime *metav1.Time `json:"lastActivityTime,omitempty"`
+
+	// LastConnectedBy is the user who made the last connection to the workspace
+	// recorded by the auth middleware
+	// +optional
+	LastConnectedBy string `json:"lastConnectedBy,omitempty"`
+
+	// LastConnectedAt is the time of the last connection to the workspace
+	// +optional
+	LastConnectedAt *metav1.Time `json:"lastConnectedAt,omitempty"`
+
+	// LastTermination describes the most recent termination of a workspace container,
+	// e.g. a crash or an out-of-memory kill
+	// +optional
+	LastTermination *ContainerTerminationStatus `json:"lastTermination,omitempty"`
+
+	// StartupStartedAt is the time at which the current startup attempt began
+	// It is cleared once the workspace is running or stopped
+	// +optional
+	StartupStartedAt *metav1.Time `json:"startupStartedAt,omitempty"`
+
+	// StartupFailures is the number of consecutive startup attempts that exceeded the startup timeout
+	// It is reset once the workspace is running or started again
+	// +optional
+	StartupFailures int32 `json:"startupFailures,omitempty"`
+
+	// PreemptionRestarts is the number of consecutive automatic restarts after a preemption
+	// The workspace pods use the priority class of preemption restarts while it is not zero
+	// +optional
+	PreemptionRestarts int32 `json:"preemptionRestarts,omitempty"`
+
+	// Preemptions is the history of the most recent preemptions of the workspace, oldest first
+	// +optional
+	Preemptions []PreemptionRecord `json:"preemptions,omitempty"`
+
 	// Conditions represent the current state of the Workspace resource.
 	// Each condition has a unique type and reflects the status of a specific aspect of the resource.
 	//
@@ -254,12 +889,16 @@ type WorkspaceStatus struct {
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+// +kubebuilder:printcolumn:name="In-Phase",type="date",JSONPath=".status.phaseTransitionTime"
 // +kubebuilder:printcolumn:name="Available",type="string",JSONPath=".status.conditions[?(@.type==\"Available\")].status"
 // +kubebuilder:printcolumn:name="Progressing",type="string",JSONPath=".status.conditions[?(@.type==\"Progressing\")].status"
 // +kubebuilder:printcolumn:name="Degraded",type="string",JSONPath=".status.conditions[?(@.type==\"Degraded\")].status"
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 // +kubebuilder:printcolumn:name="CreatedBy",type="string",JSONPath=`.metadata.annotations['workspace\.jupyter\.org/created-by']`,priority=1
 // +kubebuilder:printcolumn:name="AccessType",type="string",JSONPath=".spec.accessType",priority=1
+// +kubebuilder:printcolumn:name="LastConnectedBy",type="string",JSONPath=".status.lastConnectedBy",priority=1
+// +kubebuilder:printcolumn:name="LastActivity",type="date",JSONPath=".status.lastActivityTime",priority=1
 
 // Workspace is the Schema for the workspaces API
 type Workspace struct {
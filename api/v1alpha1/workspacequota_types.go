@@ -0,0 +1,158 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// WorkspaceQuotaScope defines which workspaces are counted against a WorkspaceQuota
+// +kubebuilder:validation:Enum=Namespace;Cluster
+type WorkspaceQuotaScope string
+
+const (
+	// WorkspaceQuotaScopeNamespace counts the workspaces in the namespace of the quota
+	WorkspaceQuotaScopeNamespace WorkspaceQuotaScope = "Namespace"
+	// WorkspaceQuotaScopeCluster counts the workspaces in all namespaces.
+	// Cluster quotas are only enforced when they are created in the controller namespace.
+	WorkspaceQuotaScopeCluster WorkspaceQuotaScope = "Cluster"
+)
+
+// WorkspaceQuotaLimits defines the limits applied to the workspaces of a user or a group
+type WorkspaceQuotaLimits struct {
+	// MaxWorkspaces limits the number of workspaces, running or stopped
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxWorkspaces *int32 `json:"maxWorkspaces,omitempty"`
+
+	// MaxRunningWorkspaces limits the number of workspaces whose desired status is Running
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxRunningWorkspaces *int32 `json:"maxRunningWorkspaces,omitempty"`
+
+	// Resources limits the total requested resources.
+	// cpu and memory count the requests of running workspaces,
+	// storage counts the storage size of all workspaces.
+	// +optional
+	Resources corev1.ResourceList `json:"resources,omitempty"`
+}
+
+// GroupQuota defines the limits shared by all the members of a group
+type GroupQuota struct {
+	// Group is the name of the group, as reported by the API server authentication
+	// +kubebuilder:validation:MinLength=1
+	Group string `json:"group"`
+
+	WorkspaceQuotaLimits `json:",inline"`
+}
+
+// WorkspaceQuotaSpec defines the desired state of WorkspaceQuota
+type WorkspaceQuotaSpec struct {
+	// Scope defines which workspaces are counted against the quota
+	// +kubebuilder:default=Namespace
+	// +optional
+	Scope WorkspaceQuotaScope `json:"scope,omitempty"`
+
+	// PerUser defines the limits applied to each user, who is identified
+	// by the workspace.jupyter.org/created-by annotation of the workspaces
+	// +optional
+	PerUser *WorkspaceQuotaLimits `json:"perUser,omitempty"`
+
+	// PerGroup defines the limits applied to the combined workspaces of the members of each group
+	// +listType=map
+	// +listMapKey=group
+	// +optional
+	PerGroup []GroupQuota `json:"perGroup,omitempty"`
+}
+
+// WorkspaceQuotaUsage defines the workspaces and resources counted against a quota
+type WorkspaceQuotaUsage struct {
+	// Workspaces is the number of workspaces
+	Workspaces int32 `json:"workspaces"`
+
+	// RunningWorkspaces is the number of workspaces whose desired status is Running
+	RunningWorkspaces int32 `json:"runningWorkspaces"`
+
+	// Resources is the total of requested resources
+	// +optional
+	Resources corev1.ResourceList `json:"resources,omitempty"`
+}
+
+// UserQuotaUsage reports the usage of a user
+type UserQuotaUsage struct {
+	// User is the sanitized name of the user
+	User string `json:"user"`
+
+	WorkspaceQuotaUsage `json:",inline"`
+}
+
+// GroupQuotaUsage reports the usage of a group
+type GroupQuotaUsage struct {
+	// Group is the name of the group
+	Group string `json:"group"`
+
+	WorkspaceQuotaUsage `json:",inline"`
+}
+
+// WorkspaceQuotaStatus defines the observed state of WorkspaceQuota
+type WorkspaceQuotaStatus struct {
+	// ObservedGeneration is the most recent generation observed by the controller
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Users reports the usage of each user owning workspaces in scope, when per-user limits are set
+	// +listType=map
+	// +listMapKey=user
+	// +optional
+	Users []UserQuotaUsage `json:"users,omitempty"`
+
+	// Groups reports the usage of each group with limits
+	// +listType=map
+	// +listMapKey=group
+	// +optional
+	Groups []GroupQuotaUsage `json:"groups,omitempty"`
+
+	// Conditions represent the latest available observations of the quota's state
+	// +listType=map
+	// +listMapKey=type
+	// +patchStrategy=merge
+	// +patchMergeKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Scope",type="string",JSONPath=".spec.scope"
+// +kubebuilder:printcolumn:name="Exceeded",type="string",JSONPath=".status.conditions[?(@.type==\"Exceeded\")].status"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// WorkspaceQuota is the Schema for the workspacequotas API
+type WorkspaceQuota struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec defines the desired state of WorkspaceQuota
+	Spec WorkspaceQuotaSpec `json:"spec"`
+
+	// Status defines the observed state of WorkspaceQuota
+	// +optional
+	Status WorkspaceQuotaStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// WorkspaceQuotaList contains a list of WorkspaceQuota
+type WorkspaceQuotaList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []WorkspaceQuota `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&WorkspaceQuota{}, &WorkspaceQuotaList{})
+}
@@ -71,14 +71,24 @@ type WorkspaceTemplateSpec struct {
 	EnvRequirements []EnvRequirement `json:"envRequirements,omitempty"`
 
 	// AllowSecondaryStorages controls whether workspaces using this template
-	// can mount additional storage volumes beyond the primary storage
+	// can mount additional storage volumes or declare secondary storages beyond the primary storage
 	// +kubebuilder:default=true
 	// +optional
 	AllowSecondaryStorages *bool `json:"allowSecondaryStorages,omitempty"`
 
+	// SecondaryStorage caps the secondary storages provisioned by the controller for workspaces using this template
+	// +optional
+	SecondaryStorage *SecondaryStorageConfig `json:"secondaryStorage,omitempty"`
+
+	// VolumeSources restricts the sources of the additional volumes of workspaces using this template.
+	// Secret and CSI sources are denied when unset
+	// +optional
+	VolumeSources *VolumeSourcePolicy `json:"volumeSources,omitempty"`
+
 	// DefaultVolumes specifies default additional volumes for workspaces using this template
 	// Volumes are applied during defaulting only if the workspace does not specify any volumes
-	// Each volume references a pre-existing PVC by name in the workspace's namespace
+	// Each volume references a pre-existing PVC, ConfigMap or Secret by name in the workspace's namespace,
+	// or a CSI driver
 	// +kubebuilder:validation:MaxItems=10
 	// +optional
 	DefaultVolumes []VolumeSpec `json:"defaultVolumes,omitempty"`
@@ -95,6 +105,18 @@ type WorkspaceTemplateSpec struct {
 	// +optional
 	DefaultTolerations []corev1.Toleration `json:"defaultTolerations,omitempty"`
 
+	// DefaultPriorityClassName specifies the default PriorityClass of workspace pods
+	// +kubebuilder:validation:MaxLength=253
+	// +optional
+	DefaultPriorityClassName string `json:"defaultPriorityClassName,omitempty"`
+
+	// AllowedPriorityClassNames is a list of PriorityClasses that workspaces can use with this template
+	// If empty, only DefaultPriorityClassName is allowed (secure by default)
+	// The priority class of the preemption restart policy must also be in this list
+	// +kubebuilder:validation:MaxItems=20
+	// +optional
+	AllowedPriorityClassNames []string `json:"allowedPriorityClassNames,omitempty"`
+
 	// DefaultOwnershipType specifies default ownershipType for workspaces using this template
 	// OwnershipType controls which users may edit/delete the workspace
 	// +kubebuilder:validation:Enum=Public;OwnerOnly
@@ -122,6 +144,28 @@ type WorkspaceTemplateSpec struct {
 	// IdleShutdownOverrides controls override behavior and bounds
 	// +optional
 	IdleShutdownOverrides *IdleShutdownOverridePolicy `json:"idleShutdownOverrides,omitempty"`
+
+	// DefaultSchedule provides default start/stop schedules for workspaces using this template
+	// +optional
+	DefaultSchedule *ScheduleSpec `json:"defaultSchedule,omitempty"`
+
+	// ScheduleOverrides controls whether and how workspaces can override the default schedule
+	// +optional
+	ScheduleOverrides *ScheduleOverridePolicy `json:"scheduleOverrides,omitempty"`
+
+	// DefaultLifecyclePolicy provides the default lifecycle policy for workspaces using this template
+	// Workspaces may shorten the configured durations but not extend or remove them
+	// +optional
+	DefaultLifecyclePolicy *LifecyclePolicySpec `json:"defaultLifecyclePolicy,omitempty"`
+
+	// DefaultStartupTimeout provides the default startup timeout for workspaces using this template
+	// +optional
+	DefaultStartupTimeout *StartupTimeoutSpec `json:"defaultStartupTimeout,omitempty"`
+
+	// DefaultPreemptionRestart provides the default preemption restart policy for workspaces using this template
+	// +optional
+	DefaultPreemptionRestart *PreemptionRestartPolicy `json:"defaultPreemptionRestart,omitempty"`
+
 	// DefaultAccessType specifies the default accessType for workspaces using this template
 	// AccessType controls which users may create connections to the workspace.
 	// +kubebuilder:validation:Enum=Public;OwnerOnly
@@ -137,6 +181,15 @@ type WorkspaceTemplateSpec struct {
 	// +optional
 	DefaultLifecycle *corev1.Lifecycle `json:"defaultLifecycle,omitempty"`
 
+	// DefaultProbes specifies default probes for the workspace container
+	// Probes that are not set use the defaults of the workspace AppType
+	// +optional
+	DefaultProbes *ProbesSpec `json:"defaultProbes,omitempty"`
+
+	// ProbeOverrides controls whether and how workspaces can override the default probes
+	// +optional
+	ProbeOverrides *ProbeOverridePolicy `json:"probeOverrides,omitempty"`
+
 	// DefaultPodSecurityContext specifies default pod-level security context
 	// +optional
 	DefaultPodSecurityContext *corev1.PodSecurityContext `json:"defaultPodSecurityContext,omitempty"`
@@ -148,6 +201,12 @@ type WorkspaceTemplateSpec struct {
 	// AppType specifies the application type for workspaces using this template
 	// +optional
 	AppType string `json:"appType,omitempty"`
+
+	// ComputeMode specifies the kind of resource running the pod of workspaces using this template
+	// Workspaces using this template must use this compute mode when it is set
+	// +kubebuilder:validation:Enum=Deployment;StatefulSet;Pod
+	// +optional
+	ComputeMode ComputeMode `json:"computeMode,omitempty"`
 }
 
 // TemplateLabel defines a label key-value pair to add to workspaces
@@ -258,8 +317,85 @@ type StorageConfig struct {
 	// +kubebuilder:default="/home/jovyan"
 	// +optional
 	DefaultMountPath string `json:"defaultMountPath,omitempty"`
+
+	// DefaultSnapshotPolicy is the default VolumeSnapshot policy for the storage
+	// +optional
+	DefaultSnapshotPolicy *SnapshotPolicy `json:"defaultSnapshotPolicy,omitempty"`
+
+	// DefaultPersistentVolumeClaimRetentionPolicy is the default retention policy of the storage
+	// when the workspace is deleted
+	// +optional
+	DefaultPersistentVolumeClaimRetentionPolicy *PersistentVolumeClaimRetentionPolicy `json:"defaultPersistentVolumeClaimRetentionPolicy,omitempty"`
+
+	// EphemeralStorage controls whether workspaces use ephemeral storage, Allowed when empty.
+	// Workspaces of templates requiring it default to EmptyDir storage.
+	// +kubebuilder:validation:Enum=Allowed;Required;Forbidden
+	// +optional
+	EphemeralStorage EphemeralStoragePolicy `json:"ephemeralStorage,omitempty"`
 }
 
+// VolumeSourcePolicy defines the sources workspaces may mount as additional volumes
+type VolumeSourcePolicy struct {
+	// AllowedTypes lists the source types workspaces may mount.
+	// PersistentVolumeClaim and ConfigMap sources are allowed when empty,
+	// Secret and CSI sources are only allowed when listed.
+	// +listType=set
+	// +optional
+	AllowedTypes []VolumeSourceType `json:"allowedTypes,omitempty"`
+
+	// AllowedPersistentVolumeClaims lists the PVCs workspaces may mount, any PVC is allowed when empty
+	// +listType=set
+	// +optional
+	AllowedPersistentVolumeClaims []string `json:"allowedPersistentVolumeClaims,omitempty"`
+
+	// AllowedConfigMaps lists the ConfigMaps workspaces may mount, any ConfigMap is allowed when empty
+	// +listType=set
+	// +optional
+	AllowedConfigMaps []string `json:"allowedConfigMaps,omitempty"`
+
+	// AllowedSecrets lists the Secrets workspaces may mount, any Secret is allowed when empty
+	// +listType=set
+	// +optional
+	AllowedSecrets []string `json:"allowedSecrets,omitempty"`
+
+	// AllowedCSIDrivers lists the CSI drivers workspaces may mount volumes of, any driver is allowed when empty
+	// +listType=set
+	// +optional
+	AllowedCSIDrivers []string `json:"allowedCSIDrivers,omitempty"`
+}
+
+// SecondaryStorageConfig defines the bounds of the secondary storages of workspaces
+type SecondaryStorageConfig struct {
+	// MaxCount is the maximum number of secondary storages of a workspace
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxCount *int32 `json:"maxCount,omitempty"`
+
+	// MinSize is the minimum allowed size of each secondary storage
+	// +optional
+	MinSize *resource.Quantity `json:"minSize,omitempty"`
+
+	// MaxSize is the maximum allowed size of each secondary storage
+	// +optional
+	MaxSize *resource.Quantity `json:"maxSize,omitempty"`
+
+	// DefaultStorageClassName is the default storage class name of secondary storages
+	// +optional
+	DefaultStorageClassName *string `json:"defaultStorageClassName,omitempty"`
+}
+
+// EphemeralStoragePolicy controls whether workspaces of a template use ephemeral storage
+type EphemeralStoragePolicy string
+
+const (
+	// EphemeralStorageAllowed lets workspaces choose their storage type
+	EphemeralStorageAllowed EphemeralStoragePolicy = "Allowed"
+	// EphemeralStorageRequired requires the EmptyDir or EphemeralVolume storage type
+	EphemeralStorageRequired EphemeralStoragePolicy = "Required"
+	// EphemeralStorageForbidden requires the Persistent storage type
+	EphemeralStorageForbidden EphemeralStoragePolicy = "Forbidden"
+)
+
 // IdleShutdownOverridePolicy defines idle shutdown override constraints
 type IdleShutdownOverridePolicy struct {
 	// Allow controls whether workspaces can override idle shutdown
@@ -274,6 +410,51 @@ type IdleShutdownOverridePolicy struct {
 	// MaxIdleTimeoutInMinutes is the maximum allowed timeout
 	// +optional
 	MaxIdleTimeoutInMinutes *int `json:"maxIdleTimeoutInMinutes,omitempty"`
+
+	// AllowMetricsDetection controls whether workspaces can use metrics-based idle detection
+	// +kubebuilder:default=true
+	// +optional
+	AllowMetricsDetection *bool `json:"allowMetricsDetection,omitempty"`
+
+	// MinCPUThreshold is the minimum allowed CPU threshold for metrics-based idle detection
+	// +optional
+	MinCPUThreshold *resource.Quantity `json:"minCPUThreshold,omitempty"`
+
+	// MaxCPUThreshold is the maximum allowed CPU threshold for metrics-based idle detection
+	// +optional
+	MaxCPUThreshold *resource.Quantity `json:"maxCPUThreshold,omitempty"`
+
+	// AllowedPrometheusURLs restricts the Prometheus APIs workspaces may use for idle detection
+	// When empty, any URL allowed by the operator with IDLE_PROMETHEUS_ALLOWED_URLS is allowed
+	// +optional
+	AllowedPrometheusURLs []string `json:"allowedPrometheusURLs,omitempty"`
+}
+
+// ScheduleOverridePolicy defines schedule override constraints
+type ScheduleOverridePolicy struct {
+	// Allow controls whether workspaces can override the default schedule
+	// +kubebuilder:default=true
+	// +optional
+	Allow *bool `json:"allow,omitempty"`
+
+	// AllowStartSchedule controls whether workspaces can define a start schedule
+	// When false, workspaces may only be stopped on a schedule
+	// +kubebuilder:default=true
+	// +optional
+	AllowStartSchedule *bool `json:"allowStartSchedule,omitempty"`
+}
+
+// ProbeOverridePolicy defines how workspaces can override the template default probes
+type ProbeOverridePolicy struct {
+	// Allow controls whether workspaces can override the default probes
+	// +kubebuilder:default=true
+	// +optional
+	Allow *bool `json:"allow,omitempty"`
+
+	// MinPeriodSeconds is the minimum period allowed for workspace probes
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinPeriodSeconds *int32 `json:"minPeriodSeconds,omitempty"`
 }
 
 // WorkspaceTemplateStatus defines the observed state of WorkspaceTemplate
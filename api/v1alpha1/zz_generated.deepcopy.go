@@ -10,8 +10,8 @@ Distributed under the terms of the MIT license
 package v1alpha1
 
 import (
-	"k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -75,6 +75,21 @@ func (in *AccessStrategyRef) DeepCopy() *AccessStrategyRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloneSource) DeepCopyInto(out *CloneSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloneSource.
+func (in *CloneSource) DeepCopy() *CloneSource {
+	if in == nil {
+		return nil
+	}
+	out := new(CloneSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ContainerConfig) DeepCopyInto(out *ContainerConfig) {
 	*out = *in
@@ -100,6 +115,25 @@ func (in *ContainerConfig) DeepCopy() *ContainerConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerTerminationStatus) DeepCopyInto(out *ContainerTerminationStatus) {
+	*out = *in
+	if in.FinishedAt != nil {
+		in, out := &in.FinishedAt, &out.FinishedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerTerminationStatus.
+func (in *ContainerTerminationStatus) DeepCopy() *ContainerTerminationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerTerminationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeploymentModifications) DeepCopyInto(out *DeploymentModifications) {
 	*out = *in
@@ -140,12 +174,64 @@ func (in *EnvRequirement) DeepCopy() *EnvRequirement {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GroupQuota) DeepCopyInto(out *GroupQuota) {
+	*out = *in
+	in.WorkspaceQuotaLimits.DeepCopyInto(&out.WorkspaceQuotaLimits)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GroupQuota.
+func (in *GroupQuota) DeepCopy() *GroupQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(GroupQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GroupQuotaUsage) DeepCopyInto(out *GroupQuotaUsage) {
+	*out = *in
+	in.WorkspaceQuotaUsage.DeepCopyInto(&out.WorkspaceQuotaUsage)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GroupQuotaUsage.
+func (in *GroupQuotaUsage) DeepCopy() *GroupQuotaUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(GroupQuotaUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IdleDetectionSpec) DeepCopyInto(out *IdleDetectionSpec) {
 	*out = *in
 	if in.HTTPGet != nil {
 		in, out := &in.HTTPGet, &out.HTTPGet
-		*out = new(v1.HTTPGetAction)
+		*out = new(corev1.HTTPGetAction)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.JupyterAPI != nil {
+		in, out := &in.JupyterAPI, &out.JupyterAPI
+		*out = new(JupyterAPIIdleDetection)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = new(MetricsIdleDetection)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ProxyActivity != nil {
+		in, out := &in.ProxyActivity, &out.ProxyActivity
+		*out = new(ProxyActivityIdleDetection)
+		**out = **in
+	}
+	if in.Probe != nil {
+		in, out := &in.Probe, &out.Probe
+		*out = new(IdleProbeSpec)
 		(*in).DeepCopyInto(*out)
 	}
 }
@@ -160,6 +246,46 @@ func (in *IdleDetectionSpec) DeepCopy() *IdleDetectionSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IdleProbeSpec) DeepCopyInto(out *IdleProbeSpec) {
+	*out = *in
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(IdleProbeTLSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IdleProbeSpec.
+func (in *IdleProbeSpec) DeepCopy() *IdleProbeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IdleProbeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IdleProbeTLSConfig) DeepCopyInto(out *IdleProbeTLSConfig) {
+	*out = *in
+	if in.CABundle != nil {
+		in, out := &in.CABundle, &out.CABundle
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IdleProbeTLSConfig.
+func (in *IdleProbeTLSConfig) DeepCopy() *IdleProbeTLSConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(IdleProbeTLSConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IdleShutdownOverridePolicy) DeepCopyInto(out *IdleShutdownOverridePolicy) {
 	*out = *in
@@ -178,6 +304,26 @@ func (in *IdleShutdownOverridePolicy) DeepCopyInto(out *IdleShutdownOverridePoli
 		*out = new(int)
 		**out = **in
 	}
+	if in.AllowMetricsDetection != nil {
+		in, out := &in.AllowMetricsDetection, &out.AllowMetricsDetection
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MinCPUThreshold != nil {
+		in, out := &in.MinCPUThreshold, &out.MinCPUThreshold
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.MaxCPUThreshold != nil {
+		in, out := &in.MaxCPUThreshold, &out.MaxCPUThreshold
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.AllowedPrometheusURLs != nil {
+		in, out := &in.AllowedPrometheusURLs, &out.AllowedPrometheusURLs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IdleShutdownOverridePolicy.
@@ -206,6 +352,27 @@ func (in *IdleShutdownSpec) DeepCopy() *IdleShutdownSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JupyterAPIIdleDetection) DeepCopyInto(out *JupyterAPIIdleDetection) {
+	*out = *in
+	out.Port = in.Port
+	if in.CountTerminals != nil {
+		in, out := &in.CountTerminals, &out.CountTerminals
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JupyterAPIIdleDetection.
+func (in *JupyterAPIIdleDetection) DeepCopy() *JupyterAPIIdleDetection {
+	if in == nil {
+		return nil
+	}
+	out := new(JupyterAPIIdleDetection)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LabelRequirement) DeepCopyInto(out *LabelRequirement) {
 	*out = *in
@@ -226,26 +393,97 @@ func (in *LabelRequirement) DeepCopy() *LabelRequirement {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LifecyclePolicySpec) DeepCopyInto(out *LifecyclePolicySpec) {
+	*out = *in
+	if in.DeleteAfterStoppedInDays != nil {
+		in, out := &in.DeleteAfterStoppedInDays, &out.DeleteAfterStoppedInDays
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxLifetimeInDays != nil {
+		in, out := &in.MaxLifetimeInDays, &out.MaxLifetimeInDays
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LifecyclePolicySpec.
+func (in *LifecyclePolicySpec) DeepCopy() *LifecyclePolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LifecyclePolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricsIdleDetection) DeepCopyInto(out *MetricsIdleDetection) {
+	*out = *in
+	out.CPUThreshold = in.CPUThreshold.DeepCopy()
+	if in.NetworkThresholdBytesPerSecond != nil {
+		in, out := &in.NetworkThresholdBytesPerSecond, &out.NetworkThresholdBytesPerSecond
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Prometheus != nil {
+		in, out := &in.Prometheus, &out.Prometheus
+		*out = new(PrometheusMetricsSource)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricsIdleDetection.
+func (in *MetricsIdleDetection) DeepCopy() *MetricsIdleDetection {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricsIdleDetection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PersistentVolumeClaimRetentionPolicy) DeepCopyInto(out *PersistentVolumeClaimRetentionPolicy) {
+	*out = *in
+	if in.RetainDuration != nil {
+		in, out := &in.RetainDuration, &out.RetainDuration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PersistentVolumeClaimRetentionPolicy.
+func (in *PersistentVolumeClaimRetentionPolicy) DeepCopy() *PersistentVolumeClaimRetentionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(PersistentVolumeClaimRetentionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PodModifications) DeepCopyInto(out *PodModifications) {
 	*out = *in
 	if in.AdditionalContainers != nil {
 		in, out := &in.AdditionalContainers, &out.AdditionalContainers
-		*out = make([]v1.Container, len(*in))
+		*out = make([]corev1.Container, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 	if in.Volumes != nil {
 		in, out := &in.Volumes, &out.Volumes
-		*out = make([]v1.Volume, len(*in))
+		*out = make([]corev1.Volume, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 	if in.InitContainers != nil {
 		in, out := &in.InitContainers, &out.InitContainers
-		*out = make([]v1.Container, len(*in))
+		*out = make([]corev1.Container, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -267,12 +505,47 @@ func (in *PodModifications) DeepCopy() *PodModifications {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreemptionRecord) DeepCopyInto(out *PreemptionRecord) {
+	*out = *in
+	in.PreemptedAt.DeepCopyInto(&out.PreemptedAt)
+	if in.RestartedAt != nil {
+		in, out := &in.RestartedAt, &out.RestartedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreemptionRecord.
+func (in *PreemptionRecord) DeepCopy() *PreemptionRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(PreemptionRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreemptionRestartPolicy) DeepCopyInto(out *PreemptionRestartPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreemptionRestartPolicy.
+func (in *PreemptionRestartPolicy) DeepCopy() *PreemptionRestartPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(PreemptionRestartPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PrimaryContainerModifications) DeepCopyInto(out *PrimaryContainerModifications) {
 	*out = *in
 	if in.VolumeMounts != nil {
 		in, out := &in.VolumeMounts, &out.VolumeMounts
-		*out = make([]v1.VolumeMount, len(*in))
+		*out = make([]corev1.VolumeMount, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -289,46 +562,374 @@ func (in *PrimaryContainerModifications) DeepCopy() *PrimaryContainerModificatio
 	if in == nil {
 		return nil
 	}
-	out := new(PrimaryContainerModifications)
+	out := new(PrimaryContainerModifications)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProbeOverridePolicy) DeepCopyInto(out *ProbeOverridePolicy) {
+	*out = *in
+	if in.Allow != nil {
+		in, out := &in.Allow, &out.Allow
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MinPeriodSeconds != nil {
+		in, out := &in.MinPeriodSeconds, &out.MinPeriodSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbeOverridePolicy.
+func (in *ProbeOverridePolicy) DeepCopy() *ProbeOverridePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbeOverridePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProbesSpec) DeepCopyInto(out *ProbesSpec) {
+	*out = *in
+	if in.ReadinessProbe != nil {
+		in, out := &in.ReadinessProbe, &out.ReadinessProbe
+		*out = new(corev1.Probe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LivenessProbe != nil {
+		in, out := &in.LivenessProbe, &out.LivenessProbe
+		*out = new(corev1.Probe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StartupProbe != nil {
+		in, out := &in.StartupProbe, &out.StartupProbe
+		*out = new(corev1.Probe)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbesSpec.
+func (in *ProbesSpec) DeepCopy() *ProbesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrometheusMetricsSource) DeepCopyInto(out *PrometheusMetricsSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusMetricsSource.
+func (in *PrometheusMetricsSource) DeepCopy() *PrometheusMetricsSource {
+	if in == nil {
+		return nil
+	}
+	out := new(PrometheusMetricsSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProxyActivityIdleDetection) DeepCopyInto(out *ProxyActivityIdleDetection) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProxyActivityIdleDetection.
+func (in *ProxyActivityIdleDetection) DeepCopy() *ProxyActivityIdleDetection {
+	if in == nil {
+		return nil
+	}
+	out := new(ProxyActivityIdleDetection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceBounds) DeepCopyInto(out *ResourceBounds) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make(map[corev1.ResourceName]ResourceRange, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceBounds.
+func (in *ResourceBounds) DeepCopy() *ResourceBounds {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceBounds)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceRange) DeepCopyInto(out *ResourceRange) {
+	*out = *in
+	out.Min = in.Min.DeepCopy()
+	out.Max = in.Max.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceRange.
+func (in *ResourceRange) DeepCopy() *ResourceRange {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceRange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleOverridePolicy) DeepCopyInto(out *ScheduleOverridePolicy) {
+	*out = *in
+	if in.Allow != nil {
+		in, out := &in.Allow, &out.Allow
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AllowStartSchedule != nil {
+		in, out := &in.AllowStartSchedule, &out.AllowStartSchedule
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleOverridePolicy.
+func (in *ScheduleOverridePolicy) DeepCopy() *ScheduleOverridePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleOverridePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleSpec) DeepCopyInto(out *ScheduleSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleSpec.
+func (in *ScheduleSpec) DeepCopy() *ScheduleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecondaryStorageConfig) DeepCopyInto(out *SecondaryStorageConfig) {
+	*out = *in
+	if in.MaxCount != nil {
+		in, out := &in.MaxCount, &out.MaxCount
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MinSize != nil {
+		in, out := &in.MinSize, &out.MinSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.MaxSize != nil {
+		in, out := &in.MaxSize, &out.MaxSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.DefaultStorageClassName != nil {
+		in, out := &in.DefaultStorageClassName, &out.DefaultStorageClassName
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecondaryStorageConfig.
+func (in *SecondaryStorageConfig) DeepCopy() *SecondaryStorageConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SecondaryStorageConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecondaryStorageSpec) DeepCopyInto(out *SecondaryStorageSpec) {
+	*out = *in
+	out.Size = in.Size.DeepCopy()
+	if in.StorageClassName != nil {
+		in, out := &in.StorageClassName, &out.StorageClassName
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecondaryStorageSpec.
+func (in *SecondaryStorageSpec) DeepCopy() *SecondaryStorageSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SecondaryStorageSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedVolume) DeepCopyInto(out *SharedVolume) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedVolume.
+func (in *SharedVolume) DeepCopy() *SharedVolume {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedVolume)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SharedVolume) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedVolumeList) DeepCopyInto(out *SharedVolumeList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SharedVolume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedVolumeList.
+func (in *SharedVolumeList) DeepCopy() *SharedVolumeList {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedVolumeList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SharedVolumeList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedVolumeSpec) DeepCopyInto(out *SharedVolumeSpec) {
+	*out = *in
+	out.Size = in.Size.DeepCopy()
+	if in.StorageClassName != nil {
+		in, out := &in.StorageClassName, &out.StorageClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ReadWriteGroups != nil {
+		in, out := &in.ReadWriteGroups, &out.ReadWriteGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedVolumeSpec.
+func (in *SharedVolumeSpec) DeepCopy() *SharedVolumeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedVolumeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedVolumeStatus) DeepCopyInto(out *SharedVolumeStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedVolumeStatus.
+func (in *SharedVolumeStatus) DeepCopy() *SharedVolumeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedVolumeStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ResourceBounds) DeepCopyInto(out *ResourceBounds) {
+func (in *SnapshotPolicy) DeepCopyInto(out *SnapshotPolicy) {
 	*out = *in
-	if in.Resources != nil {
-		in, out := &in.Resources, &out.Resources
-		*out = make(map[v1.ResourceName]ResourceRange, len(*in))
-		for key, val := range *in {
-			(*out)[key] = *val.DeepCopy()
-		}
+	if in.VolumeSnapshotClassName != nil {
+		in, out := &in.VolumeSnapshotClassName, &out.VolumeSnapshotClassName
+		*out = new(string)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceBounds.
-func (in *ResourceBounds) DeepCopy() *ResourceBounds {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotPolicy.
+func (in *SnapshotPolicy) DeepCopy() *SnapshotPolicy {
 	if in == nil {
 		return nil
 	}
-	out := new(ResourceBounds)
+	out := new(SnapshotPolicy)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ResourceRange) DeepCopyInto(out *ResourceRange) {
+func (in *StartupTimeoutSpec) DeepCopyInto(out *StartupTimeoutSpec) {
 	*out = *in
-	out.Min = in.Min.DeepCopy()
-	out.Max = in.Max.DeepCopy()
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceRange.
-func (in *ResourceRange) DeepCopy() *ResourceRange {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StartupTimeoutSpec.
+func (in *StartupTimeoutSpec) DeepCopy() *StartupTimeoutSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ResourceRange)
+	out := new(StartupTimeoutSpec)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -352,6 +953,16 @@ func (in *StorageConfig) DeepCopyInto(out *StorageConfig) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.DefaultSnapshotPolicy != nil {
+		in, out := &in.DefaultSnapshotPolicy, &out.DefaultSnapshotPolicy
+		*out = new(SnapshotPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DefaultPersistentVolumeClaimRetentionPolicy != nil {
+		in, out := &in.DefaultPersistentVolumeClaimRetentionPolicy, &out.DefaultPersistentVolumeClaimRetentionPolicy
+		*out = new(PersistentVolumeClaimRetentionPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageConfig.
@@ -373,6 +984,16 @@ func (in *StorageSpec) DeepCopyInto(out *StorageSpec) {
 		**out = **in
 	}
 	out.Size = in.Size.DeepCopy()
+	if in.Snapshot != nil {
+		in, out := &in.Snapshot, &out.Snapshot
+		*out = new(SnapshotPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PersistentVolumeClaimRetentionPolicy != nil {
+		in, out := &in.PersistentVolumeClaimRetentionPolicy, &out.PersistentVolumeClaimRetentionPolicy
+		*out = new(PersistentVolumeClaimRetentionPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageSpec.
@@ -415,9 +1036,80 @@ func (in *TemplateRef) DeepCopy() *TemplateRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserQuotaUsage) DeepCopyInto(out *UserQuotaUsage) {
+	*out = *in
+	in.WorkspaceQuotaUsage.DeepCopyInto(&out.WorkspaceQuotaUsage)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserQuotaUsage.
+func (in *UserQuotaUsage) DeepCopy() *UserQuotaUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(UserQuotaUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeSourcePolicy) DeepCopyInto(out *VolumeSourcePolicy) {
+	*out = *in
+	if in.AllowedTypes != nil {
+		in, out := &in.AllowedTypes, &out.AllowedTypes
+		*out = make([]VolumeSourceType, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedPersistentVolumeClaims != nil {
+		in, out := &in.AllowedPersistentVolumeClaims, &out.AllowedPersistentVolumeClaims
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedConfigMaps != nil {
+		in, out := &in.AllowedConfigMaps, &out.AllowedConfigMaps
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedSecrets != nil {
+		in, out := &in.AllowedSecrets, &out.AllowedSecrets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedCSIDrivers != nil {
+		in, out := &in.AllowedCSIDrivers, &out.AllowedCSIDrivers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeSourcePolicy.
+func (in *VolumeSourcePolicy) DeepCopy() *VolumeSourcePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeSourcePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VolumeSpec) DeepCopyInto(out *VolumeSpec) {
 	*out = *in
+	if in.ConfigMap != nil {
+		in, out := &in.ConfigMap, &out.ConfigMap
+		*out = new(corev1.ConfigMapVolumeSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Secret != nil {
+		in, out := &in.Secret, &out.Secret
+		*out = new(corev1.SecretVolumeSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CSI != nil {
+		in, out := &in.CSI, &out.CSI
+		*out = new(corev1.CSIVolumeSource)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeSpec.
@@ -567,7 +1259,7 @@ func (in *WorkspaceAccessStrategyStatus) DeepCopyInto(out *WorkspaceAccessStrate
 	*out = *in
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
-		*out = make([]metav1.Condition, len(*in))
+		*out = make([]v1.Condition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -616,12 +1308,188 @@ func (in *WorkspaceList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkspaceQuota) DeepCopyInto(out *WorkspaceQuota) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkspaceQuota.
+func (in *WorkspaceQuota) DeepCopy() *WorkspaceQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkspaceQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *WorkspaceQuota) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkspaceQuotaLimits) DeepCopyInto(out *WorkspaceQuotaLimits) {
+	*out = *in
+	if in.MaxWorkspaces != nil {
+		in, out := &in.MaxWorkspaces, &out.MaxWorkspaces
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxRunningWorkspaces != nil {
+		in, out := &in.MaxRunningWorkspaces, &out.MaxRunningWorkspaces
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkspaceQuotaLimits.
+func (in *WorkspaceQuotaLimits) DeepCopy() *WorkspaceQuotaLimits {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkspaceQuotaLimits)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkspaceQuotaList) DeepCopyInto(out *WorkspaceQuotaList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]WorkspaceQuota, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkspaceQuotaList.
+func (in *WorkspaceQuotaList) DeepCopy() *WorkspaceQuotaList {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkspaceQuotaList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *WorkspaceQuotaList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkspaceQuotaSpec) DeepCopyInto(out *WorkspaceQuotaSpec) {
+	*out = *in
+	if in.PerUser != nil {
+		in, out := &in.PerUser, &out.PerUser
+		*out = new(WorkspaceQuotaLimits)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PerGroup != nil {
+		in, out := &in.PerGroup, &out.PerGroup
+		*out = make([]GroupQuota, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkspaceQuotaSpec.
+func (in *WorkspaceQuotaSpec) DeepCopy() *WorkspaceQuotaSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkspaceQuotaSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkspaceQuotaStatus) DeepCopyInto(out *WorkspaceQuotaStatus) {
+	*out = *in
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make([]UserQuotaUsage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]GroupQuotaUsage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkspaceQuotaStatus.
+func (in *WorkspaceQuotaStatus) DeepCopy() *WorkspaceQuotaStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkspaceQuotaStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkspaceQuotaUsage) DeepCopyInto(out *WorkspaceQuotaUsage) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkspaceQuotaUsage.
+func (in *WorkspaceQuotaUsage) DeepCopy() *WorkspaceQuotaUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkspaceQuotaUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkspaceSpec) DeepCopyInto(out *WorkspaceSpec) {
 	*out = *in
 	if in.Resources != nil {
 		in, out := &in.Resources, &out.Resources
-		*out = new(v1.ResourceRequirements)
+		*out = new(corev1.ResourceRequirements)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.Storage != nil {
@@ -632,7 +1500,16 @@ func (in *WorkspaceSpec) DeepCopyInto(out *WorkspaceSpec) {
 	if in.Volumes != nil {
 		in, out := &in.Volumes, &out.Volumes
 		*out = make([]VolumeSpec, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SecondaryStorages != nil {
+		in, out := &in.SecondaryStorages, &out.SecondaryStorages
+		*out = make([]SecondaryStorageSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.ContainerConfig != nil {
 		in, out := &in.ContainerConfig, &out.ContainerConfig
@@ -641,7 +1518,7 @@ func (in *WorkspaceSpec) DeepCopyInto(out *WorkspaceSpec) {
 	}
 	if in.Env != nil {
 		in, out := &in.Env, &out.Env
-		*out = make([]v1.EnvVar, len(*in))
+		*out = make([]corev1.EnvVar, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -655,19 +1532,24 @@ func (in *WorkspaceSpec) DeepCopyInto(out *WorkspaceSpec) {
 	}
 	if in.Affinity != nil {
 		in, out := &in.Affinity, &out.Affinity
-		*out = new(v1.Affinity)
+		*out = new(corev1.Affinity)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.Tolerations != nil {
 		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]v1.Toleration, len(*in))
+		*out = make([]corev1.Toleration, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 	if in.Lifecycle != nil {
 		in, out := &in.Lifecycle, &out.Lifecycle
-		*out = new(v1.Lifecycle)
+		*out = new(corev1.Lifecycle)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Probes != nil {
+		in, out := &in.Probes, &out.Probes
+		*out = new(ProbesSpec)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.AccessStrategy != nil {
@@ -680,19 +1562,44 @@ func (in *WorkspaceSpec) DeepCopyInto(out *WorkspaceSpec) {
 		*out = new(TemplateRef)
 		**out = **in
 	}
+	if in.CloneFrom != nil {
+		in, out := &in.CloneFrom, &out.CloneFrom
+		*out = new(CloneSource)
+		**out = **in
+	}
 	if in.IdleShutdown != nil {
 		in, out := &in.IdleShutdown, &out.IdleShutdown
 		*out = new(IdleShutdownSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = new(ScheduleSpec)
+		**out = **in
+	}
+	if in.LifecyclePolicy != nil {
+		in, out := &in.LifecyclePolicy, &out.LifecyclePolicy
+		*out = new(LifecyclePolicySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StartupTimeout != nil {
+		in, out := &in.StartupTimeout, &out.StartupTimeout
+		*out = new(StartupTimeoutSpec)
+		**out = **in
+	}
+	if in.PreemptionRestart != nil {
+		in, out := &in.PreemptionRestart, &out.PreemptionRestart
+		*out = new(PreemptionRestartPolicy)
+		**out = **in
+	}
 	if in.PodSecurityContext != nil {
 		in, out := &in.PodSecurityContext, &out.PodSecurityContext
-		*out = new(v1.PodSecurityContext)
+		*out = new(corev1.PodSecurityContext)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.ContainerSecurityContext != nil {
 		in, out := &in.ContainerSecurityContext, &out.ContainerSecurityContext
-		*out = new(v1.SecurityContext)
+		*out = new(corev1.SecurityContext)
 		(*in).DeepCopyInto(*out)
 	}
 }
@@ -710,14 +1617,63 @@ func (in *WorkspaceSpec) DeepCopy() *WorkspaceSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkspaceStatus) DeepCopyInto(out *WorkspaceStatus) {
 	*out = *in
+	if in.PhaseTransitionTime != nil {
+		in, out := &in.PhaseTransitionTime, &out.PhaseTransitionTime
+		*out = (*in).DeepCopy()
+	}
 	if in.AccessResources != nil {
 		in, out := &in.AccessResources, &out.AccessResources
 		*out = make([]AccessResourceStatus, len(*in))
 		copy(*out, *in)
 	}
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSnapshotTime != nil {
+		in, out := &in.LastSnapshotTime, &out.LastSnapshotTime
+		*out = (*in).DeepCopy()
+	}
+	if in.StorageCapacity != nil {
+		in, out := &in.StorageCapacity, &out.StorageCapacity
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.StartedAt != nil {
+		in, out := &in.StartedAt, &out.StartedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.StoppedAt != nil {
+		in, out := &in.StoppedAt, &out.StoppedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.LastActivityTime != nil {
+		in, out := &in.LastActivityTime, &out.LastActivityTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastConnectedAt != nil {
+		in, out := &in.LastConnectedAt, &out.LastConnectedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.LastTermination != nil {
+		in, out := &in.LastTermination, &out.LastTermination
+		*out = new(ContainerTerminationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StartupStartedAt != nil {
+		in, out := &in.StartupStartedAt, &out.StartupStartedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.Preemptions != nil {
+		in, out := &in.Preemptions, &out.Preemptions
+		*out = make([]PreemptionRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
-		*out = make([]metav1.Condition, len(*in))
+		*out = make([]v1.Condition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -808,7 +1764,7 @@ func (in *WorkspaceTemplateSpec) DeepCopyInto(out *WorkspaceTemplateSpec) {
 	}
 	if in.DefaultResources != nil {
 		in, out := &in.DefaultResources, &out.DefaultResources
-		*out = new(v1.ResourceRequirements)
+		*out = new(corev1.ResourceRequirements)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.ResourceBounds != nil {
@@ -828,7 +1784,7 @@ func (in *WorkspaceTemplateSpec) DeepCopyInto(out *WorkspaceTemplateSpec) {
 	}
 	if in.BaseEnv != nil {
 		in, out := &in.BaseEnv, &out.BaseEnv
-		*out = make([]v1.EnvVar, len(*in))
+		*out = make([]corev1.EnvVar, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -845,10 +1801,22 @@ func (in *WorkspaceTemplateSpec) DeepCopyInto(out *WorkspaceTemplateSpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.SecondaryStorage != nil {
+		in, out := &in.SecondaryStorage, &out.SecondaryStorage
+		*out = new(SecondaryStorageConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VolumeSources != nil {
+		in, out := &in.VolumeSources, &out.VolumeSources
+		*out = new(VolumeSourcePolicy)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.DefaultVolumes != nil {
 		in, out := &in.DefaultVolumes, &out.DefaultVolumes
 		*out = make([]VolumeSpec, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.DefaultNodeSelector != nil {
 		in, out := &in.DefaultNodeSelector, &out.DefaultNodeSelector
@@ -859,16 +1827,21 @@ func (in *WorkspaceTemplateSpec) DeepCopyInto(out *WorkspaceTemplateSpec) {
 	}
 	if in.DefaultAffinity != nil {
 		in, out := &in.DefaultAffinity, &out.DefaultAffinity
-		*out = new(v1.Affinity)
+		*out = new(corev1.Affinity)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.DefaultTolerations != nil {
 		in, out := &in.DefaultTolerations, &out.DefaultTolerations
-		*out = make([]v1.Toleration, len(*in))
+		*out = make([]corev1.Toleration, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.AllowedPriorityClassNames != nil {
+		in, out := &in.AllowedPriorityClassNames, &out.AllowedPriorityClassNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.BaseLabels != nil {
 		in, out := &in.BaseLabels, &out.BaseLabels
 		*out = make([]TemplateLabel, len(*in))
@@ -891,6 +1864,31 @@ func (in *WorkspaceTemplateSpec) DeepCopyInto(out *WorkspaceTemplateSpec) {
 		*out = new(IdleShutdownOverridePolicy)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.DefaultSchedule != nil {
+		in, out := &in.DefaultSchedule, &out.DefaultSchedule
+		*out = new(ScheduleSpec)
+		**out = **in
+	}
+	if in.ScheduleOverrides != nil {
+		in, out := &in.ScheduleOverrides, &out.ScheduleOverrides
+		*out = new(ScheduleOverridePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DefaultLifecyclePolicy != nil {
+		in, out := &in.DefaultLifecyclePolicy, &out.DefaultLifecyclePolicy
+		*out = new(LifecyclePolicySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DefaultStartupTimeout != nil {
+		in, out := &in.DefaultStartupTimeout, &out.DefaultStartupTimeout
+		*out = new(StartupTimeoutSpec)
+		**out = **in
+	}
+	if in.DefaultPreemptionRestart != nil {
+		in, out := &in.DefaultPreemptionRestart, &out.DefaultPreemptionRestart
+		*out = new(PreemptionRestartPolicy)
+		**out = **in
+	}
 	if in.DefaultAccessStrategy != nil {
 		in, out := &in.DefaultAccessStrategy, &out.DefaultAccessStrategy
 		*out = new(AccessStrategyRef)
@@ -898,17 +1896,27 @@ func (in *WorkspaceTemplateSpec) DeepCopyInto(out *WorkspaceTemplateSpec) {
 	}
 	if in.DefaultLifecycle != nil {
 		in, out := &in.DefaultLifecycle, &out.DefaultLifecycle
-		*out = new(v1.Lifecycle)
+		*out = new(corev1.Lifecycle)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DefaultProbes != nil {
+		in, out := &in.DefaultProbes, &out.DefaultProbes
+		*out = new(ProbesSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ProbeOverrides != nil {
+		in, out := &in.ProbeOverrides, &out.ProbeOverrides
+		*out = new(ProbeOverridePolicy)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.DefaultPodSecurityContext != nil {
 		in, out := &in.DefaultPodSecurityContext, &out.DefaultPodSecurityContext
-		*out = new(v1.PodSecurityContext)
+		*out = new(corev1.PodSecurityContext)
 		(*in).DeepCopyInto(*out)
 	}
 	if in.DefaultContainerSecurityContext != nil {
 		in, out := &in.DefaultContainerSecurityContext, &out.DefaultContainerSecurityContext
-		*out = new(v1.SecurityContext)
+		*out = new(corev1.SecurityContext)
 		(*in).DeepCopyInto(*out)
 	}
 }
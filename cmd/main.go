@@ -352,6 +352,21 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "WorkspaceAccessStrategy")
 		os.Exit(1)
 	}
+
+	if err := controller.SetupWorkspaceQuotaController(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "WorkspaceQuota")
+		os.Exit(1)
+	}
+
+	if err := controller.SetupRetainedPVCController(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RetainedPVC")
+		os.Exit(1)
+	}
+
+	if err := controller.SetupSharedVolumeController(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SharedVolume")
+		os.Exit(1)
+	}
 	// Set up Workspace webhook (enabled by default, controlled by ENABLE_WORKSPACE_WEBHOOK)
 	// nolint:goconst
 	if os.Getenv("ENABLE_WORKSPACE_WEBHOOK") != "false" {
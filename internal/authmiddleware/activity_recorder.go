@@ -0,0 +1,172 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package authmiddleware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+)
+
+// activityFlushTimeout bounds the time spent writing pending activity on shutdown
+const activityFlushTimeout = 5 * time.Second
+
+// workspaceConnection is a connection of a user to a workspace
+type workspaceConnection struct {
+	user string
+	at   time.Time
+}
+
+// ActivityRecorder records the last authenticated activity and connection of workspaces in their status
+// Requests only update an in-memory record; pending records are written in batches once per
+// interval, and each workspace is written at most once per interval unless a user connected to it
+type ActivityRecorder struct {
+	restClient rest.Interface
+	interval   time.Duration
+	logger     *slog.Logger
+
+	mu                 sync.Mutex
+	pending            map[WorkspaceInfo]time.Time
+	pendingConnections map[WorkspaceInfo]workspaceConnection
+	lastWritten        map[WorkspaceInfo]time.Time
+}
+
+// NewActivityRecorder creates a new ActivityRecorder writing through the provided REST client
+func NewActivityRecorder(restClient rest.Interface, interval time.Duration, logger *slog.Logger) *ActivityRecorder {
+	return &ActivityRecorder{
+		restClient:         restClient,
+		interval:           interval,
+		logger:             logger,
+		pending:            map[WorkspaceInfo]time.Time{},
+		pendingConnections: map[WorkspaceInfo]workspaceConnection{},
+		lastWritten:        map[WorkspaceInfo]time.Time{},
+	}
+}
+
+// Record notes activity on the workspace at the given time
+// Activity within the update interval of the last written time is dropped
+func (a *ActivityRecorder) Record(workspace WorkspaceInfo, at time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if written, ok := a.lastWritten[workspace]; ok && at.Sub(written) < a.interval {
+		return
+	}
+	if pending, ok := a.pending[workspace]; !ok || at.After(pending) {
+		a.pending[workspace] = at
+	}
+}
+
+// RecordConnection notes that the user connected to the workspace at the given time
+// Connections are rare and not rate-limited, the latest one is written at the next flush
+func (a *ActivityRecorder) RecordConnection(workspace WorkspaceInfo, user string, at time.Time) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if pending, ok := a.pendingConnections[workspace]; !ok || at.After(pending.at) {
+		a.pendingConnections[workspace] = workspaceConnection{user: user, at: at}
+	}
+}
+
+// Run writes pending activity every interval until the context is cancelled,
+// then writes the remaining activity
+func (a *ActivityRecorder) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), activityFlushTimeout)
+			a.Flush(flushCtx)
+			cancel()
+			return
+		case <-ticker.C:
+			a.Flush(ctx)
+		}
+	}
+}
+
+// Flush writes all pending activity and connections to the workspaces status
+func (a *ActivityRecorder) Flush(ctx context.Context) {
+	a.mu.Lock()
+	batch := a.pending
+	connections := a.pendingConnections
+	a.pending = map[WorkspaceInfo]time.Time{}
+	a.pendingConnections = map[WorkspaceInfo]workspaceConnection{}
+	a.mu.Unlock()
+
+	// A connection is also activity on the workspace
+	for workspace, connection := range connections {
+		if at, ok := batch[workspace]; !ok || connection.at.After(at) {
+			batch[workspace] = connection.at
+		}
+	}
+
+	written := make(map[WorkspaceInfo]time.Time, len(batch))
+	for workspace, at := range batch {
+		var connection *workspaceConnection
+		if c, ok := connections[workspace]; ok {
+			connection = &c
+		}
+		if err := a.patchStatus(ctx, workspace, at, connection); err != nil {
+			// Dropped on purpose, the next request to the workspace records activity again
+			a.logger.Warn("Failed to record workspace activity",
+				"workspace", workspace.Name,
+				"namespace", workspace.Namespace,
+				"error", err)
+			continue
+		}
+		written[workspace] = at
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for workspace, at := range written {
+		a.lastWritten[workspace] = at
+	}
+	// Entries older than the interval no longer rate-limit anything
+	now := time.Now()
+	for workspace, at := range a.lastWritten {
+		if now.Sub(at) >= a.interval {
+			delete(a.lastWritten, workspace)
+		}
+	}
+}
+
+// patchStatus sets status.lastActivityTime on the workspace, and the last connection when one is given
+// The status subresource is patched so that the workspace webhooks are not involved
+func (a *ActivityRecorder) patchStatus(ctx context.Context, workspace WorkspaceInfo, at time.Time, connection *workspaceConnection) error {
+	status := map[string]interface{}{
+		"lastActivityTime": metav1.NewTime(at),
+	}
+	if connection != nil {
+		status["lastConnectedBy"] = connection.user
+		status["lastConnectedAt"] = metav1.NewTime(connection.at)
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"status": status,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build status patch: %w", err)
+	}
+
+	url := fmt.Sprintf("/apis/%s/namespaces/%s/workspaces/%s/status",
+		workspacev1alpha1.GroupVersion.String(), workspace.Namespace, workspace.Name)
+	return a.restClient.Patch(types.MergePatchType).
+		AbsPath(url).
+		Body(patch).
+		Do(ctx).
+		Error()
+}
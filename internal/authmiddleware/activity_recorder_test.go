@@ -0,0 +1,213 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package authmiddleware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jupyter-infra/jupyter-k8s/internal/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// statusPatchRecorder is a fake API server recording workspace status patches
+type statusPatchRecorder struct {
+	mu          sync.Mutex
+	patches     map[string]string
+	connections map[string]string
+	fail        bool
+}
+
+func (p *statusPatchRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.fail {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	var body struct {
+		Status struct {
+			LastActivityTime string `json:"lastActivityTime"`
+			LastConnectedBy  string `json:"lastConnectedBy"`
+			LastConnectedAt  string `json:"lastConnectedAt"`
+		} `json:"status"`
+	}
+	data, _ := io.ReadAll(r.Body)
+	_ = json.Unmarshal(data, &body)
+	if r.Method != http.MethodPatch || r.Header.Get("Content-Type") != "application/merge-patch+json" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	p.patches[r.URL.Path] = body.Status.LastActivityTime
+	if body.Status.LastConnectedBy != "" {
+		p.connections[r.URL.Path] = body.Status.LastConnectedBy + "@" + body.Status.LastConnectedAt
+	}
+	_, _ = w.Write([]byte(`{}`))
+}
+
+func newTestActivityRecorder(t *testing.T, interval time.Duration) (*ActivityRecorder, *statusPatchRecorder) {
+	patches := &statusPatchRecorder{patches: map[string]string{}, connections: map[string]string{}}
+	mockServer := NewMockK8sServer(t)
+	t.Cleanup(mockServer.Close)
+	mockServer.SetupServerWithHandler(patches.ServeHTTP)
+
+	restClient, err := mockServer.CreateRESTClient()
+	require.NoError(t, err)
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewActivityRecorder(restClient, interval, logger), patches
+}
+
+const testActivityStatusPath = "/apis/workspace.jupyter.org/v1alpha1/namespaces/ns1/workspaces/app1/status"
+
+func TestActivityRecorder_FlushPatchesLatestActivity(t *testing.T) {
+	recorder, patches := newTestActivityRecorder(t, time.Minute)
+	workspace := WorkspaceInfo{Namespace: "ns1", Name: "app1"}
+	first := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	recorder.Record(workspace, first)
+	recorder.Record(workspace, first.Add(20*time.Second))
+	recorder.Record(workspace, first.Add(10*time.Second))
+	recorder.Flush(context.Background())
+
+	require.Len(t, patches.patches, 1)
+	assert.Equal(t, "2025-01-01T10:00:20Z", patches.patches[testActivityStatusPath])
+	assert.Empty(t, recorder.pending)
+}
+
+func TestActivityRecorder_FlushPatchesLastConnection(t *testing.T) {
+	recorder, patches := newTestActivityRecorder(t, time.Minute)
+	workspace := WorkspaceInfo{Namespace: "ns1", Name: "app1"}
+	first := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	recorder.Record(workspace, first)
+	recorder.RecordConnection(workspace, "alice", first.Add(30*time.Second))
+	recorder.RecordConnection(workspace, "bob", first.Add(10*time.Second))
+	recorder.Flush(context.Background())
+
+	require.Len(t, patches.patches, 1)
+	// the connection is also activity on the workspace
+	assert.Equal(t, "2025-01-01T10:00:30Z", patches.patches[testActivityStatusPath])
+	assert.Equal(t, "alice@2025-01-01T10:00:30Z", patches.connections[testActivityStatusPath])
+	assert.Empty(t, recorder.pendingConnections)
+}
+
+func TestActivityRecorder_ConnectionsAreNotRateLimited(t *testing.T) {
+	recorder, patches := newTestActivityRecorder(t, time.Minute)
+	workspace := WorkspaceInfo{Namespace: "ns1", Name: "app1"}
+	now := time.Now()
+
+	recorder.Record(workspace, now)
+	recorder.Flush(context.Background())
+
+	recorder.RecordConnection(workspace, "alice", now.Add(time.Second))
+	recorder.Flush(context.Background())
+
+	assert.Contains(t, patches.connections[testActivityStatusPath], "alice@")
+}
+
+func TestActivityRecorder_RateLimitsWrittenWorkspaces(t *testing.T) {
+	recorder, patches := newTestActivityRecorder(t, time.Minute)
+	workspace := WorkspaceInfo{Namespace: "ns1", Name: "app1"}
+	now := time.Now()
+
+	recorder.Record(workspace, now)
+	recorder.Flush(context.Background())
+	require.Len(t, patches.patches, 1)
+
+	// Within the interval of the last write
+	recorder.Record(workspace, now.Add(30*time.Second))
+	assert.Empty(t, recorder.pending)
+
+	// After the interval
+	recorder.Record(workspace, now.Add(61*time.Second))
+	assert.Len(t, recorder.pending, 1)
+}
+
+func TestActivityRecorder_FailedWriteIsNotRateLimited(t *testing.T) {
+	recorder, patches := newTestActivityRecorder(t, time.Minute)
+	patches.fail = true
+	workspace := WorkspaceInfo{Namespace: "ns1", Name: "app1"}
+	now := time.Now()
+
+	recorder.Record(workspace, now)
+	recorder.Flush(context.Background())
+
+	assert.Empty(t, recorder.pending)
+	assert.Empty(t, recorder.lastWritten)
+
+	recorder.Record(workspace, now.Add(time.Second))
+	assert.Len(t, recorder.pending, 1)
+}
+
+func TestActivityRecorder_RunFlushesOnShutdown(t *testing.T) {
+	recorder, patches := newTestActivityRecorder(t, time.Hour)
+	recorder.Record(WorkspaceInfo{Namespace: "ns1", Name: "app1"}, time.Now())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		recorder.Run(ctx)
+		close(done)
+	}()
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after the context was cancelled")
+	}
+	patches.mu.Lock()
+	defer patches.mu.Unlock()
+	assert.Contains(t, patches.patches, testActivityStatusPath)
+}
+
+// TestHandleVerify_RecordsActivity tests that a successful verify records activity for the workspace
+func TestHandleVerify_RecordsActivity(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/verify", nil)
+	req.Header.Set("X-Forwarded-Uri", fmt.Sprintf("%s/lab", testAppPath2))
+	req.Header.Set("X-Forwarded-Host", "example.com")
+	w := httptest.NewRecorder()
+
+	claims := &jwt.Claims{
+		User:      "testuser",
+		Path:      testAppPath2,
+		Domain:    "example.com",
+		TokenType: jwt.TokenTypeSession,
+	}
+	cookieHandler := &MockCookieHandler{
+		GetCookieFunc: func(r *http.Request, path string) (string, error) {
+			return testCookieToken, nil
+		},
+	}
+	jwtHandler := &MockJWTHandler{
+		ValidateTokenFunc: func(tokenString string) (*jwt.Claims, error) {
+			return claims, nil
+		},
+		ShouldRefreshTokenFunc: func(claims *jwt.Claims) bool {
+			return false
+		},
+	}
+
+	server := createVerifyRefreshTestServer(cookieHandler, jwtHandler)
+	server.activityRecorder, _ = newTestActivityRecorder(t, time.Minute)
+
+	server.handleVerify(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, server.activityRecorder.pending, WorkspaceInfo{Namespace: "ns2", Name: "app2"})
+}
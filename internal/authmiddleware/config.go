@@ -26,6 +26,10 @@ const (
 	EnvProbeAddr       = "PROBE_ADDR"
 	EnvNamespace       = "NAMESPACE"
 
+	// Trusted proxy configuration
+	EnvUntrustedProxyPolicy  = "UNTRUSTED_PROXY_POLICY"
+	EnvEnableForwardedHeader = "ENABLE_FORWARDED_HEADER"
+
 	// Auth configuration
 	EnvJwtSigningType    = "JWT_SIGNING_TYPE"
 	EnvJwtIssuer         = "JWT_ISSUER"
@@ -39,6 +43,14 @@ const (
 	EnvEnableOAuth       = "ENABLE_OAUTH"
 	EnvEnableBearerAuth  = "ENABLE_BEARER_URL_AUTH"
 
+	// Activity tracking configuration
+	EnvEnableActivityTracking = "ENABLE_ACTIVITY_TRACKING"
+	EnvActivityUpdateInterval = "ACTIVITY_UPDATE_INTERVAL"
+
+	// Start-on-connect configuration
+	EnvEnableStartOnConnect          = "ENABLE_START_ON_CONNECT"
+	EnvStartOnConnectRefreshInterval = "START_ON_CONNECT_REFRESH_INTERVAL"
+
 	// Routing configuration
 	EnvRoutingMode                      = "ROUTING_MODE"
 	EnvWorkspaceNamespaceSubdomainRegex = "WORKSPACE_NAMESPACE_SUBDOMAIN_REGEX"
@@ -81,6 +93,8 @@ const (
 	DefaultMetricsAddr     = ":9090"
 	DefaultProbeAddr       = ":9091"
 	// DefaultTrustedProxies is a slice, defined in createDefaultConfig
+	DefaultUntrustedProxyPolicy  = UntrustedProxyPolicyIgnore
+	DefaultEnableForwardedHeader = false
 
 	// Auth defaults
 	DefaultJwtSigningType    = JWTSigningTypeStandard
@@ -95,6 +109,14 @@ const (
 	DefaultEnableOAuth       = true
 	DefaultEnableBearerAuth  = false
 
+	// Activity tracking defaults
+	DefaultEnableActivityTracking = true
+	DefaultActivityUpdateInterval = 1 * time.Minute
+
+	// Start-on-connect defaults
+	DefaultEnableStartOnConnect          = false
+	DefaultStartOnConnectRefreshInterval = 5 * time.Second
+
 	// Cookie defaults
 	DefaultCookieName     = "workspace_auth"
 	DefaultCookieSecure   = true
@@ -126,11 +148,15 @@ type Config struct {
 	ReadTimeout     time.Duration
 	WriteTimeout    time.Duration
 	ShutdownTimeout time.Duration
-	TrustedProxies  []string
+	TrustedProxies  []string // IP addresses and CIDR ranges allowed to set forwarded headers
 	MetricsAddr     string
 	ProbeAddr       string
 	Namespace       string // Namespace to watch for secrets
 
+	// Trusted proxy configuration
+	UntrustedProxyPolicy  string // UntrustedProxyPolicyReject or UntrustedProxyPolicyIgnore
+	EnableForwardedHeader bool   // Read host and protocol from the RFC 7239 Forwarded header
+
 	// Auth configuration
 	JWTSigningType    string
 	JWTIssuer         string
@@ -144,6 +170,14 @@ type Config struct {
 	EnableOAuth       bool
 	EnableBearerAuth  bool
 
+	// Activity tracking configuration
+	EnableActivityTracking bool
+	ActivityUpdateInterval time.Duration
+
+	// Start-on-connect configuration
+	EnableStartOnConnect          bool
+	StartOnConnectRefreshInterval time.Duration
+
 	// Cookie configuration
 	CookieName     string
 	CookieSecure   bool
@@ -185,6 +219,14 @@ func NewConfig() (*Config, error) {
 		return nil, err
 	}
 
+	if err := applyActivityConfig(config); err != nil {
+		return nil, err
+	}
+
+	if err := applyStartOnConnectConfig(config); err != nil {
+		return nil, err
+	}
+
 	if err := applyCookieConfig(config); err != nil {
 		return nil, err
 	}
@@ -212,6 +254,10 @@ func createDefaultConfig() *Config {
 		MetricsAddr:     DefaultMetricsAddr,
 		ProbeAddr:       DefaultProbeAddr,
 
+		// Trusted proxy defaults
+		UntrustedProxyPolicy:  DefaultUntrustedProxyPolicy,
+		EnableForwardedHeader: DefaultEnableForwardedHeader,
+
 		// Auth defaults
 		JWTSigningType:    DefaultJwtSigningType,
 		JWTIssuer:         DefaultJwtIssuer,
@@ -225,6 +271,14 @@ func createDefaultConfig() *Config {
 		EnableOAuth:       DefaultEnableOAuth,
 		EnableBearerAuth:  DefaultEnableBearerAuth,
 
+		// Activity tracking defaults
+		EnableActivityTracking: DefaultEnableActivityTracking,
+		ActivityUpdateInterval: DefaultActivityUpdateInterval,
+
+		// Start-on-connect defaults
+		EnableStartOnConnect:          DefaultEnableStartOnConnect,
+		StartOnConnectRefreshInterval: DefaultStartOnConnectRefreshInterval,
+
 		// Cookie defaults
 		CookieName:     DefaultCookieName,
 		CookieSecure:   DefaultCookieSecure,
@@ -290,6 +344,25 @@ func applyServerConfig(config *Config) error {
 	if trustedProxies := os.Getenv(EnvTrustedProxies); trustedProxies != "" {
 		config.TrustedProxies = splitAndTrim(trustedProxies, ",")
 	}
+	if _, err := ParseTrustedProxies(config.TrustedProxies); err != nil {
+		return fmt.Errorf("invalid %s: %w", EnvTrustedProxies, err)
+	}
+
+	if policy := os.Getenv(EnvUntrustedProxyPolicy); policy != "" {
+		if policy != UntrustedProxyPolicyReject && policy != UntrustedProxyPolicyIgnore {
+			return fmt.Errorf("invalid %s: %s, must be %s or %s",
+				EnvUntrustedProxyPolicy, policy, UntrustedProxyPolicyReject, UntrustedProxyPolicyIgnore)
+		}
+		config.UntrustedProxyPolicy = policy
+	}
+
+	if enableForwarded := os.Getenv(EnvEnableForwardedHeader); enableForwarded != "" {
+		enable, err := strconv.ParseBool(enableForwarded)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", EnvEnableForwardedHeader, err)
+		}
+		config.EnableForwardedHeader = enable
+	}
 
 	if metricsAddr := os.Getenv(EnvMetricsAddr); metricsAddr != "" {
 		config.MetricsAddr = metricsAddr
@@ -408,6 +481,54 @@ func applyJWTConfig(config *Config) error {
 	return nil
 }
 
+// applyActivityConfig applies activity tracking environment variable overrides
+func applyActivityConfig(config *Config) error {
+	if enableActivityTracking := os.Getenv(EnvEnableActivityTracking); enableActivityTracking != "" {
+		enable, err := strconv.ParseBool(enableActivityTracking)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", EnvEnableActivityTracking, err)
+		}
+		config.EnableActivityTracking = enable
+	}
+
+	if activityUpdateInterval := os.Getenv(EnvActivityUpdateInterval); activityUpdateInterval != "" {
+		d, err := time.ParseDuration(activityUpdateInterval)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", EnvActivityUpdateInterval, err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("%s must be positive, got %s", EnvActivityUpdateInterval, d)
+		}
+		config.ActivityUpdateInterval = d
+	}
+
+	return nil
+}
+
+// applyStartOnConnectConfig applies start-on-connect environment variable overrides
+func applyStartOnConnectConfig(config *Config) error {
+	if enableStartOnConnect := os.Getenv(EnvEnableStartOnConnect); enableStartOnConnect != "" {
+		enable, err := strconv.ParseBool(enableStartOnConnect)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", EnvEnableStartOnConnect, err)
+		}
+		config.EnableStartOnConnect = enable
+	}
+
+	if refreshInterval := os.Getenv(EnvStartOnConnectRefreshInterval); refreshInterval != "" {
+		d, err := time.ParseDuration(refreshInterval)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", EnvStartOnConnectRefreshInterval, err)
+		}
+		if d < time.Second {
+			return fmt.Errorf("%s must be at least 1s, got %s", EnvStartOnConnectRefreshInterval, d)
+		}
+		config.StartOnConnectRefreshInterval = d
+	}
+
+	return nil
+}
+
 // applyCookieConfig applies cookie-related environment variable overrides
 func applyCookieConfig(config *Config) error {
 	if cookieName := os.Getenv(EnvCookieName); cookieName != "" {
@@ -656,3 +656,212 @@ func TestOIDCVerifierInitConfig(t *testing.T) {
 		})
 	}
 }
+
+// TestActivityTrackingConfig tests the activity tracking environment variables
+func TestActivityTrackingConfig(t *testing.T) {
+	testCases := []struct {
+		name             string
+		enableValue      string
+		intervalValue    string
+		expectedEnable   bool
+		expectedInterval time.Duration
+		expectError      bool
+	}{
+		{
+			name:             "Default values when env vars not set",
+			expectedEnable:   DefaultEnableActivityTracking,
+			expectedInterval: DefaultActivityUpdateInterval,
+		},
+		{
+			name:             "Disabled with custom interval",
+			enableValue:      "false",
+			intervalValue:    "30s",
+			expectedEnable:   false,
+			expectedInterval: 30 * time.Second,
+		},
+		{
+			name:        "Invalid enable value",
+			enableValue: "maybe",
+			expectError: true,
+		},
+		{
+			name:          "Invalid interval",
+			intervalValue: "often",
+			expectError:   true,
+		},
+		{
+			name:          "Zero interval",
+			intervalValue: "0s",
+			expectError:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.enableValue != "" {
+				t.Setenv(EnvEnableActivityTracking, tc.enableValue)
+			}
+			if tc.intervalValue != "" {
+				t.Setenv(EnvActivityUpdateInterval, tc.intervalValue)
+			}
+
+			config, err := NewConfig()
+
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("NewConfig() error = %v", err)
+			}
+			if config.EnableActivityTracking != tc.expectedEnable {
+				t.Errorf("Expected EnableActivityTracking to be %v, got %v", tc.expectedEnable, config.EnableActivityTracking)
+			}
+			if config.ActivityUpdateInterval != tc.expectedInterval {
+				t.Errorf("Expected ActivityUpdateInterval to be %v, got %v", tc.expectedInterval, config.ActivityUpdateInterval)
+			}
+		})
+	}
+}
+
+// TestStartOnConnectConfig tests the start-on-connect environment variables
+func TestStartOnConnectConfig(t *testing.T) {
+	testCases := []struct {
+		name             string
+		enableValue      string
+		intervalValue    string
+		expectedEnable   bool
+		expectedInterval time.Duration
+		expectError      bool
+	}{
+		{
+			name:             "Default values when env vars not set",
+			expectedEnable:   DefaultEnableStartOnConnect,
+			expectedInterval: DefaultStartOnConnectRefreshInterval,
+		},
+		{
+			name:             "Enabled with custom interval",
+			enableValue:      "true",
+			intervalValue:    "10s",
+			expectedEnable:   true,
+			expectedInterval: 10 * time.Second,
+		},
+		{
+			name:        "Invalid enable value",
+			enableValue: "sometimes",
+			expectError: true,
+		},
+		{
+			name:          "Interval below one second",
+			intervalValue: "500ms",
+			expectError:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.enableValue != "" {
+				t.Setenv(EnvEnableStartOnConnect, tc.enableValue)
+			}
+			if tc.intervalValue != "" {
+				t.Setenv(EnvStartOnConnectRefreshInterval, tc.intervalValue)
+			}
+
+			config, err := NewConfig()
+
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("NewConfig() error = %v", err)
+			}
+			if config.EnableStartOnConnect != tc.expectedEnable {
+				t.Errorf("Expected EnableStartOnConnect to be %v, got %v", tc.expectedEnable, config.EnableStartOnConnect)
+			}
+			if config.StartOnConnectRefreshInterval != tc.expectedInterval {
+				t.Errorf("Expected StartOnConnectRefreshInterval to be %v, got %v", tc.expectedInterval, config.StartOnConnectRefreshInterval)
+			}
+		})
+	}
+}
+
+// TestTrustedProxyConfig tests the trusted proxy environment variables
+func TestTrustedProxyConfig(t *testing.T) {
+	testCases := []struct {
+		name              string
+		proxiesValue      string
+		policyValue       string
+		forwardedValue    string
+		expectedPolicy    string
+		expectedForwarded bool
+		expectError       bool
+	}{
+		{
+			name:           "Default values when env vars not set",
+			expectedPolicy: DefaultUntrustedProxyPolicy,
+		},
+		{
+			name:              "CIDR proxies with ignore policy and Forwarded header",
+			proxiesValue:      "10.244.0.0/16, 127.0.0.1",
+			policyValue:       UntrustedProxyPolicyIgnore,
+			forwardedValue:    "true",
+			expectedPolicy:    UntrustedProxyPolicyIgnore,
+			expectedForwarded: true,
+		},
+		{
+			name:         "Invalid proxy",
+			proxiesValue: "10.244.0.0/16,proxy.local",
+			expectError:  true,
+		},
+		{
+			name:        "Invalid policy",
+			policyValue: "allow",
+			expectError: true,
+		},
+		{
+			name:           "Invalid Forwarded header flag",
+			forwardedValue: "yes please",
+			expectError:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.proxiesValue != "" {
+				t.Setenv(EnvTrustedProxies, tc.proxiesValue)
+			}
+			if tc.policyValue != "" {
+				t.Setenv(EnvUntrustedProxyPolicy, tc.policyValue)
+			}
+			if tc.forwardedValue != "" {
+				t.Setenv(EnvEnableForwardedHeader, tc.forwardedValue)
+			}
+
+			config, err := NewConfig()
+
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("Expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("NewConfig() error = %v", err)
+			}
+			if config.UntrustedProxyPolicy != tc.expectedPolicy {
+				t.Errorf("Expected UntrustedProxyPolicy to be %v, got %v", tc.expectedPolicy, config.UntrustedProxyPolicy)
+			}
+			if config.EnableForwardedHeader != tc.expectedForwarded {
+				t.Errorf("Expected EnableForwardedHeader to be %v, got %v", tc.expectedForwarded, config.EnableForwardedHeader)
+			}
+		})
+	}
+}
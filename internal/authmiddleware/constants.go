@@ -17,6 +17,7 @@ const (
 	HeaderForwardedURI   = "X-Forwarded-Uri"
 	HeaderForwardedHost  = "X-Forwarded-Host"
 	HeaderForwardedProto = "X-Forwarded-Proto"
+	HeaderForwarded      = "Forwarded"
 
 	// No headers set by middleware yet
 
@@ -29,4 +30,9 @@ const (
 
 	// OIDC constants
 	OIDCAuthHeaderPrefix = "Bearer "
+
+	// Workspace values read and written by the start-on-connect route
+	WorkspaceDesiredStatusRunning = "Running"
+	WorkspaceDesiredStatusStopped = "Stopped"
+	WorkspaceConditionAvailable   = "Available"
 )
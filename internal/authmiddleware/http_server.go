@@ -76,6 +76,11 @@ func (h *HTTPServerRunnable) Start(ctx context.Context) error {
 		h.logger.Info("Successfully loaded initial JWT signing keys")
 	}
 
+	// Write recorded workspace activity in the background until shutdown
+	if h.server.activityRecorder != nil {
+		go h.server.activityRecorder.Run(ctx)
+	}
+
 	// Start server in a goroutine
 	errChan := make(chan error, 1)
 	go func() {
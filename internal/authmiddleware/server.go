@@ -25,6 +25,10 @@ type Server struct {
 	httpServer    *http.Server
 	restClient    rest.Interface
 	oidcVerifier  OIDCVerifierInterface
+	// trustedProxies are the peers allowed to set forwarded headers
+	trustedProxies TrustedProxies
+	// activityRecorder is nil when activity tracking is disabled
+	activityRecorder *ActivityRecorder
 }
 
 // NewServer creates a new server instance
@@ -61,13 +65,27 @@ func NewServer(config *Config, jwtManager jwt.Handler, cookieManager CookieHandl
 		}
 	}
 
+	// Initialize activity recorder, which needs the K8s client to write workspace status
+	var activityRecorder *ActivityRecorder
+	if config.EnableActivityTracking && restClient != nil {
+		activityRecorder = NewActivityRecorder(restClient, config.ActivityUpdateInterval, logger)
+	}
+
+	// The trusted proxies are validated when loading the config, no proxy is trusted otherwise
+	trustedProxies, err := ParseTrustedProxies(config.TrustedProxies)
+	if err != nil {
+		logger.Error("Failed to parse trusted proxies", "error", err)
+	}
+
 	return &Server{
-		config:        config,
-		jwtManager:    jwtManager,
-		cookieManager: cookieManager,
-		logger:        logger,
-		restClient:    restClient,
-		oidcVerifier:  oidcVerifier,
+		config:           config,
+		jwtManager:       jwtManager,
+		cookieManager:    cookieManager,
+		logger:           logger,
+		restClient:       restClient,
+		oidcVerifier:     oidcVerifier,
+		activityRecorder: activityRecorder,
+		trustedProxies:   trustedProxies,
 	}
 }
 
@@ -91,12 +109,15 @@ func (s *Server) Start() error {
 
 	// Register routes
 	if s.config.EnableOAuth {
-		router.HandleFunc("/auth", s.handleAuth)
+		router.HandleFunc("/auth", s.withTrustedProxies(s.handleAuth))
+		if s.config.EnableStartOnConnect {
+			router.HandleFunc("/restart", s.withTrustedProxies(s.handleRestart))
+		}
 	}
 	if s.config.EnableBearerAuth {
-		router.HandleFunc("/bearer-auth", s.handleBearerAuth)
+		router.HandleFunc("/bearer-auth", s.withTrustedProxies(s.handleBearerAuth))
 	}
-	router.HandleFunc("/verify", s.handleVerify)
+	router.HandleFunc("/verify", s.withTrustedProxies(s.handleVerify))
 	router.HandleFunc("/health", s.handleHealth)
 
 	// Configure HTTP server
@@ -12,6 +12,16 @@ import (
 	"github.com/jupyter-infra/jupyter-k8s/internal/jwt"
 )
 
+// oidcConnection holds the identity and workspace of an authorized OIDC connection request
+type oidcConnection struct {
+	username  string
+	groups    []string
+	uid       string
+	appPath   string
+	host      string
+	workspace *WorkspaceInfo
+}
+
 // handleAuth handles authentication requests
 func (s *Server) handleAuth(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -19,6 +29,50 @@ func (s *Server) handleAuth(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	connection, ok := s.authorizeOIDCConnection(w, r)
+	if !ok {
+		return
+	}
+	k8sUsername := connection.username
+	k8sGroups := connection.groups
+	k8sUID := connection.uid
+	appPath := connection.appPath
+	host := connection.host
+
+	// Generate JWT token with app path and domain for authorization scope
+	jwtToken, err := s.jwtManager.GenerateToken(k8sUsername, k8sGroups, k8sUID, nil, appPath, host, jwt.TokenTypeSession)
+	if err != nil {
+		s.logger.Error("Failed to generate token", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	// Set cookie using appPath and same domain as JWT token
+	s.cookieManager.SetCookie(w, jwtToken, appPath, host)
+	s.recordConnection(r, k8sUsername)
+
+	// Create empty response
+	response := map[string]string{}
+
+	// Log successful connection
+	s.logger.Info("Connection successful",
+		"user", k8sUID,
+		"username", k8sUsername,
+		"path", appPath,
+		"groups", k8sGroups)
+
+	// Return JSON response
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		s.logger.Error("Failed to encode JSON response", "error", err)
+	}
+}
+
+// authorizeOIDCConnection verifies the OIDC token and forwarded headers of the request,
+// then checks that the user may connect to the workspace.
+// It writes the error response and returns false when the connection is refused.
+func (s *Server) authorizeOIDCConnection(w http.ResponseWriter, r *http.Request) (*oidcConnection, bool) {
 	// Get headers from request
 	fullPath := r.Header.Get(HeaderForwardedURI)
 	host := r.Header.Get(HeaderForwardedHost)
@@ -36,26 +90,26 @@ func (s *Server) handleAuth(w http.ResponseWriter, r *http.Request) {
 	// Validate required headers
 	if fullPath == "" {
 		http.Error(w, "Missing "+HeaderForwardedURI+" header", http.StatusBadRequest)
-		return
+		return nil, false
 	}
 
 	if host == "" {
 		http.Error(w, "Missing "+HeaderForwardedHost+" header", http.StatusBadRequest)
-		return
+		return nil, false
 	}
 
 	// Authorization is required
 	if authHeader == "" {
 		s.logger.Error("Missing Authorization header")
 		http.Error(w, "Missing Authorization header", http.StatusUnauthorized)
-		return
+		return nil, false
 	}
 
 	// OIDCVerifier should always be initialized when /auth is enabled
 	if s.oidcVerifier == nil {
 		s.logger.Error("OIDC verifier is not initialized")
 		http.Error(w, "Internal server error: OIDC verifier not initialized", http.StatusInternalServerError)
-		return
+		return nil, false
 	}
 
 	// Extract token from Authorization header
@@ -63,7 +117,7 @@ func (s *Server) handleAuth(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		s.logger.Error("Failed to extract bearer token", "error", err)
 		http.Error(w, "Invalid Authorization header", http.StatusBadRequest)
-		return
+		return nil, false
 	}
 
 	// Verify the token with the OIDC provider
@@ -73,13 +127,13 @@ func (s *Server) handleAuth(w http.ResponseWriter, r *http.Request) {
 			// Server-side error (e.g., OIDC provider unavailable)
 			s.logger.Error("OIDC provider connection error", "error", err)
 			http.Error(w, "Internal server error: OIDC provider not available", http.StatusInternalServerError)
-			return
+			return nil, false
 		}
 
 		// Otherwise the token is invalid, reject with 400
 		s.logger.Error("OIDC token validation error", "error", err)
 		http.Error(w, "Invalid or expired OIDC token", http.StatusForbidden)
-		return
+		return nil, false
 	}
 
 	// Set the user identity variables from the OIDC token
@@ -93,7 +147,7 @@ func (s *Server) handleAuth(w http.ResponseWriter, r *http.Request) {
 			"token preferred username", k8sUsername,
 			"header preferred username", headerPreferredUsername)
 		http.Error(w, "Username mismatch between token and headers", http.StatusUnauthorized)
-		return
+		return nil, false
 	}
 
 	// Verify UID in header if available
@@ -102,7 +156,7 @@ func (s *Server) handleAuth(w http.ResponseWriter, r *http.Request) {
 			"token UID", k8sUID,
 			"header UID", headerUID)
 		http.Error(w, "UID verification failed", http.StatusUnauthorized)
-		return
+		return nil, false
 	}
 
 	// Verify groups in header if available
@@ -111,7 +165,7 @@ func (s *Server) handleAuth(w http.ResponseWriter, r *http.Request) {
 		if !ok {
 			s.logger.Error("Groups mismatch between token and headers", "missing groups in token", missingGroups)
 			http.Error(w, "Groups verification failed", http.StatusUnauthorized)
-			return
+			return nil, false
 		}
 	}
 
@@ -120,7 +174,7 @@ func (s *Server) handleAuth(w http.ResponseWriter, r *http.Request) {
 	if s.restClient == nil {
 		s.logger.Error("cannot authorize, REST client not set")
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
-		return
+		return nil, false
 	}
 
 	// Verify workspace access using the new extracted function
@@ -136,7 +190,7 @@ func (s *Server) handleAuth(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		s.logger.Error("Failed to verify workspace access", "error", err, "path", appPath)
 		http.Error(w, "Failed to verify workspace access", http.StatusInternalServerError)
-		return
+		return nil, false
 	}
 
 	allowed := connectionAccessReviewResult.Allowed
@@ -156,12 +210,8 @@ func (s *Server) handleAuth(w http.ResponseWriter, r *http.Request) {
 		// given such decision depends on a/ the Workspace.Spec.AccessType, b/ whether the user
 		// is the owner of the Workspace, c/ when we support peer-to-peer sharing, whether the
 		// owner shared their workspace to the user or one of the group they belong to.
-
-		// Note: if the Workspace is in Stopped state, we could trigger an automatic restart
-		// when a user makes an authorized connection request. However, we should use a different
-		// route of the authmiddleware and use a redirect (possibly '/restart')
 		http.Error(w, "Access denied: you are not authorized to connect to this workspace", http.StatusForbidden)
-		return
+		return nil, false
 	}
 
 	s.logger.Info("Connection to the workspace granted",
@@ -171,31 +221,12 @@ func (s *Server) handleAuth(w http.ResponseWriter, r *http.Request) {
 		"reason", connectionAccessReviewResult.Reason,
 	)
 
-	// Generate JWT token with app path and domain for authorization scope
-	jwtToken, err := s.jwtManager.GenerateToken(k8sUsername, k8sGroups, k8sUID, nil, appPath, host, jwt.TokenTypeSession)
-	if err != nil {
-		s.logger.Error("Failed to generate token", "error", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
-		return
-	}
-
-	// Set cookie using appPath and same domain as JWT token
-	s.cookieManager.SetCookie(w, jwtToken, appPath, host)
-
-	// Create empty response
-	response := map[string]string{}
-
-	// Log successful connection
-	s.logger.Info("Connection successful",
-		"user", k8sUID,
-		"username", k8sUsername,
-		"path", appPath,
-		"groups", k8sGroups)
-
-	// Return JSON response
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		s.logger.Error("Failed to encode JSON response", "error", err)
-	}
+	return &oidcConnection{
+		username:  k8sUsername,
+		groups:    k8sGroups,
+		uid:       k8sUID,
+		appPath:   appPath,
+		host:      host,
+		workspace: workspaceInfo,
+	}, true
 }
@@ -104,6 +104,7 @@ func (s *Server) handleBearerAuth(w http.ResponseWriter, r *http.Request) {
 
 	// Set session cookie using appPath and same domain as JWT token
 	s.cookieManager.SetCookie(w, sessionToken, appPath, host)
+	s.recordConnection(r, user)
 
 	// Log successful token exchange
 	s.logger.Info("Token exchange successful",
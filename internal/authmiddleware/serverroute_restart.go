@@ -0,0 +1,147 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package authmiddleware
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// waitingPageTemplate is served while a workspace starts; the page reloads itself
+// so that the user lands on the workspace as soon as it is available
+var waitingPageTemplate = template.Must(template.New("waiting").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="{{.RefreshSeconds}}">
+<title>Starting {{.Name}}</title>
+</head>
+<body>
+<p>Workspace <b>{{.Name}}</b> is starting. This page refreshes every {{.RefreshSeconds}} seconds
+and opens the workspace once it is available.</p>
+</body>
+</html>
+`))
+
+// handleRestart handles start-on-connect requests.
+// It authenticates and authorizes the connection like /auth, starts the workspace
+// if it is stopped, and serves a waiting page until the workspace is available,
+// at which point it redirects to the workspace.
+//
+// The waiting page is returned with 503 so that reverse proxies calling this route
+// as a forward-auth endpoint pass it on to the browser.
+func (s *Server) handleRestart(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	connection, ok := s.authorizeOIDCConnection(w, r)
+	if !ok {
+		return
+	}
+	workspaceInfo := connection.workspace
+
+	workspace, err := s.getWorkspace(r.Context(), workspaceInfo)
+	if err != nil {
+		s.logger.Error("Failed to get workspace", "error", err,
+			"workspace", workspaceInfo.Name, "namespace", workspaceInfo.Namespace)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if meta.IsStatusConditionTrue(workspace.Status.Conditions, WorkspaceConditionAvailable) {
+		http.Redirect(w, r, workspaceURL(r, connection), http.StatusFound)
+		return
+	}
+
+	if workspace.Spec.DesiredStatus == WorkspaceDesiredStatusStopped {
+		if err := s.startWorkspace(r.Context(), connection); err != nil {
+			s.logger.Error("Failed to start workspace", "error", err,
+				"username", connection.username,
+				"workspace", workspaceInfo.Name,
+				"namespace", workspaceInfo.Namespace)
+			http.Error(w, "Failed to start workspace", http.StatusInternalServerError)
+			return
+		}
+		s.logger.Info("Workspace started on connection",
+			"username", connection.username,
+			"workspace", workspaceInfo.Name,
+			"namespace", workspaceInfo.Namespace)
+	}
+
+	refreshSeconds := int(s.config.StartOnConnectRefreshInterval.Seconds())
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Retry-After", strconv.Itoa(refreshSeconds))
+	w.WriteHeader(http.StatusServiceUnavailable)
+	if err := waitingPageTemplate.Execute(w, map[string]interface{}{
+		"Name":           workspaceInfo.Name,
+		"RefreshSeconds": refreshSeconds,
+	}); err != nil {
+		s.logger.Error("Failed to write waiting page", "error", err)
+	}
+}
+
+// getWorkspace reads the workspace through the REST client
+func (s *Server) getWorkspace(ctx context.Context, workspaceInfo *WorkspaceInfo) (*workspacev1alpha1.Workspace, error) {
+	raw, err := s.restClient.Get().
+		AbsPath(workspaceURLPath(workspaceInfo)).
+		DoRaw(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workspace: %w", err)
+	}
+
+	var workspace workspacev1alpha1.Workspace
+	if err := json.Unmarshal(raw, &workspace); err != nil {
+		return nil, fmt.Errorf("failed to decode workspace: %w", err)
+	}
+	return &workspace, nil
+}
+
+// startWorkspace sets the desired status of the workspace to Running.
+// The connection is authorized by the access review before the workspace is started,
+// so the patch is made with the service account of the middleware, which is only
+// granted get and patch on workspaces when start-on-connect is enabled.
+func (s *Server) startWorkspace(ctx context.Context, connection *oidcConnection) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"desiredStatus": WorkspaceDesiredStatusRunning,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build workspace patch: %w", err)
+	}
+
+	return s.restClient.Patch(types.MergePatchType).
+		AbsPath(workspaceURLPath(connection.workspace)).
+		Body(patch).
+		Do(ctx).
+		Error()
+}
+
+// workspaceURLPath returns the API path of the workspace
+func workspaceURLPath(workspaceInfo *WorkspaceInfo) string {
+	return fmt.Sprintf("/apis/%s/namespaces/%s/workspaces/%s",
+		workspacev1alpha1.GroupVersion.String(), workspaceInfo.Namespace, workspaceInfo.Name)
+}
+
+// workspaceURL returns the URL of the workspace application the user connected to
+func workspaceURL(r *http.Request, connection *oidcConnection) string {
+	scheme := "https"
+	if r.Header.Get(HeaderForwardedProto) == "http" {
+		scheme = "http"
+	}
+	return fmt.Sprintf("%s://%s%s", scheme, connection.host, connection.appPath)
+}
@@ -0,0 +1,156 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package authmiddleware
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const testRestartWorkspacePath = "/apis/workspace.jupyter.org/v1alpha1/namespaces/ns1/workspaces/app1"
+
+// restartAPIServer is a fake API server answering access reviews and workspace reads,
+// and recording workspace patches
+type restartAPIServer struct {
+	mu        sync.Mutex
+	allowed   bool
+	workspace *workspacev1alpha1.Workspace
+	patches   []*http.Request
+	bodies    []string
+}
+
+func (a *restartAPIServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/connectionaccessreviews"):
+		_ = json.NewEncoder(w).Encode(CreateConnectionAccessReviewResponse(
+			"ns1", "app1", "github:valid-user", nil, "user-uid", a.allowed, false, "test"))
+	case r.Method == http.MethodGet && r.URL.Path == testRestartWorkspacePath:
+		_ = json.NewEncoder(w).Encode(a.workspace)
+	case r.Method == http.MethodPatch && r.URL.Path == testRestartWorkspacePath:
+		body, _ := io.ReadAll(r.Body)
+		a.patches = append(a.patches, r)
+		a.bodies = append(a.bodies, string(body))
+		_, _ = w.Write([]byte(`{}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newRestartTestServer(t *testing.T, api *restartAPIServer) *Server {
+	mockServer := NewMockK8sServer(t)
+	t.Cleanup(mockServer.Close)
+	mockServer.SetupServerWithHandler(api.ServeHTTP)
+
+	restClient, err := mockServer.CreateRESTClient()
+	require.NoError(t, err)
+
+	server := createTestServer(nil)
+	server.config.StartOnConnectRefreshInterval = 5 * time.Second
+	server.restClient = restClient
+	setupOIDCVerifier(server, nil)
+	return server
+}
+
+func newRestartRequest() *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/restart", nil)
+	req.Header.Set(HeaderForwardedURI, testAppPath+"/lab")
+	req.Header.Set(HeaderForwardedHost, "example.com")
+	req.Header.Set(HeaderForwardedProto, "https")
+	req.Header.Set(HeaderAuthorization, "Bearer mock-token")
+	return req
+}
+
+func newRestartTestWorkspace(desiredStatus string, available bool) *workspacev1alpha1.Workspace {
+	workspace := &workspacev1alpha1.Workspace{
+		ObjectMeta: metav1.ObjectMeta{Name: "app1", Namespace: "ns1"},
+		Spec:       workspacev1alpha1.WorkspaceSpec{DesiredStatus: desiredStatus},
+	}
+	if available {
+		workspace.Status.Conditions = []metav1.Condition{{
+			Type:   WorkspaceConditionAvailable,
+			Status: metav1.ConditionTrue,
+		}}
+	}
+	return workspace
+}
+
+func TestHandleRestart_StartsStoppedWorkspace(t *testing.T) {
+	api := &restartAPIServer{allowed: true, workspace: newRestartTestWorkspace(WorkspaceDesiredStatusStopped, false)}
+	server := newRestartTestServer(t, api)
+
+	w := httptest.NewRecorder()
+	server.handleRestart(w, newRestartRequest())
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "5", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), `<meta http-equiv="refresh" content="5">`)
+
+	require.Len(t, api.patches, 1)
+	assert.JSONEq(t, `{"spec":{"desiredStatus":"Running"}}`, api.bodies[0])
+	patch := api.patches[0]
+	assert.Equal(t, "application/merge-patch+json", patch.Header.Get("Content-Type"))
+	// the connection is authorized before the start, the patch does not impersonate the user
+	assert.Empty(t, patch.Header.Get("Impersonate-User"))
+	assert.Empty(t, patch.Header.Values("Impersonate-Group"))
+}
+
+func TestHandleRestart_WaitsForStartingWorkspace(t *testing.T) {
+	api := &restartAPIServer{allowed: true, workspace: newRestartTestWorkspace(WorkspaceDesiredStatusRunning, false)}
+	server := newRestartTestServer(t, api)
+
+	w := httptest.NewRecorder()
+	server.handleRestart(w, newRestartRequest())
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Empty(t, api.patches)
+}
+
+func TestHandleRestart_RedirectsToAvailableWorkspace(t *testing.T) {
+	api := &restartAPIServer{allowed: true, workspace: newRestartTestWorkspace(WorkspaceDesiredStatusRunning, true)}
+	server := newRestartTestServer(t, api)
+
+	w := httptest.NewRecorder()
+	server.handleRestart(w, newRestartRequest())
+
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, "https://example.com"+testAppPath, w.Header().Get("Location"))
+	assert.Empty(t, api.patches)
+}
+
+func TestHandleRestart_DoesNotStartWorkspaceWhenAccessDenied(t *testing.T) {
+	api := &restartAPIServer{allowed: false, workspace: newRestartTestWorkspace(WorkspaceDesiredStatusStopped, false)}
+	server := newRestartTestServer(t, api)
+
+	w := httptest.NewRecorder()
+	server.handleRestart(w, newRestartRequest())
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Empty(t, api.patches)
+}
+
+func TestHandleRestart_RejectsPostMethod(t *testing.T) {
+	server := createTestServer(nil)
+
+	w := httptest.NewRecorder()
+	server.handleRestart(w, httptest.NewRequest(http.MethodPost, "/restart", nil))
+
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
@@ -8,6 +8,7 @@ package authmiddleware
 import (
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/jupyter-infra/jupyter-k8s/internal/jwt"
 )
@@ -116,5 +117,32 @@ func (s *Server) handleVerify(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	s.recordActivity(r)
 	w.WriteHeader(http.StatusOK)
 }
+
+// recordActivity notes authenticated activity on the workspace targeted by the request
+func (s *Server) recordActivity(r *http.Request) {
+	if s.activityRecorder == nil {
+		return
+	}
+	workspaceInfo, err := s.ExtractWorkspaceInfo(r)
+	if err != nil {
+		s.logger.Debug("Cannot record activity, failed to extract workspace", "error", err)
+		return
+	}
+	s.activityRecorder.Record(*workspaceInfo, time.Now())
+}
+
+// recordConnection notes that the user connected to the workspace targeted by the request
+func (s *Server) recordConnection(r *http.Request, user string) {
+	if s.activityRecorder == nil {
+		return
+	}
+	workspaceInfo, err := s.ExtractWorkspaceInfo(r)
+	if err != nil {
+		s.logger.Debug("Cannot record connection, failed to extract workspace", "error", err)
+		return
+	}
+	s.activityRecorder.RecordConnection(*workspaceInfo, user, time.Now())
+}
@@ -0,0 +1,153 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package authmiddleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// Policies applied to requests from peers that are not trusted proxies
+const (
+	// UntrustedProxyPolicyReject refuses the request
+	UntrustedProxyPolicyReject = "reject"
+	// UntrustedProxyPolicyIgnore drops the forwarded headers and serves the request
+	UntrustedProxyPolicyIgnore = "ignore"
+)
+
+// forwardedHeaders lists the headers that only trusted proxies may set
+var forwardedHeaders = []string{
+	HeaderForwardedURI,
+	HeaderForwardedHost,
+	HeaderForwardedProto,
+	HeaderForwarded,
+}
+
+// TrustedProxies matches the peers allowed to set forwarded headers
+type TrustedProxies []netip.Prefix
+
+// ParseTrustedProxies parses a list of IP addresses and CIDR ranges
+func ParseTrustedProxies(entries []string) (TrustedProxies, error) {
+	proxies := make(TrustedProxies, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy CIDR %q: %w", entry, err)
+			}
+			proxies = append(proxies, prefix.Masked())
+			continue
+		}
+
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy address %q: %w", entry, err)
+		}
+		addr = addr.Unmap()
+		proxies = append(proxies, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return proxies, nil
+}
+
+// Contains returns true when the remote address (host:port or host) is a trusted proxy
+func (t TrustedProxies) Contains(remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	for _, prefix := range t {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// withTrustedProxies only lets trusted proxies set the forwarded headers of the request.
+// Forwarded headers from other peers are stripped, or the request is rejected, per the configured policy.
+// Requests from other peers without forwarded headers are served unchanged.
+// When enabled, the RFC 7239 Forwarded header of trusted proxies sets the host and protocol.
+func (s *Server) withTrustedProxies(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.trustedProxies.Contains(r.RemoteAddr) {
+			if !hasForwardedHeaders(r) {
+				next(w, r)
+				return
+			}
+			if s.config.UntrustedProxyPolicy == UntrustedProxyPolicyReject {
+				s.logger.Warn("Request with forwarded headers from untrusted proxy rejected",
+					"remote_addr", r.RemoteAddr, "path", r.URL.Path)
+				http.Error(w, "Forbidden: untrusted proxy", http.StatusForbidden)
+				return
+			}
+
+			s.logger.Debug("Ignoring forwarded headers from untrusted proxy", "remote_addr", r.RemoteAddr)
+			for _, header := range forwardedHeaders {
+				r.Header.Del(header)
+			}
+			next(w, r)
+			return
+		}
+
+		if s.config.EnableForwardedHeader {
+			applyForwardedHeader(r)
+		}
+		next(w, r)
+	}
+}
+
+// hasForwardedHeaders returns true when the request sets any of the headers only trusted proxies may set
+func hasForwardedHeaders(r *http.Request) bool {
+	for _, header := range forwardedHeaders {
+		if len(r.Header.Values(header)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// applyForwardedHeader sets the X-Forwarded-Host and X-Forwarded-Proto headers from the
+// RFC 7239 Forwarded header. Only the last element is used: it was added by the
+// trusted proxy that sent the request, earlier elements may come from the client.
+func applyForwardedHeader(r *http.Request) {
+	values := r.Header.Values(HeaderForwarded)
+	if len(values) == 0 {
+		return
+	}
+	elements := strings.Split(values[len(values)-1], ",")
+	params := parseForwardedElement(elements[len(elements)-1])
+
+	if host := params["host"]; host != "" {
+		r.Header.Set(HeaderForwardedHost, host)
+	}
+	if proto := params["proto"]; proto != "" {
+		r.Header.Set(HeaderForwardedProto, proto)
+	}
+}
+
+// parseForwardedElement parses the parameters of one element of a Forwarded header,
+// e.g. `for=192.0.2.60;proto=https;host="example.com"`
+func parseForwardedElement(element string) map[string]string {
+	params := map[string]string{}
+	for _, pair := range strings.Split(element, ";") {
+		key, value, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if !found {
+			continue
+		}
+		params[strings.ToLower(key)] = strings.Trim(value, `"`)
+	}
+	return params
+}
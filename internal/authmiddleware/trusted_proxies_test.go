@@ -0,0 +1,146 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package authmiddleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTrustedProxies_RejectsInvalidEntries(t *testing.T) {
+	_, err := ParseTrustedProxies([]string{"10.0.0.1", "not-an-ip"})
+	assert.Error(t, err)
+
+	_, err = ParseTrustedProxies([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
+}
+
+func TestTrustedProxies_Contains(t *testing.T) {
+	proxies, err := ParseTrustedProxies([]string{"127.0.0.1", "::1", "10.244.0.0/16", "fd00::/8"})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		remoteAddr string
+		expected   bool
+	}{
+		{"127.0.0.1:43210", true},
+		{"[::1]:43210", true},
+		{"10.244.3.7:8080", true},
+		{"[::ffff:10.244.3.7]:8080", true},
+		{"[fd12::1]:8080", true},
+		{"10.245.0.1:8080", false},
+		{"192.168.1.10:8080", false},
+		{"10.244.3.7", true},
+		{"not-an-address", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.remoteAddr, func(t *testing.T) {
+			assert.Equal(t, tc.expected, proxies.Contains(tc.remoteAddr))
+		})
+	}
+}
+
+// newTrustedProxyTestServer returns a server trusting 10.0.0.0/8 and a handler capturing the forwarded headers
+func newTrustedProxyTestServer(t *testing.T, policy string) (*Server, http.HandlerFunc, *http.Header) {
+	server := createTestServer(nil)
+	server.config.UntrustedProxyPolicy = policy
+	proxies, err := ParseTrustedProxies([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+	server.trustedProxies = proxies
+
+	captured := &http.Header{}
+	handler := server.withTrustedProxies(func(w http.ResponseWriter, r *http.Request) {
+		*captured = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	})
+	return server, handler, captured
+}
+
+func newForwardedRequest(remoteAddr string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/verify", nil)
+	req.RemoteAddr = remoteAddr
+	req.Header.Set(HeaderForwardedURI, testAppPath)
+	req.Header.Set(HeaderForwardedHost, "example.com")
+	req.Header.Set(HeaderForwardedProto, "https")
+	return req
+}
+
+func TestWithTrustedProxies_PassesHeadersFromTrustedProxy(t *testing.T) {
+	_, handler, captured := newTrustedProxyTestServer(t, UntrustedProxyPolicyReject)
+
+	w := httptest.NewRecorder()
+	handler(w, newForwardedRequest("10.1.2.3:5000"))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "example.com", captured.Get(HeaderForwardedHost))
+	assert.Equal(t, testAppPath, captured.Get(HeaderForwardedURI))
+}
+
+func TestWithTrustedProxies_RejectsUntrustedPeer(t *testing.T) {
+	_, handler, captured := newTrustedProxyTestServer(t, UntrustedProxyPolicyReject)
+
+	w := httptest.NewRecorder()
+	handler(w, newForwardedRequest("192.168.1.10:5000"))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Empty(t, *captured)
+}
+
+func TestWithTrustedProxies_ServesUntrustedPeerWithoutForwardedHeaders(t *testing.T) {
+	_, handler, _ := newTrustedProxyTestServer(t, UntrustedProxyPolicyReject)
+
+	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	req.RemoteAddr = "192.168.1.10:5000"
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestWithTrustedProxies_IgnoresHeadersFromUntrustedPeer(t *testing.T) {
+	_, handler, captured := newTrustedProxyTestServer(t, UntrustedProxyPolicyIgnore)
+
+	req := newForwardedRequest("192.168.1.10:5000")
+	req.Header.Set(HeaderForwarded, "host=evil.example.com")
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	for _, header := range forwardedHeaders {
+		assert.Empty(t, captured.Get(header), header)
+	}
+}
+
+func TestWithTrustedProxies_AppliesForwardedHeaderWhenEnabled(t *testing.T) {
+	server, handler, captured := newTrustedProxyTestServer(t, UntrustedProxyPolicyReject)
+	server.config.EnableForwardedHeader = true
+
+	req := newForwardedRequest("10.1.2.3:5000")
+	// The first element may be forged by the client, the last one is set by the trusted proxy
+	req.Header.Set(HeaderForwarded, `host=evil.example.com, for=192.0.2.60;proto=http;host="workspaces.example.com"`)
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "workspaces.example.com", captured.Get(HeaderForwardedHost))
+	assert.Equal(t, "http", captured.Get(HeaderForwardedProto))
+}
+
+func TestWithTrustedProxies_IgnoresForwardedHeaderWhenDisabled(t *testing.T) {
+	_, handler, captured := newTrustedProxyTestServer(t, UntrustedProxyPolicyReject)
+
+	req := newForwardedRequest("10.1.2.3:5000")
+	req.Header.Set(HeaderForwarded, "host=workspaces.example.com")
+	w := httptest.NewRecorder()
+	handler(w, req)
+
+	assert.Equal(t, "example.com", captured.Get(HeaderForwardedHost))
+}
@@ -0,0 +1,292 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// computeBackend manages the resource running the pod of a workspace for one compute mode
+type computeBackend interface {
+	// get returns the compute resource of the workspace, or nil when it does not exist
+	get(ctx context.Context, workspace *workspacev1alpha1.Workspace) (client.Object, error)
+	// ensureExists creates the compute resource if it doesn't exist, or brings it up to date
+	ensureExists(
+		ctx context.Context,
+		workspace *workspacev1alpha1.Workspace,
+		accessStrategy *workspacev1alpha1.WorkspaceAccessStrategy) (client.Object, error)
+	// isAvailable checks if the compute resource runs a ready pod
+	isAvailable(compute client.Object) bool
+}
+
+// allComputeModes lists the compute modes whose resources are removed when a workspace stops
+var allComputeModes = []workspacev1alpha1.ComputeMode{
+	workspacev1alpha1.ComputeModeDeployment,
+	workspacev1alpha1.ComputeModeStatefulSet,
+	workspacev1alpha1.ComputeModePod,
+}
+
+// GetComputeMode returns the compute mode of the workspace, Deployment by default
+func GetComputeMode(workspace *workspacev1alpha1.Workspace) workspacev1alpha1.ComputeMode {
+	if workspace.Spec.ComputeMode == "" {
+		return workspacev1alpha1.ComputeModeDeployment
+	}
+	return workspace.Spec.ComputeMode
+}
+
+// computeBackendFor returns the backend managing the compute resources of the given mode
+func (rm *ResourceManager) computeBackendFor(mode workspacev1alpha1.ComputeMode) computeBackend {
+	switch mode {
+	case workspacev1alpha1.ComputeModeStatefulSet:
+		return &statefulSetBackend{rm: rm}
+	case workspacev1alpha1.ComputeModePod:
+		return &podBackend{rm: rm}
+	default:
+		return &deploymentBackend{rm: rm}
+	}
+}
+
+// EnsureComputeExists creates the compute resource of the workspace if it doesn't exist,
+// or updates it if its pod spec differs
+func (rm *ResourceManager) EnsureComputeExists(
+	ctx context.Context,
+	workspace *workspacev1alpha1.Workspace,
+	accessStrategy *workspacev1alpha1.WorkspaceAccessStrategy) (client.Object, error) {
+	return rm.computeBackendFor(GetComputeMode(workspace)).ensureExists(ctx, workspace, accessStrategy)
+}
+
+// IsComputeAvailable checks if the compute resource of the workspace runs a ready pod
+func (rm *ResourceManager) IsComputeAvailable(workspace *workspacev1alpha1.Workspace, compute client.Object) bool {
+	if compute == nil {
+		return false
+	}
+	return rm.computeBackendFor(GetComputeMode(workspace)).isAvailable(compute)
+}
+
+// EnsureComputeDeleted initiates the deletion of the compute resources of the workspace, whatever
+// their compute mode, and returns true once they are all missing or being deleted.
+// It does not wait for the resources to be fully removed.
+func (rm *ResourceManager) EnsureComputeDeleted(ctx context.Context, workspace *workspacev1alpha1.Workspace) (bool, error) {
+	logger := logf.FromContext(ctx)
+	stopped := true
+	for _, mode := range allComputeModes {
+		compute, err := rm.computeBackendFor(mode).get(ctx, workspace)
+		if err != nil {
+			return false, err
+		}
+		if compute == nil || !compute.GetDeletionTimestamp().IsZero() {
+			continue
+		}
+
+		stopped = false
+		logger.Info("Deleting workspace compute resource",
+			"mode", mode,
+			"name", compute.GetName(),
+			"namespace", compute.GetNamespace())
+		if err := rm.client.Delete(ctx, compute); err != nil && !errors.IsNotFound(err) {
+			return false, fmt.Errorf("failed to delete %s: %w", mode, err)
+		}
+	}
+	return stopped, nil
+}
+
+// areComputeResourcesDeleted checks if the compute resources of all modes are fully removed
+func (rm *ResourceManager) areComputeResourcesDeleted(ctx context.Context, workspace *workspacev1alpha1.Workspace) bool {
+	for _, mode := range allComputeModes {
+		compute, err := rm.computeBackendFor(mode).get(ctx, workspace)
+		if err != nil || compute != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// deploymentBackend runs the workspace pod with a single replica Deployment
+type deploymentBackend struct {
+	rm *ResourceManager
+}
+
+func (b *deploymentBackend) get(ctx context.Context, workspace *workspacev1alpha1.Workspace) (client.Object, error) {
+	deployment, err := b.rm.getDeployment(ctx, workspace)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get deployment: %w", err)
+	}
+	return deployment, nil
+}
+
+func (b *deploymentBackend) ensureExists(
+	ctx context.Context,
+	workspace *workspacev1alpha1.Workspace,
+	accessStrategy *workspacev1alpha1.WorkspaceAccessStrategy) (client.Object, error) {
+	deployment, err := b.rm.EnsureDeploymentExists(ctx, workspace, accessStrategy)
+	if err != nil {
+		return nil, err
+	}
+	return deployment, nil
+}
+
+func (b *deploymentBackend) isAvailable(compute client.Object) bool {
+	deployment, ok := compute.(*appsv1.Deployment)
+	return ok && b.rm.IsDeploymentAvailable(deployment)
+}
+
+// statefulSetBackend runs the workspace pod with a single replica StatefulSet, which never starts
+// a new pod before the previous one is gone and keeps a stable pod name
+type statefulSetBackend struct {
+	rm *ResourceManager
+}
+
+func (b *statefulSetBackend) get(ctx context.Context, workspace *workspacev1alpha1.Workspace) (client.Object, error) {
+	statefulSet := &appsv1.StatefulSet{}
+	err := b.rm.client.Get(ctx, types.NamespacedName{
+		Name:      GenerateDeploymentName(workspace.Name),
+		Namespace: workspace.Namespace,
+	}, statefulSet)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get statefulset: %w", err)
+	}
+	return statefulSet, nil
+}
+
+func (b *statefulSetBackend) ensureExists(
+	ctx context.Context,
+	workspace *workspacev1alpha1.Workspace,
+	accessStrategy *workspacev1alpha1.WorkspaceAccessStrategy) (client.Object, error) {
+	logger := logf.FromContext(ctx)
+
+	existing, err := b.get(ctx, workspace)
+	if err != nil {
+		return nil, err
+	}
+	desired, err := b.rm.deploymentBuilder.BuildStatefulSetWithAccessStrategy(ctx, workspace, accessStrategy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build statefulset: %w", err)
+	}
+
+	if existing == nil {
+		logger.Info("Creating StatefulSet",
+			"statefulset", desired.Name,
+			"namespace", desired.Namespace)
+		if err := b.rm.client.Create(ctx, desired); err != nil {
+			return nil, fmt.Errorf("failed to create statefulset: %w", err)
+		}
+		return desired, nil
+	}
+
+	// Only perform updates when workspace is available to avoid interfering with creation
+	statefulSet := existing.(*appsv1.StatefulSet)
+	if !b.rm.statusManager.IsWorkspaceAvailable(workspace) ||
+		!podTemplateChanged(&statefulSet.Spec.Template, &desired.Spec.Template) {
+		return statefulSet, nil
+	}
+
+	// The selector and service name of a StatefulSet are immutable, only its pod template is updated
+	statefulSet.Spec.Template = desired.Spec.Template
+	logger.Info("Updating StatefulSet",
+		"statefulset", statefulSet.Name,
+		"namespace", statefulSet.Namespace)
+	if err := b.rm.client.Update(ctx, statefulSet); err != nil {
+		return nil, fmt.Errorf("failed to update statefulset: %w", err)
+	}
+	return statefulSet, nil
+}
+
+func (b *statefulSetBackend) isAvailable(compute client.Object) bool {
+	statefulSet, ok := compute.(*appsv1.StatefulSet)
+	return ok && b.rm.IsStatefulSetAvailable(statefulSet)
+}
+
+// podBackend runs the workspace as a bare pod recreated by the controller. A replacement pod
+// is only created once the previous one is fully removed, since they share the same name.
+type podBackend struct {
+	rm *ResourceManager
+}
+
+func (b *podBackend) get(ctx context.Context, workspace *workspacev1alpha1.Workspace) (client.Object, error) {
+	pod := &corev1.Pod{}
+	err := b.rm.client.Get(ctx, types.NamespacedName{
+		Name:      GenerateDeploymentName(workspace.Name),
+		Namespace: workspace.Namespace,
+	}, pod)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get pod: %w", err)
+	}
+	return pod, nil
+}
+
+func (b *podBackend) ensureExists(
+	ctx context.Context,
+	workspace *workspacev1alpha1.Workspace,
+	accessStrategy *workspacev1alpha1.WorkspaceAccessStrategy) (client.Object, error) {
+	logger := logf.FromContext(ctx)
+
+	existing, err := b.get(ctx, workspace)
+	if err != nil {
+		return nil, err
+	}
+	desired, err := b.rm.deploymentBuilder.BuildPodWithAccessStrategy(ctx, workspace, accessStrategy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build pod: %w", err)
+	}
+
+	if existing == nil {
+		logger.Info("Creating workspace Pod",
+			"pod", desired.Name,
+			"namespace", desired.Namespace)
+		if err := b.rm.client.Create(ctx, desired); err != nil {
+			return nil, fmt.Errorf("failed to create pod: %w", err)
+		}
+		return desired, nil
+	}
+
+	pod := existing.(*corev1.Pod)
+	if !pod.DeletionTimestamp.IsZero() {
+		// wait for the previous pod to be gone before creating its replacement
+		return pod, nil
+	}
+
+	// A terminated pod is replaced, and so is an outdated one once the workspace is available,
+	// to avoid interfering with creation
+	terminated := pod.Status.Phase == corev1.PodFailed || pod.Status.Phase == corev1.PodSucceeded
+	outdated := b.rm.statusManager.IsWorkspaceAvailable(workspace) &&
+		pod.Annotations[AnnotationPodSpecHash] != desired.Annotations[AnnotationPodSpecHash]
+	if !terminated && !outdated {
+		return pod, nil
+	}
+
+	logger.Info("Deleting workspace Pod to recreate it",
+		"pod", pod.Name,
+		"namespace", pod.Namespace,
+		"phase", pod.Status.Phase,
+		"outdated", outdated)
+	if err := b.rm.client.Delete(ctx, pod); err != nil && !errors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to delete pod: %w", err)
+	}
+	return pod, nil
+}
+
+func (b *podBackend) isAvailable(compute client.Object) bool {
+	pod, ok := compute.(*corev1.Pod)
+	return ok && b.rm.IsPodAvailable(pod)
+}
@@ -0,0 +1,169 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	workspaceutil "github.com/jupyter-infra/jupyter-k8s/internal/workspace"
+)
+
+func setupComputeResourceManager(t *testing.T, objs ...client.Object) (*ResourceManager, client.Client) {
+	s := runtime.NewScheme()
+	require.NoError(t, scheme.AddToScheme(s))
+	require.NoError(t, workspacev1alpha1.AddToScheme(s))
+	k8sClient := fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build()
+	return &ResourceManager{
+		client:            k8sClient,
+		scheme:            s,
+		deploymentBuilder: NewDeploymentBuilder(s, WorkspaceControllerOptions{}, k8sClient),
+		statusManager:     NewStatusManager(k8sClient),
+	}, k8sClient
+}
+
+func computeTestWorkspace(mode workspacev1alpha1.ComputeMode) *workspacev1alpha1.Workspace {
+	return &workspacev1alpha1.Workspace{
+		ObjectMeta: metav1.ObjectMeta{Name: testWorkspaceName, Namespace: "default", UID: "ws-uid"},
+		Spec: workspacev1alpha1.WorkspaceSpec{
+			Image:         "jupyter/base-notebook:latest",
+			DesiredStatus: DesiredStateRunning,
+			ComputeMode:   mode,
+		},
+	}
+}
+
+// markWorkspaceAvailable sets the Available condition that allows updates of the compute resources
+func markWorkspaceAvailable(workspace *workspacev1alpha1.Workspace) {
+	workspace.Status.Conditions = []metav1.Condition{{Type: ConditionTypeAvailable, Status: metav1.ConditionTrue}}
+}
+
+func TestEnsureComputeExists_StatefulSet(t *testing.T) {
+	rm, k8sClient := setupComputeResourceManager(t)
+	workspace := computeTestWorkspace(workspacev1alpha1.ComputeModeStatefulSet)
+
+	compute, err := rm.EnsureComputeExists(context.Background(), workspace, nil)
+	require.NoError(t, err)
+
+	statefulSet := &appsv1.StatefulSet{}
+	require.NoError(t, k8sClient.Get(context.Background(), client.ObjectKeyFromObject(compute), statefulSet))
+	assert.Equal(t, GenerateDeploymentName(workspace.Name), statefulSet.Name)
+	assert.Equal(t, int32(1), *statefulSet.Spec.Replicas)
+	assert.Equal(t, GenerateServiceName(workspace.Name), statefulSet.Spec.ServiceName)
+	assert.True(t, metav1.IsControlledBy(statefulSet, workspace))
+	assert.False(t, rm.IsComputeAvailable(workspace, compute))
+
+	statefulSet.Status.ReadyReplicas = 1
+	assert.True(t, rm.IsComputeAvailable(workspace, statefulSet))
+
+	// the pod template is updated once the workspace is available
+	markWorkspaceAvailable(workspace)
+	workspace.Spec.Image = "jupyter/scipy-notebook:latest"
+	_, err = rm.EnsureComputeExists(context.Background(), workspace, nil)
+	require.NoError(t, err)
+	require.NoError(t, k8sClient.Get(context.Background(), client.ObjectKeyFromObject(compute), statefulSet))
+	assert.Equal(t, "jupyter/scipy-notebook:latest", statefulSet.Spec.Template.Spec.Containers[0].Image)
+}
+
+func TestEnsureComputeExists_Pod(t *testing.T) {
+	rm, k8sClient := setupComputeResourceManager(t)
+	workspace := computeTestWorkspace(workspacev1alpha1.ComputeModePod)
+
+	compute, err := rm.EnsureComputeExists(context.Background(), workspace, nil)
+	require.NoError(t, err)
+
+	pod := &corev1.Pod{}
+	require.NoError(t, k8sClient.Get(context.Background(), client.ObjectKeyFromObject(compute), pod))
+	assert.Equal(t, GenerateDeploymentName(workspace.Name), pod.Name)
+	assert.NotEmpty(t, pod.Annotations[AnnotationPodSpecHash])
+	assert.Equal(t, workspace.Name, pod.Labels[workspaceutil.LabelWorkspaceName])
+	assert.True(t, metav1.IsControlledBy(pod, workspace))
+	assert.False(t, rm.IsComputeAvailable(workspace, pod))
+
+	pod.Status.Phase = corev1.PodRunning
+	pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+	assert.True(t, rm.IsComputeAvailable(workspace, pod))
+
+	// an unchanged pod is kept
+	markWorkspaceAvailable(workspace)
+	_, err = rm.EnsureComputeExists(context.Background(), workspace, nil)
+	require.NoError(t, err)
+	require.NoError(t, k8sClient.Get(context.Background(), client.ObjectKeyFromObject(compute), pod))
+}
+
+func TestEnsureComputeExists_PodRecreation(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(workspace *workspacev1alpha1.Workspace, pod *corev1.Pod)
+	}{
+		{
+			name: "failed pod",
+			modify: func(_ *workspacev1alpha1.Workspace, pod *corev1.Pod) {
+				pod.Status.Phase = corev1.PodFailed
+			},
+		},
+		{
+			name: "outdated pod of an available workspace",
+			modify: func(workspace *workspacev1alpha1.Workspace, _ *corev1.Pod) {
+				markWorkspaceAvailable(workspace)
+				workspace.Spec.Image = "jupyter/scipy-notebook:latest"
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workspace := computeTestWorkspace(workspacev1alpha1.ComputeModePod)
+			rm, _ := setupComputeResourceManager(t)
+			pod, err := rm.deploymentBuilder.BuildPodWithAccessStrategy(context.Background(), workspace, nil)
+			require.NoError(t, err)
+			tt.modify(workspace, pod)
+			rm, k8sClient := setupComputeResourceManager(t, pod)
+
+			// the pod is deleted first, and only recreated once it is gone
+			_, err = rm.EnsureComputeExists(context.Background(), workspace, nil)
+			require.NoError(t, err)
+			err = k8sClient.Get(context.Background(), client.ObjectKeyFromObject(pod), &corev1.Pod{})
+			assert.True(t, errors.IsNotFound(err))
+
+			compute, err := rm.EnsureComputeExists(context.Background(), workspace, nil)
+			require.NoError(t, err)
+			recreated := &corev1.Pod{}
+			require.NoError(t, k8sClient.Get(context.Background(), client.ObjectKeyFromObject(compute), recreated))
+			assert.Empty(t, recreated.Status.Phase)
+		})
+	}
+}
+
+func TestEnsureComputeDeleted_AllModes(t *testing.T) {
+	workspace := computeTestWorkspace(workspacev1alpha1.ComputeModePod)
+	labels := GenerateLabels(workspace.Name)
+	name := GenerateDeploymentName(workspace.Name)
+	rm, _ := setupComputeResourceManager(t,
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels}})
+
+	stopped, err := rm.EnsureComputeDeleted(context.Background(), workspace)
+	require.NoError(t, err)
+	assert.False(t, stopped)
+	assert.True(t, rm.areComputeResourcesDeleted(context.Background(), workspace))
+
+	stopped, err = rm.EnsureComputeDeleted(context.Background(), workspace)
+	require.NoError(t, err)
+	assert.True(t, stopped)
+}
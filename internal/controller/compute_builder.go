@@ -0,0 +1,93 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// BuildStatefulSetWithAccessStrategy creates a single replica StatefulSet running the same pod
+// as the workspace Deployment
+func (db *DeploymentBuilder) BuildStatefulSetWithAccessStrategy(
+	ctx context.Context,
+	workspace *workspacev1alpha1.Workspace,
+	accessStrategy *workspacev1alpha1.WorkspaceAccessStrategy,
+) (*appsv1.StatefulSet, error) {
+	deployment, err := db.BuildDeploymentWithAccessStrategy(ctx, workspace, accessStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	return &appsv1.StatefulSet{
+		ObjectMeta: deployment.ObjectMeta,
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:    deployment.Spec.Replicas,
+			Selector:    deployment.Spec.Selector,
+			ServiceName: GenerateServiceName(workspace.Name),
+			Template:    deployment.Spec.Template,
+			// The previous pod is always gone before its replacement starts, like the Recreate strategy
+			PodManagementPolicy: appsv1.OrderedReadyPodManagement,
+		},
+	}, nil
+}
+
+// BuildPodWithAccessStrategy creates the bare pod of a workspace, annotated with the hash of its spec
+// so that the controller can tell when it must be recreated
+func (db *DeploymentBuilder) BuildPodWithAccessStrategy(
+	ctx context.Context,
+	workspace *workspacev1alpha1.Workspace,
+	accessStrategy *workspacev1alpha1.WorkspaceAccessStrategy,
+) (*corev1.Pod, error) {
+	deployment, err := db.BuildDeploymentWithAccessStrategy(ctx, workspace, accessStrategy)
+	if err != nil {
+		return nil, err
+	}
+
+	template := deployment.Spec.Template
+	hash, err := podSpecHash(&template)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash pod spec: %w", err)
+	}
+	annotations := make(map[string]string, len(template.Annotations)+1)
+	for key, value := range template.Annotations {
+		annotations[key] = value
+	}
+	annotations[AnnotationPodSpecHash] = hash
+
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            deployment.Name,
+			Namespace:       deployment.Namespace,
+			Labels:          template.Labels,
+			Annotations:     annotations,
+			OwnerReferences: deployment.OwnerReferences,
+		},
+		Spec: template.Spec,
+	}, nil
+}
+
+// podSpecHash returns a hash of the labels and spec of a pod template.
+// Annotations are left out since the controller patches them on running pods.
+func podSpecHash(template *corev1.PodTemplateSpec) (string, error) {
+	data, err := json.Marshal(struct {
+		Labels map[string]string `json:"labels"`
+		Spec   corev1.PodSpec    `json:"spec"`
+	}{template.Labels, template.Spec})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8]), nil
+}
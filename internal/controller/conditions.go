@@ -25,6 +25,24 @@ const (
 
 	// ConditionTypeStopped indicates if the Workspace is in a stopped state
 	ConditionTypeStopped = "Stopped"
+
+	// ConditionTypeIdleShutdownPending indicates the Workspace is idle and will be stopped after the grace period
+	ConditionTypeIdleShutdownPending = "IdleShutdownPending"
+
+	// ConditionTypePreempted indicates the Workspace pod was preempted and the Workspace has not recovered yet
+	ConditionTypePreempted = "Preempted"
+
+	// ConditionTypeStorageResizing indicates an expansion of the Workspace storage is in progress or blocked
+	ConditionTypeStorageResizing = "StorageResizing"
+
+	// ConditionTypeQuotaExceeded indicates the controller did not start the Workspace because it would exceed a quota
+	ConditionTypeQuotaExceeded = "QuotaExceeded"
+
+	// ConditionTypeDeletionScheduled indicates the lifecycle policy of the Workspace is about to delete it
+	ConditionTypeDeletionScheduled = "DeletionScheduled"
+
+	// ConditionTypeSharedVolumeConflict indicates shared volumes of the namespace are not mounted in the Workspace
+	ConditionTypeSharedVolumeConflict = "SharedVolumeConflict"
 )
 
 // Condition reasons for Workspace resources
@@ -50,8 +68,48 @@ const (
 	ReasonServiceError    = "ServiceError"
 	ReasonNoError         = "NoError"
 
-	// ConditionTypeAvailable reasons (special cases)
+	// ConditionTypeDegraded reasons diagnosed from the workspace pod
+	ReasonImagePullFailed       = "ImagePullFailed"
+	ReasonOutOfMemory           = "OutOfMemory"
+	ReasonInsufficientResources = "InsufficientResources"
+	ReasonUnschedulable         = "Unschedulable"
+	ReasonCrashLoopBackOff      = "CrashLoopBackOff"
+	ReasonContainerConfigError  = "ContainerConfigError"
+
+	// ConditionTypeDegraded reason when the workspace exceeded its startup timeout
+	ReasonStartupFailed = "StartupFailed"
+
+	// ConditionTypeAvailable reasons (special cases), also used by ConditionTypePreempted
 	ReasonPreempted = "Preempted"
+
+	// ConditionTypePreempted reasons
+	ReasonPreemptionRestarted         = "PreemptionRestarted"
+	ReasonPreemptionRestartsExhausted = "PreemptionRestartsExhausted"
+	ReasonPreemptionRecovered         = "PreemptionRecovered"
+	ReasonPreemptionRestartCanceled   = "PreemptionRestartCanceled"
+
+	// ConditionTypeIdleShutdownPending reasons
+	ReasonIdleTimeoutReached   = "IdleTimeoutReached"
+	ReasonActivityResumed      = "ActivityResumed"
+	ReasonIdleShutdownDisabled = "IdleShutdownDisabled"
+	ReasonWorkspaceStopped     = "WorkspaceStopped"
+
+	// ConditionTypeStorageResizing reasons
+	ReasonExpansionNotSupported   = "ExpansionNotSupported"
+	ReasonResizePending           = "ResizePending"
+	ReasonResizeInProgress        = "ResizeInProgress"
+	ReasonFileSystemResizePending = "FileSystemResizePending"
+	ReasonResizeCompleted         = "ResizeCompleted"
+
+	// ConditionTypeQuotaExceeded reasons
+	ReasonStartBlockedByQuota = "StartBlockedByQuota"
+
+	// ConditionTypeDeletionScheduled reasons
+	ReasonLifecycleDeletionScheduled = "LifecycleDeletionScheduled"
+	ReasonLifecycleDeletionSkipped   = "LifecycleDeletionSkipped"
+
+	// ConditionTypeSharedVolumeConflict reasons
+	ReasonMountPathConflict = "MountPathConflict"
 )
 
 // NewCondition creates a new condition with the specified status
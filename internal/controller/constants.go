@@ -20,6 +20,13 @@ const (
 
 	// JupyterPort is the default port for Jupyter server
 	JupyterPort = 8888
+	// JupyterPortName is the name of the workspace container port
+	JupyterPortName = "http"
+	// JupyterBaseURLEnv is the environment variable holding the Jupyter server base URL
+	JupyterBaseURLEnv = "JUPYTER_BASE_URL"
+
+	// AppTypeJupyter is the AppType of Jupyter server workspaces
+	AppTypeJupyter = "jupyter"
 
 	// DefaultMountPath is the default mount path for workspace storage
 	DefaultMountPath = "/home/jovyan"
@@ -48,6 +55,8 @@ const (
 
 	// AnnotationCreatedBy is the annotation key for tracking resource creator
 	AnnotationCreatedBy = "workspace.jupyter.org/created-by"
+	// AnnotationCreatedByGroups is the annotation key for tracking the groups of the resource creator
+	AnnotationCreatedByGroups = "workspace.jupyter.org/created-by-groups"
 	// AnnotationLastUpdatedBy is the annotation key for tracking last updater
 	AnnotationLastUpdatedBy = "workspace.jupyter.org/last-updated-by"
 	// AnnotationServiceAccountUsers is the annotation key for service account users
@@ -67,6 +76,31 @@ const (
 
 	// PreemptionReasonAnnotation is the annotation key for preemption reason
 	PreemptionReasonAnnotation = "workspace.jupyter.org/preemption-reason"
+	// PreemptedAtAnnotation is the annotation key for the time of the last preemption
+	PreemptedAtAnnotation = "workspace.jupyter.org/preempted-at"
+
+	// AnnotationDeletionProtection is the annotation key protecting a workspace from deletion by its lifecycle policy
+	AnnotationDeletionProtection = "workspace.jupyter.org/deletion-protection"
+
+	// AnnotationLifecycleDeletion is the annotation key recording why the lifecycle policy deleted a workspace
+	AnnotationLifecycleDeletion = "workspace.jupyter.org/lifecycle-deletion"
+
+	// AnnotationAllowClone is the annotation key letting users other than the creator clone a workspace
+	AnnotationAllowClone = "workspace.jupyter.org/allow-clone"
+
+	// AnnotationIdleShutdownAt is the pod annotation holding the time at which an idle workspace will be stopped
+	AnnotationIdleShutdownAt = "workspace.jupyter.org/idle-shutdown-at"
+	// IdleShutdownNoticeVolumeName is the name of the downward API volume exposing the idle shutdown notice
+	IdleShutdownNoticeVolumeName = "idle-shutdown-notice"
+	// IdleShutdownNoticeMountPath is the directory where the idle shutdown notice is mounted
+	IdleShutdownNoticeMountPath = "/etc/workspace-notice"
+	// IdleShutdownNoticeFileName is the file holding the scheduled idle shutdown time, empty when none is pending
+	IdleShutdownNoticeFileName = "idle-shutdown-at"
+	// IdleShutdownNoticeFileEnv is the environment variable pointing the workspace to the idle shutdown notice file
+	IdleShutdownNoticeFileEnv = "WORKSPACE_IDLE_SHUTDOWN_NOTICE_FILE"
+
+	// AnnotationPodSpecHash is the annotation holding the hash of the spec a bare workspace pod was built from
+	AnnotationPodSpecHash = "workspace.jupyter.org/pod-spec-hash"
 
 	// KindPod represents the Pod resource kind
 	KindPod = "Pod"
@@ -88,23 +122,86 @@ const (
 	// LongRequeueDelay is the delay for long reconciliation cycles
 	LongRequeueDelay = 60 * time.Second
 
+	// StartupPollMinDelay is the shortest delay between readiness checks of a starting workspace
+	StartupPollMinDelay = 1 * time.Second
+	// StartupPollMaxDelay is the longest delay between readiness checks of a starting workspace
+	StartupPollMaxDelay = 30 * time.Second
+	// StartupRetryBaseDelay is the backoff before the first retry of a workspace that failed to start
+	StartupRetryBaseDelay = 30 * time.Second
+	// StartupRetryMaxDelay caps the exponential backoff between startup retries
+	StartupRetryMaxDelay = 10 * time.Minute
+
+	// DefaultPreemptionRestartMaxAttempts is the default number of consecutive restarts after preemption
+	DefaultPreemptionRestartMaxAttempts = 3
+	// DefaultPreemptionRestartBackoff is the default delay before the first restart after preemption
+	DefaultPreemptionRestartBackoff = 30 * time.Second
+	// DefaultPreemptionRestartMaxBackoff is the default cap of the delay before a restart after preemption
+	DefaultPreemptionRestartMaxBackoff = 10 * time.Minute
+	// PreemptionRestartResetAfter is how long a workspace must run before its preemption restarts count is reset
+	PreemptionRestartResetAfter = 10 * time.Minute
+	// MaxPreemptionHistory is the number of preemptions kept in the workspace status
+	MaxPreemptionHistory = 10
+
 	// IdleCheckInterval is the interval for checking workspace idle status
 	IdleCheckInterval = 5 * time.Minute
 
+	// ScheduleRequeueSlack is added to the next schedule boundary so the requeue never fires early
+	ScheduleRequeueSlack = 1 * time.Second
+
 	// WorkspaceFinalizerName is the finalizer name for workspace cleanup protection
 	WorkspaceFinalizerName = "workspace.jupyter.org/workspace-protection"
 
+	// SecondaryVolumeNamePrefix prefixes the names of the pod volumes mounting secondary storages
+	SecondaryVolumeNamePrefix = "secondary-"
+
+	// SharedVolumeNamePrefix prefixes the names of the pod volumes mounting shared volumes
+	SharedVolumeNamePrefix = "shared-"
+
 	// ControllerPodNamespaceEnv is the environment variable for the controller pod namespace
 	ControllerPodNamespaceEnv = "CONTROLLER_POD_NAMESPACE"
 
 	// ControllerPodServiceAccountEnv is the environment variable for the controller pod service account
 	ControllerPodServiceAccountEnv = "CONTROLLER_POD_SERVICE_ACCOUNT"
 
+	// IdlePrometheusAllowedURLsEnv is the environment variable listing, comma-separated, the Prometheus APIs
+	// that workspaces may use for metrics-based idle detection. When unset, the Prometheus source is disabled.
+	IdlePrometheusAllowedURLsEnv = "IDLE_PROMETHEUS_ALLOWED_URLS"
+
 	// ResourcePrefix is the prefix for workspace resource names
 	ResourcePrefix = "workspace"
 
 	// ReservedMetadataPrefix is the prefix reserved for system-managed labels and annotations
 	ReservedMetadataPrefix = "workspace.jupyter.org/"
+
+	// VolumeSnapshotAPIGroup is the API group of CSI VolumeSnapshots
+	VolumeSnapshotAPIGroup = "snapshot.storage.k8s.io"
+	// VolumeSnapshotAPIVersion is the API version of CSI VolumeSnapshots
+	VolumeSnapshotAPIVersion = "v1"
+	// KindVolumeSnapshot represents the VolumeSnapshot resource kind
+	KindVolumeSnapshot = "VolumeSnapshot"
+
+	// ComponentSnapshot is the component label value for workspace storage snapshots
+	ComponentSnapshot = "snapshot"
+
+	// DefaultSnapshotRetain is the default number of snapshots kept per workspace
+	DefaultSnapshotRetain = 3
+
+	// ComponentRetainedStorage is the component label value of PVCs retained from deleted workspaces
+	ComponentRetainedStorage = "retained-storage"
+	// AnnotationRetainedFrom is the PVC annotation holding the name of the deleted workspace it was retained from
+	AnnotationRetainedFrom = "workspace.jupyter.org/retained-from"
+	// AnnotationRetainUntil is the PVC annotation holding the time after which a retained PVC is deleted
+	AnnotationRetainUntil = "workspace.jupyter.org/retain-until"
+
+	// ComponentSharedVolume is the component label value of the PVCs provisioned for shared volumes
+	ComponentSharedVolume = "shared-volume"
+	// LabelSharedVolumeName is the label key for the name of the shared volume of a PVC
+	LabelSharedVolumeName = "workspace.jupyter.org/shared-volume-name"
+
+	// ComponentSecondaryStorage is the component label value of the secondary storage PVCs of workspaces
+	ComponentSecondaryStorage = "secondary-storage"
+	// LabelSecondaryStorageName is the label key for the name of the secondary storage of a PVC
+	LabelSecondaryStorageName = "workspace.jupyter.org/secondary-storage-name"
 )
 
 // MetadataKeyPolicy defines how a system-managed metadata key behaves across operations
@@ -115,18 +212,27 @@ const (
 	SetOnCreateOnly MetadataKeyPolicy = iota
 	// SetAlways indicates the key is set on every create/update by the system
 	SetAlways
+	// SetByUser indicates the key is set by users and read by the system
+	SetByUser
+	// SetByController indicates the key is only set by the controller, users cannot add or change it
+	SetByController
 )
 
 // SystemManagedMetadataKeys defines all workspace.jupyter.org/ prefixed keys that the system manages.
 // Any new system-managed key with the reserved prefix MUST be added here.
 var SystemManagedMetadataKeys = map[string]MetadataKeyPolicy{
 	AnnotationCreatedBy:             SetOnCreateOnly,
+	AnnotationCreatedByGroups:       SetOnCreateOnly,
 	AnnotationLastUpdatedBy:         SetAlways,
 	PreemptionReasonAnnotation:      SetAlways,
+	PreemptedAtAnnotation:           SetAlways,
 	LabelWorkspaceTemplate:          SetAlways,
 	LabelWorkspaceTemplateNamespace: SetAlways,
 	LabelAccessStrategyName:         SetAlways,
 	LabelAccessStrategyNamespace:    SetAlways,
+	AnnotationDeletionProtection:    SetByUser,
+	AnnotationLifecycleDeletion:     SetByController,
+	AnnotationAllowClone:            SetByUser,
 }
 
 // GenerateDeploymentName creates a consistent deployment name
@@ -144,6 +250,31 @@ func GeneratePVCName(workspaceName string) string {
 	return fmt.Sprintf("%s-%s-pvc", ResourcePrefix, workspaceName)
 }
 
+// GenerateSecondaryPVCName creates a consistent PVC name for a secondary storage of a workspace
+func GenerateSecondaryPVCName(workspaceName, storageName string) string {
+	return fmt.Sprintf("%s-%s-secondary-%s-pvc", ResourcePrefix, workspaceName, storageName)
+}
+
+// GenerateSecondaryVolumeName creates the name of the pod volume mounting a secondary storage
+func GenerateSecondaryVolumeName(storageName string) string {
+	return SecondaryVolumeNamePrefix + storageName
+}
+
+// GenerateSharedVolumePVCName creates a consistent PVC name for a shared volume
+func GenerateSharedVolumePVCName(sharedVolumeName string) string {
+	return fmt.Sprintf("%s-shared-%s-pvc", ResourcePrefix, sharedVolumeName)
+}
+
+// GenerateSharedVolumeName creates the name of the pod volume mounting a shared volume
+func GenerateSharedVolumeName(sharedVolumeName string) string {
+	return SharedVolumeNamePrefix + sharedVolumeName
+}
+
+// GenerateSnapshotName creates a consistent VolumeSnapshot name for the given snapshot time
+func GenerateSnapshotName(workspaceName string, t time.Time) string {
+	return fmt.Sprintf("%s-%s-snap-%s", ResourcePrefix, workspaceName, t.UTC().Format("20060102150405"))
+}
+
 // GenerateLabels creates consistent labels for resources
 func GenerateLabels(workspaceName string) map[string]string {
 	return map[string]string{
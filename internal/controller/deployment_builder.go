@@ -8,6 +8,7 @@ package controller
 import (
 	"context"
 	"fmt"
+	"path"
 
 	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
 
@@ -26,6 +27,7 @@ type DeploymentBuilder struct {
 	scheme        *runtime.Scheme
 	options       WorkspaceControllerOptions
 	imageResolver *ImageResolver
+	client        client.Client
 }
 
 // NewDeploymentBuilder creates a new DeploymentBuilder
@@ -34,16 +36,35 @@ func NewDeploymentBuilder(scheme *runtime.Scheme, options WorkspaceControllerOpt
 		scheme:        scheme,
 		options:       options,
 		imageResolver: NewImageResolver(options.ApplicationImagesRegistry),
+		client:        k8sClient,
 	}
 }
 
 // BuildDeployment creates a Deployment resource for the given Workspace
 func (db *DeploymentBuilder) BuildDeployment(ctx context.Context, workspace *workspacev1alpha1.Workspace) (*appsv1.Deployment, error) {
+	return db.buildDeployment(ctx, workspace, nil)
+}
+
+// buildDeployment creates the Deployment resource, with the primary container env of the access strategy if any
+func (db *DeploymentBuilder) buildDeployment(
+	ctx context.Context,
+	workspace *workspacev1alpha1.Workspace,
+	accessStrategy *workspacev1alpha1.WorkspaceAccessStrategy,
+) (*appsv1.Deployment, error) {
 	resources := db.parseResourceRequirements(workspace)
 
+	spec, err := db.buildDeploymentSpec(workspace, resources, accessStrategy)
+	if err != nil {
+		return nil, err
+	}
+
 	deployment := &appsv1.Deployment{
 		ObjectMeta: db.buildObjectMeta(workspace),
-		Spec:       db.buildDeploymentSpec(workspace, resources),
+		Spec:       spec,
+	}
+
+	if err := db.applySharedVolumes(ctx, &deployment.Spec.Template.Spec, workspace); err != nil {
+		return nil, err
 	}
 
 	if err := controllerutil.SetControllerReference(workspace, deployment, db.scheme); err != nil {
@@ -61,13 +82,13 @@ func (db *DeploymentBuilder) BuildDeploymentWithAccessStrategy(
 	accessStrategy *workspacev1alpha1.WorkspaceAccessStrategy,
 ) (*appsv1.Deployment, error) {
 	// Build the base deployment
-	deployment, err := db.BuildDeployment(ctx, workspace)
+	deployment, err := db.buildDeployment(ctx, workspace, accessStrategy)
 	if err != nil {
 		return nil, err
 	}
 
 	if accessStrategy != nil {
-		if err := db.ApplyAccessStrategyToDeployment(deployment, workspace, accessStrategy); err != nil {
+		if err := db.applyDeploymentSpecModifications(deployment, accessStrategy); err != nil {
 			return nil, fmt.Errorf("failed to apply access strategy to deployment: %w", err)
 		}
 	}
@@ -133,10 +154,19 @@ func (db *DeploymentBuilder) buildPodAnnotations(workspace *workspacev1alpha1.Wo
 }
 
 // buildDeploymentSpec creates the deployment specification
-func (db *DeploymentBuilder) buildDeploymentSpec(workspace *workspacev1alpha1.Workspace, resources corev1.ResourceRequirements) appsv1.DeploymentSpec {
+func (db *DeploymentBuilder) buildDeploymentSpec(
+	workspace *workspacev1alpha1.Workspace,
+	resources corev1.ResourceRequirements,
+	accessStrategy *workspacev1alpha1.WorkspaceAccessStrategy,
+) (appsv1.DeploymentSpec, error) {
 	// Single replica for Jupyter workspaces (stateful, user-specific workloads)
 	replicas := int32(1)
 
+	podSpec, err := db.buildPodSpec(workspace, resources, accessStrategy)
+	if err != nil {
+		return appsv1.DeploymentSpec{}, err
+	}
+
 	return appsv1.DeploymentSpec{
 		Replicas: &replicas,
 		Strategy: appsv1.DeploymentStrategy{
@@ -150,49 +180,129 @@ func (db *DeploymentBuilder) buildDeploymentSpec(workspace *workspacev1alpha1.Wo
 				Labels:      db.buildPodLabels(workspace),
 				Annotations: db.buildPodAnnotations(workspace),
 			},
-			Spec: db.buildPodSpec(workspace, resources),
+			Spec: podSpec,
 		},
+	}, nil
+}
+
+// buildStorageVolumeSource returns the volume source of the workspace storage for its storage type
+func buildStorageVolumeSource(workspace *workspacev1alpha1.Workspace, storageConfig *ResolvedStorageConfig) corev1.VolumeSource {
+	switch workspace.Spec.Storage.Type {
+	case workspacev1alpha1.StorageTypeEmptyDir:
+		sizeLimit := storageConfig.Size
+		return corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{SizeLimit: &sizeLimit},
+		}
+	case workspacev1alpha1.StorageTypeEphemeralVolume:
+		return corev1.VolumeSource{
+			Ephemeral: &corev1.EphemeralVolumeSource{
+				VolumeClaimTemplate: &corev1.PersistentVolumeClaimTemplate{
+					Spec: corev1.PersistentVolumeClaimSpec{
+						AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+						StorageClassName: storageConfig.StorageClassName,
+						Resources: corev1.VolumeResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceStorage: storageConfig.Size},
+						},
+					},
+				},
+			},
+		}
+	default:
+		return corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: GetPVCName(workspace),
+			},
+		}
+	}
+}
+
+// buildVolumeSource returns the volume source of an additional volume of the workspace
+func buildVolumeSource(volume *workspacev1alpha1.VolumeSpec) corev1.VolumeSource {
+	switch {
+	case volume.ConfigMap != nil:
+		return corev1.VolumeSource{ConfigMap: volume.ConfigMap.DeepCopy()}
+	case volume.Secret != nil:
+		return corev1.VolumeSource{Secret: volume.Secret.DeepCopy()}
+	case volume.CSI != nil:
+		return corev1.VolumeSource{CSI: volume.CSI.DeepCopy()}
+	default:
+		return corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: volume.PersistentVolumeClaimName,
+				ReadOnly:  volume.ReadOnly,
+			},
+		}
 	}
 }
 
 // buildPodSpec creates the pod specification
-func (db *DeploymentBuilder) buildPodSpec(workspace *workspacev1alpha1.Workspace, resources corev1.ResourceRequirements) corev1.PodSpec {
+func (db *DeploymentBuilder) buildPodSpec(
+	workspace *workspacev1alpha1.Workspace,
+	resources corev1.ResourceRequirements,
+	accessStrategy *workspacev1alpha1.WorkspaceAccessStrategy,
+) (corev1.PodSpec, error) {
+	container, err := db.buildPrimaryContainer(workspace, resources, accessStrategy)
+	if err != nil {
+		return corev1.PodSpec{}, err
+	}
+
 	podSpec := corev1.PodSpec{
-		Containers: []corev1.Container{
-			db.buildPrimaryContainer(workspace, resources),
-		},
+		Containers: []corev1.Container{container},
 	}
 
 	storageConfig := ResolveStorageConfig(workspace)
 	if storageConfig != nil {
 		podSpec.Volumes = []corev1.Volume{
 			{
-				Name: "workspace-storage",
-				VolumeSource: corev1.VolumeSource{
-					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-						ClaimName: GeneratePVCName(workspace.Name),
-					},
-				},
+				Name:         "workspace-storage",
+				VolumeSource: buildStorageVolumeSource(workspace, storageConfig),
 			},
 		}
 	}
 
 	// Add additional volumes from spec
-	for _, vol := range workspace.Spec.Volumes {
+	for i := range workspace.Spec.Volumes {
+		vol := &workspace.Spec.Volumes[i]
 		if vol.Name == "workspace-storage" {
 			// Skip if name conflicts with primary storage
 			continue
 		}
 		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
-			Name: vol.Name,
+			Name:         vol.Name,
+			VolumeSource: buildVolumeSource(vol),
+		})
+	}
+
+	// Add the volumes of the secondary storages provisioned by the controller
+	for _, storage := range workspace.Spec.SecondaryStorages {
+		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+			Name: GenerateSecondaryVolumeName(storage.Name),
 			VolumeSource: corev1.VolumeSource{
 				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-					ClaimName: vol.PersistentVolumeClaimName,
+					ClaimName: GenerateSecondaryPVCName(workspace.Name, storage.Name),
 				},
 			},
 		})
 	}
 
+	// Expose the idle shutdown notice annotation of the pod as a file. The notice is always mounted,
+	// so that enabling or disabling idle shutdown does not change the pod template and restart the pod
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name: IdleShutdownNoticeVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			DownwardAPI: &corev1.DownwardAPIVolumeSource{
+				Items: []corev1.DownwardAPIVolumeFile{
+					{
+						Path: IdleShutdownNoticeFileName,
+						FieldRef: &corev1.ObjectFieldSelector{
+							FieldPath: fmt.Sprintf("metadata.annotations['%s']", AnnotationIdleShutdownAt),
+						},
+					},
+				},
+			},
+		},
+	})
+
 	// Set scheduling fields from workspace spec
 	if len(workspace.Spec.NodeSelector) > 0 {
 		podSpec.NodeSelector = workspace.Spec.NodeSelector
@@ -215,11 +325,25 @@ func (db *DeploymentBuilder) buildPodSpec(workspace *workspacev1alpha1.Workspace
 		podSpec.SecurityContext = workspace.Spec.PodSecurityContext
 	}
 
-	return podSpec
+	if workspace.Spec.PriorityClassName != "" {
+		podSpec.PriorityClassName = workspace.Spec.PriorityClassName
+	}
+
+	// Bump the priority of a workspace restarted after a preemption, it takes precedence over the workspace priority class
+	if policy := workspace.Spec.PreemptionRestart; policy != nil && policy.PriorityClassName != "" &&
+		workspace.Status.PreemptionRestarts > 0 {
+		podSpec.PriorityClassName = policy.PriorityClassName
+	}
+
+	return podSpec, nil
 }
 
 // buildPrimaryContainer creates the container specification
-func (db *DeploymentBuilder) buildPrimaryContainer(workspace *workspacev1alpha1.Workspace, resources corev1.ResourceRequirements) corev1.Container {
+func (db *DeploymentBuilder) buildPrimaryContainer(
+	workspace *workspacev1alpha1.Workspace,
+	resources corev1.ResourceRequirements,
+	accessStrategy *workspacev1alpha1.WorkspaceAccessStrategy,
+) (corev1.Container, error) {
 	image := db.imageResolver.ResolveImage(workspace)
 
 	// Get command and args from container config if specified
@@ -241,13 +365,12 @@ func (db *DeploymentBuilder) buildPrimaryContainer(workspace *workspacev1alpha1.
 		Env:             workspace.Spec.Env,
 		Ports: []corev1.ContainerPort{
 			{
-				Name:          "http",
+				Name:          JupyterPortName,
 				ContainerPort: JupyterPort,
 				Protocol:      corev1.ProtocolTCP,
 			},
 		},
 		Resources: resources,
-		// TODO: Add probes
 	}
 
 	storageConfig := ResolveStorageConfig(workspace)
@@ -269,10 +392,42 @@ func (db *DeploymentBuilder) buildPrimaryContainer(workspace *workspacev1alpha1.
 		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
 			Name:      vol.Name,
 			MountPath: vol.MountPath,
+			ReadOnly:  vol.ReadOnly,
+			SubPath:   vol.SubPath,
 		})
 	}
 
-	return container
+	// Add secondary storage mounts
+	for _, storage := range workspace.Spec.SecondaryStorages {
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      GenerateSecondaryVolumeName(storage.Name),
+			MountPath: storage.MountPath,
+		})
+	}
+
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      IdleShutdownNoticeVolumeName,
+		MountPath: IdleShutdownNoticeMountPath,
+		ReadOnly:  true,
+	})
+	// Copy the env so that the workspace spec is not modified
+	container.Env = append(append([]corev1.EnvVar{}, workspace.Spec.Env...), corev1.EnvVar{
+		Name:  IdleShutdownNoticeFileEnv,
+		Value: path.Join(IdleShutdownNoticeMountPath, IdleShutdownNoticeFileName),
+	})
+
+	if accessStrategy != nil {
+		// Copy the env so that the workspace spec is not modified
+		container.Env = append([]corev1.EnvVar{}, container.Env...)
+		if err := db.addAccessStrategyEnvToContainer(&container, workspace, accessStrategy); err != nil {
+			return corev1.Container{}, fmt.Errorf("failed to add environment variables to container: %w", err)
+		}
+	}
+
+	// The access strategy env may set the base URL that the default probes target
+	applyProbes(&container, workspace)
+
+	return container, nil
 }
 
 // parseResourceRequirements extracts and validates resource requirements
@@ -318,19 +473,20 @@ func (db *DeploymentBuilder) NeedsUpdate(
 		return false, fmt.Errorf("failed to build desired deployment: %w", err)
 	}
 
+	return podTemplateChanged(&existingDeployment.Spec.Template, &desiredDeployment.Spec.Template), nil
+}
+
+// podTemplateChanged reports whether the existing pod template differs from the desired one
+func podTemplateChanged(existing, desired *corev1.PodTemplateSpec) bool {
 	// Compare pod template specs and metadata using semantic equality
-	if !equality.Semantic.DeepEqual(existingDeployment.Spec.Template.Spec, desiredDeployment.Spec.Template.Spec) {
-		return true, nil
+	if !equality.Semantic.DeepEqual(existing.Spec, desired.Spec) {
+		return true
 	}
 
 	// Compare pod template metadata (labels and annotations)
-	if !equality.Semantic.DeepEqual(existingDeployment.Spec.Template.Labels, desiredDeployment.Spec.Template.Labels) {
-		return true, nil
-	}
-
-	if !equality.Semantic.DeepEqual(existingDeployment.Spec.Template.Annotations, desiredDeployment.Spec.Template.Annotations) {
-		return true, nil
+	if !equality.Semantic.DeepEqual(existing.Labels, desired.Labels) {
+		return true
 	}
 
-	return false, nil
+	return !equality.Semantic.DeepEqual(existing.Annotations, desired.Annotations)
 }
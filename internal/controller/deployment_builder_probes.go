@@ -0,0 +1,129 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package controller
+
+import (
+	"strings"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// Default probe settings
+const (
+	// DefaultStartupProbeFailureThreshold allows applications 5 minutes to start
+	DefaultStartupProbeFailureThreshold = 60
+	// DefaultStartupProbePeriodSeconds is the period of the default startup probe
+	DefaultStartupProbePeriodSeconds = 5
+	// DefaultReadinessProbePeriodSeconds is the period of the default readiness probe
+	DefaultReadinessProbePeriodSeconds = 10
+	// DefaultLivenessProbePeriodSeconds is the period of the default liveness probe
+	DefaultLivenessProbePeriodSeconds = 30
+	// DefaultLivenessProbeFailureThreshold tolerates a busy application for a few minutes before restarting it
+	DefaultLivenessProbeFailureThreshold = 5
+	// DefaultProbeTimeoutSeconds is the timeout of the default probes
+	DefaultProbeTimeoutSeconds = 5
+
+	// jupyterProbePath is the Jupyter server endpoint answering without authentication
+	jupyterProbePath = "/api"
+)
+
+// applyProbes sets the probes of the workspace container
+// Probes set on the workspace take precedence over the defaults of its AppType,
+// and DisableDefaults leaves the probes that are not set empty
+func applyProbes(container *corev1.Container, workspace *workspacev1alpha1.Workspace) {
+	var probes workspacev1alpha1.ProbesSpec
+	if workspace.Spec.Probes != nil {
+		probes = *workspace.Spec.Probes.DeepCopy()
+	}
+	if !probes.DisableDefaults {
+		defaults := defaultProbes(workspace.Spec.AppType, container)
+		if probes.ReadinessProbe == nil {
+			probes.ReadinessProbe = defaults.ReadinessProbe
+		}
+		if probes.LivenessProbe == nil {
+			probes.LivenessProbe = defaults.LivenessProbe
+		}
+		if probes.StartupProbe == nil {
+			probes.StartupProbe = defaults.StartupProbe
+		}
+	}
+
+	container.ReadinessProbe = withProbeDefaults(probes.ReadinessProbe)
+	container.LivenessProbe = withProbeDefaults(probes.LivenessProbe)
+	container.StartupProbe = withProbeDefaults(probes.StartupProbe)
+}
+
+// withProbeDefaults sets the fields that the API server would default on the probe,
+// so that the built deployment compares equal to the stored one
+func withProbeDefaults(probe *corev1.Probe) *corev1.Probe {
+	if probe == nil {
+		return nil
+	}
+	if probe.TimeoutSeconds == 0 {
+		probe.TimeoutSeconds = 1
+	}
+	if probe.PeriodSeconds == 0 {
+		probe.PeriodSeconds = 10
+	}
+	if probe.SuccessThreshold == 0 {
+		probe.SuccessThreshold = 1
+	}
+	if probe.FailureThreshold == 0 {
+		probe.FailureThreshold = 3
+	}
+	if probe.HTTPGet != nil && probe.HTTPGet.Scheme == "" {
+		probe.HTTPGet.Scheme = corev1.URISchemeHTTP
+	}
+	return probe
+}
+
+// defaultProbes returns the default probes for the AppType
+// Jupyter servers are probed on their REST API; other applications get no default probes,
+// since the controller cannot know which port or path they serve
+func defaultProbes(appType string, container *corev1.Container) workspacev1alpha1.ProbesSpec {
+	var handler corev1.ProbeHandler
+	switch appType {
+	case AppTypeJupyter:
+		handler.HTTPGet = &corev1.HTTPGetAction{
+			Path: jupyterProbeURL(container),
+			Port: intstr.FromString(JupyterPortName),
+		}
+	default:
+		return workspacev1alpha1.ProbesSpec{}
+	}
+
+	return workspacev1alpha1.ProbesSpec{
+		StartupProbe: &corev1.Probe{
+			ProbeHandler:     handler,
+			PeriodSeconds:    DefaultStartupProbePeriodSeconds,
+			TimeoutSeconds:   DefaultProbeTimeoutSeconds,
+			FailureThreshold: DefaultStartupProbeFailureThreshold,
+		},
+		ReadinessProbe: &corev1.Probe{
+			ProbeHandler:   *handler.DeepCopy(),
+			PeriodSeconds:  DefaultReadinessProbePeriodSeconds,
+			TimeoutSeconds: DefaultProbeTimeoutSeconds,
+		},
+		LivenessProbe: &corev1.Probe{
+			ProbeHandler:     *handler.DeepCopy(),
+			PeriodSeconds:    DefaultLivenessProbePeriodSeconds,
+			TimeoutSeconds:   DefaultProbeTimeoutSeconds,
+			FailureThreshold: DefaultLivenessProbeFailureThreshold,
+		},
+	}
+}
+
+// jupyterProbeURL returns the probe path under the Jupyter server base URL of the container
+func jupyterProbeURL(container *corev1.Container) string {
+	for _, env := range container.Env {
+		if env.Name == JupyterBaseURLEnv && env.ValueFrom == nil {
+			return strings.TrimSuffix(env.Value, "/") + jupyterProbePath
+		}
+	}
+	return jupyterProbePath
+}
@@ -0,0 +1,139 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+)
+
+func TestApplyProbes_JupyterDefaultsUseBaseURL(t *testing.T) {
+	workspace := &workspacev1alpha1.Workspace{
+		Spec: workspacev1alpha1.WorkspaceSpec{AppType: AppTypeJupyter},
+	}
+	container := &corev1.Container{
+		Env: []corev1.EnvVar{{Name: JupyterBaseURLEnv, Value: "/workspaces/ns1/app1/"}},
+	}
+
+	applyProbes(container, workspace)
+
+	for _, probe := range []*corev1.Probe{container.StartupProbe, container.ReadinessProbe, container.LivenessProbe} {
+		require.NotNil(t, probe)
+		require.NotNil(t, probe.HTTPGet)
+		assert.Equal(t, "/workspaces/ns1/app1/api", probe.HTTPGet.Path)
+		assert.Equal(t, intstr.FromString(JupyterPortName), probe.HTTPGet.Port)
+		assert.Equal(t, corev1.URISchemeHTTP, probe.HTTPGet.Scheme)
+		assert.Equal(t, int32(1), probe.SuccessThreshold)
+	}
+	assert.Equal(t, int32(DefaultStartupProbeFailureThreshold), container.StartupProbe.FailureThreshold)
+	assert.Equal(t, int32(3), container.ReadinessProbe.FailureThreshold)
+}
+
+func TestApplyProbes_UnknownAppTypesGetNoDefaults(t *testing.T) {
+	workspace := &workspacev1alpha1.Workspace{
+		Spec: workspacev1alpha1.WorkspaceSpec{AppType: "code-server"},
+	}
+	container := &corev1.Container{}
+
+	applyProbes(container, workspace)
+
+	assert.Nil(t, container.StartupProbe)
+	assert.Nil(t, container.ReadinessProbe)
+	assert.Nil(t, container.LivenessProbe)
+}
+
+func TestApplyProbes_DisableDefaults(t *testing.T) {
+	workspace := &workspacev1alpha1.Workspace{
+		Spec: workspacev1alpha1.WorkspaceSpec{
+			AppType: AppTypeJupyter,
+			Probes: &workspacev1alpha1.ProbesSpec{
+				DisableDefaults: true,
+				ReadinessProbe: &corev1.Probe{
+					ProbeHandler: corev1.ProbeHandler{
+						TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromString(JupyterPortName)},
+					},
+				},
+			},
+		},
+	}
+	container := &corev1.Container{}
+
+	applyProbes(container, workspace)
+
+	require.NotNil(t, container.ReadinessProbe)
+	assert.NotNil(t, container.ReadinessProbe.TCPSocket)
+	assert.Nil(t, container.StartupProbe)
+	assert.Nil(t, container.LivenessProbe)
+}
+
+func TestApplyProbes_WorkspaceProbesOverrideDefaults(t *testing.T) {
+	workspace := &workspacev1alpha1.Workspace{
+		Spec: workspacev1alpha1.WorkspaceSpec{
+			AppType: AppTypeJupyter,
+			Probes: &workspacev1alpha1.ProbesSpec{
+				ReadinessProbe: &corev1.Probe{
+					ProbeHandler: corev1.ProbeHandler{
+						Exec: &corev1.ExecAction{Command: []string{"test", "-f", "/tmp/ready"}},
+					},
+				},
+			},
+		},
+	}
+	container := &corev1.Container{}
+
+	applyProbes(container, workspace)
+
+	require.NotNil(t, container.ReadinessProbe.Exec)
+	assert.Equal(t, int32(10), container.ReadinessProbe.PeriodSeconds)
+	assert.Equal(t, int32(1), container.ReadinessProbe.TimeoutSeconds)
+	// The workspace spec is not modified by the defaulting
+	assert.Zero(t, workspace.Spec.Probes.ReadinessProbe.PeriodSeconds)
+	// Probes that are not overridden keep the AppType defaults
+	require.NotNil(t, container.LivenessProbe.HTTPGet)
+	assert.Equal(t, "/api", container.LivenessProbe.HTTPGet.Path)
+}
+
+func TestBuildPrimaryContainer_ProbesUseAccessStrategyBaseURL(t *testing.T) {
+	workspace := &workspacev1alpha1.Workspace{
+		ObjectMeta: metav1.ObjectMeta{Name: "app1", Namespace: "ns1"},
+		Spec: workspacev1alpha1.WorkspaceSpec{
+			AppType: AppTypeJupyter,
+			Env:     []corev1.EnvVar{{Name: JupyterBaseURLEnv, Value: "/original/"}},
+		},
+	}
+	accessStrategy := &workspacev1alpha1.WorkspaceAccessStrategy{
+		ObjectMeta: metav1.ObjectMeta{Name: "strategy", Namespace: "default"},
+		Spec: workspacev1alpha1.WorkspaceAccessStrategySpec{
+			DeploymentModifications: &workspacev1alpha1.DeploymentModifications{
+				PodModifications: &workspacev1alpha1.PodModifications{
+					PrimaryContainerModifications: &workspacev1alpha1.PrimaryContainerModifications{
+						MergeEnv: []workspacev1alpha1.AccessEnvTemplate{{
+							Name:          JupyterBaseURLEnv,
+							ValueTemplate: "/workspaces/{{ .Workspace.Namespace }}/{{ .Workspace.Name }}/",
+						}},
+					},
+				},
+			},
+		},
+	}
+	builder := NewDeploymentBuilder(runtime.NewScheme(), WorkspaceControllerOptions{}, nil)
+
+	container, err := builder.buildPrimaryContainer(workspace, corev1.ResourceRequirements{}, accessStrategy)
+
+	require.NoError(t, err)
+	require.NotNil(t, container.ReadinessProbe)
+	assert.Equal(t, "/workspaces/ns1/app1/api", container.ReadinessProbe.HTTPGet.Path)
+	// The workspace spec is not modified by the access strategy env
+	assert.Equal(t, "/original/", workspace.Spec.Env[0].Value)
+}
@@ -61,13 +61,13 @@ var _ = Describe("DeploymentBuilder", func() {
 			Expect(err).NotTo(HaveOccurred())
 
 			// Verify volume is added
-			Expect(deployment.Spec.Template.Spec.Volumes).To(HaveLen(1))
+			Expect(deployment.Spec.Template.Spec.Volumes).To(HaveLen(2))
 			Expect(deployment.Spec.Template.Spec.Volumes[0].Name).To(Equal("workspace-storage"))
 			Expect(deployment.Spec.Template.Spec.Volumes[0].VolumeSource.PersistentVolumeClaim.ClaimName).To(Equal(GeneratePVCName(workspace.Name)))
 
 			// Verify volume mount is added to container
 			container := deployment.Spec.Template.Spec.Containers[0]
-			Expect(container.VolumeMounts).To(HaveLen(1))
+			Expect(container.VolumeMounts).To(HaveLen(2))
 			Expect(container.VolumeMounts[0].Name).To(Equal("workspace-storage"))
 			Expect(container.VolumeMounts[0].MountPath).To(Equal(DefaultMountPath))
 		})
@@ -89,9 +89,9 @@ var _ = Describe("DeploymentBuilder", func() {
 			Expect(err).NotTo(HaveOccurred())
 
 			// Verify volume is added from workspace spec
-			Expect(deployment.Spec.Template.Spec.Volumes).To(HaveLen(1))
+			Expect(deployment.Spec.Template.Spec.Volumes).To(HaveLen(2))
 			container := deployment.Spec.Template.Spec.Containers[0]
-			Expect(container.VolumeMounts).To(HaveLen(1))
+			Expect(container.VolumeMounts).To(HaveLen(2))
 		})
 	})
 
@@ -128,7 +128,7 @@ var _ = Describe("DeploymentBuilder", func() {
 			container := deployment.Spec.Template.Spec.Containers[0]
 
 			// Check volume mounts
-			Expect(container.VolumeMounts).To(HaveLen(3)) // workspace-storage + 2 additional
+			Expect(container.VolumeMounts).To(HaveLen(4)) // workspace-storage + 2 additional + idle shutdown notice
 
 			volumeMountMap := make(map[string]string)
 			for _, vm := range container.VolumeMounts {
@@ -139,7 +139,7 @@ var _ = Describe("DeploymentBuilder", func() {
 			Expect(volumeMountMap["shared-volume"]).To(Equal("/shared"))
 
 			// Check volumes
-			Expect(deployment.Spec.Template.Spec.Volumes).To(HaveLen(3)) // workspace-storage + 2 additional
+			Expect(deployment.Spec.Template.Spec.Volumes).To(HaveLen(4)) // workspace-storage + 2 additional + idle shutdown notice
 
 			volumeMap := make(map[string]string)
 			for _, v := range deployment.Spec.Template.Spec.Volumes {
@@ -151,6 +151,127 @@ var _ = Describe("DeploymentBuilder", func() {
 			Expect(volumeMap["data-volume"]).To(Equal("data-pvc"))
 			Expect(volumeMap["shared-volume"]).To(Equal("shared-pvc"))
 		})
+
+		It("should mount ConfigMap, Secret and CSI volumes with their mount options", func() {
+			workspace := &workspacev1alpha1.Workspace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-workspace-volume-sources",
+					Namespace: "default",
+				},
+				Spec: workspacev1alpha1.WorkspaceSpec{
+					Volumes: []workspacev1alpha1.VolumeSpec{
+						{
+							Name:                      "datasets",
+							PersistentVolumeClaimName: "datasets-pvc",
+							MountPath:                 "/datasets",
+							ReadOnly:                  true,
+							SubPath:                   "public",
+						},
+						{
+							Name: "team-config",
+							ConfigMap: &corev1.ConfigMapVolumeSource{
+								LocalObjectReference: corev1.LocalObjectReference{Name: "team-config"},
+							},
+							MountPath: "/etc/team",
+						},
+						{
+							Name:      "team-creds",
+							Secret:    &corev1.SecretVolumeSource{SecretName: "team-creds"},
+							MountPath: "/etc/creds",
+						},
+						{
+							Name:      "s3",
+							CSI:       &corev1.CSIVolumeSource{Driver: "s3.csi.aws.com"},
+							MountPath: "/s3",
+						},
+					},
+				},
+			}
+
+			deployment, err := deploymentBuilder.BuildDeployment(ctx, workspace)
+			Expect(err).NotTo(HaveOccurred())
+
+			volumes := make(map[string]corev1.Volume)
+			for _, v := range deployment.Spec.Template.Spec.Volumes {
+				volumes[v.Name] = v
+			}
+			Expect(volumes["datasets"].PersistentVolumeClaim.ClaimName).To(Equal("datasets-pvc"))
+			Expect(volumes["datasets"].PersistentVolumeClaim.ReadOnly).To(BeTrue())
+			Expect(volumes["team-config"].ConfigMap.Name).To(Equal("team-config"))
+			Expect(volumes["team-creds"].Secret.SecretName).To(Equal("team-creds"))
+			Expect(volumes["s3"].CSI.Driver).To(Equal("s3.csi.aws.com"))
+
+			mounts := make(map[string]corev1.VolumeMount)
+			for _, vm := range deployment.Spec.Template.Spec.Containers[0].VolumeMounts {
+				mounts[vm.Name] = vm
+			}
+			Expect(mounts["datasets"].ReadOnly).To(BeTrue())
+			Expect(mounts["datasets"].SubPath).To(Equal("public"))
+			Expect(mounts["team-config"].MountPath).To(Equal("/etc/team"))
+			Expect(mounts["s3"].ReadOnly).To(BeFalse())
+		})
+	})
+
+	Context("Idle Shutdown Notice", func() {
+		It("should expose the idle shutdown notice", func() {
+			workspace := &workspacev1alpha1.Workspace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-workspace-idle-notice",
+					Namespace: "default",
+				},
+				Spec: workspacev1alpha1.WorkspaceSpec{
+					Env: []corev1.EnvVar{{Name: "FOO", Value: "bar"}},
+					IdleShutdown: &workspacev1alpha1.IdleShutdownSpec{
+						Enabled:              true,
+						IdleTimeoutInMinutes: 30,
+						GracePeriodInMinutes: 5,
+					},
+				},
+			}
+
+			deployment, err := deploymentBuilder.BuildDeployment(ctx, workspace)
+			Expect(err).NotTo(HaveOccurred())
+
+			volumes := deployment.Spec.Template.Spec.Volumes
+			Expect(volumes).To(HaveLen(1))
+			Expect(volumes[0].Name).To(Equal(IdleShutdownNoticeVolumeName))
+			Expect(volumes[0].DownwardAPI).NotTo(BeNil())
+			Expect(volumes[0].DownwardAPI.Items[0].FieldRef.FieldPath).To(
+				Equal("metadata.annotations['workspace.jupyter.org/idle-shutdown-at']"))
+
+			container := deployment.Spec.Template.Spec.Containers[0]
+			Expect(container.VolumeMounts).To(ContainElement(corev1.VolumeMount{
+				Name:      IdleShutdownNoticeVolumeName,
+				MountPath: IdleShutdownNoticeMountPath,
+				ReadOnly:  true,
+			}))
+			Expect(container.Env).To(ContainElement(corev1.EnvVar{
+				Name:  IdleShutdownNoticeFileEnv,
+				Value: "/etc/workspace-notice/idle-shutdown-at",
+			}))
+			Expect(workspace.Spec.Env).To(HaveLen(1))
+		})
+
+		It("should not change the pod template when idle shutdown is toggled", func() {
+			workspace := &workspacev1alpha1.Workspace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-workspace-toggle-idle-notice",
+					Namespace: "default",
+				},
+			}
+
+			withoutIdleShutdown, err := deploymentBuilder.BuildDeployment(ctx, workspace)
+			Expect(err).NotTo(HaveOccurred())
+
+			workspace.Spec.IdleShutdown = &workspacev1alpha1.IdleShutdownSpec{
+				Enabled:              true,
+				IdleTimeoutInMinutes: 30,
+				GracePeriodInMinutes: 5,
+			}
+			withIdleShutdown, err := deploymentBuilder.BuildDeployment(ctx, workspace)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(withIdleShutdown.Spec.Template.Spec).To(Equal(withoutIdleShutdown.Spec.Template.Spec))
+		})
 	})
 
 	Context("Container Configuration", func() {
@@ -204,7 +325,7 @@ var _ = Describe("DeploymentBuilder", func() {
 
 			container := deployment.Spec.Template.Spec.Containers[0]
 
-			Expect(container.Env).To(HaveLen(2))
+			Expect(container.Env).To(HaveLen(3))
 			Expect(container.Env[0].Name).To(Equal("MY_VAR"))
 			Expect(container.Env[0].Value).To(Equal("my-value"))
 			Expect(container.Env[1].Name).To(Equal("ANOTHER_VAR"))
@@ -251,7 +372,7 @@ var _ = Describe("DeploymentBuilder", func() {
 
 			container := deployment.Spec.Template.Spec.Containers[0]
 
-			Expect(container.Env).To(HaveLen(2))
+			Expect(container.Env).To(HaveLen(3))
 			Expect(container.Env[0].Name).To(Equal("SECRET_VALUE"))
 			Expect(container.Env[0].ValueFrom).NotTo(BeNil())
 			Expect(container.Env[0].ValueFrom.SecretKeyRef).NotTo(BeNil())
@@ -281,7 +402,7 @@ var _ = Describe("DeploymentBuilder", func() {
 			Expect(deployment).NotTo(BeNil())
 
 			container := deployment.Spec.Template.Spec.Containers[0]
-			Expect(container.Env).To(BeEmpty())
+			Expect(container.Env).To(ConsistOf(HaveField("Name", IdleShutdownNoticeFileEnv)))
 		})
 
 		It("should handle nil container config", func() {
@@ -300,7 +421,7 @@ var _ = Describe("DeploymentBuilder", func() {
 			Expect(deployment).NotTo(BeNil())
 
 			container := deployment.Spec.Template.Spec.Containers[0]
-			Expect(container.Env).To(BeEmpty())
+			Expect(container.Env).To(ConsistOf(HaveField("Name", IdleShutdownNoticeFileEnv)))
 		})
 
 		It("should set command, args, and env together", func() {
@@ -331,7 +452,7 @@ var _ = Describe("DeploymentBuilder", func() {
 
 			Expect(container.Command).To(Equal([]string{"/bin/sh"}))
 			Expect(container.Args).To(Equal([]string{"-c", "echo $MY_VAR"}))
-			Expect(container.Env).To(HaveLen(1))
+			Expect(container.Env).To(HaveLen(2))
 			Expect(container.Env[0].Name).To(Equal("MY_VAR"))
 			Expect(container.Env[0].Value).To(Equal("test-value"))
 		})
@@ -8,6 +8,7 @@ package controller
 import (
 	"context"
 	"fmt"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -25,6 +26,9 @@ type IdleCheckResult struct {
 	// true = temporary failure, retry later
 	// false = permanent failure, stop checking
 	ShouldRetry bool
+
+	// LastActivity is the last activity reported by the workspace, zero when the detector does not know it
+	LastActivity time.Time
 }
 
 // WorkspaceIdleChecker provides utilities for checking workspace idle status
@@ -51,7 +55,7 @@ func (w *WorkspaceIdleChecker) CheckWorkspaceIdle(ctx context.Context, workspace
 	}
 
 	// Create appropriate detector
-	detector, err := CreateIdleDetector(&idleConfig.Detection)
+	detector, err := CreateIdleDetector(&idleConfig.Detection, w.client)
 	if err != nil {
 		logger.Error(err, "Failed to create idle detector")
 		return &IdleCheckResult{IsIdle: false, ShouldRetry: false}, fmt.Errorf("failed to create idle detector: %w", err)
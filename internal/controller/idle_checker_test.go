@@ -16,6 +16,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
@@ -107,7 +108,7 @@ func setupWorkspaceIdleCheckerTest(_ *testing.T) *testSetup {
 
 	// Override factory function
 	originalCreateIdleDetector := CreateIdleDetector
-	CreateIdleDetector = func(detection *workspacev1alpha1.IdleDetectionSpec) (IdleDetector, error) {
+	CreateIdleDetector = func(detection *workspacev1alpha1.IdleDetectionSpec, reader client.Reader) (IdleDetector, error) {
 		return mockDetector, nil
 	}
 
@@ -277,7 +278,7 @@ func TestWorkspaceIdleChecker_CheckWorkspaceIdle_DetectorCreationFailure(t *test
 
 	// Override factory function to return error
 	originalCreateIdleDetector := CreateIdleDetector
-	CreateIdleDetector = func(detection *workspacev1alpha1.IdleDetectionSpec) (IdleDetector, error) {
+	CreateIdleDetector = func(detection *workspacev1alpha1.IdleDetectionSpec, reader client.Reader) (IdleDetector, error) {
 		return nil, fmt.Errorf("failed to create detector: unsupported detection method")
 	}
 	defer func() {
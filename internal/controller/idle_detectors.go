@@ -15,6 +15,7 @@ import (
 	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
 	"github.com/jupyter-infra/jupyter-k8s/internal/pluginadapters"
 	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
@@ -28,10 +29,28 @@ type IdleDetector interface {
 	CheckIdle(ctx context.Context, workspaceName string, pod *corev1.Pod, idleConfig *workspacev1alpha1.IdleShutdownSpec) (*IdleCheckResult, error)
 }
 
-func createIdleDetectorImpl(detection *workspacev1alpha1.IdleDetectionSpec) (IdleDetector, error) {
+func createIdleDetectorImpl(detection *workspacev1alpha1.IdleDetectionSpec, reader client.Reader) (IdleDetector, error) {
+	// Direct probes call the idle endpoint from the controller and need no pod exec
+	fetcher, err := newIdleEndpointFetcher(detection.Probe)
+	if err != nil {
+		return nil, err
+	}
+
 	switch {
+	case detection.JupyterAPI != nil:
+		if fetcher != nil {
+			return NewJupyterAPIDetectorWithFetcher(fetcher), nil
+		}
+		return NewJupyterAPIDetector(), nil
 	case detection.HTTPGet != nil:
+		if fetcher != nil {
+			return NewHTTPGetDetectorWithFetcher(fetcher), nil
+		}
 		return NewHTTPGetDetector(), nil
+	case detection.Metrics != nil:
+		return NewMetricsDetector(detection.Metrics)
+	case detection.ProxyActivity != nil:
+		return NewProxyActivityDetector(reader), nil
 	default:
 		return nil, fmt.Errorf("no detection method configured")
 	}
@@ -43,12 +62,21 @@ var CreateIdleDetector = createIdleDetectorImpl
 // HTTPGetDetector implements HTTP endpoint checking
 type HTTPGetDetector struct {
 	execUtil pluginadapters.PodExecInterface
+	fetcher  IdleEndpointFetcher
 }
 
 // NewHTTPGetDetectorWithExec creates a new HTTPGetDetector with the provided pluginadapters.PodExecInterface
 func NewHTTPGetDetectorWithExec(execUtil pluginadapters.PodExecInterface) *HTTPGetDetector {
 	return &HTTPGetDetector{
 		execUtil: execUtil,
+		fetcher:  &execEndpointFetcher{execUtil: execUtil},
+	}
+}
+
+// NewHTTPGetDetectorWithFetcher creates a new HTTPGetDetector reaching the endpoint through the provided fetcher
+func NewHTTPGetDetectorWithFetcher(fetcher IdleEndpointFetcher) *HTTPGetDetector {
+	return &HTTPGetDetector{
+		fetcher: fetcher,
 	}
 }
 
@@ -72,44 +100,13 @@ func (h *HTTPGetDetector) CheckIdle(ctx context.Context, workspaceName string, p
 		return &IdleCheckResult{IsIdle: false, ShouldRetry: false}, fmt.Errorf("httpGet config is nil")
 	}
 
-	// Build URL with scheme support
-	scheme := string(httpGetConfig.Scheme)
-	if scheme == "" {
-		scheme = "http"
-	}
 	port := httpGetConfig.Port.String()
-	url := fmt.Sprintf("%s://localhost:%s%s", scheme, port, httpGetConfig.Path)
-
-	// Single curl call with status code
-	cmd := []string{"curl", "-s", "-w", "\\nHTTP Status: %{http_code}\\n", url}
 
 	logger.V(1).Info("Calling idle endpoint", "port", port, "path", httpGetConfig.Path)
 
-	// Always execute in the workspace container
-	const workspaceContainerName = "workspace"
-	output, err := h.execUtil.ExecInPod(ctx, pod, workspaceContainerName, cmd, "")
+	statusCode, body, err := h.fetcher.Get(ctx, workspaceName, pod, httpGetConfig.Scheme, port, httpGetConfig.Path)
 	if err != nil {
-		// Handle curl exit codes - connection refused (temporary failure)
-		if strings.Contains(err.Error(), "exit code 7") {
-			return &IdleCheckResult{IsIdle: false, ShouldRetry: true}, fmt.Errorf("connection refused")
-		}
-		return &IdleCheckResult{IsIdle: false, ShouldRetry: true}, fmt.Errorf("curl execution failed: %w", err)
-	}
-
-	// Parse output to separate response body and status code
-	lines := strings.Split(output, "\n")
-	var responseBody strings.Builder
-	var statusCode string
-
-	for _, line := range lines {
-		if strings.HasPrefix(line, "HTTP Status: ") {
-			statusCode = strings.TrimPrefix(line, "HTTP Status: ")
-		} else if line != "" {
-			if responseBody.Len() > 0 {
-				responseBody.WriteString("\n")
-			}
-			responseBody.WriteString(line)
-		}
+		return &IdleCheckResult{IsIdle: false, ShouldRetry: true}, err
 	}
 
 	switch statusCode {
@@ -119,29 +116,65 @@ func (h *HTTPGetDetector) CheckIdle(ctx context.Context, workspaceName string, p
 	case "200":
 		// Parse the JSON response
 		var idleResp EndpointIdleResponse
-		if err := json.Unmarshal([]byte(responseBody.String()), &idleResp); err != nil {
-			logger.Error(err, "Failed to parse idle response", "output", responseBody.String())
+		if err := json.Unmarshal([]byte(body), &idleResp); err != nil {
+			logger.Error(err, "Failed to parse idle response", "output", body)
 			return &IdleCheckResult{IsIdle: false, ShouldRetry: true}, fmt.Errorf("failed to parse idle response: %w", err)
 		}
 
 		// Validate the response
 		if idleResp.LastActivity == "" {
-			logger.Error(nil, "Empty lastActiveTimestamp in response", "output", responseBody.String())
+			logger.Error(nil, "Empty lastActiveTimestamp in response", "output", body)
 			return &IdleCheckResult{IsIdle: false, ShouldRetry: true}, fmt.Errorf("invalid idle response: empty lastActiveTimestamp")
 		}
 
 		// Check if workspace is idle based on timeout
-		isIdle := h.checkIdleTimeout(ctx, workspaceName, &idleResp, idleConfig)
+		isIdle, lastActivity := h.checkIdleTimeout(ctx, workspaceName, &idleResp, idleConfig)
 		logger.V(1).Info("Successfully retrieved idle status", "lastActivity", idleResp.LastActivity, "isIdle", isIdle)
-		return &IdleCheckResult{IsIdle: isIdle, ShouldRetry: true}, nil
+		return &IdleCheckResult{IsIdle: isIdle, ShouldRetry: true, LastActivity: lastActivity}, nil
 	default:
 		// treat other HTTP errors as retryable
 		return &IdleCheckResult{IsIdle: false, ShouldRetry: true}, fmt.Errorf("unexpected HTTP status: %s", statusCode)
 	}
 }
 
+// execHTTPGetInPod performs an HTTP GET with curl from inside the workspace container
+// Returns the HTTP status code and the response body
+func execHTTPGetInPod(ctx context.Context, execUtil pluginadapters.PodExecInterface, pod *corev1.Pod, url string) (string, string, error) {
+	// Single curl call with status code
+	cmd := []string{"curl", "-s", "-w", "\\nHTTP Status: %{http_code}\\n", url}
+
+	// Always execute in the workspace container
+	output, err := execUtil.ExecInPod(ctx, pod, workspaceContainerName, cmd, "")
+	if err != nil {
+		// Handle curl exit codes - connection refused (temporary failure)
+		if strings.Contains(err.Error(), "exit code 7") {
+			return "", "", fmt.Errorf("connection refused")
+		}
+		return "", "", fmt.Errorf("curl execution failed: %w", err)
+	}
+
+	// Parse output to separate response body and status code
+	lines := strings.Split(output, "\n")
+	var responseBody strings.Builder
+	var statusCode string
+
+	for _, line := range lines {
+		if strings.HasPrefix(line, "HTTP Status: ") {
+			statusCode = strings.TrimPrefix(line, "HTTP Status: ")
+		} else if line != "" {
+			if responseBody.Len() > 0 {
+				responseBody.WriteString("\n")
+			}
+			responseBody.WriteString(line)
+		}
+	}
+
+	return statusCode, responseBody.String(), nil
+}
+
 // checkIdleTimeout checks if workspace should be stopped due to idle timeout
-func (h *HTTPGetDetector) checkIdleTimeout(ctx context.Context, workspaceName string, idleResp *EndpointIdleResponse, idleConfig *workspacev1alpha1.IdleShutdownSpec) bool {
+// It also returns the parsed last activity time, zero when it cannot be parsed
+func (h *HTTPGetDetector) checkIdleTimeout(ctx context.Context, workspaceName string, idleResp *EndpointIdleResponse, idleConfig *workspacev1alpha1.IdleShutdownSpec) (bool, time.Time) {
 	logger := logf.FromContext(ctx).WithValues("workspace", workspaceName)
 
 	// Parse last activity time with case-insensitive timezone
@@ -151,7 +184,7 @@ func (h *HTTPGetDetector) checkIdleTimeout(ctx context.Context, workspaceName st
 	lastActivity, err := time.Parse(time.RFC3339, lastActivityStr)
 	if err != nil {
 		logger.Error(err, "Failed to parse last activity time", "lastActivity", idleResp.LastActivity)
-		return false
+		return false, time.Time{}
 	}
 
 	timeout := time.Duration(idleConfig.IdleTimeoutInMinutes) * time.Minute
@@ -159,7 +192,7 @@ func (h *HTTPGetDetector) checkIdleTimeout(ctx context.Context, workspaceName st
 
 	if idleTime > timeout {
 		logger.Info("Idle timeout reached", "idleTime", idleTime, "timeout", timeout, "lastActivity", lastActivity)
-		return true
+		return true, lastActivity
 	}
 
 	logger.V(1).Info("Workspace still active, timeout not reached",
@@ -167,5 +200,5 @@ func (h *HTTPGetDetector) checkIdleTimeout(ctx context.Context, workspaceName st
 		"timeout", timeout,
 		"remaining", timeout-idleTime,
 		"lastActivity", lastActivity)
-	return false
+	return false, lastActivity
 }
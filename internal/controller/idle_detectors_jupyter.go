@@ -0,0 +1,213 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	"github.com/jupyter-infra/jupyter-k8s/internal/pluginadapters"
+	corev1 "k8s.io/api/core/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// jupyterKernelStateBusy is the execution state of a kernel running code
+	jupyterKernelStateBusy = "busy"
+)
+
+// JupyterStatusResponse represents the response from the Jupyter Server /api/status endpoint
+type JupyterStatusResponse struct {
+	LastActivity string `json:"last_activity"`
+	Connections  int    `json:"connections"`
+	Kernels      int    `json:"kernels"`
+}
+
+// JupyterKernel represents a kernel returned by the Jupyter Server /api/kernels endpoint
+type JupyterKernel struct {
+	ID             string `json:"id"`
+	ExecutionState string `json:"execution_state"`
+	LastActivity   string `json:"last_activity"`
+	Connections    int    `json:"connections"`
+}
+
+// JupyterTerminal represents a terminal returned by the Jupyter Server /api/terminals endpoint
+type JupyterTerminal struct {
+	Name         string `json:"name"`
+	LastActivity string `json:"last_activity"`
+}
+
+// JupyterAPIDetector implements idle detection using the Jupyter Server REST API
+// A workspace is active while any kernel is busy or any terminal is open,
+// otherwise it is idle once the most recent reported activity is older than the timeout
+type JupyterAPIDetector struct {
+	fetcher IdleEndpointFetcher
+}
+
+// NewJupyterAPIDetectorWithExec creates a new JupyterAPIDetector with the provided pluginadapters.PodExecInterface
+func NewJupyterAPIDetectorWithExec(execUtil pluginadapters.PodExecInterface) *JupyterAPIDetector {
+	return NewJupyterAPIDetectorWithFetcher(&execEndpointFetcher{execUtil: execUtil})
+}
+
+// NewJupyterAPIDetectorWithFetcher creates a new JupyterAPIDetector reaching the server through the provided fetcher
+func NewJupyterAPIDetectorWithFetcher(fetcher IdleEndpointFetcher) *JupyterAPIDetector {
+	return &JupyterAPIDetector{
+		fetcher: fetcher,
+	}
+}
+
+// NewJupyterAPIDetector creates a new JupyterAPIDetector with a real PodExecUtil
+func NewJupyterAPIDetector() *JupyterAPIDetector {
+	execUtil, err := NewPodExecUtil()
+	if err != nil {
+		// In production, this should not happen if k8s config is available
+		panic(fmt.Sprintf("Failed to create pod exec util: %v", err))
+	}
+	return NewJupyterAPIDetectorWithExec(execUtil)
+}
+
+// CheckIdle implements the IdleDetector interface using the Jupyter Server REST API
+func (j *JupyterAPIDetector) CheckIdle(ctx context.Context, workspaceName string, pod *corev1.Pod, idleConfig *workspacev1alpha1.IdleShutdownSpec) (*IdleCheckResult, error) {
+	logger := logf.FromContext(ctx).WithValues("workspace", workspaceName, "pod", pod.Name)
+
+	jupyterConfig := idleConfig.Detection.JupyterAPI
+	if jupyterConfig == nil {
+		return &IdleCheckResult{IsIdle: false, ShouldRetry: false}, fmt.Errorf("jupyterAPI config is nil")
+	}
+	target := newJupyterAPITarget(workspaceName, jupyterConfig)
+
+	// /api/status tells us whether this is a Jupyter server at all
+	var status JupyterStatusResponse
+	if result, err := j.getJSON(ctx, pod, target, "/api/status", &status); err != nil {
+		return result, err
+	}
+
+	var kernels []JupyterKernel
+	if result, err := j.getJSON(ctx, pod, target, "/api/kernels", &kernels); err != nil {
+		return result, err
+	}
+
+	// Terminals may be disabled on the server, in which case the endpoint is missing
+	var terminals []JupyterTerminal
+	countTerminals := jupyterConfig.CountTerminals == nil || *jupyterConfig.CountTerminals
+	if countTerminals {
+		if result, err := j.getJSON(ctx, pod, target, "/api/terminals", &terminals); err != nil {
+			if result.ShouldRetry {
+				return result, err
+			}
+			logger.V(1).Info("Terminals endpoint unavailable, ignoring terminals", "error", err.Error())
+			terminals = nil
+		}
+	}
+
+	busyKernels := 0
+	lastActivity := parseJupyterTime(status.LastActivity)
+	for _, kernel := range kernels {
+		if kernel.ExecutionState == jupyterKernelStateBusy {
+			busyKernels++
+		}
+		if t := parseJupyterTime(kernel.LastActivity); t.After(lastActivity) {
+			lastActivity = t
+		}
+	}
+	for _, terminal := range terminals {
+		if t := parseJupyterTime(terminal.LastActivity); t.After(lastActivity) {
+			lastActivity = t
+		}
+	}
+
+	if busyKernels > 0 || len(terminals) > 0 {
+		logger.V(1).Info("Workspace active", "busyKernels", busyKernels, "openTerminals", len(terminals))
+		return &IdleCheckResult{IsIdle: false, ShouldRetry: true, LastActivity: time.Now()}, nil
+	}
+
+	if lastActivity.IsZero() {
+		return &IdleCheckResult{IsIdle: false, ShouldRetry: true}, fmt.Errorf("invalid jupyter status response: no last_activity")
+	}
+
+	timeout := time.Duration(idleConfig.IdleTimeoutInMinutes) * time.Minute
+	idleTime := time.Since(lastActivity)
+	if idleTime > timeout {
+		logger.Info("Idle timeout reached", "idleTime", idleTime, "timeout", timeout, "lastActivity", lastActivity)
+		return &IdleCheckResult{IsIdle: true, ShouldRetry: true, LastActivity: lastActivity}, nil
+	}
+
+	logger.V(1).Info("Workspace still active, timeout not reached",
+		"idleTime", idleTime,
+		"timeout", timeout,
+		"remaining", timeout-idleTime,
+		"lastActivity", lastActivity)
+	return &IdleCheckResult{IsIdle: false, ShouldRetry: true, LastActivity: lastActivity}, nil
+}
+
+// jupyterAPITarget identifies the Jupyter server to query
+type jupyterAPITarget struct {
+	workspaceName string
+	scheme        corev1.URIScheme
+	port          string
+	basePath      string
+}
+
+// newJupyterAPITarget builds the Jupyter server target from the detection config
+func newJupyterAPITarget(workspaceName string, config *workspacev1alpha1.JupyterAPIIdleDetection) jupyterAPITarget {
+	port := config.Port.String()
+	if port == "0" || port == "" {
+		port = fmt.Sprintf("%d", JupyterPort)
+	}
+	basePath := strings.TrimSuffix(config.BasePath, "/")
+	if basePath != "" && !strings.HasPrefix(basePath, "/") {
+		basePath = "/" + basePath
+	}
+	return jupyterAPITarget{
+		workspaceName: workspaceName,
+		scheme:        config.Scheme,
+		port:          port,
+		basePath:      basePath,
+	}
+}
+
+// getJSON fetches a Jupyter API endpoint and decodes its JSON response into out
+func (j *JupyterAPIDetector) getJSON(ctx context.Context, pod *corev1.Pod, target jupyterAPITarget, endpoint string, out interface{}) (*IdleCheckResult, error) {
+	url := target.basePath + endpoint
+	statusCode, body, err := j.fetcher.Get(ctx, target.workspaceName, pod, target.scheme, target.port, url)
+	if err != nil {
+		return &IdleCheckResult{IsIdle: false, ShouldRetry: true}, err
+	}
+
+	switch statusCode {
+	case "200":
+		if err := json.Unmarshal([]byte(body), out); err != nil {
+			return &IdleCheckResult{IsIdle: false, ShouldRetry: true}, fmt.Errorf("failed to parse response from %s: %w", url, err)
+		}
+		return nil, nil
+	case "404":
+		// 404 is a permanent failure - endpoint doesn't exist
+		return &IdleCheckResult{IsIdle: false, ShouldRetry: false}, fmt.Errorf("endpoint not found: %s", url)
+	case "401", "403":
+		// The controller does not authenticate against the Jupyter server
+		return &IdleCheckResult{IsIdle: false, ShouldRetry: false}, fmt.Errorf("jupyter server requires authentication: %s", url)
+	default:
+		// treat other HTTP errors as retryable
+		return &IdleCheckResult{IsIdle: false, ShouldRetry: true}, fmt.Errorf("unexpected HTTP status: %s", statusCode)
+	}
+}
+
+// parseJupyterTime parses a Jupyter Server timestamp, returning the zero time if it is missing or invalid
+// Some Jupyter servers return lowercase 'z' instead of uppercase 'Z' for UTC timezone
+func parseJupyterTime(value string) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339Nano, strings.ToUpper(value))
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
@@ -0,0 +1,170 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+)
+
+func createJupyterIdleConfig() *workspacev1alpha1.IdleShutdownSpec {
+	return &workspacev1alpha1.IdleShutdownSpec{
+		IdleTimeoutInMinutes: 30,
+		Detection: workspacev1alpha1.IdleDetectionSpec{
+			JupyterAPI: &workspacev1alpha1.JupyterAPIIdleDetection{
+				Port:     intstr.FromInt(8888),
+				BasePath: "/workspaces/default/test/",
+			},
+		},
+	}
+}
+
+// mockJupyterEndpoint sets the curl output returned for a given Jupyter API path
+func mockJupyterEndpoint(m *MockPodExecUtil, path, body, status string) {
+	m.On("ExecInPod", mock.Anything, mock.Anything, "workspace",
+		mock.MatchedBy(func(cmd []string) bool {
+			return strings.HasSuffix(cmd[len(cmd)-1], path)
+		}), "").Return(fmt.Sprintf("%s\nHTTP Status: %s", body, status), nil)
+}
+
+func TestCreateIdleDetector_JupyterAPI(t *testing.T) {
+	originalCreateIdleDetector := CreateIdleDetector
+	CreateIdleDetector = func(detection *workspacev1alpha1.IdleDetectionSpec, reader client.Reader) (IdleDetector, error) {
+		if detection.JupyterAPI != nil {
+			return NewJupyterAPIDetectorWithExec(&MockPodExecUtil{}), nil
+		}
+		return originalCreateIdleDetector(detection, reader)
+	}
+	defer func() {
+		CreateIdleDetector = originalCreateIdleDetector
+	}()
+
+	detector, err := CreateIdleDetector(&createJupyterIdleConfig().Detection, nil)
+
+	assert.NoError(t, err)
+	assert.IsType(t, &JupyterAPIDetector{}, detector)
+}
+
+func TestJupyterAPIDetector_BusyKernelIsActive(t *testing.T) {
+	mockExecUtil := &MockPodExecUtil{}
+	detector := NewJupyterAPIDetectorWithExec(mockExecUtil)
+	old := time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339Nano)
+
+	mockJupyterEndpoint(mockExecUtil, "/api/status", fmt.Sprintf(`{"last_activity": %q, "kernels": 1}`, old), "200")
+	mockJupyterEndpoint(mockExecUtil, "/api/kernels", fmt.Sprintf(`[{"id": "k1", "execution_state": "busy", "last_activity": %q}]`, old), "200")
+	mockJupyterEndpoint(mockExecUtil, "/api/terminals", `[]`, "200")
+
+	result, err := detector.CheckIdle(context.Background(), testWorkspaceName, createTestPod(), createJupyterIdleConfig())
+
+	require.NoError(t, err)
+	assert.False(t, result.IsIdle)
+	assert.True(t, result.ShouldRetry)
+	mockExecUtil.AssertExpectations(t)
+}
+
+func TestJupyterAPIDetector_OpenTerminalIsActive(t *testing.T) {
+	mockExecUtil := &MockPodExecUtil{}
+	detector := NewJupyterAPIDetectorWithExec(mockExecUtil)
+	old := time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339Nano)
+
+	mockJupyterEndpoint(mockExecUtil, "/api/status", fmt.Sprintf(`{"last_activity": %q}`, old), "200")
+	mockJupyterEndpoint(mockExecUtil, "/api/kernels", `[]`, "200")
+	mockJupyterEndpoint(mockExecUtil, "/api/terminals", fmt.Sprintf(`[{"name": "1", "last_activity": %q}]`, old), "200")
+
+	result, err := detector.CheckIdle(context.Background(), testWorkspaceName, createTestPod(), createJupyterIdleConfig())
+
+	require.NoError(t, err)
+	assert.False(t, result.IsIdle)
+}
+
+func TestJupyterAPIDetector_IgnoresTerminalsWhenDisabled(t *testing.T) {
+	mockExecUtil := &MockPodExecUtil{}
+	detector := NewJupyterAPIDetectorWithExec(mockExecUtil)
+	old := time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339Nano)
+	idleConfig := createJupyterIdleConfig()
+	countTerminals := false
+	idleConfig.Detection.JupyterAPI.CountTerminals = &countTerminals
+
+	mockJupyterEndpoint(mockExecUtil, "/api/status", fmt.Sprintf(`{"last_activity": %q}`, old), "200")
+	mockJupyterEndpoint(mockExecUtil, "/api/kernels", fmt.Sprintf(`[{"id": "k1", "execution_state": "idle", "last_activity": %q}]`, old), "200")
+
+	result, err := detector.CheckIdle(context.Background(), testWorkspaceName, createTestPod(), idleConfig)
+
+	require.NoError(t, err)
+	assert.True(t, result.IsIdle)
+	mockExecUtil.AssertNumberOfCalls(t, "ExecInPod", 2)
+}
+
+func TestJupyterAPIDetector_RecentKernelActivityIsNotIdle(t *testing.T) {
+	mockExecUtil := &MockPodExecUtil{}
+	detector := NewJupyterAPIDetectorWithExec(mockExecUtil)
+	old := time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339Nano)
+	recent := strings.ToLower(time.Now().Add(-5 * time.Minute).UTC().Format(time.RFC3339Nano))
+
+	mockJupyterEndpoint(mockExecUtil, "/api/status", fmt.Sprintf(`{"last_activity": %q}`, old), "200")
+	mockJupyterEndpoint(mockExecUtil, "/api/kernels", fmt.Sprintf(`[{"id": "k1", "execution_state": "idle", "last_activity": %q}]`, recent), "200")
+	mockJupyterEndpoint(mockExecUtil, "/api/terminals", `[]`, "200")
+
+	result, err := detector.CheckIdle(context.Background(), testWorkspaceName, createTestPod(), createJupyterIdleConfig())
+
+	require.NoError(t, err)
+	assert.False(t, result.IsIdle)
+}
+
+func TestJupyterAPIDetector_MissingTerminalsEndpoint(t *testing.T) {
+	mockExecUtil := &MockPodExecUtil{}
+	detector := NewJupyterAPIDetectorWithExec(mockExecUtil)
+	old := time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339Nano)
+
+	mockJupyterEndpoint(mockExecUtil, "/api/status", fmt.Sprintf(`{"last_activity": %q}`, old), "200")
+	mockJupyterEndpoint(mockExecUtil, "/api/kernels", `[]`, "200")
+	mockJupyterEndpoint(mockExecUtil, "/api/terminals", ``, "404")
+
+	result, err := detector.CheckIdle(context.Background(), testWorkspaceName, createTestPod(), createJupyterIdleConfig())
+
+	require.NoError(t, err)
+	assert.True(t, result.IsIdle)
+}
+
+func TestJupyterAPIDetector_StatusNotFoundIsPermanent(t *testing.T) {
+	mockExecUtil := &MockPodExecUtil{}
+	detector := NewJupyterAPIDetectorWithExec(mockExecUtil)
+
+	mockJupyterEndpoint(mockExecUtil, "/api/status", ``, "404")
+
+	result, err := detector.CheckIdle(context.Background(), testWorkspaceName, createTestPod(), createJupyterIdleConfig())
+
+	assert.Error(t, err)
+	assert.False(t, result.IsIdle)
+	assert.False(t, result.ShouldRetry)
+}
+
+func TestNewJupyterAPITarget(t *testing.T) {
+	target := newJupyterAPITarget(testWorkspaceName, &workspacev1alpha1.JupyterAPIIdleDetection{})
+	assert.Equal(t, "8888", target.port)
+	assert.Equal(t, "", target.basePath)
+
+	target = newJupyterAPITarget(testWorkspaceName, &workspacev1alpha1.JupyterAPIIdleDetection{
+		Port:     intstr.FromInt(9000),
+		Scheme:   "HTTPS",
+		BasePath: "base/",
+	})
+	assert.Equal(t, "9000", target.port)
+	assert.Equal(t, "/base", target.basePath)
+	assert.Equal(t, corev1.URISchemeHTTPS, target.scheme)
+}
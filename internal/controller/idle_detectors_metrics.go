@@ -0,0 +1,349 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// DefaultMetricsCPUThreshold is the default CPU usage below which a workspace counts as idle
+	DefaultMetricsCPUThreshold = "50m"
+
+	// DefaultPrometheusRateWindow is the default window over which Prometheus usage rates are computed
+	DefaultPrometheusRateWindow = 5 * time.Minute
+
+	// usageActivityRetention is how long activity of pods no longer observed is remembered
+	usageActivityRetention = 24 * time.Hour
+
+	// workspaceContainerName is the name of the workspace container in the pod
+	workspaceContainerName = "workspace"
+)
+
+// PodUsage is the resource usage of a workspace pod
+type PodUsage struct {
+	// CPUMillicores is the CPU usage of the workspace container
+	CPUMillicores int64
+
+	// NetworkBytesPerSecond is the traffic received and transmitted by the pod, nil when not available
+	NetworkBytesPerSecond *float64
+}
+
+// PodUsageSource reads the resource usage of a workspace pod
+type PodUsageSource interface {
+	Usage(ctx context.Context, pod *corev1.Pod) (*PodUsage, error)
+}
+
+// MetricsDetector implements idle detection from the resource usage of the workspace container
+// Usage sources only report recent usage, so the time of the last observed activity is tracked
+// in memory, and a workspace is idle once its usage stayed below the thresholds for the timeout
+type MetricsDetector struct {
+	source  PodUsageSource
+	tracker *usageActivityTracker
+	now     func() time.Time
+}
+
+// NewMetricsDetectorWithSource creates a new MetricsDetector reading usage from the provided source
+func NewMetricsDetectorWithSource(source PodUsageSource) *MetricsDetector {
+	return &MetricsDetector{
+		source:  source,
+		tracker: defaultUsageActivityTracker,
+		now:     time.Now,
+	}
+}
+
+// NewMetricsDetector creates a new MetricsDetector for the configured usage source
+func NewMetricsDetector(config *workspacev1alpha1.MetricsIdleDetection) (*MetricsDetector, error) {
+	switch config.Source {
+	case workspacev1alpha1.MetricsIdleSourcePrometheus:
+		if config.Prometheus == nil {
+			return nil, fmt.Errorf("prometheus config is nil")
+		}
+		if !IsPrometheusURLAllowed(config.Prometheus.URL) {
+			return nil, fmt.Errorf("prometheus URL %s is not in %s", config.Prometheus.URL, IdlePrometheusAllowedURLsEnv)
+		}
+		return NewMetricsDetectorWithSource(NewPrometheusUsageSource(config.Prometheus)), nil
+	case workspacev1alpha1.MetricsIdleSourceMetricsServer, "":
+		cfg, err := getConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Kubernetes config: %w", err)
+		}
+		clientset, err := newClientset(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Kubernetes clientset: %w", err)
+		}
+		return NewMetricsDetectorWithSource(NewMetricsServerUsageSource(clientset.Discovery().RESTClient())), nil
+	default:
+		return nil, fmt.Errorf("unsupported metrics source: %s", config.Source)
+	}
+}
+
+// GetAllowedPrometheusURLs returns the Prometheus APIs the operator allows for idle detection
+func GetAllowedPrometheusURLs() []string {
+	allowed := []string{}
+	for _, value := range strings.Split(os.Getenv(IdlePrometheusAllowedURLsEnv), ",") {
+		if value = strings.TrimSuffix(strings.TrimSpace(value), "/"); value != "" {
+			allowed = append(allowed, value)
+		}
+	}
+	return allowed
+}
+
+// IsPrometheusURLAllowed reports whether the operator allows the Prometheus API for idle detection.
+// The controller sends requests to this URL, so it is never taken from workspaces without this check.
+func IsPrometheusURLAllowed(prometheusURL string) bool {
+	return slices.Contains(GetAllowedPrometheusURLs(), strings.TrimSuffix(prometheusURL, "/"))
+}
+
+// CheckIdle implements the IdleDetector interface using container resource usage
+func (m *MetricsDetector) CheckIdle(ctx context.Context, workspaceName string, pod *corev1.Pod, idleConfig *workspacev1alpha1.IdleShutdownSpec) (*IdleCheckResult, error) {
+	logger := logf.FromContext(ctx).WithValues("workspace", workspaceName, "pod", pod.Name)
+
+	metricsConfig := idleConfig.Detection.Metrics
+	if metricsConfig == nil {
+		return &IdleCheckResult{IsIdle: false, ShouldRetry: false}, fmt.Errorf("metrics config is nil")
+	}
+
+	usage, err := m.source.Usage(ctx, pod)
+	if err != nil {
+		return &IdleCheckResult{IsIdle: false, ShouldRetry: true}, err
+	}
+
+	cpuThreshold := metricsConfig.CPUThreshold
+	if cpuThreshold.IsZero() {
+		cpuThreshold = resource.MustParse(DefaultMetricsCPUThreshold)
+	}
+	active := usage.CPUMillicores >= cpuThreshold.MilliValue()
+	if metricsConfig.NetworkThresholdBytesPerSecond != nil && usage.NetworkBytesPerSecond != nil &&
+		*usage.NetworkBytesPerSecond >= float64(*metricsConfig.NetworkThresholdBytesPerSecond) {
+		active = true
+	}
+
+	now := m.now()
+	lastActivity := m.tracker.observe(pod.UID, active, now)
+
+	timeout := time.Duration(idleConfig.IdleTimeoutInMinutes) * time.Minute
+	idleTime := now.Sub(lastActivity)
+	if idleTime > timeout {
+		logger.Info("Idle timeout reached", "idleTime", idleTime, "timeout", timeout, "lastActivity", lastActivity,
+			"cpuMillicores", usage.CPUMillicores)
+		return &IdleCheckResult{IsIdle: true, ShouldRetry: true}, nil
+	}
+
+	logger.V(1).Info("Workspace still active, timeout not reached",
+		"active", active,
+		"cpuMillicores", usage.CPUMillicores,
+		"idleTime", idleTime,
+		"timeout", timeout,
+		"lastActivity", lastActivity)
+	return &IdleCheckResult{IsIdle: false, ShouldRetry: true}, nil
+}
+
+// usageActivityTracker remembers the last time each pod was observed above the usage thresholds
+type usageActivityTracker struct {
+	mu       sync.Mutex
+	activity map[types.UID]usageActivity
+}
+
+type usageActivity struct {
+	lastActive time.Time
+	lastSeen   time.Time
+}
+
+// defaultUsageActivityTracker is shared by all metrics detectors, which are created for each check
+var defaultUsageActivityTracker = newUsageActivityTracker()
+
+func newUsageActivityTracker() *usageActivityTracker {
+	return &usageActivityTracker{activity: map[types.UID]usageActivity{}}
+}
+
+// observe records an observation of the pod and returns the time of its last activity
+// The first observation of a pod counts as activity, so that pods are never stopped
+// before the controller watched them for a full idle timeout
+func (t *usageActivityTracker) observe(uid types.UID, active bool, now time.Time) time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entry, found := t.activity[uid]
+	if !found || active {
+		entry.lastActive = now
+	}
+	entry.lastSeen = now
+	t.activity[uid] = entry
+
+	// Forget pods that are gone
+	for key, other := range t.activity {
+		if now.Sub(other.lastSeen) > usageActivityRetention {
+			delete(t.activity, key)
+		}
+	}
+	return entry.lastActive
+}
+
+// podMetrics is the subset of a metrics.k8s.io/v1beta1 PodMetrics used by the controller
+type podMetrics struct {
+	Containers []struct {
+		Name  string              `json:"name"`
+		Usage corev1.ResourceList `json:"usage"`
+	} `json:"containers"`
+}
+
+// MetricsServerUsageSource reads CPU usage from the metrics.k8s.io API
+type MetricsServerUsageSource struct {
+	restClient rest.Interface
+}
+
+// NewMetricsServerUsageSource creates a MetricsServerUsageSource using a REST client rooted at the API server
+func NewMetricsServerUsageSource(restClient rest.Interface) *MetricsServerUsageSource {
+	return &MetricsServerUsageSource{restClient: restClient}
+}
+
+// Usage implements PodUsageSource with the usage averaged over the metrics-server window
+func (s *MetricsServerUsageSource) Usage(ctx context.Context, pod *corev1.Pod) (*PodUsage, error) {
+	path := fmt.Sprintf("/apis/metrics.k8s.io/v1beta1/namespaces/%s/pods/%s", pod.Namespace, pod.Name)
+	raw, err := s.restClient.Get().AbsPath(path).DoRaw(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pod metrics: %w", err)
+	}
+
+	var metrics podMetrics
+	if err := json.Unmarshal(raw, &metrics); err != nil {
+		return nil, fmt.Errorf("failed to parse pod metrics: %w", err)
+	}
+
+	for _, container := range metrics.Containers {
+		if container.Name == workspaceContainerName {
+			return &PodUsage{CPUMillicores: container.Usage.Cpu().MilliValue()}, nil
+		}
+	}
+	return nil, fmt.Errorf("no metrics for container %s", workspaceContainerName)
+}
+
+// prometheusQueryResponse is the response of the Prometheus /api/v1/query endpoint
+type prometheusQueryResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Value []interface{} `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// PrometheusUsageSource reads CPU and network usage from cAdvisor metrics in a Prometheus-compatible API
+type PrometheusUsageSource struct {
+	baseURL    string
+	rateWindow time.Duration
+	httpClient *http.Client
+}
+
+// NewPrometheusUsageSource creates a PrometheusUsageSource from the source configuration
+func NewPrometheusUsageSource(config *workspacev1alpha1.PrometheusMetricsSource) *PrometheusUsageSource {
+	rateWindow := DefaultPrometheusRateWindow
+	if config.RateWindowSeconds > 0 {
+		rateWindow = time.Duration(config.RateWindowSeconds) * time.Second
+	}
+	return &PrometheusUsageSource{
+		baseURL:    strings.TrimSuffix(config.URL, "/"),
+		rateWindow: rateWindow,
+		httpClient: &http.Client{Timeout: DefaultIdleProbeTimeout},
+	}
+}
+
+// Usage implements PodUsageSource with usage rates over the configured window
+func (s *PrometheusUsageSource) Usage(ctx context.Context, pod *corev1.Pod) (*PodUsage, error) {
+	window := fmt.Sprintf("%ds", int(s.rateWindow.Seconds()))
+
+	cpuQuery := fmt.Sprintf(`sum(rate(container_cpu_usage_seconds_total{namespace=%q,pod=%q,container=%q}[%s]))`,
+		pod.Namespace, pod.Name, workspaceContainerName, window)
+	cpuCores, found, err := s.query(ctx, cpuQuery)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("no CPU samples for pod %s", pod.Name)
+	}
+
+	networkQuery := fmt.Sprintf(`sum(rate({__name__=~"container_network_(receive|transmit)_bytes_total",namespace=%q,pod=%q}[%s]))`,
+		pod.Namespace, pod.Name, window)
+	networkBytes, found, err := s.query(ctx, networkQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &PodUsage{CPUMillicores: int64(cpuCores * 1000)}
+	if found {
+		usage.NetworkBytesPerSecond = &networkBytes
+	}
+	return usage, nil
+}
+
+// query runs an instant query returning a single scalar sample
+// Returns false without error when the query matched no series
+func (s *PrometheusUsageSource) query(ctx context.Context, query string) (float64, bool, error) {
+	endpoint := s.baseURL + "/api/v1/query?" + url.Values{"query": {query}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to build prometheus request: %w", err)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, false, fmt.Errorf("prometheus request failed: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxIdleResponseBytes))
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to read prometheus response: %w", err)
+	}
+
+	var result prometheusQueryResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return 0, false, fmt.Errorf("failed to parse prometheus response (HTTP %d): %w", resp.StatusCode, err)
+	}
+	if result.Status != "success" {
+		return 0, false, fmt.Errorf("prometheus query failed: %s", result.Error)
+	}
+	if len(result.Data.Result) == 0 {
+		return 0, false, nil
+	}
+
+	sample := result.Data.Result[0].Value
+	if len(sample) != 2 {
+		return 0, false, fmt.Errorf("unexpected prometheus sample: %v", sample)
+	}
+	valueStr, ok := sample[1].(string)
+	if !ok {
+		return 0, false, fmt.Errorf("unexpected prometheus sample value: %v", sample[1])
+	}
+	value, err := strconv.ParseFloat(valueStr, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to parse prometheus sample value: %w", err)
+	}
+	return value, true, nil
+}
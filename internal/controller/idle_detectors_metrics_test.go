@@ -0,0 +1,273 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+)
+
+// fakeUsageSource returns a fixed usage
+type fakeUsageSource struct {
+	usage *PodUsage
+	err   error
+}
+
+func (f *fakeUsageSource) Usage(_ context.Context, _ *corev1.Pod) (*PodUsage, error) {
+	return f.usage, f.err
+}
+
+func createMetricsIdleConfig() *workspacev1alpha1.IdleShutdownSpec {
+	return &workspacev1alpha1.IdleShutdownSpec{
+		Enabled:              true,
+		IdleTimeoutInMinutes: 30,
+		Detection: workspacev1alpha1.IdleDetectionSpec{
+			Metrics: &workspacev1alpha1.MetricsIdleDetection{
+				Source:       workspacev1alpha1.MetricsIdleSourceMetricsServer,
+				CPUThreshold: resource.MustParse("100m"),
+			},
+		},
+	}
+}
+
+// newTestMetricsDetector creates a detector with its own tracker and a controllable clock
+func newTestMetricsDetector(source PodUsageSource, now *time.Time) *MetricsDetector {
+	detector := NewMetricsDetectorWithSource(source)
+	detector.tracker = newUsageActivityTracker()
+	detector.now = func() time.Time { return *now }
+	return detector
+}
+
+func TestMetricsDetector_IdleAfterTimeoutBelowThreshold(t *testing.T) {
+	now := time.Now()
+	source := &fakeUsageSource{usage: &PodUsage{CPUMillicores: 20}}
+	detector := newTestMetricsDetector(source, &now)
+	pod := createTestPod()
+	pod.UID = types.UID("pod-uid")
+	idleConfig := createMetricsIdleConfig()
+
+	// First observation counts as activity
+	result, err := detector.CheckIdle(context.Background(), testWorkspaceName, pod, idleConfig)
+	require.NoError(t, err)
+	assert.False(t, result.IsIdle)
+
+	now = now.Add(20 * time.Minute)
+	result, err = detector.CheckIdle(context.Background(), testWorkspaceName, pod, idleConfig)
+	require.NoError(t, err)
+	assert.False(t, result.IsIdle)
+
+	now = now.Add(11 * time.Minute)
+	result, err = detector.CheckIdle(context.Background(), testWorkspaceName, pod, idleConfig)
+	require.NoError(t, err)
+	assert.True(t, result.IsIdle)
+	assert.True(t, result.ShouldRetry)
+}
+
+func TestMetricsDetector_ActivityResetsTimeout(t *testing.T) {
+	now := time.Now()
+	source := &fakeUsageSource{usage: &PodUsage{CPUMillicores: 20}}
+	detector := newTestMetricsDetector(source, &now)
+	pod := createTestPod()
+	idleConfig := createMetricsIdleConfig()
+
+	_, err := detector.CheckIdle(context.Background(), testWorkspaceName, pod, idleConfig)
+	require.NoError(t, err)
+
+	// CPU above threshold
+	now = now.Add(25 * time.Minute)
+	source.usage = &PodUsage{CPUMillicores: 500}
+	result, err := detector.CheckIdle(context.Background(), testWorkspaceName, pod, idleConfig)
+	require.NoError(t, err)
+	assert.False(t, result.IsIdle)
+
+	now = now.Add(25 * time.Minute)
+	source.usage = &PodUsage{CPUMillicores: 20}
+	result, err = detector.CheckIdle(context.Background(), testWorkspaceName, pod, idleConfig)
+	require.NoError(t, err)
+	assert.False(t, result.IsIdle)
+}
+
+func TestMetricsDetector_NetworkActivity(t *testing.T) {
+	now := time.Now()
+	networkBytes := 5000.0
+	source := &fakeUsageSource{usage: &PodUsage{CPUMillicores: 20, NetworkBytesPerSecond: &networkBytes}}
+	detector := newTestMetricsDetector(source, &now)
+	pod := createTestPod()
+	idleConfig := createMetricsIdleConfig()
+	threshold := int64(1000)
+	idleConfig.Detection.Metrics.NetworkThresholdBytesPerSecond = &threshold
+
+	_, err := detector.CheckIdle(context.Background(), testWorkspaceName, pod, idleConfig)
+	require.NoError(t, err)
+
+	now = now.Add(time.Hour)
+	result, err := detector.CheckIdle(context.Background(), testWorkspaceName, pod, idleConfig)
+	require.NoError(t, err)
+	assert.False(t, result.IsIdle, "network traffic above threshold should count as activity")
+}
+
+func TestMetricsDetector_SourceError(t *testing.T) {
+	now := time.Now()
+	detector := newTestMetricsDetector(&fakeUsageSource{err: errors.New("metrics unavailable")}, &now)
+
+	result, err := detector.CheckIdle(context.Background(), testWorkspaceName, createTestPod(), createMetricsIdleConfig())
+	assert.Error(t, err)
+	assert.False(t, result.IsIdle)
+	assert.True(t, result.ShouldRetry)
+}
+
+func TestMetricsDetector_NilConfig(t *testing.T) {
+	now := time.Now()
+	detector := newTestMetricsDetector(&fakeUsageSource{}, &now)
+
+	result, err := detector.CheckIdle(context.Background(), testWorkspaceName, createTestPod(), createTestIdleConfig())
+	assert.Error(t, err)
+	assert.False(t, result.ShouldRetry)
+}
+
+func TestUsageActivityTracker_ForgetsOldPods(t *testing.T) {
+	tracker := newUsageActivityTracker()
+	now := time.Now()
+	tracker.observe("old", false, now)
+	tracker.observe("new", false, now.Add(usageActivityRetention+time.Minute))
+
+	assert.NotContains(t, tracker.activity, types.UID("old"))
+	assert.Contains(t, tracker.activity, types.UID("new"))
+}
+
+func TestMetricsServerUsageSource_Usage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/apis/metrics.k8s.io/v1beta1/namespaces/default/pods/test-pod", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"containers": [
+			{"name": "sidecar", "usage": {"cpu": "900m", "memory": "10Mi"}},
+			{"name": "workspace", "usage": {"cpu": "12500000n", "memory": "1Gi"}}
+		]}`))
+	}))
+	defer server.Close()
+
+	clientset, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	require.NoError(t, err)
+	source := NewMetricsServerUsageSource(clientset.Discovery().RESTClient())
+
+	usage, err := source.Usage(context.Background(), createTestPod())
+	require.NoError(t, err)
+	assert.Equal(t, int64(13), usage.CPUMillicores)
+	assert.Nil(t, usage.NetworkBytesPerSecond)
+}
+
+func TestMetricsServerUsageSource_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	clientset, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	require.NoError(t, err)
+	source := NewMetricsServerUsageSource(clientset.Discovery().RESTClient())
+
+	_, err = source.Usage(context.Background(), createTestPod())
+	assert.Error(t, err)
+}
+
+// prometheusVector formats a single-sample instant query response
+func prometheusVector(value string) string {
+	return fmt.Sprintf(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1700000000,%q]}]}}`, value)
+}
+
+func TestPrometheusUsageSource_Usage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/query", r.URL.Path)
+		query := r.URL.Query().Get("query")
+		assert.Contains(t, query, `namespace="default",pod="test-pod"`)
+		assert.Contains(t, query, "[120s]")
+		switch {
+		case strings.Contains(query, "container_cpu_usage_seconds_total"):
+			_, _ = w.Write([]byte(prometheusVector("0.25")))
+		case strings.Contains(query, "container_network_"):
+			_, _ = w.Write([]byte(prometheusVector("2048.5")))
+		default:
+			t.Errorf("unexpected query: %s", query)
+		}
+	}))
+	defer server.Close()
+
+	source := NewPrometheusUsageSource(&workspacev1alpha1.PrometheusMetricsSource{URL: server.URL + "/", RateWindowSeconds: 120})
+	usage, err := source.Usage(context.Background(), createTestPod())
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(250), usage.CPUMillicores)
+	require.NotNil(t, usage.NetworkBytesPerSecond)
+	assert.InDelta(t, 2048.5, *usage.NetworkBytesPerSecond, 0.001)
+}
+
+func TestPrometheusUsageSource_NoSamples(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+	}))
+	defer server.Close()
+
+	source := NewPrometheusUsageSource(&workspacev1alpha1.PrometheusMetricsSource{URL: server.URL})
+	_, err := source.Usage(context.Background(), createTestPod())
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "no CPU samples")
+}
+
+func TestPrometheusUsageSource_QueryError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"status":"error","errorType":"bad_data","error":"parse error"}`))
+	}))
+	defer server.Close()
+
+	source := NewPrometheusUsageSource(&workspacev1alpha1.PrometheusMetricsSource{URL: server.URL})
+	_, err := source.Usage(context.Background(), createTestPod())
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "parse error")
+}
+
+func TestCreateIdleDetector_MetricsPrometheus(t *testing.T) {
+	t.Setenv(IdlePrometheusAllowedURLsEnv, "http://thanos:9090, http://prometheus:9090/")
+	detector, err := CreateIdleDetector(&workspacev1alpha1.IdleDetectionSpec{
+		Metrics: &workspacev1alpha1.MetricsIdleDetection{
+			Source:     workspacev1alpha1.MetricsIdleSourcePrometheus,
+			Prometheus: &workspacev1alpha1.PrometheusMetricsSource{URL: "http://prometheus:9090"},
+		},
+	}, nil)
+
+	require.NoError(t, err)
+	require.IsType(t, &MetricsDetector{}, detector)
+	assert.IsType(t, &PrometheusUsageSource{}, detector.(*MetricsDetector).source)
+}
+
+func TestCreateIdleDetector_MetricsPrometheusURLNotAllowed(t *testing.T) {
+	t.Setenv(IdlePrometheusAllowedURLsEnv, "http://thanos:9090")
+	_, err := CreateIdleDetector(&workspacev1alpha1.IdleDetectionSpec{
+		Metrics: &workspacev1alpha1.MetricsIdleDetection{
+			Source:     workspacev1alpha1.MetricsIdleSourcePrometheus,
+			Prometheus: &workspacev1alpha1.PrometheusMetricsSource{URL: "http://169.254.169.254"},
+		},
+	}, nil)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), IdlePrometheusAllowedURLsEnv)
+}
@@ -0,0 +1,69 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ProxyActivityDetector implements idle detection from the last authenticated request
+// recorded by the auth middleware in the workspace status
+type ProxyActivityDetector struct {
+	reader client.Reader
+}
+
+// NewProxyActivityDetector creates a new ProxyActivityDetector reading workspaces with the provided reader
+func NewProxyActivityDetector(reader client.Reader) *ProxyActivityDetector {
+	return &ProxyActivityDetector{
+		reader: reader,
+	}
+}
+
+// CheckIdle implements the IdleDetector interface using status.lastActivityTime
+func (p *ProxyActivityDetector) CheckIdle(ctx context.Context, workspaceName string, pod *corev1.Pod, idleConfig *workspacev1alpha1.IdleShutdownSpec) (*IdleCheckResult, error) {
+	logger := logf.FromContext(ctx).WithValues("workspace", workspaceName, "pod", pod.Name)
+
+	if p.reader == nil {
+		return &IdleCheckResult{IsIdle: false, ShouldRetry: false}, fmt.Errorf("kubernetes client is nil")
+	}
+
+	workspace := &workspacev1alpha1.Workspace{}
+	if err := p.reader.Get(ctx, types.NamespacedName{Name: workspaceName, Namespace: pod.Namespace}, workspace); err != nil {
+		return &IdleCheckResult{IsIdle: false, ShouldRetry: true}, fmt.Errorf("failed to get workspace: %w", err)
+	}
+
+	// Requests recorded before the pod started belong to a previous run of the workspace,
+	// and a workspace nobody connected to yet is idle from the time its pod started
+	lastActivity := pod.CreationTimestamp.Time
+	if pod.Status.StartTime != nil {
+		lastActivity = pod.Status.StartTime.Time
+	}
+	if recorded := workspace.Status.LastActivityTime; recorded != nil && recorded.After(lastActivity) {
+		lastActivity = recorded.Time
+	}
+
+	timeout := time.Duration(idleConfig.IdleTimeoutInMinutes) * time.Minute
+	idleTime := time.Since(lastActivity)
+	if idleTime > timeout {
+		logger.Info("Idle timeout reached", "idleTime", idleTime, "timeout", timeout, "lastActivity", lastActivity)
+		return &IdleCheckResult{IsIdle: true, ShouldRetry: true}, nil
+	}
+
+	logger.V(1).Info("Workspace still active, timeout not reached",
+		"idleTime", idleTime,
+		"timeout", timeout,
+		"remaining", timeout-idleTime,
+		"lastActivity", lastActivity)
+	return &IdleCheckResult{IsIdle: false, ShouldRetry: true}, nil
+}
@@ -0,0 +1,117 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+)
+
+func createProxyIdleConfig() *workspacev1alpha1.IdleShutdownSpec {
+	return &workspacev1alpha1.IdleShutdownSpec{
+		Enabled:              true,
+		IdleTimeoutInMinutes: 30,
+		Detection: workspacev1alpha1.IdleDetectionSpec{
+			ProxyActivity: &workspacev1alpha1.ProxyActivityIdleDetection{},
+		},
+	}
+}
+
+// newProxyDetectorWithWorkspace creates a detector reading a workspace with the given last activity time
+func newProxyDetectorWithWorkspace(lastActivity *time.Time) *ProxyActivityDetector {
+	scheme := runtime.NewScheme()
+	_ = workspacev1alpha1.AddToScheme(scheme)
+
+	workspace := &workspacev1alpha1.Workspace{
+		ObjectMeta: metav1.ObjectMeta{Name: testWorkspaceName, Namespace: "default"},
+	}
+	if lastActivity != nil {
+		workspace.Status.LastActivityTime = &metav1.Time{Time: *lastActivity}
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(workspace).
+		WithStatusSubresource(workspace).
+		Build()
+	return NewProxyActivityDetector(fakeClient)
+}
+
+// createStartedPod returns a running pod started at the given time
+func createStartedPod(startedAt time.Time) *corev1.Pod {
+	pod := createTestPod()
+	pod.Status.StartTime = &metav1.Time{Time: startedAt}
+	return pod
+}
+
+func TestProxyActivityDetector_RecentActivity(t *testing.T) {
+	lastActivity := time.Now().Add(-5 * time.Minute)
+	detector := newProxyDetectorWithWorkspace(&lastActivity)
+
+	result, err := detector.CheckIdle(context.Background(), testWorkspaceName, createStartedPod(time.Now().Add(-2*time.Hour)), createProxyIdleConfig())
+
+	require.NoError(t, err)
+	assert.False(t, result.IsIdle)
+	assert.True(t, result.ShouldRetry)
+}
+
+func TestProxyActivityDetector_IdleAfterTimeout(t *testing.T) {
+	lastActivity := time.Now().Add(-45 * time.Minute)
+	detector := newProxyDetectorWithWorkspace(&lastActivity)
+
+	result, err := detector.CheckIdle(context.Background(), testWorkspaceName, createStartedPod(time.Now().Add(-2*time.Hour)), createProxyIdleConfig())
+
+	require.NoError(t, err)
+	assert.True(t, result.IsIdle)
+}
+
+func TestProxyActivityDetector_IgnoresActivityBeforePodStart(t *testing.T) {
+	lastActivity := time.Now().Add(-3 * time.Hour)
+	detector := newProxyDetectorWithWorkspace(&lastActivity)
+
+	result, err := detector.CheckIdle(context.Background(), testWorkspaceName, createStartedPod(time.Now().Add(-10*time.Minute)), createProxyIdleConfig())
+
+	require.NoError(t, err)
+	assert.False(t, result.IsIdle, "a freshly started workspace must not be stopped because of an old activity time")
+}
+
+func TestProxyActivityDetector_NoActivityRecorded(t *testing.T) {
+	detector := newProxyDetectorWithWorkspace(nil)
+
+	result, err := detector.CheckIdle(context.Background(), testWorkspaceName, createStartedPod(time.Now().Add(-10*time.Minute)), createProxyIdleConfig())
+	require.NoError(t, err)
+	assert.False(t, result.IsIdle)
+
+	result, err = detector.CheckIdle(context.Background(), testWorkspaceName, createStartedPod(time.Now().Add(-time.Hour)), createProxyIdleConfig())
+	require.NoError(t, err)
+	assert.True(t, result.IsIdle)
+}
+
+func TestProxyActivityDetector_WorkspaceNotFound(t *testing.T) {
+	detector := newProxyDetectorWithWorkspace(nil)
+
+	result, err := detector.CheckIdle(context.Background(), "missing", createStartedPod(time.Now()), createProxyIdleConfig())
+
+	assert.Error(t, err)
+	assert.True(t, result.ShouldRetry)
+}
+
+func TestCreateIdleDetector_ProxyActivity(t *testing.T) {
+	detector, err := CreateIdleDetector(&createProxyIdleConfig().Detection, nil)
+
+	require.NoError(t, err)
+	assert.IsType(t, &ProxyActivityDetector{}, detector)
+}
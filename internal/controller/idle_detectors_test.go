@@ -17,6 +17,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
 )
@@ -57,7 +58,7 @@ func createTestIdleConfig() *workspacev1alpha1.IdleShutdownSpec {
 func TestCreateIdleDetector_HTTPGet_Success(t *testing.T) {
 	// Override factory function to avoid K8s client creation in tests
 	originalCreateIdleDetector := CreateIdleDetector
-	CreateIdleDetector = func(detection *workspacev1alpha1.IdleDetectionSpec) (IdleDetector, error) {
+	CreateIdleDetector = func(detection *workspacev1alpha1.IdleDetectionSpec, reader client.Reader) (IdleDetector, error) {
 		if detection.HTTPGet != nil {
 			mockExecUtil := &MockPodExecUtil{}
 			return NewHTTPGetDetectorWithExec(mockExecUtil), nil
@@ -75,7 +76,7 @@ func TestCreateIdleDetector_HTTPGet_Success(t *testing.T) {
 		},
 	}
 
-	detector, err := CreateIdleDetector(detection)
+	detector, err := CreateIdleDetector(detection, nil)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, detector)
@@ -85,7 +86,7 @@ func TestCreateIdleDetector_HTTPGet_Success(t *testing.T) {
 func TestCreateIdleDetector_NoDetectionMethod_Error(t *testing.T) {
 	detection := &workspacev1alpha1.IdleDetectionSpec{}
 
-	detector, err := CreateIdleDetector(detection)
+	detector, err := CreateIdleDetector(detection, nil)
 
 	assert.Error(t, err)
 	assert.Nil(t, detector)
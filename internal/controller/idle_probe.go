@@ -0,0 +1,218 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	"github.com/jupyter-infra/jupyter-k8s/internal/pluginadapters"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// DefaultIdleProbeTimeout is the default timeout of direct idle probe requests
+	DefaultIdleProbeTimeout = 5 * time.Second
+
+	// maxIdleResponseBytes bounds the size of idle endpoint responses read by the controller
+	maxIdleResponseBytes = 1 << 20
+
+	// maxIdleProbeClients bounds the number of HTTP client configurations kept for direct idle probes
+	maxIdleProbeClients = 32
+)
+
+// IdleEndpointFetcher performs HTTP GET requests against the idle endpoint of a workspace pod
+type IdleEndpointFetcher interface {
+	// Get returns the HTTP status code and the response body
+	Get(ctx context.Context, workspaceName string, pod *corev1.Pod, scheme corev1.URIScheme, port, path string) (string, string, error)
+}
+
+// execEndpointFetcher runs curl inside the workspace container through pods/exec
+type execEndpointFetcher struct {
+	execUtil pluginadapters.PodExecInterface
+}
+
+// Get implements IdleEndpointFetcher by calling localhost from inside the workspace container
+func (e *execEndpointFetcher) Get(ctx context.Context, _ string, pod *corev1.Pod, scheme corev1.URIScheme, port, path string) (string, string, error) {
+	url := fmt.Sprintf("%s://localhost:%s%s", normalizeIdleScheme(scheme), port, path)
+	return execHTTPGetInPod(ctx, e.execUtil, pod, url)
+}
+
+// DirectEndpointFetcher calls the idle endpoint from the controller, through the workspace
+// Service or the pod IP, so that neither curl in the image nor pods/exec RBAC is needed
+type DirectEndpointFetcher struct {
+	mode       workspacev1alpha1.IdleProbeMode
+	httpClient *http.Client
+}
+
+// NewDirectEndpointFetcher creates a DirectEndpointFetcher from the probe configuration
+// The HTTP client is shared by the fetchers of the same configuration, so that connections are reused
+func NewDirectEndpointFetcher(probe *workspacev1alpha1.IdleProbeSpec) (*DirectEndpointFetcher, error) {
+	httpClient, err := defaultIdleProbeClients.get(probe)
+	if err != nil {
+		return nil, err
+	}
+	return &DirectEndpointFetcher{
+		mode:       probe.Mode,
+		httpClient: httpClient,
+	}, nil
+}
+
+// idleProbeClientCache holds the HTTP clients of direct idle probes by configuration
+type idleProbeClientCache struct {
+	mu      sync.Mutex
+	clients map[string]*http.Client
+}
+
+// defaultIdleProbeClients is shared by all direct fetchers, which are created for each check
+var defaultIdleProbeClients = newIdleProbeClientCache()
+
+func newIdleProbeClientCache() *idleProbeClientCache {
+	return &idleProbeClientCache{clients: map[string]*http.Client{}}
+}
+
+// get returns the HTTP client for the probe configuration, creating it on first use
+func (c *idleProbeClientCache) get(probe *workspacev1alpha1.IdleProbeSpec) (*http.Client, error) {
+	timeout := DefaultIdleProbeTimeout
+	if probe.TimeoutSeconds > 0 {
+		timeout = time.Duration(probe.TimeoutSeconds) * time.Second
+	}
+	key := idleProbeClientKey(timeout, probe.TLS)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if httpClient, found := c.clients[key]; found {
+		return httpClient, nil
+	}
+
+	httpClient, err := newIdleProbeHTTPClient(timeout, probe.TLS)
+	if err != nil {
+		return nil, err
+	}
+	// Bound the cache, configurations come from workspaces and templates
+	if len(c.clients) >= maxIdleProbeClients {
+		for evicted, evictedClient := range c.clients {
+			evictedClient.CloseIdleConnections()
+			delete(c.clients, evicted)
+			break
+		}
+	}
+	c.clients[key] = httpClient
+	return httpClient, nil
+}
+
+// idleProbeClientKey identifies the HTTP client settings of a probe
+func idleProbeClientKey(timeout time.Duration, tlsSpec *workspacev1alpha1.IdleProbeTLSConfig) string {
+	if tlsSpec == nil {
+		return timeout.String()
+	}
+	caBundleHash := sha256.Sum256(tlsSpec.CABundle)
+	return fmt.Sprintf("%s/%t/%s/%x", timeout, tlsSpec.InsecureSkipVerify, tlsSpec.ServerName, caBundleHash)
+}
+
+// newIdleProbeHTTPClient creates the HTTP client of direct idle probes
+func newIdleProbeHTTPClient(timeout time.Duration, tlsSpec *workspacev1alpha1.IdleProbeTLSConfig) (*http.Client, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if tlsSpec != nil {
+		tlsConfig.InsecureSkipVerify = tlsSpec.InsecureSkipVerify
+		tlsConfig.ServerName = tlsSpec.ServerName
+		if len(tlsSpec.CABundle) > 0 {
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(tlsSpec.CABundle) {
+				return nil, fmt.Errorf("invalid idle probe caBundle: no PEM certificates found")
+			}
+			tlsConfig.RootCAs = pool
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		// Idle endpoints are expected to answer directly, redirects usually point to a login page
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}, nil
+}
+
+// Get implements IdleEndpointFetcher with a direct HTTP request from the controller
+func (d *DirectEndpointFetcher) Get(ctx context.Context, workspaceName string, pod *corev1.Pod, scheme corev1.URIScheme, port, path string) (string, string, error) {
+	host, err := d.host(workspaceName, pod)
+	if err != nil {
+		return "", "", err
+	}
+	url := fmt.Sprintf("%s://%s%s", normalizeIdleScheme(scheme), net.JoinHostPort(host, port), path)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to build idle probe request: %w", err)
+	}
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return "", "", fmt.Errorf("idle probe request failed: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxIdleResponseBytes))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read idle probe response: %w", err)
+	}
+
+	return strconv.Itoa(resp.StatusCode), string(body), nil
+}
+
+// host returns the host to reach depending on the probe mode
+func (d *DirectEndpointFetcher) host(workspaceName string, pod *corev1.Pod) (string, error) {
+	switch d.mode {
+	case workspacev1alpha1.IdleProbeModeService:
+		return fmt.Sprintf("%s.%s.svc", GenerateServiceName(workspaceName), pod.Namespace), nil
+	case workspacev1alpha1.IdleProbeModePodIP:
+		if pod.Status.PodIP == "" {
+			return "", fmt.Errorf("pod %s has no IP assigned", pod.Name)
+		}
+		return pod.Status.PodIP, nil
+	default:
+		return "", fmt.Errorf("unsupported direct idle probe mode: %s", d.mode)
+	}
+}
+
+// newIdleEndpointFetcher returns the fetcher for the probe configuration,
+// or nil when the idle endpoint is reached through pods/exec
+func newIdleEndpointFetcher(probe *workspacev1alpha1.IdleProbeSpec) (IdleEndpointFetcher, error) {
+	if probe == nil || probe.Mode == "" || probe.Mode == workspacev1alpha1.IdleProbeModeExec {
+		return nil, nil
+	}
+	fetcher, err := NewDirectEndpointFetcher(probe)
+	if err != nil {
+		return nil, err
+	}
+	return fetcher, nil
+}
+
+// normalizeIdleScheme returns the lower-case URL scheme, defaulting to http
+func normalizeIdleScheme(scheme corev1.URIScheme) string {
+	if scheme == "" {
+		return "http"
+	}
+	return strings.ToLower(string(scheme))
+}
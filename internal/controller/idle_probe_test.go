@@ -0,0 +1,176 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+)
+
+// podForServer returns a running pod whose IP is the host of the test server, along with the server port
+func podForServer(t *testing.T, server *httptest.Server) (*corev1.Pod, string) {
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+	pod := createTestPod()
+	pod.Status.PodIP = host
+	return pod, port
+}
+
+func TestNewIdleEndpointFetcher_ExecByDefault(t *testing.T) {
+	fetcher, err := newIdleEndpointFetcher(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, fetcher)
+
+	fetcher, err = newIdleEndpointFetcher(&workspacev1alpha1.IdleProbeSpec{Mode: workspacev1alpha1.IdleProbeModeExec})
+	assert.NoError(t, err)
+	assert.Nil(t, fetcher)
+}
+
+func TestCreateIdleDetector_DirectProbe(t *testing.T) {
+	detector, err := CreateIdleDetector(&workspacev1alpha1.IdleDetectionSpec{
+		HTTPGet: &corev1.HTTPGetAction{Path: "/api/idle", Port: intstr.FromInt(8888)},
+		Probe:   &workspacev1alpha1.IdleProbeSpec{Mode: workspacev1alpha1.IdleProbeModePodIP},
+	}, nil)
+
+	require.NoError(t, err)
+	require.IsType(t, &HTTPGetDetector{}, detector)
+	assert.IsType(t, &DirectEndpointFetcher{}, detector.(*HTTPGetDetector).fetcher)
+	assert.Nil(t, detector.(*HTTPGetDetector).execUtil)
+}
+
+func TestDirectEndpointFetcher_PodIP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/idle", r.URL.Path)
+		_, _ = fmt.Fprintf(w, `{"lastActiveTimestamp": %q}`, time.Now().Add(-45*time.Minute).Format(time.RFC3339))
+	}))
+	defer server.Close()
+	pod, port := podForServer(t, server)
+
+	fetcher, err := NewDirectEndpointFetcher(&workspacev1alpha1.IdleProbeSpec{Mode: workspacev1alpha1.IdleProbeModePodIP})
+	require.NoError(t, err)
+
+	detector := NewHTTPGetDetectorWithFetcher(fetcher)
+	idleConfig := createTestIdleConfig()
+	idleConfig.Detection.HTTPGet.Port = intstr.Parse(port)
+
+	result, err := detector.CheckIdle(context.Background(), testWorkspaceName, pod, idleConfig)
+	require.NoError(t, err)
+	assert.True(t, result.IsIdle)
+}
+
+func TestDirectEndpointFetcher_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+	pod, port := podForServer(t, server)
+
+	fetcher, err := NewDirectEndpointFetcher(&workspacev1alpha1.IdleProbeSpec{
+		Mode:           workspacev1alpha1.IdleProbeModePodIP,
+		TimeoutSeconds: 1,
+	})
+	require.NoError(t, err)
+
+	_, _, err = fetcher.Get(context.Background(), testWorkspaceName, pod, corev1.URISchemeHTTP, port, "/api/idle")
+	assert.Error(t, err)
+}
+
+func TestDirectEndpointFetcher_TLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+	pod, port := podForServer(t, server)
+
+	// Untrusted certificate is rejected
+	fetcher, err := NewDirectEndpointFetcher(&workspacev1alpha1.IdleProbeSpec{Mode: workspacev1alpha1.IdleProbeModePodIP})
+	require.NoError(t, err)
+	_, _, err = fetcher.Get(context.Background(), testWorkspaceName, pod, corev1.URISchemeHTTPS, port, "/")
+	assert.Error(t, err)
+
+	// Trusted through the CA bundle
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	fetcher, err = NewDirectEndpointFetcher(&workspacev1alpha1.IdleProbeSpec{
+		Mode: workspacev1alpha1.IdleProbeModePodIP,
+		TLS:  &workspacev1alpha1.IdleProbeTLSConfig{CABundle: caBundle, ServerName: "example.com"},
+	})
+	require.NoError(t, err)
+	statusCode, body, err := fetcher.Get(context.Background(), testWorkspaceName, pod, corev1.URISchemeHTTPS, port, "/")
+	require.NoError(t, err)
+	assert.Equal(t, "200", statusCode)
+	assert.Equal(t, "ok", body)
+
+	// Verification disabled
+	fetcher, err = NewDirectEndpointFetcher(&workspacev1alpha1.IdleProbeSpec{
+		Mode: workspacev1alpha1.IdleProbeModePodIP,
+		TLS:  &workspacev1alpha1.IdleProbeTLSConfig{InsecureSkipVerify: true},
+	})
+	require.NoError(t, err)
+	statusCode, _, err = fetcher.Get(context.Background(), testWorkspaceName, pod, corev1.URISchemeHTTPS, port, "/")
+	require.NoError(t, err)
+	assert.Equal(t, "200", statusCode)
+}
+
+func TestNewDirectEndpointFetcher_InvalidCABundle(t *testing.T) {
+	_, err := NewDirectEndpointFetcher(&workspacev1alpha1.IdleProbeSpec{
+		Mode: workspacev1alpha1.IdleProbeModeService,
+		TLS:  &workspacev1alpha1.IdleProbeTLSConfig{CABundle: []byte("not a certificate")},
+	})
+	assert.Error(t, err)
+}
+
+func TestNewDirectEndpointFetcher_ReusesHTTPClient(t *testing.T) {
+	probe := &workspacev1alpha1.IdleProbeSpec{Mode: workspacev1alpha1.IdleProbeModeService, TimeoutSeconds: 7}
+	first, err := NewDirectEndpointFetcher(probe)
+	require.NoError(t, err)
+	second, err := NewDirectEndpointFetcher(probe)
+	require.NoError(t, err)
+	assert.Same(t, first.httpClient, second.httpClient)
+
+	insecure, err := NewDirectEndpointFetcher(&workspacev1alpha1.IdleProbeSpec{
+		Mode:           workspacev1alpha1.IdleProbeModeService,
+		TimeoutSeconds: 7,
+		TLS:            &workspacev1alpha1.IdleProbeTLSConfig{InsecureSkipVerify: true},
+	})
+	require.NoError(t, err)
+	assert.NotSame(t, first.httpClient, insecure.httpClient)
+}
+
+func TestIdleProbeClientCache_Bounded(t *testing.T) {
+	cache := newIdleProbeClientCache()
+	for i := 1; i <= maxIdleProbeClients+5; i++ {
+		_, err := cache.get(&workspacev1alpha1.IdleProbeSpec{TimeoutSeconds: int32(i)})
+		require.NoError(t, err)
+	}
+	assert.Len(t, cache.clients, maxIdleProbeClients)
+}
+
+func TestDirectEndpointFetcher_Host(t *testing.T) {
+	pod := createTestPod()
+	service := &DirectEndpointFetcher{mode: workspacev1alpha1.IdleProbeModeService}
+	host, err := service.host(testWorkspaceName, pod)
+	require.NoError(t, err)
+	assert.Equal(t, "workspace-test-workspace-service.default.svc", host)
+
+	podIP := &DirectEndpointFetcher{mode: workspacev1alpha1.IdleProbeModePodIP}
+	_, err = podIP.host(testWorkspaceName, pod)
+	assert.Error(t, err)
+}
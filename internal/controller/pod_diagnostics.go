@@ -0,0 +1,184 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+)
+
+// Pod event reasons that indicate a workspace pod is failing to start
+const (
+	PodEventReasonFailedScheduling = "FailedScheduling"
+	PodEventReasonFailed           = "Failed"
+	PodEventReasonBackOff          = "BackOff"
+)
+
+// PodFailure describes why a workspace pod is failing to become ready
+type PodFailure struct {
+	// Reason is the Degraded condition reason
+	Reason string
+	// Message is the human-readable explanation surfaced in the Degraded condition
+	Message string
+}
+
+// PodDiagnosis is the outcome of inspecting the pods of a workspace
+type PodDiagnosis struct {
+	// Failure is set when a pod is failing to start, nil when pods are merely starting
+	Failure *PodFailure
+	// LastTermination is the most recent container termination across the pods, if any
+	LastTermination *workspacev1alpha1.ContainerTerminationStatus
+}
+
+// diagnoseWorkspacePods lists the pods of the workspace and inspects them for failures
+func diagnoseWorkspacePods(
+	ctx context.Context,
+	k8sClient client.Client,
+	workspace *workspacev1alpha1.Workspace) (*PodDiagnosis, error) {
+	podList := &corev1.PodList{}
+	if err := k8sClient.List(ctx, podList,
+		client.InNamespace(workspace.Namespace),
+		client.MatchingLabels(GenerateLabels(workspace.Name))); err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	return diagnosePods(podList.Items), nil
+}
+
+// diagnosePods returns the first failure found across the pods and their most recent container termination
+func diagnosePods(pods []corev1.Pod) *PodDiagnosis {
+	diagnosis := &PodDiagnosis{}
+	for i := range pods {
+		pod := &pods[i]
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		if diagnosis.Failure == nil {
+			diagnosis.Failure = diagnosePodFailure(pod)
+		}
+		if termination := latestContainerTermination(pod); termination != nil {
+			if diagnosis.LastTermination == nil || isLaterTermination(termination, diagnosis.LastTermination) {
+				diagnosis.LastTermination = termination
+			}
+		}
+	}
+	return diagnosis
+}
+
+// diagnosePodFailure maps the scheduling and container states of a pod to a Degraded reason
+func diagnosePodFailure(pod *corev1.Pod) *PodFailure {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type != corev1.PodScheduled ||
+			condition.Status != corev1.ConditionFalse ||
+			condition.Reason != corev1.PodReasonUnschedulable {
+			continue
+		}
+		if strings.Contains(condition.Message, "Insufficient") {
+			return &PodFailure{
+				Reason:  ReasonInsufficientResources,
+				Message: fmt.Sprintf("Pod %s cannot be scheduled: %s", pod.Name, condition.Message),
+			}
+		}
+		return &PodFailure{
+			Reason:  ReasonUnschedulable,
+			Message: fmt.Sprintf("Pod %s cannot be scheduled: %s", pod.Name, condition.Message),
+		}
+	}
+
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...),
+		pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		if failure := diagnoseContainerFailure(status); failure != nil {
+			return failure
+		}
+	}
+	return nil
+}
+
+// diagnoseContainerFailure maps the state of a single container to a Degraded reason
+func diagnoseContainerFailure(status corev1.ContainerStatus) *PodFailure {
+	if terminated := status.State.Terminated; terminated != nil && terminated.Reason == "OOMKilled" {
+		return &PodFailure{
+			Reason:  ReasonOutOfMemory,
+			Message: fmt.Sprintf("Container %s was killed because it ran out of memory", status.Name),
+		}
+	}
+
+	waiting := status.State.Waiting
+	if waiting == nil {
+		return nil
+	}
+	switch waiting.Reason {
+	case "ErrImagePull", "ImagePullBackOff", "InvalidImageName", "ErrImageNeverPull":
+		return &PodFailure{
+			Reason:  ReasonImagePullFailed,
+			Message: fmt.Sprintf("Container %s failed to pull image %s: %s", status.Name, status.Image, waiting.Message),
+		}
+	case "CreateContainerConfigError", "CreateContainerError":
+		return &PodFailure{
+			Reason:  ReasonContainerConfigError,
+			Message: fmt.Sprintf("Container %s cannot be created: %s", status.Name, waiting.Message),
+		}
+	case "CrashLoopBackOff":
+		if last := status.LastTerminationState.Terminated; last != nil && last.Reason == "OOMKilled" {
+			return &PodFailure{
+				Reason:  ReasonOutOfMemory,
+				Message: fmt.Sprintf("Container %s keeps running out of memory (%d restarts)", status.Name, status.RestartCount),
+			}
+		}
+		message := fmt.Sprintf("Container %s keeps crashing (%d restarts)", status.Name, status.RestartCount)
+		if last := status.LastTerminationState.Terminated; last != nil {
+			message = fmt.Sprintf("%s, last exit code %d", message, last.ExitCode)
+		}
+		return &PodFailure{
+			Reason:  ReasonCrashLoopBackOff,
+			Message: message,
+		}
+	}
+	return nil
+}
+
+// latestContainerTermination returns the most recent termination across the containers of a pod
+func latestContainerTermination(pod *corev1.Pod) *workspacev1alpha1.ContainerTerminationStatus {
+	var latest *workspacev1alpha1.ContainerTerminationStatus
+	for _, status := range pod.Status.ContainerStatuses {
+		terminated := status.State.Terminated
+		if terminated == nil {
+			terminated = status.LastTerminationState.Terminated
+		}
+		if terminated == nil {
+			continue
+		}
+		termination := &workspacev1alpha1.ContainerTerminationStatus{
+			ContainerName: status.Name,
+			ExitCode:      terminated.ExitCode,
+			Reason:        terminated.Reason,
+			Message:       terminated.Message,
+			RestartCount:  status.RestartCount,
+		}
+		if !terminated.FinishedAt.IsZero() {
+			finishedAt := terminated.FinishedAt
+			termination.FinishedAt = &finishedAt
+		}
+		if latest == nil || isLaterTermination(termination, latest) {
+			latest = termination
+		}
+	}
+	return latest
+}
+
+// isLaterTermination reports whether a finished after b
+func isLaterTermination(a, b *workspacev1alpha1.ContainerTerminationStatus) bool {
+	if a.FinishedAt == nil {
+		return false
+	}
+	return b.FinishedAt == nil || a.FinishedAt.After(b.FinishedAt.Time)
+}
@@ -0,0 +1,224 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+)
+
+func newDiagnosticsTestPod(status corev1.PodStatus) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "workspace-test-workspace-abc123-xyz789",
+			Namespace: "default",
+			Labels:    GenerateLabels(testWorkspaceName),
+		},
+		Status: status,
+	}
+}
+
+func TestDiagnosePodFailure(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         corev1.PodStatus
+		expectedReason string
+	}{
+		{
+			name:   "healthy pod",
+			status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		{
+			name: "insufficient resources",
+			status: corev1.PodStatus{Conditions: []corev1.PodCondition{{
+				Type:    corev1.PodScheduled,
+				Status:  corev1.ConditionFalse,
+				Reason:  corev1.PodReasonUnschedulable,
+				Message: "0/3 nodes are available: 3 Insufficient nvidia.com/gpu.",
+			}}},
+			expectedReason: ReasonInsufficientResources,
+		},
+		{
+			name: "unschedulable",
+			status: corev1.PodStatus{Conditions: []corev1.PodCondition{{
+				Type:    corev1.PodScheduled,
+				Status:  corev1.ConditionFalse,
+				Reason:  corev1.PodReasonUnschedulable,
+				Message: "0/3 nodes are available: 3 node(s) didn't match Pod's node affinity/selector.",
+			}}},
+			expectedReason: ReasonUnschedulable,
+		},
+		{
+			name: "image pull back-off",
+			status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "workspace",
+				Image: "missing:latest",
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}},
+			}}},
+			expectedReason: ReasonImagePullFailed,
+		},
+		{
+			name: "init container image pull error",
+			status: corev1.PodStatus{InitContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "init",
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ErrImagePull"}},
+			}}},
+			expectedReason: ReasonImagePullFailed,
+		},
+		{
+			name: "crash loop after out of memory",
+			status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+				Name:                 "workspace",
+				RestartCount:         3,
+				State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+				LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137}},
+			}}},
+			expectedReason: ReasonOutOfMemory,
+		},
+		{
+			name: "out of memory kill",
+			status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "workspace",
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137}},
+			}}},
+			expectedReason: ReasonOutOfMemory,
+		},
+		{
+			name: "crash loop",
+			status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+				Name:                 "workspace",
+				State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+				LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Error", ExitCode: 1}},
+			}}},
+			expectedReason: ReasonCrashLoopBackOff,
+		},
+		{
+			name: "container config error",
+			status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+				Name: "workspace",
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+					Reason:  "CreateContainerConfigError",
+					Message: `secret "missing" not found`,
+				}},
+			}}},
+			expectedReason: ReasonContainerConfigError,
+		},
+		{
+			name: "container creating",
+			status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "workspace",
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}},
+			}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failure := diagnosePodFailure(newDiagnosticsTestPod(tt.status))
+			if tt.expectedReason == "" {
+				assert.Nil(t, failure)
+				return
+			}
+			require.NotNil(t, failure)
+			assert.Equal(t, tt.expectedReason, failure.Reason)
+			assert.NotEmpty(t, failure.Message)
+		})
+	}
+}
+
+func TestDiagnosePods_LatestTermination(t *testing.T) {
+	older := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+	newer := metav1.NewTime(time.Now().Truncate(time.Second))
+
+	oldPod := newDiagnosticsTestPod(corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+		Name:                 "workspace",
+		RestartCount:         1,
+		LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Error", ExitCode: 1, FinishedAt: older}},
+	}}})
+	newPod := newDiagnosticsTestPod(corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+		Name:                 "workspace",
+		RestartCount:         2,
+		LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137, FinishedAt: newer}},
+	}}})
+	newPod.Name = "workspace-test-workspace-abc123-other"
+
+	diagnosis := diagnosePods([]corev1.Pod{*oldPod, *newPod})
+
+	assert.Nil(t, diagnosis.Failure)
+	require.NotNil(t, diagnosis.LastTermination)
+	assert.Equal(t, "OOMKilled", diagnosis.LastTermination.Reason)
+	assert.Equal(t, int32(137), diagnosis.LastTermination.ExitCode)
+	assert.Equal(t, int32(2), diagnosis.LastTermination.RestartCount)
+	assert.True(t, diagnosis.LastTermination.FinishedAt.Equal(&newer))
+}
+
+func TestDiagnoseStartingWorkspace(t *testing.T) {
+	workspace := &workspacev1alpha1.Workspace{
+		ObjectMeta: metav1.ObjectMeta{Name: testWorkspaceName, Namespace: "default"},
+		Spec:       workspacev1alpha1.WorkspaceSpec{DesiredStatus: DesiredStateRunning},
+	}
+	pod := newDiagnosticsTestPod(corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+		Name:                 "workspace",
+		RestartCount:         4,
+		State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+		LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137}},
+	}}})
+	scheme := runtime.NewScheme()
+	require.NoError(t, workspacev1alpha1.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(workspace, pod).
+		WithStatusSubresource(workspace).
+		Build()
+	recorder := record.NewFakeRecorder(10)
+	sm := NewStateMachine(
+		&ResourceManager{client: fakeClient, scheme: scheme},
+		NewStatusManager(fakeClient),
+		recorder,
+		NewWorkspaceIdleChecker(fakeClient),
+	)
+
+	failure := sm.diagnoseStartingWorkspace(context.Background(), workspace)
+
+	require.NotNil(t, failure)
+	assert.Equal(t, ReasonOutOfMemory, failure.Reason)
+	require.NotNil(t, workspace.Status.LastTermination)
+	assert.Equal(t, "OOMKilled", workspace.Status.LastTermination.Reason)
+	require.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, "Warning OutOfMemory")
+
+	// UpdateStartingStatus surfaces the failure in the Degraded condition
+	snapshot := workspace.Status.DeepCopy()
+	readiness := WorkspaceRunningReadiness{serviceReady: true, podFailure: failure}
+	require.NoError(t, sm.statusManager.UpdateStartingStatus(context.Background(), workspace, readiness, snapshot))
+
+	updated := &workspacev1alpha1.Workspace{}
+	require.NoError(t, fakeClient.Get(context.Background(), client.ObjectKeyFromObject(workspace), updated))
+	degraded := meta.FindStatusCondition(updated.Status.Conditions, ConditionTypeDegraded)
+	require.NotNil(t, degraded)
+	assert.Equal(t, metav1.ConditionTrue, degraded.Status)
+	assert.Equal(t, ReasonOutOfMemory, degraded.Reason)
+	require.NotNil(t, updated.Status.LastTermination)
+	assert.Equal(t, int32(137), updated.Status.LastTermination.ExitCode)
+
+	// the same failure does not emit a second event
+	sm.diagnoseStartingWorkspace(context.Background(), updated)
+	assert.Empty(t, recorder.Events)
+}
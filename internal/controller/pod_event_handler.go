@@ -8,6 +8,7 @@ package controller
 import (
 	"context"
 	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -117,7 +118,7 @@ func (h *PodEventHandler) HandleWorkspacePodEvents(ctx context.Context, obj clie
 	return nil
 }
 
-// HandleKubernetesEvents processes Kubernetes events for preemption detection
+// HandleKubernetesEvents processes Kubernetes events for preemption and pod failure detection
 func (h *PodEventHandler) HandleKubernetesEvents(ctx context.Context, obj client.Object) []reconcile.Request {
 	event, ok := obj.(*corev1.Event)
 	if !ok {
@@ -127,29 +128,17 @@ func (h *PodEventHandler) HandleKubernetesEvents(ctx context.Context, obj client
 	logger := logf.FromContext(ctx).WithValues("event", event.Name, "pod", event.InvolvedObject.Name)
 
 	// Check if this is a preemption event
-	if event.InvolvedObject.Kind == KindPod &&
-		event.Reason == DesiredStateStopped &&
-		strings.Contains(event.Message, "Preempted") {
-
+	if isPodPreemptionEvent(event) {
 		logger.Info("Detected pod preemption event",
 			"pod", event.InvolvedObject.Name,
 			"namespace", event.InvolvedObject.Namespace,
 			"message", event.Message)
 
-		// Extract workspace name from pod name
-		podName := event.InvolvedObject.Name
-		if !strings.HasPrefix(podName, "jupyter-") {
+		workspaceName := h.getWorkspaceNameForPod(ctx, event.InvolvedObject.Namespace, event.InvolvedObject.Name)
+		if workspaceName == "" {
 			return nil
 		}
 
-		parts := strings.Split(podName, "-")
-		if len(parts) < 4 {
-			return nil
-		}
-
-		// Remove "jupyter-" prefix and last two hash suffixes
-		workspaceName := strings.Join(parts[1:len(parts)-2], "-")
-
 		logger.Info("Pod was preempted, updating workspace desiredStatus to Stopped",
 			"workspace", workspaceName)
 		h.updateWorkspaceDesiredStatus(ctx, workspaceName, event.InvolvedObject.Namespace, DesiredStateStopped)
@@ -165,9 +154,80 @@ func (h *PodEventHandler) HandleKubernetesEvents(ctx context.Context, obj client
 		}
 	}
 
+	// Reconcile the workspace when one of its pods fails to start so the failure
+	// is surfaced in the Degraded condition without waiting for the next poll
+	if isPodFailureEvent(event) {
+		pod := &corev1.Pod{}
+		if err := h.client.Get(ctx, client.ObjectKey{
+			Name:      event.InvolvedObject.Name,
+			Namespace: event.InvolvedObject.Namespace,
+		}, pod); err != nil {
+			logger.V(1).Info("Pod of failure event not found, skipping", "error", err.Error())
+			return nil
+		}
+		workspaceName, ok := pod.Labels[workspaceutil.LabelWorkspaceName]
+		if !ok {
+			return nil
+		}
+
+		logger.Info("Detected workspace pod failure event",
+			"workspace", workspaceName,
+			"reason", event.Reason,
+			"message", event.Message)
+		return []reconcile.Request{
+			{
+				NamespacedName: client.ObjectKey{
+					Name:      workspaceName,
+					Namespace: event.InvolvedObject.Namespace,
+				},
+			},
+		}
+	}
+
 	return nil
 }
 
+// isPodPreemptionEvent reports whether the event signals that a pod was preempted
+func isPodPreemptionEvent(event *corev1.Event) bool {
+	if event.InvolvedObject.Kind != KindPod {
+		return false
+	}
+	return event.Reason == "Preempted" ||
+		(event.Reason == DesiredStateStopped && strings.Contains(event.Message, "Preempted"))
+}
+
+// getWorkspaceNameForPod returns the name of the workspace owning the pod, empty if it is not a workspace pod.
+// Preempted pods may already be gone, in which case the name is derived from the pod name:
+// workspace-<workspace name>-<replicaset hash>-<pod hash>
+func (h *PodEventHandler) getWorkspaceNameForPod(ctx context.Context, namespace, podName string) string {
+	pod := &corev1.Pod{}
+	if err := h.client.Get(ctx, client.ObjectKey{Name: podName, Namespace: namespace}, pod); err == nil {
+		return pod.Labels[workspaceutil.LabelWorkspaceName]
+	}
+
+	prefix := ResourcePrefix + "-"
+	if !strings.HasPrefix(podName, prefix) {
+		return ""
+	}
+	parts := strings.Split(strings.TrimPrefix(podName, prefix), "-")
+	if len(parts) < 3 {
+		return ""
+	}
+	return strings.Join(parts[:len(parts)-2], "-")
+}
+
+// isPodFailureEvent reports whether the event signals that a pod is failing to schedule or start
+func isPodFailureEvent(event *corev1.Event) bool {
+	if event.InvolvedObject.Kind != KindPod || event.Type != corev1.EventTypeWarning {
+		return false
+	}
+	switch event.Reason {
+	case PodEventReasonFailedScheduling, PodEventReasonFailed, PodEventReasonBackOff:
+		return true
+	}
+	return false
+}
+
 // handlePodRunning handles when a workspace pod enters running state
 func (h *PodEventHandler) handlePodRunning(ctx context.Context, pod *corev1.Pod, workspaceName string) {
 	logger := logf.FromContext(ctx).WithValues("pod", pod.Name, "workspace", workspaceName)
@@ -274,12 +334,18 @@ func (h *PodEventHandler) updateWorkspaceDesiredStatus(ctx context.Context, work
 		return
 	}
 
-	// Add annotation to track preemption reason
+	// Add annotations to track preemption reason and time
 	if desiredStatus == DesiredStateStopped {
+		// A preemption raises several events, and the pod of a stopping workspace is not preempted
+		if workspace.Spec.DesiredStatus == DesiredStateStopped {
+			logger.V(1).Info("Workspace is already stopped, ignoring preemption event")
+			return
+		}
 		if workspace.Annotations == nil {
 			workspace.Annotations = make(map[string]string)
 		}
 		workspace.Annotations[PreemptionReasonAnnotation] = PreemptedReason
+		workspace.Annotations[PreemptedAtAnnotation] = time.Now().UTC().Format(time.RFC3339)
 	}
 
 	if workspace.Spec.DesiredStatus != desiredStatus {
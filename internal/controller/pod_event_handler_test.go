@@ -13,6 +13,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
@@ -514,3 +515,124 @@ func TestWorkspaceNameExtraction(t *testing.T) {
 		})
 	}
 }
+
+func TestHandleKubernetesEvents_PodFailure(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "workspace-test-workspace-abc123-xyz789",
+			Namespace: "test-ns",
+			Labels:    map[string]string{workspaceutil.LabelWorkspaceName: "test-workspace"},
+		},
+	}
+	otherPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "unrelated-pod", Namespace: "test-ns"},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pod, otherPod).Build()
+	handler := &PodEventHandler{client: fakeClient}
+
+	tests := []struct {
+		name      string
+		podName   string
+		eventType string
+		reason    string
+		expected  bool
+	}{
+		{name: "failed scheduling", podName: pod.Name, eventType: corev1.EventTypeWarning, reason: PodEventReasonFailedScheduling, expected: true},
+		{name: "image pull failure", podName: pod.Name, eventType: corev1.EventTypeWarning, reason: PodEventReasonFailed, expected: true},
+		{name: "back-off", podName: pod.Name, eventType: corev1.EventTypeWarning, reason: PodEventReasonBackOff, expected: true},
+		{name: "normal event", podName: pod.Name, eventType: corev1.EventTypeNormal, reason: "Pulled", expected: false},
+		{name: "pod without workspace label", podName: otherPod.Name, eventType: corev1.EventTypeWarning, reason: PodEventReasonBackOff, expected: false},
+		{name: "pod not found", podName: "missing-pod", eventType: corev1.EventTypeWarning, reason: PodEventReasonBackOff, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := &corev1.Event{
+				ObjectMeta: metav1.ObjectMeta{Name: "event", Namespace: "test-ns"},
+				InvolvedObject: corev1.ObjectReference{
+					Kind:      KindPod,
+					Name:      tt.podName,
+					Namespace: "test-ns",
+				},
+				Type:   tt.eventType,
+				Reason: tt.reason,
+			}
+
+			requests := handler.HandleKubernetesEvents(context.Background(), event)
+
+			if !tt.expected {
+				if len(requests) != 0 {
+					t.Errorf("Expected no reconcile request, got %v", requests)
+				}
+				return
+			}
+			if len(requests) != 1 || requests[0].Name != "test-workspace" || requests[0].Namespace != "test-ns" {
+				t.Errorf("Expected a reconcile request for test-ns/test-workspace, got %v", requests)
+			}
+		})
+	}
+}
+
+func TestHandleKubernetesEvents_Preemption(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = workspacev1alpha1.AddToScheme(scheme)
+
+	tests := []struct {
+		name          string
+		podName       string
+		podExists     bool
+		desiredStatus string
+		expectStamped bool
+	}{
+		{name: "pod with workspace label", podName: "workspace-my-ws-7d4b8c9f6d-x8k2m", podExists: true, desiredStatus: DesiredStateRunning, expectStamped: true},
+		{name: "deleted pod resolved from its name", podName: "workspace-my-ws-7d4b8c9f6d-x8k2m", desiredStatus: DesiredStateRunning, expectStamped: true},
+		{name: "already stopped workspace", podName: "workspace-my-ws-7d4b8c9f6d-x8k2m", podExists: true, desiredStatus: DesiredStateStopped},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workspace := &workspacev1alpha1.Workspace{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-ws", Namespace: "test-ns"},
+				Spec:       workspacev1alpha1.WorkspaceSpec{DesiredStatus: tt.desiredStatus},
+			}
+			builder := fake.NewClientBuilder().WithScheme(scheme).WithObjects(workspace)
+			if tt.podExists {
+				builder = builder.WithObjects(&corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      tt.podName,
+						Namespace: "test-ns",
+						Labels:    map[string]string{workspaceutil.LabelWorkspaceName: "my-ws"},
+					},
+				})
+			}
+			fakeClient := builder.Build()
+			handler := &PodEventHandler{client: fakeClient}
+
+			event := &corev1.Event{
+				InvolvedObject: corev1.ObjectReference{Kind: KindPod, Name: tt.podName, Namespace: "test-ns"},
+				Reason:         "Preempted",
+				Message:        "Preempted in order to admit critical pod",
+			}
+			requests := handler.HandleKubernetesEvents(context.Background(), event)
+			if len(requests) != 1 || requests[0].Name != "my-ws" {
+				t.Fatalf("Expected a reconcile request for test-ns/my-ws, got %v", requests)
+			}
+
+			updated := &workspacev1alpha1.Workspace{}
+			if err := fakeClient.Get(context.Background(), client.ObjectKeyFromObject(workspace), updated); err != nil {
+				t.Fatalf("Failed to get workspace: %v", err)
+			}
+			_, stamped := updated.Annotations[PreemptedAtAnnotation]
+			if stamped != tt.expectStamped {
+				t.Errorf("Expected preempted-at annotation set to be %v, got %v", tt.expectStamped, stamped)
+			}
+			if updated.Spec.DesiredStatus != DesiredStateStopped {
+				t.Errorf("Expected desired status Stopped, got %s", updated.Spec.DesiredStatus)
+			}
+		})
+	}
+}
@@ -9,12 +9,14 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"time"
 
 	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
@@ -62,6 +64,31 @@ func resolveMountPath(workspace *workspacev1alpha1.Workspace) string {
 	return DefaultMountPath
 }
 
+// GetPVCName returns the name of the workspace PVC, which is the adopted PVC when the storage references one
+func GetPVCName(workspace *workspacev1alpha1.Workspace) string {
+	if workspace.Spec.Storage != nil && workspace.Spec.Storage.ExistingClaimName != "" {
+		return workspace.Spec.Storage.ExistingClaimName
+	}
+	return GeneratePVCName(workspace.Name)
+}
+
+// IsEphemeralStorage checks if the workspace storage is an ephemeral volume deleted with the pod,
+// in which case no PVC is created for it
+func IsEphemeralStorage(workspace *workspacev1alpha1.Workspace) bool {
+	if workspace.Spec.Storage == nil {
+		return false
+	}
+	return GetStorageType(workspace) != workspacev1alpha1.StorageTypePersistent
+}
+
+// GetStorageType returns the type of the workspace storage, Persistent when unset
+func GetStorageType(workspace *workspacev1alpha1.Workspace) workspacev1alpha1.StorageType {
+	if workspace.Spec.Storage == nil || workspace.Spec.Storage.Type == "" {
+		return workspacev1alpha1.StorageTypePersistent
+	}
+	return workspace.Spec.Storage.Type
+}
+
 // ResolveStorageConfig determines storage configuration from workspace
 // Returns nil if no storage is requested
 func ResolveStorageConfig(workspace *workspacev1alpha1.Workspace) *ResolvedStorageConfig {
@@ -90,6 +117,21 @@ func (pb *PVCBuilder) BuildPVC(workspace *workspacev1alpha1.Workspace) (*corev1.
 		Spec:       pb.buildPVCSpecWithSize(storageConfig.Size, storageConfig.StorageClassName),
 	}
 
+	// Clone the primary PVC of the source workspace, or restore from an existing VolumeSnapshot if requested
+	if workspace.Spec.CloneFrom != nil {
+		pvc.Spec.DataSource = &corev1.TypedLocalObjectReference{
+			Kind: "PersistentVolumeClaim",
+			Name: GeneratePVCName(workspace.Spec.CloneFrom.Name),
+		}
+	} else if workspace.Spec.Storage.RestoreFromSnapshot != "" {
+		apiGroup := VolumeSnapshotAPIGroup
+		pvc.Spec.DataSource = &corev1.TypedLocalObjectReference{
+			APIGroup: &apiGroup,
+			Kind:     KindVolumeSnapshot,
+			Name:     workspace.Spec.Storage.RestoreFromSnapshot,
+		}
+	}
+
 	// Set owner reference for garbage collection
 	if err := controllerutil.SetControllerReference(workspace, pvc, pb.scheme); err != nil {
 		return nil, fmt.Errorf("failed to set controller reference: %w", err)
@@ -98,10 +140,66 @@ func (pb *PVCBuilder) BuildPVC(workspace *workspacev1alpha1.Workspace) (*corev1.
 	return pvc, nil
 }
 
+// BuildSecondaryPVC creates the PersistentVolumeClaim of a secondary storage of the given Workspace
+func (pb *PVCBuilder) BuildSecondaryPVC(
+	workspace *workspacev1alpha1.Workspace,
+	storage *workspacev1alpha1.SecondaryStorageSpec) (*corev1.PersistentVolumeClaim, error) {
+	labels := GenerateLabels(workspace.Name)
+	labels[LabelComponent] = ComponentSecondaryStorage
+	labels[LabelSecondaryStorageName] = storage.Name
+
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      GenerateSecondaryPVCName(workspace.Name, storage.Name),
+			Namespace: workspace.Namespace,
+			Labels:    labels,
+		},
+		Spec: pb.buildPVCSpecWithSize(storage.Size, storage.StorageClassName),
+	}
+
+	// Set owner reference for garbage collection
+	if err := controllerutil.SetControllerReference(workspace, pvc, pb.scheme); err != nil {
+		return nil, fmt.Errorf("failed to set controller reference: %w", err)
+	}
+
+	return pvc, nil
+}
+
+// BuildVolumeSnapshot creates a VolumeSnapshot of the workspace PVC taken at the given time
+// Snapshots are intentionally not owned by the Workspace so that they outlive it
+func (pb *PVCBuilder) BuildVolumeSnapshot(workspace *workspacev1alpha1.Workspace, now time.Time) *unstructured.Unstructured {
+	snapshot := &unstructured.Unstructured{}
+	snapshot.SetAPIVersion(VolumeSnapshotAPIGroup + "/" + VolumeSnapshotAPIVersion)
+	snapshot.SetKind(KindVolumeSnapshot)
+	snapshot.SetName(GenerateSnapshotName(workspace.Name, now))
+	snapshot.SetNamespace(workspace.Namespace)
+
+	labels := GenerateLabels(workspace.Name)
+	labels[LabelComponent] = ComponentSnapshot
+	snapshot.SetLabels(labels)
+
+	// Carry the workspace owner so that restores can be restricted to the same user
+	if createdBy := workspace.Annotations[AnnotationCreatedBy]; createdBy != "" {
+		snapshot.SetAnnotations(map[string]string{AnnotationCreatedBy: createdBy})
+	}
+
+	spec := map[string]interface{}{
+		"source": map[string]interface{}{
+			"persistentVolumeClaimName": GetPVCName(workspace),
+		},
+	}
+	if policy := workspace.Spec.Storage.Snapshot; policy != nil && policy.VolumeSnapshotClassName != nil {
+		spec["volumeSnapshotClassName"] = *policy.VolumeSnapshotClassName
+	}
+	snapshot.Object["spec"] = spec
+
+	return snapshot
+}
+
 // buildObjectMeta creates the metadata for the PVC
 func (pb *PVCBuilder) buildObjectMeta(workspace *workspacev1alpha1.Workspace) metav1.ObjectMeta {
 	return metav1.ObjectMeta{
-		Name:      GeneratePVCName(workspace.Name),
+		Name:      GetPVCName(workspace),
 		Namespace: workspace.Namespace,
 		Labels:    GenerateLabels(workspace.Name),
 	}
@@ -151,7 +249,7 @@ func (pb *PVCBuilder) NeedsUpdate(ctx context.Context, existingPVC *corev1.Persi
 	// 2. Check Storage Size (can be increased but not decreased for bound claims)
 	existingStorage := existingPVC.Spec.Resources.Requests[corev1.ResourceStorage]
 	desiredStorage := desiredPVC.Spec.Resources.Requests[corev1.ResourceStorage]
-	if !existingStorage.Equal(desiredStorage) {
+	if desiredStorage.Cmp(existingStorage) > 0 {
 		return true, nil
 	}
 
@@ -8,11 +8,13 @@ package controller
 import (
 	"context"
 	"testing"
+	"time"
 
 	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
 )
@@ -227,4 +229,111 @@ func TestPVCBuilder_UpdateDetection(t *testing.T) {
 	if !needsUpdate {
 		t.Error("Expected update needed")
 	}
+
+	// PVCs cannot be shrunk
+	workspace.Spec.Storage.Size = resource.MustParse("5Gi")
+	needsUpdate, err = builder.NeedsUpdate(ctx, existingPVC, workspace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if needsUpdate {
+		t.Error("Expected no update needed when shrinking")
+	}
+}
+
+func TestPVCBuilder_RestoreFromSnapshot(t *testing.T) {
+	builder := setupPVCBuilder()
+	workspace := &workspacev1alpha1.Workspace{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-workspace", Namespace: "default"},
+		Spec: workspacev1alpha1.WorkspaceSpec{
+			Storage: &workspacev1alpha1.StorageSpec{
+				Size:                resource.MustParse("5Gi"),
+				RestoreFromSnapshot: "workspace-old-snap-20250101000000",
+			},
+		},
+	}
+
+	pvc, err := builder.BuildPVC(workspace)
+	if err != nil {
+		t.Fatalf("BuildPVC failed: %v", err)
+	}
+	if pvc.Spec.DataSource == nil {
+		t.Fatal("Expected PVC dataSource to be set")
+	}
+	if pvc.Spec.DataSource.Kind != KindVolumeSnapshot || *pvc.Spec.DataSource.APIGroup != VolumeSnapshotAPIGroup {
+		t.Errorf("Expected VolumeSnapshot dataSource, got %+v", pvc.Spec.DataSource)
+	}
+	if pvc.Spec.DataSource.Name != "workspace-old-snap-20250101000000" {
+		t.Errorf("Expected dataSource name 'workspace-old-snap-20250101000000', got %s", pvc.Spec.DataSource.Name)
+	}
+}
+
+func TestPVCBuilder_BuildVolumeSnapshot(t *testing.T) {
+	builder := setupPVCBuilder()
+	className := "csi-snapclass"
+	workspace := &workspacev1alpha1.Workspace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test-workspace",
+			Namespace:   "default",
+			Annotations: map[string]string{AnnotationCreatedBy: "alice"},
+		},
+		Spec: workspacev1alpha1.WorkspaceSpec{
+			Storage: &workspacev1alpha1.StorageSpec{
+				Snapshot: &workspacev1alpha1.SnapshotPolicy{OnStop: true, VolumeSnapshotClassName: &className},
+			},
+		},
+	}
+
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	snapshot := builder.BuildVolumeSnapshot(workspace, now)
+
+	if snapshot.GetName() != "workspace-test-workspace-snap-20250102030405" {
+		t.Errorf("Unexpected snapshot name %s", snapshot.GetName())
+	}
+	if snapshot.GetKind() != KindVolumeSnapshot {
+		t.Errorf("Expected kind %s, got %s", KindVolumeSnapshot, snapshot.GetKind())
+	}
+	if snapshot.GetLabels()[LabelComponent] != ComponentSnapshot {
+		t.Errorf("Expected component label %s, got %s", ComponentSnapshot, snapshot.GetLabels()[LabelComponent])
+	}
+	if snapshot.GetAnnotations()[AnnotationCreatedBy] != "alice" {
+		t.Errorf("Expected created-by annotation to be carried over")
+	}
+	if len(snapshot.GetOwnerReferences()) != 0 {
+		t.Errorf("Expected snapshot to have no owner references")
+	}
+
+	pvcName, _, _ := unstructured.NestedString(snapshot.Object, "spec", "source", "persistentVolumeClaimName")
+	if pvcName != GeneratePVCName(workspace.Name) {
+		t.Errorf("Expected source PVC %s, got %s", GeneratePVCName(workspace.Name), pvcName)
+	}
+	snapshotClass, _, _ := unstructured.NestedString(snapshot.Object, "spec", "volumeSnapshotClassName")
+	if snapshotClass != className {
+		t.Errorf("Expected snapshot class %s, got %s", className, snapshotClass)
+	}
+}
+
+func TestPVCBuilder_CloneFrom(t *testing.T) {
+	builder := setupPVCBuilder()
+	workspace := &workspacev1alpha1.Workspace{
+		ObjectMeta: metav1.ObjectMeta{Name: "student-1", Namespace: "default"},
+		Spec: workspacev1alpha1.WorkspaceSpec{
+			Storage:   &workspacev1alpha1.StorageSpec{Size: resource.MustParse("5Gi")},
+			CloneFrom: &workspacev1alpha1.CloneSource{Name: "instructor"},
+		},
+	}
+
+	pvc, err := builder.BuildPVC(workspace)
+	if err != nil {
+		t.Fatalf("BuildPVC failed: %v", err)
+	}
+	if pvc.Spec.DataSource == nil {
+		t.Fatal("Expected PVC dataSource to be set")
+	}
+	if pvc.Spec.DataSource.Kind != "PersistentVolumeClaim" || pvc.Spec.DataSource.APIGroup != nil {
+		t.Errorf("Expected PersistentVolumeClaim dataSource, got %+v", pvc.Spec.DataSource)
+	}
+	if pvc.Spec.DataSource.Name != GeneratePVCName("instructor") {
+		t.Errorf("Expected dataSource name %s, got %s", GeneratePVCName("instructor"), pvc.Spec.DataSource.Name)
+	}
 }
@@ -8,15 +8,20 @@ package controller
 import (
 	"context"
 	"fmt"
+	"time"
 
 	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	workspaceutil "github.com/jupyter-infra/jupyter-k8s/internal/workspace"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
@@ -81,7 +86,7 @@ func (rm *ResourceManager) getService(ctx context.Context, workspace *workspacev
 // getPVC retrieves the PVC for a Workspace
 func (rm *ResourceManager) getPVC(ctx context.Context, workspace *workspacev1alpha1.Workspace) (*corev1.PersistentVolumeClaim, error) {
 	pvc := &corev1.PersistentVolumeClaim{}
-	pvcName := GeneratePVCName(workspace.Name)
+	pvcName := GetPVCName(workspace)
 
 	err := rm.client.Get(ctx, types.NamespacedName{
 		Name:      pvcName,
@@ -356,6 +361,11 @@ func (rm *ResourceManager) EnsurePVCDeleted(ctx context.Context, workspace *work
 		return nil, fmt.Errorf("failed to get PVC: %w", err)
 	}
 
+	// PVCs released by the workspace, e.g. retained by its retention or lifecycle policy, are kept
+	if !metav1.IsControlledBy(pvc, workspace) {
+		return nil, nil
+	}
+
 	if pvc != nil && pvc.DeletionTimestamp.IsZero() {
 		logger := logf.FromContext(ctx)
 		logger.Info("Deleting PVC", "pvc", pvc.Name, "namespace", pvc.Namespace)
@@ -365,6 +375,133 @@ func (rm *ResourceManager) EnsurePVCDeleted(ctx context.Context, workspace *work
 	return pvc, nil
 }
 
+// RetainPVC releases the PVC from the workspace so that it outlives it. The PVC is relabeled as retained
+// storage so that a new workspace can adopt it, and is deleted after retainUntil when it is set.
+func (rm *ResourceManager) RetainPVC(ctx context.Context, workspace *workspacev1alpha1.Workspace, retainUntil *time.Time) error {
+	pvc, err := rm.getPVC(ctx, workspace)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get PVC: %w", err)
+	}
+	return rm.retainPVC(ctx, pvc, workspace, retainUntil)
+}
+
+// retainPVC releases a PVC owned by the workspace and relabels it as retained storage
+func (rm *ResourceManager) retainPVC(
+	ctx context.Context,
+	pvc *corev1.PersistentVolumeClaim,
+	workspace *workspacev1alpha1.Workspace,
+	retainUntil *time.Time) error {
+	if !metav1.IsControlledBy(pvc, workspace) {
+		return nil
+	}
+
+	ownerReferences := []metav1.OwnerReference{}
+	for _, ref := range pvc.OwnerReferences {
+		if ref.UID != workspace.UID {
+			ownerReferences = append(ownerReferences, ref)
+		}
+	}
+	pvc.OwnerReferences = ownerReferences
+
+	// Retained PVCs no longer match the selectors of the workspace resources
+	if pvc.Labels == nil {
+		pvc.Labels = map[string]string{}
+	}
+	delete(pvc.Labels, workspaceutil.LabelWorkspaceName)
+	pvc.Labels[LabelComponent] = ComponentRetainedStorage
+
+	// Carry the workspace owner so that adoption can be restricted to the same user
+	if pvc.Annotations == nil {
+		pvc.Annotations = map[string]string{}
+	}
+	pvc.Annotations[AnnotationRetainedFrom] = workspace.Name
+	if createdBy := workspace.Annotations[AnnotationCreatedBy]; createdBy != "" {
+		pvc.Annotations[AnnotationCreatedBy] = createdBy
+	}
+	if retainUntil != nil {
+		pvc.Annotations[AnnotationRetainUntil] = retainUntil.UTC().Format(time.RFC3339)
+	}
+
+	logf.FromContext(ctx).Info("Retaining PVC of workspace",
+		"pvc", pvc.Name,
+		"namespace", pvc.Namespace,
+		"retainUntil", retainUntil)
+	if err := rm.client.Update(ctx, pvc); err != nil {
+		return fmt.Errorf("failed to retain PVC: %w", err)
+	}
+	return nil
+}
+
+// getPVCRetention returns whether the retention policy of the workspace storage keeps its PVCs
+// when the workspace is deleted, and until when. Workspaces deleted by a lifecycle policy retaining
+// storage keep their PVCs whatever the retention policy.
+func getPVCRetention(workspace *workspacev1alpha1.Workspace) (bool, *time.Time) {
+	if policy := workspace.Spec.LifecyclePolicy; policy != nil && policy.RetainStorage &&
+		workspace.Annotations[AnnotationLifecycleDeletion] != "" {
+		return true, nil
+	}
+	if workspace.Spec.Storage == nil || workspace.Spec.Storage.PersistentVolumeClaimRetentionPolicy == nil {
+		return false, nil
+	}
+
+	policy := workspace.Spec.Storage.PersistentVolumeClaimRetentionPolicy
+	switch policy.WhenDeleted {
+	case workspacev1alpha1.PersistentVolumeClaimRetentionPolicyRetain:
+		return true, nil
+	case workspacev1alpha1.PersistentVolumeClaimRetentionPolicyRetainFor:
+		var retainFor time.Duration
+		if policy.RetainDuration != nil {
+			retainFor = policy.RetainDuration.Duration
+		}
+		retainUntil := time.Now().Add(retainFor)
+		return true, &retainUntil
+	default:
+		return false, nil
+	}
+}
+
+// applyPVCRetentionPolicy retains the workspace PVC when the retention policy of its storage asks for it
+func (rm *ResourceManager) applyPVCRetentionPolicy(ctx context.Context, workspace *workspacev1alpha1.Workspace) error {
+	retain, retainUntil := getPVCRetention(workspace)
+	if !retain {
+		return nil
+	}
+	return rm.RetainPVC(ctx, workspace, retainUntil)
+}
+
+// adoptPVC takes ownership of a PVC retained from a deleted workspace and restores the given workspace labels
+func (rm *ResourceManager) adoptPVC(
+	ctx context.Context,
+	pvc *corev1.PersistentVolumeClaim,
+	workspace *workspacev1alpha1.Workspace,
+	labels map[string]string) (*corev1.PersistentVolumeClaim, error) {
+	if err := controllerutil.SetControllerReference(workspace, pvc, rm.scheme); err != nil {
+		return nil, fmt.Errorf("failed to set controller reference: %w", err)
+	}
+	if pvc.Labels == nil {
+		pvc.Labels = map[string]string{}
+	}
+	for key, value := range labels {
+		pvc.Labels[key] = value
+	}
+	delete(pvc.Annotations, AnnotationRetainedFrom)
+	delete(pvc.Annotations, AnnotationRetainUntil)
+
+	logf.FromContext(ctx).Info("Adopting retained PVC", "pvc", pvc.Name, "namespace", pvc.Namespace)
+	if err := rm.client.Update(ctx, pvc); err != nil {
+		return nil, fmt.Errorf("failed to adopt PVC: %w", err)
+	}
+	return pvc, nil
+}
+
+// IsRetainedPVC checks if the PVC was retained from a deleted workspace
+func IsRetainedPVC(pvc *corev1.PersistentVolumeClaim) bool {
+	return pvc.Labels[LabelComponent] == ComponentRetainedStorage
+}
+
 // EnsurePVCExists creates a PVC if it doesn't exist, or updates it if the spec differs
 // It uses workspace storage if specified
 func (rm *ResourceManager) EnsurePVCExists(ctx context.Context, workspace *workspacev1alpha1.Workspace) (*corev1.PersistentVolumeClaim, error) {
@@ -374,15 +511,34 @@ func (rm *ResourceManager) EnsurePVCExists(ctx context.Context, workspace *works
 	if !hasStorage {
 		return nil, nil // No storage requested
 	}
+	if IsEphemeralStorage(workspace) {
+		return nil, nil // Ephemeral storage is part of the pod spec
+	}
 
+	existingClaimName := workspace.Spec.Storage.ExistingClaimName
 	pvc, err := rm.getPVC(ctx, workspace)
 	if err != nil {
 		if errors.IsNotFound(err) {
+			if existingClaimName != "" {
+				return nil, fmt.Errorf("PVC %s referenced by spec.storage.existingClaimName not found", existingClaimName)
+			}
 			return rm.createPVC(ctx, workspace)
 		}
 		return nil, fmt.Errorf("failed to get PVC: %w", err)
 	}
 
+	// Retained PVCs are only reused when the workspace adopts them explicitly
+	if IsRetainedPVC(pvc) {
+		if existingClaimName != pvc.Name {
+			return nil, fmt.Errorf("PVC %s is retained from deleted workspace %s, "+
+				"set spec.storage.existingClaimName to adopt it", pvc.Name, pvc.Annotations[AnnotationRetainedFrom])
+		}
+		return rm.adoptPVC(ctx, pvc, workspace, GenerateLabels(workspace.Name))
+	}
+	if existingClaimName != "" && !metav1.IsControlledBy(pvc, workspace) {
+		return nil, fmt.Errorf("PVC %s referenced by spec.storage.existingClaimName is not retained storage", existingClaimName)
+	}
+
 	return rm.ensurePVCUpToDate(ctx, pvc, workspace)
 }
 
@@ -399,6 +555,17 @@ func (rm *ResourceManager) ensurePVCUpToDate(ctx context.Context, pvc *corev1.Pe
 	}
 
 	if needsUpdate {
+		// The API server rejects expansions the storage class does not allow, check it up front
+		allowed, err := rm.IsVolumeExpansionAllowed(ctx, pvc)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			logf.FromContext(ctx).Info("Storage class does not allow volume expansion, PVC not resized",
+				"pvc", pvc.Name,
+				"namespace", pvc.Namespace)
+			return pvc, nil
+		}
 		return rm.updatePVC(ctx, pvc, workspace)
 	}
 
@@ -425,6 +592,23 @@ func (rm *ResourceManager) updatePVC(ctx context.Context, pvc *corev1.Persistent
 	return pvc, nil
 }
 
+// IsVolumeExpansionAllowed checks if the storage class of the PVC allows volume expansion
+func (rm *ResourceManager) IsVolumeExpansionAllowed(ctx context.Context, pvc *corev1.PersistentVolumeClaim) (bool, error) {
+	// A PVC without storage class is statically bound and cannot be expanded
+	if pvc.Spec.StorageClassName == nil || *pvc.Spec.StorageClassName == "" {
+		return false, nil
+	}
+
+	storageClass := &storagev1.StorageClass{}
+	if err := rm.client.Get(ctx, types.NamespacedName{Name: *pvc.Spec.StorageClassName}, storageClass); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get storage class: %w", err)
+	}
+	return storageClass.AllowVolumeExpansion != nil && *storageClass.AllowVolumeExpansion, nil
+}
+
 // CleanupAllResources performs comprehensive cleanup of all workspace resources
 func (rm *ResourceManager) CleanupAllResources(ctx context.Context, workspace *workspacev1alpha1.Workspace) (bool, error) {
 	logger := logf.FromContext(ctx)
@@ -436,8 +620,8 @@ func (rm *ResourceManager) CleanupAllResources(ctx context.Context, workspace *w
 		// Continue with other deletions, don't block on access strategy
 	}
 
-	// Delete deployment
-	_, err := rm.EnsureDeploymentDeleted(ctx, workspace)
+	// Delete deployment, statefulset or pod
+	_, err := rm.EnsureComputeDeleted(ctx, workspace)
 	if err != nil {
 		return false, err
 	}
@@ -448,11 +632,17 @@ func (rm *ResourceManager) CleanupAllResources(ctx context.Context, workspace *w
 		return false, err
 	}
 
-	// Delete PVC
+	// Retain the PVC if its retention policy asks for it, otherwise delete it
+	if err := rm.applyPVCRetentionPolicy(ctx, workspace); err != nil {
+		return false, err
+	}
 	_, err = rm.EnsurePVCDeleted(ctx, workspace)
 	if err != nil {
 		return false, err
 	}
+	if err := rm.EnsureSecondaryPVCsDeleted(ctx, workspace); err != nil {
+		return false, err
+	}
 
 	// Check if all resources are fully deleted using helper function
 	if rm.AreAllResourcesDeleted(ctx, workspace) {
@@ -466,22 +656,29 @@ func (rm *ResourceManager) CleanupAllResources(ctx context.Context, workspace *w
 
 // AreAllResourcesDeleted checks if all workspace resources are fully removed (not found)
 func (rm *ResourceManager) AreAllResourcesDeleted(ctx context.Context, workspace *workspacev1alpha1.Workspace) bool {
-	// Check deployment - must be NotFound (fully deleted)
-	_, err := rm.getDeployment(ctx, workspace)
-	if err == nil || !errors.IsNotFound(err) {
+	// Check deployment, statefulset and pod - must be NotFound (fully deleted)
+	if !rm.areComputeResourcesDeleted(ctx, workspace) {
 		return false // Still exists or other error
 	}
 
 	// Check service - must be NotFound (fully deleted)
-	_, err = rm.getService(ctx, workspace)
+	_, err := rm.getService(ctx, workspace)
 	if err == nil || !errors.IsNotFound(err) {
 		return false // Still exists or other error
 	}
 
-	// Check PVC - must be NotFound (fully deleted)
-	_, err = rm.getPVC(ctx, workspace)
-	if err == nil || !errors.IsNotFound(err) {
-		return false // Still exists or other error
+	// Check PVC - must be NotFound (fully deleted) or released by the workspace
+	pvc, err := rm.getPVC(ctx, workspace)
+	if err == nil && metav1.IsControlledBy(pvc, workspace) {
+		return false // Still exists
+	}
+	if err != nil && !errors.IsNotFound(err) {
+		return false // Other error
+	}
+
+	// Check the PVCs of secondary storages are deleted
+	if !rm.areSecondaryPVCsDeleted(ctx, workspace) {
+		return false
 	}
 
 	// Check access resources are deleted
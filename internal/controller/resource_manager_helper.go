@@ -93,3 +93,36 @@ func (rm *ResourceManager) IsPVCMissingOrDeleting(pvc *corev1.PersistentVolumeCl
 	// Check if the PVC has a deletion timestamp (is being deleted)
 	return !pvc.DeletionTimestamp.IsZero()
 }
+
+// IsStatefulSetAvailable checks if the StatefulSet runs all its replicas and they are ready
+func (rm *ResourceManager) IsStatefulSetAvailable(statefulSet *appsv1.StatefulSet) bool {
+	// If statefulset is nil, it's not available
+	if statefulSet == nil {
+		return false
+	}
+
+	replicas := int32(1)
+	if statefulSet.Spec.Replicas != nil {
+		replicas = *statefulSet.Spec.Replicas
+	}
+	return statefulSet.Status.ReadyReplicas > 0 &&
+		statefulSet.Status.ReadyReplicas >= replicas
+}
+
+// IsPodAvailable checks if a bare workspace Pod is running and ready
+func (rm *ResourceManager) IsPodAvailable(pod *corev1.Pod) bool {
+	// If pod is nil or being deleted, it's not available
+	if pod == nil || !pod.DeletionTimestamp.IsZero() {
+		return false
+	}
+
+	if pod.Status.Phase != corev1.PodRunning {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
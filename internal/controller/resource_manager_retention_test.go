@@ -0,0 +1,170 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	workspaceutil "github.com/jupyter-infra/jupyter-k8s/internal/workspace"
+)
+
+// retentionTestWorkspace returns a workspace with storage and the given PVC retention policy
+func retentionTestWorkspace(policy *workspacev1alpha1.PersistentVolumeClaimRetentionPolicy) *workspacev1alpha1.Workspace {
+	workspace := computeTestWorkspace(workspacev1alpha1.ComputeModeDeployment)
+	workspace.Annotations = map[string]string{AnnotationCreatedBy: "alice"}
+	workspace.Spec.Storage = &workspacev1alpha1.StorageSpec{
+		Size:                                 resource.MustParse("10Gi"),
+		PersistentVolumeClaimRetentionPolicy: policy,
+	}
+	return workspace
+}
+
+// retentionTestPVC returns the PVC of the workspace, controlled by it
+func retentionTestPVC(t *testing.T, rm *ResourceManager, workspace *workspacev1alpha1.Workspace) *corev1.PersistentVolumeClaim {
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      GeneratePVCName(workspace.Name),
+			Namespace: workspace.Namespace,
+			Labels:    GenerateLabels(workspace.Name),
+		},
+	}
+	require.NoError(t, controllerutil.SetControllerReference(workspace, pvc, rm.scheme))
+	return pvc
+}
+
+func TestApplyPVCRetentionPolicy_RetainFor(t *testing.T) {
+	workspace := retentionTestWorkspace(&workspacev1alpha1.PersistentVolumeClaimRetentionPolicy{
+		WhenDeleted:    workspacev1alpha1.PersistentVolumeClaimRetentionPolicyRetainFor,
+		RetainDuration: &metav1.Duration{Duration: 24 * time.Hour},
+	})
+	rm, k8sClient := setupComputeResourceManager(t)
+	require.NoError(t, k8sClient.Create(context.Background(), retentionTestPVC(t, rm, workspace)))
+
+	require.NoError(t, rm.applyPVCRetentionPolicy(context.Background(), workspace))
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	require.NoError(t, k8sClient.Get(context.Background(),
+		client.ObjectKey{Name: GeneratePVCName(workspace.Name), Namespace: "default"}, pvc))
+	assert.Empty(t, pvc.OwnerReferences)
+	assert.True(t, IsRetainedPVC(pvc))
+	assert.NotContains(t, pvc.Labels, workspaceutil.LabelWorkspaceName)
+	assert.Equal(t, testWorkspaceName, pvc.Annotations[AnnotationRetainedFrom])
+	assert.Equal(t, "alice", pvc.Annotations[AnnotationCreatedBy])
+	retainUntil, err := time.Parse(time.RFC3339, pvc.Annotations[AnnotationRetainUntil])
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(24*time.Hour), retainUntil, time.Minute)
+}
+
+func TestApplyPVCRetentionPolicy_Delete(t *testing.T) {
+	workspace := retentionTestWorkspace(&workspacev1alpha1.PersistentVolumeClaimRetentionPolicy{
+		WhenDeleted: workspacev1alpha1.PersistentVolumeClaimRetentionPolicyDelete,
+	})
+	rm, k8sClient := setupComputeResourceManager(t)
+	require.NoError(t, k8sClient.Create(context.Background(), retentionTestPVC(t, rm, workspace)))
+
+	require.NoError(t, rm.applyPVCRetentionPolicy(context.Background(), workspace))
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	require.NoError(t, k8sClient.Get(context.Background(),
+		client.ObjectKey{Name: GeneratePVCName(workspace.Name), Namespace: "default"}, pvc))
+	assert.False(t, IsRetainedPVC(pvc))
+	assert.True(t, metav1.IsControlledBy(pvc, workspace))
+}
+
+func TestEnsurePVCExists_AdoptsRetainedPVC(t *testing.T) {
+	previous := retentionTestWorkspace(&workspacev1alpha1.PersistentVolumeClaimRetentionPolicy{
+		WhenDeleted: workspacev1alpha1.PersistentVolumeClaimRetentionPolicyRetain,
+	})
+	rm, k8sClient := setupComputeResourceManager(t)
+	require.NoError(t, k8sClient.Create(context.Background(), retentionTestPVC(t, rm, previous)))
+	require.NoError(t, rm.RetainPVC(context.Background(), previous, nil))
+
+	workspace := retentionTestWorkspace(nil)
+	workspace.Name = "adopting-workspace"
+	workspace.UID = "adopting-uid"
+	workspace.Spec.Storage.ExistingClaimName = GeneratePVCName(previous.Name)
+
+	pvc, err := rm.EnsurePVCExists(context.Background(), workspace)
+
+	require.NoError(t, err)
+	assert.True(t, metav1.IsControlledBy(pvc, workspace))
+	assert.False(t, IsRetainedPVC(pvc))
+	assert.Equal(t, "adopting-workspace", pvc.Labels[workspaceutil.LabelWorkspaceName])
+	assert.NotContains(t, pvc.Annotations, AnnotationRetainedFrom)
+	// the adopted PVC is mounted in place of the generated one
+	assert.Equal(t, pvc.Name, GetPVCName(workspace))
+}
+
+func TestEnsurePVCExists_RetainedPVCRequiresExistingClaim(t *testing.T) {
+	workspace := retentionTestWorkspace(&workspacev1alpha1.PersistentVolumeClaimRetentionPolicy{
+		WhenDeleted: workspacev1alpha1.PersistentVolumeClaimRetentionPolicyRetain,
+	})
+	rm, k8sClient := setupComputeResourceManager(t)
+	require.NoError(t, k8sClient.Create(context.Background(), retentionTestPVC(t, rm, workspace)))
+	require.NoError(t, rm.RetainPVC(context.Background(), workspace, nil))
+
+	// a new workspace with the same name does not silently reuse the retained PVC
+	recreated := retentionTestWorkspace(nil)
+	recreated.UID = "recreated-uid"
+	_, err := rm.EnsurePVCExists(context.Background(), recreated)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "set spec.storage.existingClaimName to adopt it")
+
+	recreated.Spec.Storage.ExistingClaimName = "missing-pvc"
+	_, err = rm.EnsurePVCExists(context.Background(), recreated)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
+
+func TestRetainedPVCReconcile(t *testing.T) {
+	expired := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "expired",
+			Namespace: "default",
+			Labels:    map[string]string{LabelComponent: ComponentRetainedStorage},
+			Annotations: map[string]string{
+				AnnotationRetainUntil: time.Now().Add(-time.Minute).UTC().Format(time.RFC3339),
+			},
+		},
+	}
+	retained := expired.DeepCopy()
+	retained.Name = "retained"
+	retained.Annotations[AnnotationRetainUntil] = time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	forever := expired.DeepCopy()
+	forever.Name = "forever"
+	forever.Annotations = nil
+	rm, k8sClient := setupComputeResourceManager(t, expired, retained, forever)
+	reconciler := &RetainedPVCReconciler{Client: k8sClient, Scheme: rm.scheme}
+
+	result, err := reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(expired)})
+	require.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+	err = k8sClient.Get(context.Background(), client.ObjectKeyFromObject(expired), &corev1.PersistentVolumeClaim{})
+	assert.True(t, errors.IsNotFound(err))
+
+	result, err = reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(retained)})
+	require.NoError(t, err)
+	assert.Greater(t, result.RequeueAfter, 59*time.Minute)
+	require.NoError(t, k8sClient.Get(context.Background(), client.ObjectKeyFromObject(retained), &corev1.PersistentVolumeClaim{}))
+
+	result, err = reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(forever)})
+	require.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+	require.NoError(t, k8sClient.Get(context.Background(), client.ObjectKeyFromObject(forever), &corev1.PersistentVolumeClaim{}))
+}
@@ -0,0 +1,182 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	workspaceutil "github.com/jupyter-infra/jupyter-k8s/internal/workspace"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// EnsureSecondaryPVCsExist creates the PVCs of the secondary storages of the workspace, expands them
+// when their size grew, and retains the PVCs of secondary storages removed from the spec.
+// A retained PVC is adopted again when its storage is added back to the workspace it was retained from.
+func (rm *ResourceManager) EnsureSecondaryPVCsExist(ctx context.Context, workspace *workspacev1alpha1.Workspace) error {
+	logger := logf.FromContext(ctx)
+	wanted := make(map[string]bool, len(workspace.Spec.SecondaryStorages))
+
+	for i := range workspace.Spec.SecondaryStorages {
+		storage := &workspace.Spec.SecondaryStorages[i]
+		wanted[storage.Name] = true
+
+		pvc := &corev1.PersistentVolumeClaim{}
+		err := rm.client.Get(ctx, types.NamespacedName{
+			Name:      GenerateSecondaryPVCName(workspace.Name, storage.Name),
+			Namespace: workspace.Namespace,
+		}, pvc)
+		if errors.IsNotFound(err) {
+			pvc, err = rm.pvcBuilder.BuildSecondaryPVC(workspace, storage)
+			if err != nil {
+				return fmt.Errorf("failed to build PVC of secondary storage %s: %w", storage.Name, err)
+			}
+			logger.Info("Creating PVC of secondary storage", "pvc", pvc.Name, "storage", storage.Name)
+			if err := rm.client.Create(ctx, pvc); err != nil {
+				return fmt.Errorf("failed to create PVC of secondary storage %s: %w", storage.Name, err)
+			}
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get PVC of secondary storage %s: %w", storage.Name, err)
+		}
+
+		if !metav1.IsControlledBy(pvc, workspace) {
+			if !isRetainedFromWorkspace(pvc, workspace) {
+				return fmt.Errorf("PVC %s of secondary storage %s exists and is not owned by the workspace", pvc.Name, storage.Name)
+			}
+			labels := GenerateLabels(workspace.Name)
+			labels[LabelComponent] = ComponentSecondaryStorage
+			labels[LabelSecondaryStorageName] = storage.Name
+			if pvc, err = rm.adoptPVC(ctx, pvc, workspace, labels); err != nil {
+				return fmt.Errorf("failed to adopt PVC of secondary storage %s: %w", storage.Name, err)
+			}
+		}
+		if err := rm.expandSecondaryPVC(ctx, pvc, storage); err != nil {
+			return err
+		}
+	}
+
+	// Removed storages are retained rather than deleted, so that removing an entry by mistake loses no data
+	_, retainUntil := getPVCRetention(workspace)
+	return rm.releaseSecondaryPVCs(ctx, workspace, wanted, true, retainUntil)
+}
+
+// expandSecondaryPVC grows the PVC of a secondary storage to its size when its storage class allows it
+func (rm *ResourceManager) expandSecondaryPVC(
+	ctx context.Context,
+	pvc *corev1.PersistentVolumeClaim,
+	storage *workspacev1alpha1.SecondaryStorageSpec) error {
+	requested := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	if storage.Size.Cmp(requested) <= 0 {
+		return nil
+	}
+
+	allowed, err := rm.IsVolumeExpansionAllowed(ctx, pvc)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		logf.FromContext(ctx).Info("Storage class does not allow volume expansion, secondary PVC not resized",
+			"pvc", pvc.Name,
+			"storage", storage.Name)
+		return nil
+	}
+
+	if pvc.Spec.Resources.Requests == nil {
+		pvc.Spec.Resources.Requests = corev1.ResourceList{}
+	}
+	pvc.Spec.Resources.Requests[corev1.ResourceStorage] = storage.Size
+	logf.FromContext(ctx).Info("Expanding PVC of secondary storage",
+		"pvc", pvc.Name,
+		"from", requested.String(),
+		"to", storage.Size.String())
+	if err := rm.client.Update(ctx, pvc); err != nil {
+		return fmt.Errorf("failed to expand PVC of secondary storage %s: %w", storage.Name, err)
+	}
+	return nil
+}
+
+// EnsureSecondaryPVCsDeleted releases the PVCs of all the secondary storages of the workspace:
+// they are retained when the retention policy of the workspace storage asks for it, and deleted otherwise
+func (rm *ResourceManager) EnsureSecondaryPVCsDeleted(ctx context.Context, workspace *workspacev1alpha1.Workspace) error {
+	retain, retainUntil := getPVCRetention(workspace)
+	return rm.releaseSecondaryPVCs(ctx, workspace, nil, retain, retainUntil)
+}
+
+// releaseSecondaryPVCs retains or deletes the secondary storage PVCs owned by the workspace that are not wanted
+func (rm *ResourceManager) releaseSecondaryPVCs(
+	ctx context.Context,
+	workspace *workspacev1alpha1.Workspace,
+	wanted map[string]bool,
+	retain bool,
+	retainUntil *time.Time) error {
+	pvcs, err := rm.listSecondaryPVCs(ctx, workspace)
+	if err != nil {
+		return err
+	}
+
+	for i := range pvcs {
+		pvc := &pvcs[i]
+		if wanted[pvc.Labels[LabelSecondaryStorageName]] || !pvc.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if retain {
+			if err := rm.retainPVC(ctx, pvc, workspace, retainUntil); err != nil {
+				return fmt.Errorf("failed to retain PVC of secondary storage: %w", err)
+			}
+			continue
+		}
+		logf.FromContext(ctx).Info("Deleting PVC of secondary storage", "pvc", pvc.Name, "namespace", pvc.Namespace)
+		if err := rm.client.Delete(ctx, pvc); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete PVC of secondary storage: %w", err)
+		}
+	}
+	return nil
+}
+
+// isRetainedFromWorkspace checks if the PVC was retained from a workspace of the same name and creator
+func isRetainedFromWorkspace(pvc *corev1.PersistentVolumeClaim, workspace *workspacev1alpha1.Workspace) bool {
+	return IsRetainedPVC(pvc) &&
+		pvc.Annotations[AnnotationRetainedFrom] == workspace.Name &&
+		pvc.Annotations[AnnotationCreatedBy] == workspace.Annotations[AnnotationCreatedBy]
+}
+
+// areSecondaryPVCsDeleted checks if all the secondary storage PVCs of the workspace are fully removed
+func (rm *ResourceManager) areSecondaryPVCsDeleted(ctx context.Context, workspace *workspacev1alpha1.Workspace) bool {
+	pvcs, err := rm.listSecondaryPVCs(ctx, workspace)
+	return err == nil && len(pvcs) == 0
+}
+
+// listSecondaryPVCs lists the secondary storage PVCs owned by the workspace
+func (rm *ResourceManager) listSecondaryPVCs(
+	ctx context.Context,
+	workspace *workspacev1alpha1.Workspace) ([]corev1.PersistentVolumeClaim, error) {
+	pvcList := &corev1.PersistentVolumeClaimList{}
+	if err := rm.client.List(ctx, pvcList,
+		client.InNamespace(workspace.Namespace),
+		client.MatchingLabels{
+			workspaceutil.LabelWorkspaceName: workspace.Name,
+			LabelComponent:                   ComponentSecondaryStorage,
+		}); err != nil {
+		return nil, fmt.Errorf("failed to list PVCs of secondary storages: %w", err)
+	}
+
+	pvcs := make([]corev1.PersistentVolumeClaim, 0, len(pvcList.Items))
+	for _, pvc := range pvcList.Items {
+		if metav1.IsControlledBy(&pvc, workspace) {
+			pvcs = append(pvcs, pvc)
+		}
+	}
+	return pvcs, nil
+}
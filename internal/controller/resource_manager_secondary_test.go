@@ -0,0 +1,161 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+)
+
+// secondaryTestWorkspace returns a workspace with a scratch and a data secondary storage
+func secondaryTestWorkspace() *workspacev1alpha1.Workspace {
+	storageClassName := "standard"
+	workspace := resizeTestWorkspace("10Gi")
+	workspace.UID = types.UID("secondary-test-uid")
+	workspace.Spec.SecondaryStorages = []workspacev1alpha1.SecondaryStorageSpec{
+		{Name: "scratch", Size: resource.MustParse("50Gi"), StorageClassName: &storageClassName, MountPath: "/scratch"},
+		{Name: "data", Size: resource.MustParse("10Gi"), MountPath: "/data"},
+	}
+	return workspace
+}
+
+func TestEnsureSecondaryPVCsExist_CreatesPVCs(t *testing.T) {
+	workspace := secondaryTestWorkspace()
+	sm, fakeClient, _ := newStorageTestStateMachine(t, resizeTestStorageClass(true))
+
+	require.NoError(t, sm.resourceManager.EnsureSecondaryPVCsExist(context.Background(), workspace))
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	require.NoError(t, fakeClient.Get(context.Background(), types.NamespacedName{
+		Name:      GenerateSecondaryPVCName(workspace.Name, "scratch"),
+		Namespace: workspace.Namespace,
+	}, pvc))
+	assert.Equal(t, "standard", *pvc.Spec.StorageClassName)
+	assert.True(t, pvc.Spec.Resources.Requests.Storage().Equal(resource.MustParse("50Gi")))
+	assert.Equal(t, ComponentSecondaryStorage, pvc.Labels[LabelComponent])
+	assert.Equal(t, "scratch", pvc.Labels[LabelSecondaryStorageName])
+	require.Len(t, pvc.OwnerReferences, 1)
+	assert.Equal(t, workspace.UID, pvc.OwnerReferences[0].UID)
+
+	pvcs, err := sm.resourceManager.listSecondaryPVCs(context.Background(), workspace)
+	require.NoError(t, err)
+	assert.Len(t, pvcs, 2)
+}
+
+func TestEnsureSecondaryPVCsExist_ExpandsAndRetainsRemoved(t *testing.T) {
+	workspace := secondaryTestWorkspace()
+	workspace.Annotations = map[string]string{AnnotationCreatedBy: "alice"}
+	sm, fakeClient, _ := newStorageTestStateMachine(t, resizeTestStorageClass(true))
+	require.NoError(t, sm.resourceManager.EnsureSecondaryPVCsExist(context.Background(), workspace))
+
+	removed := workspace.Spec.SecondaryStorages[1]
+	workspace.Spec.SecondaryStorages = workspace.Spec.SecondaryStorages[:1]
+	workspace.Spec.SecondaryStorages[0].Size = resource.MustParse("100Gi")
+	require.NoError(t, sm.resourceManager.EnsureSecondaryPVCsExist(context.Background(), workspace))
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	require.NoError(t, fakeClient.Get(context.Background(), types.NamespacedName{
+		Name:      GenerateSecondaryPVCName(workspace.Name, "scratch"),
+		Namespace: workspace.Namespace,
+	}, pvc))
+	assert.True(t, pvc.Spec.Resources.Requests.Storage().Equal(resource.MustParse("100Gi")))
+
+	// the removed storage is retained rather than deleted
+	retained := &corev1.PersistentVolumeClaim{}
+	dataKey := types.NamespacedName{Name: GenerateSecondaryPVCName(workspace.Name, "data"), Namespace: workspace.Namespace}
+	require.NoError(t, fakeClient.Get(context.Background(), dataKey, retained))
+	assert.True(t, IsRetainedPVC(retained))
+	assert.Empty(t, retained.OwnerReferences)
+	assert.Equal(t, workspace.Name, retained.Annotations[AnnotationRetainedFrom])
+
+	// adding the storage back adopts the retained PVC
+	workspace.Spec.SecondaryStorages = append(workspace.Spec.SecondaryStorages, removed)
+	require.NoError(t, sm.resourceManager.EnsureSecondaryPVCsExist(context.Background(), workspace))
+	adopted := &corev1.PersistentVolumeClaim{}
+	require.NoError(t, fakeClient.Get(context.Background(), dataKey, adopted))
+	assert.Equal(t, ComponentSecondaryStorage, adopted.Labels[LabelComponent])
+	assert.Equal(t, "data", adopted.Labels[LabelSecondaryStorageName])
+	require.Len(t, adopted.OwnerReferences, 1)
+	assert.Equal(t, workspace.UID, adopted.OwnerReferences[0].UID)
+}
+
+func TestEnsureSecondaryPVCsExist_RejectsForeignPVC(t *testing.T) {
+	workspace := secondaryTestWorkspace()
+	foreign := resizeTestPVC("50Gi", "50Gi")
+	foreign.Name = GenerateSecondaryPVCName(workspace.Name, "scratch")
+	sm, _, _ := newStorageTestStateMachine(t, foreign, resizeTestStorageClass(true))
+
+	err := sm.resourceManager.EnsureSecondaryPVCsExist(context.Background(), workspace)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is not owned by the workspace")
+}
+
+func TestEnsureSecondaryPVCsDeleted(t *testing.T) {
+	workspace := secondaryTestWorkspace()
+	sm, _, _ := newStorageTestStateMachine(t, resizeTestStorageClass(true))
+	require.NoError(t, sm.resourceManager.EnsureSecondaryPVCsExist(context.Background(), workspace))
+	assert.False(t, sm.resourceManager.areSecondaryPVCsDeleted(context.Background(), workspace))
+
+	require.NoError(t, sm.resourceManager.EnsureSecondaryPVCsDeleted(context.Background(), workspace))
+	assert.True(t, sm.resourceManager.areSecondaryPVCsDeleted(context.Background(), workspace))
+}
+
+func TestEnsureSecondaryPVCsDeleted_RetentionPolicy(t *testing.T) {
+	workspace := secondaryTestWorkspace()
+	workspace.Spec.Storage.PersistentVolumeClaimRetentionPolicy = &workspacev1alpha1.PersistentVolumeClaimRetentionPolicy{
+		WhenDeleted: workspacev1alpha1.PersistentVolumeClaimRetentionPolicyRetain,
+	}
+	sm, fakeClient, _ := newStorageTestStateMachine(t, resizeTestStorageClass(true))
+	require.NoError(t, sm.resourceManager.EnsureSecondaryPVCsExist(context.Background(), workspace))
+
+	require.NoError(t, sm.resourceManager.EnsureSecondaryPVCsDeleted(context.Background(), workspace))
+	assert.True(t, sm.resourceManager.areSecondaryPVCsDeleted(context.Background(), workspace))
+
+	for _, storage := range workspace.Spec.SecondaryStorages {
+		pvc := &corev1.PersistentVolumeClaim{}
+		require.NoError(t, fakeClient.Get(context.Background(), types.NamespacedName{
+			Name:      GenerateSecondaryPVCName(workspace.Name, storage.Name),
+			Namespace: workspace.Namespace,
+		}, pvc))
+		assert.True(t, IsRetainedPVC(pvc), storage.Name)
+		assert.NotContains(t, pvc.Annotations, AnnotationRetainUntil)
+	}
+}
+
+func TestBuildPodSpec_SecondaryStorages(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, workspacev1alpha1.AddToScheme(scheme))
+	builder := NewDeploymentBuilder(scheme, WorkspaceControllerOptions{}, nil)
+	workspace := secondaryTestWorkspace()
+
+	podSpec, err := builder.buildPodSpec(workspace, corev1.ResourceRequirements{}, nil)
+	require.NoError(t, err)
+
+	var claimNames []string
+	for _, volume := range podSpec.Volumes {
+		if volume.PersistentVolumeClaim != nil {
+			claimNames = append(claimNames, volume.PersistentVolumeClaim.ClaimName)
+		}
+	}
+	assert.Contains(t, claimNames, GenerateSecondaryPVCName(workspace.Name, "scratch"))
+	assert.Contains(t, claimNames, GenerateSecondaryPVCName(workspace.Name, "data"))
+
+	mounts := map[string]string{}
+	for _, mount := range podSpec.Containers[0].VolumeMounts {
+		mounts[mount.Name] = mount.MountPath
+	}
+	assert.Equal(t, "/scratch", mounts[GenerateSecondaryVolumeName("scratch")])
+	assert.Equal(t, "/data", mounts[GenerateSecondaryVolumeName("data")])
+}
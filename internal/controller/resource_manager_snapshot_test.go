@@ -0,0 +1,88 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func setupSnapshotResourceManager(objs ...client.Object) (*ResourceManager, client.Client) {
+	s := runtime.NewScheme()
+	_ = scheme.AddToScheme(s)
+	_ = workspacev1alpha1.AddToScheme(s)
+	k8sClient := fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build()
+	return &ResourceManager{client: k8sClient, scheme: s, pvcBuilder: NewPVCBuilder(s)}, k8sClient
+}
+
+func snapshotTestWorkspace() *workspacev1alpha1.Workspace {
+	return &workspacev1alpha1.Workspace{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-workspace", Namespace: "default"},
+		Spec: workspacev1alpha1.WorkspaceSpec{
+			Storage: &workspacev1alpha1.StorageSpec{
+				Size:     resource.MustParse("1Gi"),
+				Snapshot: &workspacev1alpha1.SnapshotPolicy{OnStop: true, Retain: 2},
+			},
+		},
+	}
+}
+
+func listWorkspaceSnapshots(t *testing.T, k8sClient client.Client) []unstructured.Unstructured {
+	list := &unstructured.UnstructuredList{}
+	list.SetAPIVersion(VolumeSnapshotAPIGroup + "/" + VolumeSnapshotAPIVersion)
+	list.SetKind(KindVolumeSnapshot + "List")
+	require.NoError(t, k8sClient.List(context.Background(), list, client.InNamespace("default")))
+	return list.Items
+}
+
+func TestResourceManager_CreateVolumeSnapshot_NoPVC(t *testing.T) {
+	rm, k8sClient := setupSnapshotResourceManager()
+
+	snapshot, err := rm.CreateVolumeSnapshot(context.Background(), snapshotTestWorkspace(), time.Now())
+	require.NoError(t, err)
+	assert.Nil(t, snapshot)
+	assert.Empty(t, listWorkspaceSnapshots(t, k8sClient))
+}
+
+func TestResourceManager_CreateAndPruneVolumeSnapshots(t *testing.T) {
+	workspace := snapshotTestWorkspace()
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: GeneratePVCName(workspace.Name), Namespace: workspace.Namespace},
+	}
+	rm, k8sClient := setupSnapshotResourceManager(pvc)
+	ctx := context.Background()
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 4; i++ {
+		snapshot, err := rm.CreateVolumeSnapshot(ctx, workspace, start.Add(time.Duration(i)*time.Hour))
+		require.NoError(t, err)
+		require.NotNil(t, snapshot)
+	}
+	assert.Len(t, listWorkspaceSnapshots(t, k8sClient), 4)
+
+	require.NoError(t, rm.PruneVolumeSnapshots(ctx, workspace, 2))
+
+	remaining := listWorkspaceSnapshots(t, k8sClient)
+	require.Len(t, remaining, 2)
+	names := []string{remaining[0].GetName(), remaining[1].GetName()}
+	assert.ElementsMatch(t, []string{
+		GenerateSnapshotName(workspace.Name, start.Add(2*time.Hour)),
+		GenerateSnapshotName(workspace.Name, start.Add(3*time.Hour)),
+	}, names)
+}
@@ -0,0 +1,96 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// RetainedPVCReconciler deletes the PVCs retained from deleted workspaces once their retention expires
+type RetainedPVCReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// Reconcile deletes the retained PVC when its retain-until time has passed,
+// or requeues until then. PVCs retained without an expiry are kept.
+func (r *RetainedPVCReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := logf.FromContext(ctx).WithValues(
+		"pvc", req.Name,
+		"namespace", req.Namespace)
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := r.Get(ctx, req.NamespacedName, pvc); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !IsRetainedPVC(pvc) || !pvc.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	value, ok := pvc.Annotations[AnnotationRetainUntil]
+	if !ok {
+		return ctrl.Result{}, nil
+	}
+	retainUntil, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		logger.Error(err, "Ignoring invalid retain-until annotation of retained PVC", "value", value)
+		return ctrl.Result{}, nil
+	}
+	if now := time.Now(); now.Before(retainUntil) {
+		return ctrl.Result{RequeueAfter: retainUntil.Sub(now)}, nil
+	}
+
+	logger.Info("Deleting retained PVC after its retention expired",
+		"retainedFrom", pvc.Annotations[AnnotationRetainedFrom],
+		"retainUntil", value)
+	if err := r.Delete(ctx, pvc); err != nil && !errors.IsNotFound(err) {
+		logger.Error(err, "Failed to delete retained PVC")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+// Only PVCs retained from deleted workspaces are reconciled.
+func (r *RetainedPVCReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	logger := mgr.GetLogger().WithName("retainedpvc-setup")
+	logger.Info("Setting up retained PVC controller")
+
+	err := ctrl.NewControllerManagedBy(mgr).
+		For(&corev1.PersistentVolumeClaim{}, builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+			return obj.GetLabels()[LabelComponent] == ComponentRetainedStorage
+		}))).
+		Named("retainedpvc").
+		Complete(r)
+
+	if err != nil {
+		logger.Error(err, "Failed to setup retained PVC controller")
+		return err
+	}
+
+	logger.Info("Successfully registered retained PVC controller with manager")
+	return nil
+}
+
+// SetupRetainedPVCController sets up the retained PVC controller with the Manager
+func SetupRetainedPVCController(mgr ctrl.Manager) error {
+	reconciler := &RetainedPVCReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}
+
+	return reconciler.SetupWithManager(mgr)
+}
@@ -0,0 +1,106 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	workspaceutil "github.com/jupyter-infra/jupyter-k8s/internal/workspace"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// applySharedVolumes mounts the shared volumes of the workspace namespace that match the groups of its creator.
+// Shared volumes whose PVC is not provisioned yet, or which conflict with a volume or a mount path
+// of the workspace, are skipped.
+func (db *DeploymentBuilder) applySharedVolumes(ctx context.Context, podSpec *corev1.PodSpec, workspace *workspacev1alpha1.Workspace) error {
+	_, err := db.mountSharedVolumes(ctx, podSpec, workspace)
+	return err
+}
+
+// sharedVolumeConflicts returns the names of the shared volumes matching the workspace that are skipped
+// because they conflict with a volume or a mount path of the workspace
+func (db *DeploymentBuilder) sharedVolumeConflicts(ctx context.Context, workspace *workspacev1alpha1.Workspace) ([]string, error) {
+	podSpec, err := db.buildPodSpec(workspace, corev1.ResourceRequirements{}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return db.mountSharedVolumes(ctx, &podSpec, workspace)
+}
+
+// mountSharedVolumes adds the shared volumes of the workspace to the pod spec,
+// and returns the names of those skipped because of a conflict
+func (db *DeploymentBuilder) mountSharedVolumes(
+	ctx context.Context,
+	podSpec *corev1.PodSpec,
+	workspace *workspacev1alpha1.Workspace) ([]string, error) {
+	if db.client == nil || len(podSpec.Containers) == 0 {
+		return nil, nil
+	}
+
+	sharedVolumes := &workspacev1alpha1.SharedVolumeList{}
+	if err := db.client.List(ctx, sharedVolumes, client.InNamespace(workspace.Namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list shared volumes: %w", err)
+	}
+	// Keep the pod spec stable whatever the order of the list
+	slices.SortFunc(sharedVolumes.Items, func(a, b workspacev1alpha1.SharedVolume) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	var conflicts []string
+	container := &podSpec.Containers[0]
+	for i := range sharedVolumes.Items {
+		sharedVolume := &sharedVolumes.Items[i]
+		mounted, readOnly := workspaceutil.SharedVolumeAccess(sharedVolume, workspace)
+		if !mounted || sharedVolume.Status.ClaimName == "" || !sharedVolume.DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		volumeName := GenerateSharedVolumeName(sharedVolume.Name)
+		if hasVolume(podSpec, volumeName) || hasMountPath(container, sharedVolume.Spec.MountPath) {
+			logf.FromContext(ctx).Info("Skipping shared volume conflicting with a workspace volume",
+				"sharedVolume", sharedVolume.Name,
+				"mountPath", sharedVolume.Spec.MountPath)
+			conflicts = append(conflicts, sharedVolume.Name)
+			continue
+		}
+
+		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+			Name: volumeName,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: sharedVolume.Status.ClaimName,
+					ReadOnly:  readOnly,
+				},
+			},
+		})
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      volumeName,
+			MountPath: sharedVolume.Spec.MountPath,
+			ReadOnly:  readOnly,
+		})
+	}
+	return conflicts, nil
+}
+
+// hasVolume checks if the pod spec has a volume with the given name
+func hasVolume(podSpec *corev1.PodSpec, name string) bool {
+	return slices.ContainsFunc(podSpec.Volumes, func(volume corev1.Volume) bool {
+		return volume.Name == name
+	})
+}
+
+// hasMountPath checks if the container mounts a volume at the given path
+func hasMountPath(container *corev1.Container, mountPath string) bool {
+	return slices.ContainsFunc(container.VolumeMounts, func(mount corev1.VolumeMount) bool {
+		return mount.MountPath == mountPath
+	})
+}
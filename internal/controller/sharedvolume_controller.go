@@ -0,0 +1,222 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// SharedVolume condition types and reasons
+const (
+	// SharedVolumeConditionTypeReady indicates whether the PVC of the shared volume is bound
+	SharedVolumeConditionTypeReady = "Ready"
+
+	ReasonClaimBound   = "ClaimBound"
+	ReasonClaimPending = "ClaimPending"
+	ReasonClaimLost    = "ClaimLost"
+	ReasonInvalidSize  = "InvalidSize"
+)
+
+// SharedVolumeReconciler provisions the ReadWriteMany PVCs of SharedVolume objects
+type SharedVolumeReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=workspace.jupyter.org,resources=sharedvolumes/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=workspace.jupyter.org,resources=sharedvolumes/finalizers,verbs=update
+
+// Reconcile creates the PVC of the shared volume, expands it when the size grows and reports
+// whether it is bound. The PVC is owned by the shared volume and deleted with it.
+// Workspaces mount the shared volume when their pod is built.
+func (r *SharedVolumeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := logf.FromContext(ctx).WithValues(
+		"sharedvolume", req.Name,
+		"namespace", req.Namespace)
+
+	sharedVolume := &workspacev1alpha1.SharedVolume{}
+	if err := r.Get(ctx, req.NamespacedName, sharedVolume); err != nil {
+		if errors.IsNotFound(err) {
+			logger.V(1).Info("SharedVolume not found, it may have been deleted")
+			return ctrl.Result{}, nil
+		}
+		logger.Error(err, "Failed to get SharedVolume")
+		return ctrl.Result{}, err
+	}
+	if !sharedVolume.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	status := sharedVolume.Status.DeepCopy()
+	status.ObservedGeneration = sharedVolume.Generation
+	if sharedVolume.Spec.Size.Sign() <= 0 {
+		// No PVC is provisioned for an empty size, the volume is reported until the size is fixed
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:    SharedVolumeConditionTypeReady,
+			Status:  metav1.ConditionFalse,
+			Reason:  ReasonInvalidSize,
+			Message: fmt.Sprintf("Size %s must be greater than zero", sharedVolume.Spec.Size.String()),
+		})
+	} else {
+		pvc, err := r.ensureSharedVolumePVC(ctx, sharedVolume)
+		if err != nil {
+			logger.Error(err, "Failed to ensure PVC of SharedVolume")
+			return ctrl.Result{}, err
+		}
+		status.ClaimName = pvc.Name
+		meta.SetStatusCondition(&status.Conditions, sharedVolumeReadyCondition(pvc))
+	}
+
+	if equality.Semantic.DeepEqual(&sharedVolume.Status, status) {
+		return ctrl.Result{}, nil
+	}
+	sharedVolume.Status = *status
+	if err := r.Status().Update(ctx, sharedVolume); err != nil {
+		logger.Error(err, "Failed to update SharedVolume status")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// ensureSharedVolumePVC creates the PVC of the shared volume if missing, or expands it when the size grew
+func (r *SharedVolumeReconciler) ensureSharedVolumePVC(
+	ctx context.Context,
+	sharedVolume *workspacev1alpha1.SharedVolume) (*corev1.PersistentVolumeClaim, error) {
+	logger := logf.FromContext(ctx)
+	pvc := &corev1.PersistentVolumeClaim{}
+	err := r.Get(ctx, types.NamespacedName{
+		Name:      GenerateSharedVolumePVCName(sharedVolume.Name),
+		Namespace: sharedVolume.Namespace,
+	}, pvc)
+	if errors.IsNotFound(err) {
+		pvc = buildSharedVolumePVC(sharedVolume)
+		if err := controllerutil.SetControllerReference(sharedVolume, pvc, r.Scheme); err != nil {
+			return nil, fmt.Errorf("failed to set controller reference: %w", err)
+		}
+		if err := r.Create(ctx, pvc); err != nil {
+			return nil, fmt.Errorf("failed to create PVC: %w", err)
+		}
+		logger.Info("Created PVC of SharedVolume", "pvc", pvc.Name)
+		return pvc, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PVC: %w", err)
+	}
+
+	if !metav1.IsControlledBy(pvc, sharedVolume) {
+		return nil, fmt.Errorf("PVC %s exists and is not owned by the shared volume", pvc.Name)
+	}
+
+	requested := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	if sharedVolume.Spec.Size.Cmp(requested) > 0 {
+		if pvc.Spec.Resources.Requests == nil {
+			pvc.Spec.Resources.Requests = corev1.ResourceList{}
+		}
+		pvc.Spec.Resources.Requests[corev1.ResourceStorage] = sharedVolume.Spec.Size
+		if err := r.Update(ctx, pvc); err != nil {
+			return nil, fmt.Errorf("failed to expand PVC: %w", err)
+		}
+		logger.Info("Expanded PVC of SharedVolume",
+			"pvc", pvc.Name,
+			"from", requested.String(),
+			"to", sharedVolume.Spec.Size.String())
+	}
+	return pvc, nil
+}
+
+// buildSharedVolumePVC creates the ReadWriteMany PVC of a shared volume
+func buildSharedVolumePVC(sharedVolume *workspacev1alpha1.SharedVolume) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      GenerateSharedVolumePVCName(sharedVolume.Name),
+			Namespace: sharedVolume.Namespace,
+			Labels: map[string]string{
+				AppLabel:              AppLabelValue,
+				LabelComponent:        ComponentSharedVolume,
+				LabelSharedVolumeName: sharedVolume.Name,
+			},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany},
+			StorageClassName: sharedVolume.Spec.StorageClassName,
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: sharedVolume.Spec.Size},
+			},
+		},
+	}
+}
+
+// sharedVolumeReadyCondition returns the Ready condition of a shared volume from the phase of its PVC
+func sharedVolumeReadyCondition(pvc *corev1.PersistentVolumeClaim) metav1.Condition {
+	switch pvc.Status.Phase {
+	case corev1.ClaimBound:
+		return metav1.Condition{
+			Type:    SharedVolumeConditionTypeReady,
+			Status:  metav1.ConditionTrue,
+			Reason:  ReasonClaimBound,
+			Message: fmt.Sprintf("PVC %s is bound", pvc.Name),
+		}
+	case corev1.ClaimLost:
+		return metav1.Condition{
+			Type:    SharedVolumeConditionTypeReady,
+			Status:  metav1.ConditionFalse,
+			Reason:  ReasonClaimLost,
+			Message: fmt.Sprintf("PVC %s lost its volume", pvc.Name),
+		}
+	default:
+		return metav1.Condition{
+			Type:    SharedVolumeConditionTypeReady,
+			Status:  metav1.ConditionFalse,
+			Reason:  ReasonClaimPending,
+			Message: fmt.Sprintf("PVC %s is waiting for a ReadWriteMany volume", pvc.Name),
+		}
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+// Shared volumes are reconciled when their PVC changes.
+func (r *SharedVolumeReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	logger := mgr.GetLogger().WithName("sharedvolume-setup")
+	logger.Info("Setting up SharedVolume controller")
+
+	err := ctrl.NewControllerManagedBy(mgr).
+		For(&workspacev1alpha1.SharedVolume{}).
+		Owns(&corev1.PersistentVolumeClaim{}).
+		Named("sharedvolume").
+		Complete(r)
+
+	if err != nil {
+		logger.Error(err, "Failed to setup SharedVolume controller")
+		return err
+	}
+
+	logger.Info("Successfully registered SharedVolume controller with manager")
+	return nil
+}
+
+// SetupSharedVolumeController sets up the SharedVolume controller with the Manager
+func SetupSharedVolumeController(mgr ctrl.Manager) error {
+	reconciler := &SharedVolumeReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}
+
+	return reconciler.SetupWithManager(mgr)
+}
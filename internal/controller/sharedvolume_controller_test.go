@@ -0,0 +1,181 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	workspaceutil "github.com/jupyter-infra/jupyter-k8s/internal/workspace"
+)
+
+func newSharedVolumeTestReconciler(t *testing.T, objects ...client.Object) (*SharedVolumeReconciler, client.Client) {
+	s := runtime.NewScheme()
+	require.NoError(t, scheme.AddToScheme(s))
+	require.NoError(t, workspacev1alpha1.AddToScheme(s))
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(s).
+		WithObjects(objects...).
+		WithStatusSubresource(&workspacev1alpha1.SharedVolume{}).
+		Build()
+	return &SharedVolumeReconciler{Client: fakeClient, Scheme: s}, fakeClient
+}
+
+func newTestSharedVolume(name string) *workspacev1alpha1.SharedVolume {
+	return &workspacev1alpha1.SharedVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: "sv-uid", Generation: 1},
+		Spec: workspacev1alpha1.SharedVolumeSpec{
+			Size:      resource.MustParse("50Gi"),
+			MountPath: "/home/jovyan/shared",
+		},
+	}
+}
+
+func TestSharedVolumeReconcile_ProvisionsPVC(t *testing.T) {
+	sharedVolume := newTestSharedVolume("datasets")
+	reconciler, fakeClient := newSharedVolumeTestReconciler(t, sharedVolume)
+
+	_, err := reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(sharedVolume)})
+	require.NoError(t, err)
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	require.NoError(t, fakeClient.Get(context.Background(),
+		client.ObjectKey{Name: GenerateSharedVolumePVCName("datasets"), Namespace: "default"}, pvc))
+	assert.Equal(t, []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}, pvc.Spec.AccessModes)
+	assert.True(t, pvc.Spec.Resources.Requests.Storage().Equal(resource.MustParse("50Gi")))
+	assert.True(t, metav1.IsControlledBy(pvc, sharedVolume))
+	assert.Equal(t, ComponentSharedVolume, pvc.Labels[LabelComponent])
+
+	updated := &workspacev1alpha1.SharedVolume{}
+	require.NoError(t, fakeClient.Get(context.Background(), client.ObjectKeyFromObject(sharedVolume), updated))
+	assert.Equal(t, pvc.Name, updated.Status.ClaimName)
+	assert.Equal(t, int64(1), updated.Status.ObservedGeneration)
+	ready := meta.FindStatusCondition(updated.Status.Conditions, SharedVolumeConditionTypeReady)
+	require.NotNil(t, ready)
+	assert.Equal(t, metav1.ConditionFalse, ready.Status)
+	assert.Equal(t, ReasonClaimPending, ready.Reason)
+
+	// the PVC is expanded when the size grows, and the volume is ready once bound
+	pvc.Status.Phase = corev1.ClaimBound
+	require.NoError(t, fakeClient.Status().Update(context.Background(), pvc))
+	updated.Spec.Size = resource.MustParse("100Gi")
+	require.NoError(t, fakeClient.Update(context.Background(), updated))
+
+	_, err = reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(sharedVolume)})
+	require.NoError(t, err)
+
+	require.NoError(t, fakeClient.Get(context.Background(), client.ObjectKeyFromObject(pvc), pvc))
+	assert.True(t, pvc.Spec.Resources.Requests.Storage().Equal(resource.MustParse("100Gi")))
+	require.NoError(t, fakeClient.Get(context.Background(), client.ObjectKeyFromObject(sharedVolume), updated))
+	ready = meta.FindStatusCondition(updated.Status.Conditions, SharedVolumeConditionTypeReady)
+	require.NotNil(t, ready)
+	assert.Equal(t, metav1.ConditionTrue, ready.Status)
+}
+
+func TestSharedVolumeReconcile_RejectsEmptySize(t *testing.T) {
+	sharedVolume := newTestSharedVolume("datasets")
+	sharedVolume.Spec.Size = resource.MustParse("0")
+	reconciler, fakeClient := newSharedVolumeTestReconciler(t, sharedVolume)
+
+	_, err := reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(sharedVolume)})
+	require.NoError(t, err)
+
+	pvcs := &corev1.PersistentVolumeClaimList{}
+	require.NoError(t, fakeClient.List(context.Background(), pvcs))
+	assert.Empty(t, pvcs.Items)
+	updated := &workspacev1alpha1.SharedVolume{}
+	require.NoError(t, fakeClient.Get(context.Background(), client.ObjectKeyFromObject(sharedVolume), updated))
+	assert.Empty(t, updated.Status.ClaimName)
+	ready := meta.FindStatusCondition(updated.Status.Conditions, SharedVolumeConditionTypeReady)
+	require.NotNil(t, ready)
+	assert.Equal(t, ReasonInvalidSize, ready.Reason)
+}
+
+func TestSharedVolumeEventHandler_EnqueuesWorkspacesOfNamespace(t *testing.T) {
+	sharedVolume := newTestSharedVolume("datasets")
+	inNamespace := resizeTestWorkspace("10Gi")
+	otherNamespace := resizeTestWorkspace("10Gi")
+	otherNamespace.Namespace = "other"
+	_, fakeClient := newSharedVolumeTestReconciler(t, inNamespace, otherNamespace)
+	reconciler := &WorkspaceReconciler{Client: fakeClient}
+
+	requests := reconciler.sharedVolumeEventHandler(context.Background(), sharedVolume)
+
+	require.Len(t, requests, 1)
+	assert.Equal(t, client.ObjectKeyFromObject(inNamespace), requests[0].NamespacedName)
+}
+
+func TestReconcileSharedVolumeConflicts(t *testing.T) {
+	sharedVolume := newTestSharedVolume("datasets")
+	sharedVolume.Status.ClaimName = GenerateSharedVolumePVCName("datasets")
+	sm, fakeClient, recorder := newStorageTestStateMachine(t, sharedVolume)
+	sm.resourceManager.deploymentBuilder = NewDeploymentBuilder(fakeClient.Scheme(), WorkspaceControllerOptions{}, fakeClient)
+
+	workspace := resizeTestWorkspace("10Gi")
+	workspace.Spec.Storage.MountPath = sharedVolume.Spec.MountPath
+	sm.reconcileSharedVolumeConflicts(context.Background(), workspace)
+
+	conflict := meta.FindStatusCondition(workspace.Status.Conditions, ConditionTypeSharedVolumeConflict)
+	require.NotNil(t, conflict)
+	assert.Equal(t, ReasonMountPathConflict, conflict.Reason)
+	assert.Contains(t, conflict.Message, "datasets")
+	require.Len(t, recorder.Events, 1)
+
+	// the warning is only emitted once, and the condition is removed once the conflict is resolved
+	sm.reconcileSharedVolumeConflicts(context.Background(), workspace)
+	assert.Len(t, recorder.Events, 1)
+	workspace.Spec.Storage.MountPath = "/home/jovyan"
+	sm.reconcileSharedVolumeConflicts(context.Background(), workspace)
+	assert.Nil(t, meta.FindStatusCondition(workspace.Status.Conditions, ConditionTypeSharedVolumeConflict))
+}
+
+func TestApplySharedVolumes(t *testing.T) {
+	readOnly := newTestSharedVolume("datasets")
+	readOnly.Spec.ReadWriteGroups = []string{"teachers"}
+	readOnly.Status.ClaimName = GenerateSharedVolumePVCName("datasets")
+	otherGroup := newTestSharedVolume("staff")
+	otherGroup.Spec.MountPath = "/home/jovyan/staff"
+	otherGroup.Spec.Groups = []string{"staff"}
+	otherGroup.Status.ClaimName = GenerateSharedVolumePVCName("staff")
+	notProvisioned := newTestSharedVolume("pending")
+	notProvisioned.Spec.MountPath = "/home/jovyan/pending"
+	_, fakeClient := newSharedVolumeTestReconciler(t, readOnly, otherGroup, notProvisioned)
+	builder := NewDeploymentBuilder(fakeClient.Scheme(), WorkspaceControllerOptions{}, fakeClient)
+
+	workspace := resizeTestWorkspace("10Gi")
+	workspace.Annotations = map[string]string{workspaceutil.AnnotationCreatedByGroups: "students"}
+	deployment, err := builder.BuildDeployment(context.Background(), workspace)
+	require.NoError(t, err)
+
+	podSpec := deployment.Spec.Template.Spec
+	require.Len(t, podSpec.Volumes, 3)
+	assert.Equal(t, GenerateSharedVolumeName("datasets"), podSpec.Volumes[2].Name)
+	assert.Equal(t, readOnly.Status.ClaimName, podSpec.Volumes[2].PersistentVolumeClaim.ClaimName)
+	assert.True(t, podSpec.Volumes[2].PersistentVolumeClaim.ReadOnly)
+	mounts := podSpec.Containers[0].VolumeMounts
+	require.Len(t, mounts, 3)
+	assert.Equal(t, "/home/jovyan/shared", mounts[2].MountPath)
+	assert.True(t, mounts[2].ReadOnly)
+
+	// a shared volume conflicting with the workspace mounts is skipped
+	workspace.Spec.Storage.MountPath = "/home/jovyan/shared"
+	deployment, err = builder.BuildDeployment(context.Background(), workspace)
+	require.NoError(t, err)
+	assert.Len(t, deployment.Spec.Template.Spec.Volumes, 2)
+}
@@ -12,6 +12,8 @@ import (
 	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -57,14 +59,37 @@ func (sm *StateMachine) ReconcileDesiredState(
 	accessStrategy *workspacev1alpha1.WorkspaceAccessStrategy) (ctrl.Result, error) {
 	logger := logf.FromContext(ctx)
 
+	// Delete workspaces whose lifecycle policy expired before doing anything else
+	deleted, err := sm.reconcileLifecyclePolicy(ctx, workspace)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if deleted {
+		return ctrl.Result{}, nil
+	}
+
+	// Apply start/stop schedules, they may flip the desired status
+	scheduleChanged, err := sm.reconcileSchedule(ctx, workspace)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if scheduleChanged {
+		return ctrl.Result{RequeueAfter: MinimalRequeueDelay}, nil
+	}
+
 	desiredStatus := sm.getDesiredStatus(workspace)
 	snapshotStatus := workspace.DeepCopy().Status
 
+	var result ctrl.Result
 	switch desiredStatus {
 	case DesiredStateStopped:
-		return sm.reconcileDesiredStoppedStatus(ctx, workspace, &snapshotStatus)
+		sm.recordPreemption(ctx, workspace)
+		result, err = sm.reconcileDesiredStoppedStatus(ctx, workspace, &snapshotStatus)
+		if err == nil {
+			result, err = sm.restartPreemptedWorkspace(ctx, workspace, result)
+		}
 	case DesiredStateRunning:
-		return sm.reconcileDesiredRunningStatus(ctx, workspace, &snapshotStatus, accessStrategy)
+		result, err = sm.reconcileDesiredRunningStatus(ctx, workspace, &snapshotStatus, accessStrategy)
 	default:
 		err := fmt.Errorf("unknown desired status: %s", desiredStatus)
 		// Update error condition
@@ -75,6 +100,9 @@ func (sm *StateMachine) ReconcileDesiredState(
 		}
 		return ctrl.Result{RequeueAfter: LongRequeueDelay}, err
 	}
+
+	result, err = sm.requeueForNextScheduleBoundary(workspace, result, err)
+	return sm.requeueForLifecyclePolicy(workspace, result, err)
 }
 
 // getDesiredStatus returns the desired status with default fallback
@@ -104,12 +132,12 @@ func (sm *StateMachine) reconcileDesiredStoppedStatus(
 		// Continue with deletion of other resources, don't block on access strategy
 	}
 
-	// Ensure deployment is deleted - this is an asynchronous operation
-	// EnsureDeploymentDeleted only ensures the delete API request is accepted by K8s
-	// It does not wait for the deployment to be fully removed
-	deployment, deploymentErr := sm.resourceManager.EnsureDeploymentDeleted(ctx, workspace)
+	// Ensure the compute resources are deleted - this is an asynchronous operation
+	// EnsureComputeDeleted only ensures the delete API requests are accepted by K8s
+	// It does not wait for the deployment, statefulset or pod to be fully removed
+	deploymentDeleted, deploymentErr := sm.resourceManager.EnsureComputeDeleted(ctx, workspace)
 	if deploymentErr != nil {
-		err := fmt.Errorf("failed to get deployment: %w", deploymentErr)
+		err := fmt.Errorf("failed to delete compute resources: %w", deploymentErr)
 		// Update error condition
 		if statusErr := sm.statusManager.UpdateErrorStatus(
 			ctx, workspace, ReasonDeploymentError, err.Error(), snapshotStatus); statusErr != nil {
@@ -134,7 +162,6 @@ func (sm *StateMachine) reconcileDesiredStoppedStatus(
 
 	// Check if resources are fully deleted (asynchronous deletion check)
 	// A nil resource means the resource has been fully deleted
-	deploymentDeleted := sm.resourceManager.IsDeploymentMissingOrDeleting(deployment)
 	serviceDeleted := sm.resourceManager.IsServiceMissingOrDeleting(service)
 	accessResourcesDeleted := sm.resourceManager.AreAccessResourcesDeleted(workspace)
 
@@ -168,6 +195,9 @@ func (sm *StateMachine) reconcileDesiredStoppedStatus(
 				sm.recorder.Event(workspace, corev1.EventTypeNormal, "WorkspaceStopped", "Workspace has been stopped")
 			}
 
+			// Snapshot the workspace storage now that nothing is writing to it
+			sm.snapshotStorageOnStop(ctx, workspace, snapshotStatus)
+
 			if err := sm.statusManager.UpdateStoppedStatus(ctx, workspace, snapshotStatus); err != nil {
 				return ctrl.Result{}, err
 			}
@@ -211,7 +241,7 @@ func (sm *StateMachine) reconcileDesiredRunningStatus(
 	logger.Info("Attempting to bring Workspace status to 'Running'")
 
 	// Ensure PVC exists first (if storage is configured)
-	_, err := sm.resourceManager.EnsurePVCExists(ctx, workspace)
+	pvc, err := sm.resourceManager.EnsurePVCExists(ctx, workspace)
 	if err != nil {
 		pvcErr := fmt.Errorf("failed to ensure PVC exists: %w", err)
 		if statusErr := sm.statusManager.UpdateErrorStatus(
@@ -220,11 +250,24 @@ func (sm *StateMachine) reconcileDesiredRunningStatus(
 		}
 		return ctrl.Result{}, pvcErr
 	}
+	sm.reconcileStorageResize(ctx, workspace, pvc)
+	// a workspace that is starting is no longer blocked by a quota
+	meta.RemoveStatusCondition(&workspace.Status.Conditions, ConditionTypeQuotaExceeded)
+
+	if err := sm.resourceManager.EnsureSecondaryPVCsExist(ctx, workspace); err != nil {
+		pvcErr := fmt.Errorf("failed to ensure secondary storage PVCs exist: %w", err)
+		if statusErr := sm.statusManager.UpdateErrorStatus(
+			ctx, workspace, ReasonDeploymentError, pvcErr.Error(), snapshotStatus); statusErr != nil {
+			logger.Error(statusErr, "Failed to update error status")
+		}
+		return ctrl.Result{}, pvcErr
+	}
 
-	// EnsureDeploymentExists creates deployment if missing, or returns existing deployment
-	deployment, err := sm.resourceManager.EnsureDeploymentExists(ctx, workspace, accessStrategy)
+	// EnsureComputeExists creates the deployment, statefulset or pod of the compute mode if missing,
+	// or returns the existing one
+	deployment, err := sm.resourceManager.EnsureComputeExists(ctx, workspace, accessStrategy)
 	if err != nil {
-		deployErr := fmt.Errorf("failed to ensure deployment exists: %w", err)
+		deployErr := fmt.Errorf("failed to ensure compute resource exists: %w", err)
 		// Update error condition
 		if statusErr := sm.statusManager.UpdateErrorStatus(
 			ctx, workspace, ReasonDeploymentError, deployErr.Error(), snapshotStatus); statusErr != nil {
@@ -232,6 +275,7 @@ func (sm *StateMachine) reconcileDesiredRunningStatus(
 		}
 		return ctrl.Result{}, deployErr
 	}
+	sm.reconcileSharedVolumeConflicts(ctx, workspace)
 
 	// Ensure service exists
 	// EnsureServiceExists internally fetches the service and returns it with current status
@@ -247,9 +291,10 @@ func (sm *StateMachine) reconcileDesiredRunningStatus(
 	}
 
 	// Check if resources are fully ready (asynchronous readiness check)
-	// For deployments, we check the Available condition and/or replica counts
+	// For deployments, we check the Available condition and/or replica counts,
+	// for statefulsets the ready replicas and for bare pods the Ready condition
 	// For services, we just check if the Service object exists
-	deploymentReady := sm.resourceManager.IsDeploymentAvailable(deployment)
+	deploymentReady := sm.resourceManager.IsComputeAvailable(workspace, deployment)
 	serviceReady := sm.resourceManager.IsServiceAvailable(service)
 
 	// Apply access strategy when compute and service resources are ready
@@ -285,13 +330,40 @@ func (sm *StateMachine) reconcileDesiredRunningStatus(
 		serviceReady:         serviceReady,
 		accessResourcesReady: false,
 	}
-	if err := sm.statusManager.UpdateStartingStatus(
-		ctx, workspace, readiness, snapshotStatus); err != nil {
-		return ctrl.Result{}, err
+	return sm.reconcileStartingWorkspace(ctx, workspace, snapshotStatus, readiness)
+}
+
+// diagnoseStartingWorkspace inspects the pods of a starting workspace, records the last
+// container termination in the status and returns the failure preventing the start, if any
+func (sm *StateMachine) diagnoseStartingWorkspace(
+	ctx context.Context,
+	workspace *workspacev1alpha1.Workspace) *PodFailure {
+	logger := logf.FromContext(ctx)
+
+	diagnosis, err := diagnoseWorkspacePods(ctx, sm.resourceManager.client, workspace)
+	if err != nil {
+		// diagnostics are best effort, the workspace keeps starting
+		logger.Error(err, "Failed to diagnose workspace pods")
+		return nil
+	}
+	if diagnosis.LastTermination != nil {
+		workspace.Status.LastTermination = diagnosis.LastTermination
+	}
+	if diagnosis.Failure == nil {
+		return nil
 	}
 
-	// Requeue to check resource readiness again later
-	return ctrl.Result{RequeueAfter: PollRequeueDelay}, nil
+	// only emit an event when the failure reason changes to avoid flooding on every poll.
+	// Once the startup timed out, the StartupFailed event already reported the failure of this attempt
+	degraded := meta.FindStatusCondition(workspace.Status.Conditions, ConditionTypeDegraded)
+	alreadyReported := degraded != nil && degraded.Status == metav1.ConditionTrue &&
+		(degraded.Reason == diagnosis.Failure.Reason || degraded.Reason == ReasonStartupFailed)
+	if !alreadyReported {
+		logger.Info("Workspace pod is failing to start",
+			"reason", diagnosis.Failure.Reason, "message", diagnosis.Failure.Message)
+		sm.recorder.Event(workspace, corev1.EventTypeWarning, diagnosis.Failure.Reason, diagnosis.Failure.Message)
+	}
+	return diagnosis.Failure
 }
 
 // handleIdleShutdownForRunningWorkspace handles idle shutdown logic for running workspaces
@@ -306,13 +378,17 @@ func (sm *StateMachine) handleIdleShutdownForRunningWorkspace(
 	// If idle shutdown is not enabled, no requeue needed
 	if idleConfig == nil || !idleConfig.Enabled {
 		logger.V(2).Info("Idle shutdown not enabled")
-		return ctrl.Result{}, nil
+		return ctrl.Result{}, sm.cancelIdleShutdown(ctx, workspace, ReasonIdleShutdownDisabled, "Idle shutdown was disabled")
 	}
 
 	logger.Info("Processing idle shutdown",
 		"enabled", idleConfig.Enabled,
 		"idleTimeoutInMinutes", idleConfig.IdleTimeoutInMinutes,
+		"gracePeriodInMinutes", idleConfig.GracePeriodInMinutes,
 		"hasHTTPGet", idleConfig.Detection.HTTPGet != nil,
+		"hasJupyterAPI", idleConfig.Detection.JupyterAPI != nil,
+		"hasMetrics", idleConfig.Detection.Metrics != nil,
+		"hasProxyActivity", idleConfig.Detection.ProxyActivity != nil,
 		"workspace", workspace.Name,
 		"namespace", workspace.Namespace)
 
@@ -338,16 +414,21 @@ func (sm *StateMachine) handleIdleShutdownForRunningWorkspace(
 		logger.Error(err, "Temporary failure checking idle status, will retry")
 	} else {
 		logger.V(1).Info("Successfully checked idle status", "isIdle", result.IsIdle)
+		sm.recordLastActivity(ctx, workspace, result.LastActivity)
 		if result.IsIdle {
-			logger.Info("Workspace idle timeout reached, stopping workspace",
+			logger.Info("Workspace idle timeout reached",
 				"timeout", idleConfig.IdleTimeoutInMinutes)
-			return sm.stopWorkspaceDueToIdle(ctx, workspace, idleConfig)
+			return sm.handleIdleWorkspace(ctx, workspace, idleConfig)
+		}
+		if err := sm.cancelIdleShutdown(ctx, workspace, ReasonActivityResumed, "Activity resumed, idle shutdown cancelled"); err != nil {
+			return ctrl.Result{}, err
 		}
 	}
 
 	// Requeue for next idle check
-	logger.V(1).Info("Scheduling next idle check", "interval", IdleCheckInterval)
-	return ctrl.Result{RequeueAfter: IdleCheckInterval}, nil
+	interval := idleCheckDelay(workspace, idleConfig)
+	logger.V(1).Info("Scheduling next idle check", "interval", interval)
+	return ctrl.Result{RequeueAfter: interval}, nil
 }
 
 // stopWorkspaceDueToIdle stops the workspace due to idle timeout
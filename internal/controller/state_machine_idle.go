@@ -0,0 +1,186 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// handleIdleWorkspace stops an idle workspace, warning its users first when a grace period is configured
+func (sm *StateMachine) handleIdleWorkspace(
+	ctx context.Context,
+	workspace *workspacev1alpha1.Workspace,
+	idleConfig *workspacev1alpha1.IdleShutdownSpec) (ctrl.Result, error) {
+	logger := logf.FromContext(ctx).WithValues("workspace", workspace.Name)
+
+	gracePeriod := time.Duration(idleConfig.GracePeriodInMinutes) * time.Minute
+	if gracePeriod <= 0 {
+		return sm.stopWorkspaceDueToIdle(ctx, workspace, idleConfig)
+	}
+
+	shutdownAt, pending := idleShutdownTime(workspace, idleConfig)
+	if !pending {
+		return sm.startIdleShutdownGracePeriod(ctx, workspace, gracePeriod)
+	}
+
+	if remaining := time.Until(shutdownAt); remaining > 0 {
+		logger.V(1).Info("Idle shutdown pending", "shutdownAt", shutdownAt, "remaining", remaining)
+		return ctrl.Result{RequeueAfter: min(remaining, IdleCheckInterval)}, nil
+	}
+
+	logger.Info("Idle shutdown grace period elapsed, stopping workspace", "gracePeriod", gracePeriod)
+	return sm.stopWorkspaceDueToIdle(ctx, workspace, idleConfig)
+}
+
+// startIdleShutdownGracePeriod sets the IdleShutdownPending condition, records a warning event
+// and writes the scheduled shutdown time to the workspace pod
+func (sm *StateMachine) startIdleShutdownGracePeriod(
+	ctx context.Context,
+	workspace *workspacev1alpha1.Workspace,
+	gracePeriod time.Duration) (ctrl.Result, error) {
+	logger := logf.FromContext(ctx).WithValues("workspace", workspace.Name)
+
+	// Conditions are stored with a precision of one second, the shutdown time is derived from them
+	now := time.Now().Truncate(time.Second)
+	shutdownAt := now.Add(gracePeriod)
+	message := fmt.Sprintf("Workspace is idle and will be stopped at %s unless activity resumes",
+		shutdownAt.UTC().Format(time.RFC3339))
+
+	meta.SetStatusCondition(&workspace.Status.Conditions, metav1.Condition{
+		Type:               ConditionTypeIdleShutdownPending,
+		Status:             metav1.ConditionTrue,
+		Reason:             ReasonIdleTimeoutReached,
+		Message:            message,
+		LastTransitionTime: metav1.NewTime(now),
+	})
+	if err := sm.resourceManager.client.Status().Update(ctx, workspace); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to set idle shutdown pending condition: %w", err)
+	}
+
+	sm.recorder.Event(workspace, corev1.EventTypeWarning, "IdleShutdownPending", message)
+	logger.Info("Idle shutdown pending", "shutdownAt", shutdownAt)
+
+	// The notice is informational, the grace period applies even if it cannot be written
+	if err := sm.setIdleShutdownNotice(ctx, workspace, &shutdownAt); err != nil {
+		logger.Error(err, "Failed to write idle shutdown notice to workspace pod")
+	}
+
+	return ctrl.Result{RequeueAfter: min(gracePeriod, IdleCheckInterval)}, nil
+}
+
+// cancelIdleShutdown clears a pending idle shutdown, if any
+func (sm *StateMachine) cancelIdleShutdown(
+	ctx context.Context,
+	workspace *workspacev1alpha1.Workspace,
+	reason, message string) error {
+	logger := logf.FromContext(ctx).WithValues("workspace", workspace.Name)
+
+	if !meta.IsStatusConditionTrue(workspace.Status.Conditions, ConditionTypeIdleShutdownPending) {
+		return nil
+	}
+
+	meta.SetStatusCondition(&workspace.Status.Conditions, metav1.Condition{
+		Type:    ConditionTypeIdleShutdownPending,
+		Status:  metav1.ConditionFalse,
+		Reason:  reason,
+		Message: message,
+	})
+	if err := sm.resourceManager.client.Status().Update(ctx, workspace); err != nil {
+		return fmt.Errorf("failed to clear idle shutdown pending condition: %w", err)
+	}
+
+	sm.recorder.Event(workspace, corev1.EventTypeNormal, "IdleShutdownCancelled", message)
+	logger.Info("Idle shutdown cancelled", "reason", reason)
+
+	if err := sm.setIdleShutdownNotice(ctx, workspace, nil); err != nil {
+		logger.Error(err, "Failed to clear idle shutdown notice from workspace pod")
+	}
+	return nil
+}
+
+// setIdleShutdownNotice annotates the workspace pod with the scheduled shutdown time, or removes
+// the annotation when shutdownAt is nil. The annotation is mounted in the workspace container
+// through a downward API volume so that the workspace UI can display it
+func (sm *StateMachine) setIdleShutdownNotice(ctx context.Context, workspace *workspacev1alpha1.Workspace, shutdownAt *time.Time) error {
+	pod, err := sm.idleChecker.findWorkspacePod(ctx, workspace)
+	if err != nil {
+		return err
+	}
+
+	patch := client.MergeFrom(pod.DeepCopy())
+	if shutdownAt == nil {
+		if _, ok := pod.Annotations[AnnotationIdleShutdownAt]; !ok {
+			return nil
+		}
+		delete(pod.Annotations, AnnotationIdleShutdownAt)
+	} else {
+		if pod.Annotations == nil {
+			pod.Annotations = map[string]string{}
+		}
+		pod.Annotations[AnnotationIdleShutdownAt] = shutdownAt.UTC().Format(time.RFC3339)
+	}
+
+	if err := sm.resourceManager.client.Patch(ctx, pod, patch); err != nil {
+		return fmt.Errorf("failed to patch pod %s: %w", pod.Name, err)
+	}
+	return nil
+}
+
+// idleShutdownTime returns the time at which a pending idle shutdown stops the workspace,
+// and false when no idle shutdown is pending
+func idleShutdownTime(workspace *workspacev1alpha1.Workspace, idleConfig *workspacev1alpha1.IdleShutdownSpec) (time.Time, bool) {
+	pending := meta.FindStatusCondition(workspace.Status.Conditions, ConditionTypeIdleShutdownPending)
+	if pending == nil || pending.Status != metav1.ConditionTrue {
+		return time.Time{}, false
+	}
+	gracePeriod := time.Duration(idleConfig.GracePeriodInMinutes) * time.Minute
+	return pending.LastTransitionTime.Add(gracePeriod), true
+}
+
+// idleCheckDelay returns the delay until the next idle check, which happens no later
+// than the end of a pending grace period
+func idleCheckDelay(workspace *workspacev1alpha1.Workspace, idleConfig *workspacev1alpha1.IdleShutdownSpec) time.Duration {
+	shutdownAt, pending := idleShutdownTime(workspace, idleConfig)
+	if !pending {
+		return IdleCheckInterval
+	}
+	remaining := time.Until(shutdownAt)
+	if remaining <= 0 {
+		return MinimalRequeueDelay
+	}
+	return min(remaining, IdleCheckInterval)
+}
+
+// recordLastActivity sets status.lastActivityTime to the activity reported by the idle detector
+// when it is more recent than the recorded one. The status is patched so that activity recorded
+// concurrently by the auth middleware is not overwritten with a stale resource version
+func (sm *StateMachine) recordLastActivity(ctx context.Context, workspace *workspacev1alpha1.Workspace, lastActivity time.Time) {
+	if lastActivity.IsZero() {
+		return
+	}
+	// Status times are stored with a precision of one second
+	at := metav1.NewTime(lastActivity.Truncate(time.Second))
+	if recorded := workspace.Status.LastActivityTime; recorded != nil && !at.After(recorded.Time) {
+		return
+	}
+
+	patch := client.MergeFrom(workspace.DeepCopy())
+	workspace.Status.LastActivityTime = &at
+	if err := sm.resourceManager.client.Status().Patch(ctx, workspace, patch); err != nil {
+		// best effort, the next idle check records it again
+		logf.FromContext(ctx).Error(err, "Failed to record workspace last activity", "workspace", workspace.Name)
+	}
+}
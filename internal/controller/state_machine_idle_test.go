@@ -0,0 +1,204 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+)
+
+// newIdleGraceTestStateMachine creates a state machine backed by a fake client holding the workspace and its pod
+func newIdleGraceTestStateMachine(t *testing.T, workspace *workspacev1alpha1.Workspace) (*StateMachine, client.Client, *record.FakeRecorder) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, workspacev1alpha1.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+
+	pod := createTestPod()
+	pod.Labels = GenerateLabels(workspace.Name)
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(workspace, pod).
+		WithStatusSubresource(workspace).
+		Build()
+	recorder := record.NewFakeRecorder(10)
+
+	sm := NewStateMachine(
+		&ResourceManager{client: fakeClient},
+		NewStatusManager(fakeClient),
+		recorder,
+		NewWorkspaceIdleChecker(fakeClient),
+	)
+	return sm, fakeClient, recorder
+}
+
+func createGracePeriodWorkspace(gracePeriodInMinutes int) *workspacev1alpha1.Workspace {
+	idleConfig := createProxyIdleConfig()
+	idleConfig.GracePeriodInMinutes = gracePeriodInMinutes
+	return &workspacev1alpha1.Workspace{
+		ObjectMeta: metav1.ObjectMeta{Name: testWorkspaceName, Namespace: "default"},
+		Spec: workspacev1alpha1.WorkspaceSpec{
+			DesiredStatus: DesiredStateRunning,
+			IdleShutdown:  idleConfig,
+		},
+	}
+}
+
+// setIdleShutdownPending marks the idle shutdown of the workspace as pending since the given time
+func setIdleShutdownPending(workspace *workspacev1alpha1.Workspace, since time.Time) {
+	meta.SetStatusCondition(&workspace.Status.Conditions, metav1.Condition{
+		Type:               ConditionTypeIdleShutdownPending,
+		Status:             metav1.ConditionTrue,
+		Reason:             ReasonIdleTimeoutReached,
+		Message:            "Workspace is idle",
+		LastTransitionTime: metav1.NewTime(since),
+	})
+}
+
+func getTestWorkspaceAndPod(t *testing.T, c client.Client) (*workspacev1alpha1.Workspace, *corev1.Pod) {
+	workspace := &workspacev1alpha1.Workspace{}
+	require.NoError(t, c.Get(context.Background(), client.ObjectKey{Name: testWorkspaceName, Namespace: "default"}, workspace))
+	pod := &corev1.Pod{}
+	require.NoError(t, c.Get(context.Background(), client.ObjectKey{Name: testPodName, Namespace: "default"}, pod))
+	return workspace, pod
+}
+
+func TestHandleIdleWorkspace_NoGracePeriodStopsImmediately(t *testing.T) {
+	workspace := createGracePeriodWorkspace(0)
+	sm, c, _ := newIdleGraceTestStateMachine(t, workspace)
+
+	_, err := sm.handleIdleWorkspace(context.Background(), workspace, workspace.Spec.IdleShutdown)
+	require.NoError(t, err)
+
+	updated, _ := getTestWorkspaceAndPod(t, c)
+	assert.Equal(t, DesiredStateStopped, updated.Spec.DesiredStatus)
+	assert.Nil(t, meta.FindStatusCondition(updated.Status.Conditions, ConditionTypeIdleShutdownPending))
+}
+
+func TestHandleIdleWorkspace_StartsGracePeriod(t *testing.T) {
+	workspace := createGracePeriodWorkspace(10)
+	sm, c, recorder := newIdleGraceTestStateMachine(t, workspace)
+
+	result, err := sm.handleIdleWorkspace(context.Background(), workspace, workspace.Spec.IdleShutdown)
+	require.NoError(t, err)
+	assert.Equal(t, IdleCheckInterval, result.RequeueAfter)
+
+	updated, pod := getTestWorkspaceAndPod(t, c)
+	assert.Equal(t, DesiredStateRunning, updated.Spec.DesiredStatus)
+	assert.True(t, meta.IsStatusConditionTrue(updated.Status.Conditions, ConditionTypeIdleShutdownPending))
+
+	shutdownAt, pending := idleShutdownTime(updated, updated.Spec.IdleShutdown)
+	require.True(t, pending)
+	assert.WithinDuration(t, time.Now().Add(10*time.Minute), shutdownAt, 2*time.Second)
+	assert.Equal(t, shutdownAt.UTC().Format(time.RFC3339), pod.Annotations[AnnotationIdleShutdownAt])
+
+	require.Len(t, recorder.Events, 1)
+	event := <-recorder.Events
+	assert.Contains(t, event, "IdleShutdownPending")
+	assert.Contains(t, event, shutdownAt.UTC().Format(time.RFC3339))
+}
+
+func TestHandleIdleWorkspace_WaitsDuringGracePeriod(t *testing.T) {
+	workspace := createGracePeriodWorkspace(10)
+	setIdleShutdownPending(workspace, time.Now().Add(-8*time.Minute))
+	sm, c, _ := newIdleGraceTestStateMachine(t, workspace)
+
+	result, err := sm.handleIdleWorkspace(context.Background(), workspace, workspace.Spec.IdleShutdown)
+	require.NoError(t, err)
+	assert.InDelta(t, 2*time.Minute, result.RequeueAfter, float64(2*time.Second))
+
+	updated, _ := getTestWorkspaceAndPod(t, c)
+	assert.Equal(t, DesiredStateRunning, updated.Spec.DesiredStatus)
+}
+
+func TestHandleIdleWorkspace_StopsAfterGracePeriod(t *testing.T) {
+	workspace := createGracePeriodWorkspace(10)
+	setIdleShutdownPending(workspace, time.Now().Add(-11*time.Minute))
+	sm, c, _ := newIdleGraceTestStateMachine(t, workspace)
+
+	_, err := sm.handleIdleWorkspace(context.Background(), workspace, workspace.Spec.IdleShutdown)
+	require.NoError(t, err)
+
+	updated, _ := getTestWorkspaceAndPod(t, c)
+	assert.Equal(t, DesiredStateStopped, updated.Spec.DesiredStatus)
+}
+
+func TestCancelIdleShutdown_ClearsConditionAndNotice(t *testing.T) {
+	workspace := createGracePeriodWorkspace(10)
+	setIdleShutdownPending(workspace, time.Now().Add(-time.Minute))
+	sm, c, recorder := newIdleGraceTestStateMachine(t, workspace)
+
+	_, pod := getTestWorkspaceAndPod(t, c)
+	pod.Annotations = map[string]string{AnnotationIdleShutdownAt: "2025-01-01T00:00:00Z"}
+	require.NoError(t, c.Update(context.Background(), pod))
+
+	err := sm.cancelIdleShutdown(context.Background(), workspace, ReasonActivityResumed, "Activity resumed")
+	require.NoError(t, err)
+
+	updated, pod := getTestWorkspaceAndPod(t, c)
+	condition := meta.FindStatusCondition(updated.Status.Conditions, ConditionTypeIdleShutdownPending)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, ReasonActivityResumed, condition.Reason)
+	assert.NotContains(t, pod.Annotations, AnnotationIdleShutdownAt)
+
+	require.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, "IdleShutdownCancelled")
+}
+
+func TestCancelIdleShutdown_NoopWhenNotPending(t *testing.T) {
+	workspace := createGracePeriodWorkspace(10)
+	sm, c, recorder := newIdleGraceTestStateMachine(t, workspace)
+
+	require.NoError(t, sm.cancelIdleShutdown(context.Background(), workspace, ReasonActivityResumed, "Activity resumed"))
+
+	updated, _ := getTestWorkspaceAndPod(t, c)
+	assert.Nil(t, meta.FindStatusCondition(updated.Status.Conditions, ConditionTypeIdleShutdownPending))
+	assert.Empty(t, recorder.Events)
+}
+
+func TestIdleCheckDelay(t *testing.T) {
+	workspace := createGracePeriodWorkspace(10)
+	assert.Equal(t, IdleCheckInterval, idleCheckDelay(workspace, workspace.Spec.IdleShutdown))
+
+	setIdleShutdownPending(workspace, time.Now().Add(-9*time.Minute))
+	assert.InDelta(t, time.Minute, idleCheckDelay(workspace, workspace.Spec.IdleShutdown), float64(2*time.Second))
+
+	elapsed := createGracePeriodWorkspace(10)
+	setIdleShutdownPending(elapsed, time.Now().Add(-time.Hour))
+	assert.Equal(t, MinimalRequeueDelay, idleCheckDelay(elapsed, elapsed.Spec.IdleShutdown))
+}
+
+func TestRecordLastActivity(t *testing.T) {
+	recorded := time.Now().Add(-time.Hour).Truncate(time.Second)
+	workspace := createGracePeriodWorkspace(0)
+	workspace.Status.LastActivityTime = &metav1.Time{Time: recorded}
+	sm, c, _ := newIdleGraceTestStateMachine(t, workspace)
+
+	// older or unknown activity is ignored
+	sm.recordLastActivity(context.Background(), workspace, recorded.Add(-time.Minute))
+	sm.recordLastActivity(context.Background(), workspace, time.Time{})
+	updated, _ := getTestWorkspaceAndPod(t, c)
+	assert.True(t, updated.Status.LastActivityTime.Equal(&metav1.Time{Time: recorded}))
+
+	latest := time.Now()
+	sm.recordLastActivity(context.Background(), workspace, latest)
+	updated, _ = getTestWorkspaceAndPod(t, c)
+	assert.Equal(t, latest.Truncate(time.Second).Unix(), updated.Status.LastActivityTime.Unix())
+}
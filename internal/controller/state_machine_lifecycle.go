@@ -0,0 +1,180 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// lifecycleDeletion describes when and why the lifecycle policy deletes a workspace
+type lifecycleDeletion struct {
+	at     time.Time
+	reason string
+}
+
+// reconcileLifecyclePolicy deletes the workspace once its lifecycle policy expires,
+// and warns during the preceding warning period with the DeletionScheduled condition and an event
+// emitted when the condition changes.
+// Returns true when the workspace was deleted.
+func (sm *StateMachine) reconcileLifecyclePolicy(ctx context.Context, workspace *workspacev1alpha1.Workspace) (bool, error) {
+	logger := logf.FromContext(ctx).WithValues("workspace", workspace.Name)
+
+	deletion := sm.nextLifecycleDeletion(workspace)
+	if deletion == nil {
+		return false, sm.setLifecycleDeletionCondition(ctx, workspace, nil)
+	}
+	policy := workspace.Spec.LifecyclePolicy
+
+	if time.Now().Before(deletion.at) {
+		if time.Now().Before(lifecycleWarningTime(policy, deletion)) {
+			return false, sm.setLifecycleDeletionCondition(ctx, workspace, nil)
+		}
+		condition := NewCondition(ConditionTypeDeletionScheduled, metav1.ConditionTrue, ReasonLifecycleDeletionScheduled,
+			fmt.Sprintf("Workspace will be deleted at %s: %s", deletion.at.UTC().Format(time.RFC3339), deletion.reason))
+		return false, sm.setLifecycleDeletionCondition(ctx, workspace, &condition)
+	}
+
+	if isDeletionProtected(workspace) {
+		logger.V(1).Info("Skipping lifecycle deletion of protected workspace", "reason", deletion.reason)
+		condition := NewCondition(ConditionTypeDeletionScheduled, metav1.ConditionFalse, ReasonLifecycleDeletionSkipped,
+			fmt.Sprintf("Workspace is not deleted because of the %s annotation: %s", AnnotationDeletionProtection, deletion.reason))
+		return false, sm.setLifecycleDeletionCondition(ctx, workspace, &condition)
+	}
+
+	// Record the deletion so that the finalizer retains the storage when the policy asks for it
+	if workspace.Annotations[AnnotationLifecycleDeletion] == "" {
+		if workspace.Annotations == nil {
+			workspace.Annotations = map[string]string{}
+		}
+		workspace.Annotations[AnnotationLifecycleDeletion] = deletion.reason
+		if err := sm.resourceManager.client.Update(ctx, workspace); err != nil {
+			return false, fmt.Errorf("failed to record lifecycle deletion: %w", err)
+		}
+	}
+
+	logger.Info("Deleting workspace per lifecycle policy", "reason", deletion.reason, "retainStorage", policy.RetainStorage)
+	sm.recorder.Event(workspace, corev1.EventTypeWarning, "LifecycleDeletion",
+		fmt.Sprintf("Deleting workspace: %s", deletion.reason))
+
+	if err := sm.resourceManager.client.Delete(ctx, workspace); err != nil && !errors.IsNotFound(err) {
+		return false, fmt.Errorf("failed to delete workspace: %w", err)
+	}
+	return true, nil
+}
+
+// setLifecycleDeletionCondition sets the DeletionScheduled condition of the workspace, or removes it when nil,
+// and persists the status when it changed. An event is emitted when the condition message changes.
+func (sm *StateMachine) setLifecycleDeletionCondition(
+	ctx context.Context,
+	workspace *workspacev1alpha1.Workspace,
+	condition *metav1.Condition) error {
+	existing := meta.FindStatusCondition(workspace.Status.Conditions, ConditionTypeDeletionScheduled)
+	if condition == nil {
+		if existing == nil {
+			return nil
+		}
+		meta.RemoveStatusCondition(&workspace.Status.Conditions, ConditionTypeDeletionScheduled)
+	} else {
+		if existing != nil && existing.Message == condition.Message {
+			return nil
+		}
+		eventType := corev1.EventTypeWarning
+		if condition.Status == metav1.ConditionFalse {
+			eventType = corev1.EventTypeNormal
+		}
+		sm.recorder.Event(workspace, eventType, condition.Reason, condition.Message)
+		meta.SetStatusCondition(&workspace.Status.Conditions, *condition)
+	}
+
+	if err := sm.resourceManager.client.Status().Update(ctx, workspace); err != nil {
+		return fmt.Errorf("failed to update lifecycle deletion condition: %w", err)
+	}
+	return nil
+}
+
+// nextLifecycleDeletion returns the earliest deletion required by the workspace lifecycle policy,
+// or nil when the policy does not require any
+func (sm *StateMachine) nextLifecycleDeletion(workspace *workspacev1alpha1.Workspace) *lifecycleDeletion {
+	policy := workspace.Spec.LifecyclePolicy
+	if policy == nil {
+		return nil
+	}
+
+	var deletion *lifecycleDeletion
+	if policy.MaxLifetimeInDays != nil {
+		days := *policy.MaxLifetimeInDays
+		deletion = &lifecycleDeletion{
+			at:     workspace.CreationTimestamp.Add(daysToDuration(days)),
+			reason: fmt.Sprintf("maximum lifetime of %d days reached", days),
+		}
+	}
+
+	// Only workspaces that are meant to stay stopped count as stopped
+	stopped := workspace.Status.StoppedAt != nil && sm.getDesiredStatus(workspace) == DesiredStateStopped
+	if policy.DeleteAfterStoppedInDays != nil && stopped {
+		days := *policy.DeleteAfterStoppedInDays
+		at := workspace.Status.StoppedAt.Add(daysToDuration(days))
+		if deletion == nil || at.Before(deletion.at) {
+			deletion = &lifecycleDeletion{
+				at:     at,
+				reason: fmt.Sprintf("stopped for %d days", days),
+			}
+		}
+	}
+	return deletion
+}
+
+// requeueForLifecyclePolicy shortens the requeue delay so that the next reconciliation
+// happens right when the deletion warning period starts or when the workspace must be deleted
+func (sm *StateMachine) requeueForLifecyclePolicy(
+	workspace *workspacev1alpha1.Workspace,
+	result ctrl.Result,
+	err error) (ctrl.Result, error) {
+	if err != nil {
+		return result, err
+	}
+
+	deletion := sm.nextLifecycleDeletion(workspace)
+	if deletion == nil || isDeletionProtected(workspace) {
+		return result, nil
+	}
+
+	next := deletion.at
+	if warningAt := lifecycleWarningTime(workspace.Spec.LifecyclePolicy, deletion); time.Now().Before(warningAt) {
+		next = warningAt
+	}
+
+	untilNext := time.Until(next) + ScheduleRequeueSlack
+	if result.RequeueAfter == 0 || untilNext < result.RequeueAfter {
+		result.RequeueAfter = untilNext
+	}
+	return result, nil
+}
+
+// lifecycleWarningTime returns the time from which the upcoming deletion is announced
+func lifecycleWarningTime(policy *workspacev1alpha1.LifecyclePolicySpec, deletion *lifecycleDeletion) time.Time {
+	return deletion.at.Add(-time.Duration(policy.DeletionWarningInHours) * time.Hour)
+}
+
+// isDeletionProtected returns true when the workspace opted out of deletion by its lifecycle policy
+func isDeletionProtected(workspace *workspacev1alpha1.Workspace) bool {
+	return workspace.Annotations[AnnotationDeletionProtection] == "true"
+}
+
+// daysToDuration converts a number of days to a duration
+func daysToDuration(days int32) time.Duration {
+	return time.Duration(days) * 24 * time.Hour
+}
@@ -0,0 +1,230 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+)
+
+// newLifecycleTestStateMachine creates a state machine backed by a fake client holding the given objects
+func newLifecycleTestStateMachine(t *testing.T, objects ...client.Object) (*StateMachine, client.Client, *record.FakeRecorder) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, workspacev1alpha1.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objects...).
+		WithStatusSubresource(&workspacev1alpha1.Workspace{}).
+		Build()
+	recorder := record.NewFakeRecorder(10)
+
+	sm := NewStateMachine(
+		&ResourceManager{client: fakeClient, scheme: scheme},
+		NewStatusManager(fakeClient),
+		recorder,
+		NewWorkspaceIdleChecker(fakeClient),
+	)
+	return sm, fakeClient, recorder
+}
+
+// createStoppedWorkspace returns a workspace stopped since the given time, deleted after 30 stopped days
+func createStoppedWorkspace(stoppedAt time.Time) *workspacev1alpha1.Workspace {
+	days := int32(30)
+	return &workspacev1alpha1.Workspace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              testWorkspaceName,
+			Namespace:         "default",
+			UID:               "workspace-uid",
+			CreationTimestamp: metav1.NewTime(stoppedAt.Add(-time.Hour)),
+		},
+		Spec: workspacev1alpha1.WorkspaceSpec{
+			DesiredStatus: DesiredStateStopped,
+			LifecyclePolicy: &workspacev1alpha1.LifecyclePolicySpec{
+				DeleteAfterStoppedInDays: &days,
+				DeletionWarningInHours:   24,
+			},
+		},
+		Status: workspacev1alpha1.WorkspaceStatus{
+			StoppedAt: &metav1.Time{Time: stoppedAt},
+		},
+	}
+}
+
+func workspaceExists(t *testing.T, c client.Client) bool {
+	err := c.Get(context.Background(), client.ObjectKey{Name: testWorkspaceName, Namespace: "default"}, &workspacev1alpha1.Workspace{})
+	if apierrors.IsNotFound(err) {
+		return false
+	}
+	require.NoError(t, err)
+	return true
+}
+
+func TestReconcileLifecyclePolicy_NotExpired(t *testing.T) {
+	workspace := createStoppedWorkspace(time.Now().Add(-10 * 24 * time.Hour))
+	sm, c, recorder := newLifecycleTestStateMachine(t, workspace)
+
+	deleted, err := sm.reconcileLifecyclePolicy(context.Background(), workspace)
+	require.NoError(t, err)
+	assert.False(t, deleted)
+	assert.True(t, workspaceExists(t, c))
+	assert.Empty(t, recorder.Events)
+}
+
+func TestReconcileLifecyclePolicy_WarnsBeforeDeletion(t *testing.T) {
+	workspace := createStoppedWorkspace(time.Now().Add(-30*24*time.Hour + 2*time.Hour))
+	sm, c, recorder := newLifecycleTestStateMachine(t, workspace)
+
+	deleted, err := sm.reconcileLifecyclePolicy(context.Background(), workspace)
+	require.NoError(t, err)
+	assert.False(t, deleted)
+	assert.True(t, workspaceExists(t, c))
+
+	require.Len(t, recorder.Events, 1)
+	event := <-recorder.Events
+	assert.Contains(t, event, "LifecycleDeletionScheduled")
+	assert.Contains(t, event, "stopped for 30 days")
+	scheduled := meta.FindStatusCondition(workspace.Status.Conditions, ConditionTypeDeletionScheduled)
+	require.NotNil(t, scheduled)
+	assert.Equal(t, ReasonLifecycleDeletionScheduled, scheduled.Reason)
+
+	// The warning is only emitted when the scheduled deletion changes
+	_, err = sm.reconcileLifecyclePolicy(context.Background(), workspace)
+	require.NoError(t, err)
+	assert.Empty(t, recorder.Events)
+
+	// The condition is removed once the workspace is no longer about to be deleted
+	workspace.Spec.DesiredStatus = DesiredStateRunning
+	_, err = sm.reconcileLifecyclePolicy(context.Background(), workspace)
+	require.NoError(t, err)
+	assert.Nil(t, meta.FindStatusCondition(workspace.Status.Conditions, ConditionTypeDeletionScheduled))
+}
+
+func TestReconcileLifecyclePolicy_DeletesLongStoppedWorkspace(t *testing.T) {
+	workspace := createStoppedWorkspace(time.Now().Add(-31 * 24 * time.Hour))
+	sm, c, recorder := newLifecycleTestStateMachine(t, workspace)
+
+	deleted, err := sm.reconcileLifecyclePolicy(context.Background(), workspace)
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	assert.False(t, workspaceExists(t, c))
+
+	require.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, "LifecycleDeletion")
+}
+
+func TestReconcileLifecyclePolicy_IgnoresStopTimeWhenDesiredRunning(t *testing.T) {
+	workspace := createStoppedWorkspace(time.Now().Add(-31 * 24 * time.Hour))
+	workspace.Spec.DesiredStatus = DesiredStateRunning
+	sm, c, _ := newLifecycleTestStateMachine(t, workspace)
+
+	deleted, err := sm.reconcileLifecyclePolicy(context.Background(), workspace)
+	require.NoError(t, err)
+	assert.False(t, deleted)
+	assert.True(t, workspaceExists(t, c))
+}
+
+func TestReconcileLifecyclePolicy_MaxLifetime(t *testing.T) {
+	lifetime := int32(7)
+	workspace := createStoppedWorkspace(time.Now())
+	workspace.Spec.DesiredStatus = DesiredStateRunning
+	workspace.Spec.LifecyclePolicy.MaxLifetimeInDays = &lifetime
+	workspace.CreationTimestamp = metav1.NewTime(time.Now().Add(-8 * 24 * time.Hour))
+	sm, c, recorder := newLifecycleTestStateMachine(t, workspace)
+
+	deleted, err := sm.reconcileLifecyclePolicy(context.Background(), workspace)
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	assert.False(t, workspaceExists(t, c))
+	assert.Contains(t, <-recorder.Events, "maximum lifetime of 7 days reached")
+}
+
+func TestReconcileLifecyclePolicy_HonorsDeletionProtection(t *testing.T) {
+	workspace := createStoppedWorkspace(time.Now().Add(-31 * 24 * time.Hour))
+	workspace.Annotations = map[string]string{AnnotationDeletionProtection: "true"}
+	sm, c, recorder := newLifecycleTestStateMachine(t, workspace)
+
+	deleted, err := sm.reconcileLifecyclePolicy(context.Background(), workspace)
+	require.NoError(t, err)
+	assert.False(t, deleted)
+	assert.True(t, workspaceExists(t, c))
+	assert.Contains(t, <-recorder.Events, "LifecycleDeletionSkipped")
+
+	result, err := sm.requeueForLifecyclePolicy(workspace, ctrl.Result{}, nil)
+	require.NoError(t, err)
+	assert.Zero(t, result.RequeueAfter)
+}
+
+func TestReconcileLifecyclePolicy_RetainsStorage(t *testing.T) {
+	workspace := createStoppedWorkspace(time.Now().Add(-31 * 24 * time.Hour))
+	workspace.Spec.LifecyclePolicy.RetainStorage = true
+
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: GeneratePVCName(workspace.Name), Namespace: "default"},
+	}
+	sm, c, _ := newLifecycleTestStateMachine(t, workspace, pvc)
+	require.NoError(t, controllerutil.SetControllerReference(workspace, pvc, sm.resourceManager.scheme))
+	require.NoError(t, c.Update(context.Background(), pvc))
+
+	deleted, err := sm.reconcileLifecyclePolicy(context.Background(), workspace)
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	assert.Equal(t, "stopped for 30 days", workspace.Annotations[AnnotationLifecycleDeletion])
+
+	// The finalizer cleanup retains the PVC of workspaces deleted by the policy
+	require.NoError(t, sm.resourceManager.applyPVCRetentionPolicy(context.Background(), workspace))
+	remaining, err := sm.resourceManager.EnsurePVCDeleted(context.Background(), workspace)
+	require.NoError(t, err)
+	assert.Nil(t, remaining)
+	retained := &corev1.PersistentVolumeClaim{}
+	require.NoError(t, c.Get(context.Background(), client.ObjectKeyFromObject(pvc), retained))
+	assert.Empty(t, retained.OwnerReferences)
+	assert.True(t, IsRetainedPVC(retained))
+}
+
+func TestGetPVCRetention_OnlyRetainsStorageOnLifecycleDeletion(t *testing.T) {
+	workspace := createStoppedWorkspace(time.Now())
+	workspace.Spec.LifecyclePolicy.RetainStorage = true
+
+	retain, _ := getPVCRetention(workspace)
+	assert.False(t, retain)
+
+	workspace.Annotations = map[string]string{AnnotationLifecycleDeletion: "stopped for 30 days"}
+	retain, retainUntil := getPVCRetention(workspace)
+	assert.True(t, retain)
+	assert.Nil(t, retainUntil)
+}
+
+func TestRequeueForLifecyclePolicy(t *testing.T) {
+	// Deleted in 20 days, warned one day before
+	workspace := createStoppedWorkspace(time.Now().Add(-10 * 24 * time.Hour))
+	sm, _, _ := newLifecycleTestStateMachine(t, workspace)
+
+	result, err := sm.requeueForLifecyclePolicy(workspace, ctrl.Result{}, nil)
+	require.NoError(t, err)
+	assert.InDelta(t, 19*24*time.Hour, result.RequeueAfter, float64(time.Minute))
+
+	result, err = sm.requeueForLifecyclePolicy(workspace, ctrl.Result{RequeueAfter: time.Minute}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, result.RequeueAfter)
+}
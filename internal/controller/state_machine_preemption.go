@@ -0,0 +1,224 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// recordPreemption adds the last preemption of the workspace to its status history and sets
+// the Preempted condition, or resets the restarts of a workspace stopped for another reason.
+// The status is persisted by the stopped status update that follows.
+func (sm *StateMachine) recordPreemption(ctx context.Context, workspace *workspacev1alpha1.Workspace) {
+	preemptedAt, ok := getPreemptionTime(workspace)
+	if !ok {
+		// a workspace stopped other than by a preemption starts over with its restarts,
+		// so that its next start does not use the priority class of preemption restarts
+		workspace.Status.PreemptionRestarts = 0
+		cancelPendingPreemptionRestart(ctx, workspace)
+		return
+	}
+	history := workspace.Status.Preemptions
+	var last *workspacev1alpha1.PreemptionRecord
+	if len(history) > 0 {
+		last = &history[len(history)-1]
+		if !last.PreemptedAt.Time.Before(preemptedAt) {
+			// already recorded
+			return
+		}
+	}
+
+	// a workspace that ran long enough since its last preemption starts over with its restart attempts
+	startedAt := workspace.Status.StartedAt
+	if startedAt != nil && preemptedAt.Sub(startedAt.Time) >= PreemptionRestartResetAfter &&
+		(last == nil || startedAt.After(last.PreemptedAt.Time)) {
+		workspace.Status.PreemptionRestarts = 0
+	}
+
+	history = append(history, workspacev1alpha1.PreemptionRecord{PreemptedAt: metav1.NewTime(preemptedAt)})
+	if len(history) > MaxPreemptionHistory {
+		history = history[len(history)-MaxPreemptionHistory:]
+	}
+	workspace.Status.Preemptions = history
+
+	logf.FromContext(ctx).Info("Recorded workspace preemption",
+		"preemptedAt", preemptedAt, "restarts", workspace.Status.PreemptionRestarts)
+
+	policy := workspace.Spec.PreemptionRestart
+	if policy == nil || !policy.Enabled {
+		meta.SetStatusCondition(&workspace.Status.Conditions, NewCondition(
+			ConditionTypePreempted,
+			metav1.ConditionTrue,
+			ReasonPreempted,
+			PreemptedReason,
+		))
+		return
+	}
+
+	maxAttempts := preemptionRestartMaxAttempts(policy)
+	if workspace.Status.PreemptionRestarts >= maxAttempts {
+		message := fmt.Sprintf("Workspace was preempted after %d automatic restarts, it will not be restarted again",
+			workspace.Status.PreemptionRestarts)
+		meta.SetStatusCondition(&workspace.Status.Conditions, NewCondition(
+			ConditionTypePreempted,
+			metav1.ConditionTrue,
+			ReasonPreemptionRestartsExhausted,
+			message,
+		))
+		sm.recorder.Event(workspace, corev1.EventTypeWarning, ReasonPreemptionRestartsExhausted, message)
+		return
+	}
+
+	attempt := workspace.Status.PreemptionRestarts + 1
+	meta.SetStatusCondition(&workspace.Status.Conditions, NewCondition(
+		ConditionTypePreempted,
+		metav1.ConditionTrue,
+		ReasonPreempted,
+		fmt.Sprintf("%s, restarting in %s (attempt %d of %d)",
+			PreemptedReason, preemptionRestartBackoff(policy, attempt), attempt, maxAttempts),
+	))
+}
+
+// cancelPendingPreemptionRestart updates the Preempted condition of a workspace whose pending automatic restart
+// was canceled by a user, which removes the preemption annotations while keeping the workspace stopped
+func cancelPendingPreemptionRestart(ctx context.Context, workspace *workspacev1alpha1.Workspace) {
+	policy := workspace.Spec.PreemptionRestart
+	preempted := meta.FindStatusCondition(workspace.Status.Conditions, ConditionTypePreempted)
+	if policy == nil || !policy.Enabled || preempted == nil ||
+		preempted.Status != metav1.ConditionTrue || preempted.Reason != ReasonPreempted {
+		return
+	}
+	logf.FromContext(ctx).Info("Automatic restart of the preempted workspace was canceled")
+	meta.SetStatusCondition(&workspace.Status.Conditions, NewCondition(
+		ConditionTypePreempted,
+		metav1.ConditionTrue,
+		ReasonPreemptionRestartCanceled,
+		"Workspace was preempted, its automatic restart was canceled because it was stopped by a user",
+	))
+}
+
+// restartPreemptedWorkspace sets the desired status of a stopped, preempted workspace back to Running
+// once the backoff of its restart attempt elapsed
+func (sm *StateMachine) restartPreemptedWorkspace(
+	ctx context.Context,
+	workspace *workspacev1alpha1.Workspace,
+	result ctrl.Result) (ctrl.Result, error) {
+	policy := workspace.Spec.PreemptionRestart
+	if policy == nil || !policy.Enabled || workspace.Status.Phase != workspacev1alpha1.WorkspacePhaseStopped {
+		return result, nil
+	}
+	preemptedAt, ok := getPreemptionTime(workspace)
+	if !ok || len(workspace.Status.Preemptions) == 0 {
+		return result, nil
+	}
+	logger := logf.FromContext(ctx).WithValues("workspace", workspace.Name)
+
+	// the restart is counted first, so that a failed spec update does not count it twice
+	last := &workspace.Status.Preemptions[len(workspace.Status.Preemptions)-1]
+	if last.RestartedAt == nil {
+		maxAttempts := preemptionRestartMaxAttempts(policy)
+		if workspace.Status.PreemptionRestarts >= maxAttempts {
+			return result, nil
+		}
+		attempt := workspace.Status.PreemptionRestarts + 1
+		now := time.Now()
+		restartAt := preemptedAt.Add(preemptionRestartBackoff(policy, attempt))
+		if now.Before(restartAt) {
+			return ctrl.Result{RequeueAfter: restartAt.Sub(now)}, nil
+		}
+
+		blocked, err := sm.isStartBlockedByQuota(ctx, workspace)
+		if err != nil {
+			logger.Error(err, "Failed to check quotas before the preemption restart")
+			return ctrl.Result{}, err
+		}
+		if blocked {
+			// the restart is not counted, it is retried once the quota allows it
+			if err := sm.resourceManager.client.Status().Update(ctx, workspace); err != nil {
+				logger.Error(err, "Failed to record the quota blocking the preemption restart")
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{RequeueAfter: LongRequeueDelay}, nil
+		}
+
+		workspace.Status.PreemptionRestarts = attempt
+		last.RestartedAt = &metav1.Time{Time: now}
+		message := fmt.Sprintf("Workspace restarted after preemption (attempt %d of %d)", attempt, maxAttempts)
+		meta.SetStatusCondition(&workspace.Status.Conditions, NewCondition(
+			ConditionTypePreempted,
+			metav1.ConditionTrue,
+			ReasonPreemptionRestarted,
+			message,
+		))
+		if err := sm.resourceManager.client.Status().Update(ctx, workspace); err != nil {
+			logger.Error(err, "Failed to record preemption restart")
+			return ctrl.Result{}, err
+		}
+		sm.recorder.Event(workspace, corev1.EventTypeNormal, "PreemptionRestart", message)
+	}
+
+	workspace.Spec.DesiredStatus = DesiredStateRunning
+	delete(workspace.Annotations, PreemptionReasonAnnotation)
+	delete(workspace.Annotations, PreemptedAtAnnotation)
+	if err := sm.resourceManager.client.Update(ctx, workspace); err != nil {
+		logger.Error(err, "Failed to update workspace desired status")
+		return ctrl.Result{}, err
+	}
+	logger.Info("Updated workspace desired status to Running after preemption",
+		"restarts", workspace.Status.PreemptionRestarts)
+	return ctrl.Result{RequeueAfter: MinimalRequeueDelay}, nil
+}
+
+// getPreemptionTime returns the time of the last preemption of the workspace, if it is stopped by one
+func getPreemptionTime(workspace *workspacev1alpha1.Workspace) (time.Time, bool) {
+	if workspace.Annotations[PreemptionReasonAnnotation] != PreemptedReason {
+		return time.Time{}, false
+	}
+	preemptedAt, err := time.Parse(time.RFC3339, workspace.Annotations[PreemptedAtAnnotation])
+	if err != nil {
+		return time.Time{}, false
+	}
+	return preemptedAt, true
+}
+
+// preemptionRestartMaxAttempts returns the number of consecutive restarts allowed by the policy
+func preemptionRestartMaxAttempts(policy *workspacev1alpha1.PreemptionRestartPolicy) int32 {
+	if policy.MaxAttempts <= 0 {
+		return DefaultPreemptionRestartMaxAttempts
+	}
+	return policy.MaxAttempts
+}
+
+// preemptionRestartBackoff returns the exponential backoff before the given restart attempt
+func preemptionRestartBackoff(policy *workspacev1alpha1.PreemptionRestartPolicy, attempt int32) time.Duration {
+	delay := DefaultPreemptionRestartBackoff
+	if policy.BackoffSeconds > 0 {
+		delay = time.Duration(policy.BackoffSeconds) * time.Second
+	}
+	maxDelay := DefaultPreemptionRestartMaxBackoff
+	if policy.MaxBackoffSeconds > 0 {
+		maxDelay = time.Duration(policy.MaxBackoffSeconds) * time.Second
+	}
+	for i := int32(1); i < attempt; i++ {
+		delay *= 2
+		if delay >= maxDelay {
+			return maxDelay
+		}
+	}
+	if delay > maxDelay {
+		return maxDelay
+	}
+	return delay
+}
@@ -0,0 +1,256 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+)
+
+// createPreemptedWorkspace returns a stopped workspace preempted at the given time with restarts enabled
+func createPreemptedWorkspace(preemptedAt time.Time, restarts int32) *workspacev1alpha1.Workspace {
+	return &workspacev1alpha1.Workspace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testWorkspaceName,
+			Namespace: "default",
+			Annotations: map[string]string{
+				PreemptionReasonAnnotation: PreemptedReason,
+				PreemptedAtAnnotation:      preemptedAt.UTC().Format(time.RFC3339),
+			},
+		},
+		Spec: workspacev1alpha1.WorkspaceSpec{
+			DesiredStatus: DesiredStateStopped,
+			PreemptionRestart: &workspacev1alpha1.PreemptionRestartPolicy{
+				Enabled:           true,
+				MaxAttempts:       3,
+				BackoffSeconds:    30,
+				MaxBackoffSeconds: 600,
+				PriorityClassName: "workspace-high",
+			},
+		},
+		Status: workspacev1alpha1.WorkspaceStatus{
+			Phase:              workspacev1alpha1.WorkspacePhaseStopped,
+			PreemptionRestarts: restarts,
+		},
+	}
+}
+
+func TestPreemptionRestartBackoff(t *testing.T) {
+	policy := &workspacev1alpha1.PreemptionRestartPolicy{BackoffSeconds: 30, MaxBackoffSeconds: 100}
+	assert.Equal(t, 30*time.Second, preemptionRestartBackoff(policy, 1))
+	assert.Equal(t, 60*time.Second, preemptionRestartBackoff(policy, 2))
+	assert.Equal(t, 100*time.Second, preemptionRestartBackoff(policy, 3))
+
+	defaults := &workspacev1alpha1.PreemptionRestartPolicy{}
+	assert.Equal(t, DefaultPreemptionRestartBackoff, preemptionRestartBackoff(defaults, 1))
+	assert.Equal(t, DefaultPreemptionRestartMaxBackoff, preemptionRestartBackoff(defaults, 10))
+	assert.Equal(t, int32(DefaultPreemptionRestartMaxAttempts), preemptionRestartMaxAttempts(defaults))
+}
+
+func TestRecordPreemption_AppendsHistoryOnce(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	workspace := createPreemptedWorkspace(now, 2)
+	workspace.Status.Preemptions = []workspacev1alpha1.PreemptionRecord{
+		{PreemptedAt: metav1.NewTime(now.Add(-2 * time.Hour))},
+	}
+	sm, _, _ := newStartupTestStateMachine(t, workspace)
+
+	sm.recordPreemption(context.Background(), workspace)
+
+	require.Len(t, workspace.Status.Preemptions, 2)
+	assert.True(t, workspace.Status.Preemptions[1].PreemptedAt.Time.Equal(now))
+	assert.Equal(t, int32(2), workspace.Status.PreemptionRestarts)
+	condition := meta.FindStatusCondition(workspace.Status.Conditions, ConditionTypePreempted)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, ReasonPreempted, condition.Reason)
+	assert.Contains(t, condition.Message, "restarting in 2m0s (attempt 3 of 3)")
+
+	// the same preemption is not recorded twice
+	sm.recordPreemption(context.Background(), workspace)
+	assert.Len(t, workspace.Status.Preemptions, 2)
+}
+
+func TestRecordPreemption_ResetsRestartsAfterStableRun(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	workspace := createPreemptedWorkspace(now, 3)
+	workspace.Status.StartedAt = &metav1.Time{Time: now.Add(-time.Hour)}
+	workspace.Status.Preemptions = []workspacev1alpha1.PreemptionRecord{
+		{PreemptedAt: metav1.NewTime(now.Add(-2 * time.Hour))},
+	}
+	sm, _, _ := newStartupTestStateMachine(t, workspace)
+
+	sm.recordPreemption(context.Background(), workspace)
+
+	assert.Equal(t, int32(0), workspace.Status.PreemptionRestarts)
+}
+
+func TestRecordPreemption_KeepsLimitedHistory(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	workspace := createPreemptedWorkspace(now, 0)
+	for i := MaxPreemptionHistory; i > 0; i-- {
+		workspace.Status.Preemptions = append(workspace.Status.Preemptions,
+			workspacev1alpha1.PreemptionRecord{PreemptedAt: metav1.NewTime(now.Add(-time.Duration(i) * time.Hour))})
+	}
+	sm, _, _ := newStartupTestStateMachine(t, workspace)
+
+	sm.recordPreemption(context.Background(), workspace)
+
+	require.Len(t, workspace.Status.Preemptions, MaxPreemptionHistory)
+	assert.True(t, workspace.Status.Preemptions[MaxPreemptionHistory-1].PreemptedAt.Time.Equal(now))
+}
+
+func TestRecordPreemption_RestartsExhausted(t *testing.T) {
+	workspace := createPreemptedWorkspace(time.Now().Add(-time.Hour), 3)
+	sm, fakeClient, recorder := newStartupTestStateMachine(t, workspace)
+
+	sm.recordPreemption(context.Background(), workspace)
+
+	condition := meta.FindStatusCondition(workspace.Status.Conditions, ConditionTypePreempted)
+	require.NotNil(t, condition)
+	assert.Equal(t, ReasonPreemptionRestartsExhausted, condition.Reason)
+	require.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, ReasonPreemptionRestartsExhausted)
+
+	result, err := sm.restartPreemptedWorkspace(context.Background(), workspace, ctrl.Result{})
+	require.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+
+	updated := &workspacev1alpha1.Workspace{}
+	require.NoError(t, fakeClient.Get(context.Background(), client.ObjectKeyFromObject(workspace), updated))
+	assert.Equal(t, DesiredStateStopped, updated.Spec.DesiredStatus)
+}
+
+func TestRestartPreemptedWorkspace_WaitsForBackoff(t *testing.T) {
+	workspace := createPreemptedWorkspace(time.Now(), 1)
+	workspace.Status.Preemptions = []workspacev1alpha1.PreemptionRecord{{PreemptedAt: metav1.Now()}}
+	sm, fakeClient, _ := newStartupTestStateMachine(t, workspace)
+
+	result, err := sm.restartPreemptedWorkspace(context.Background(), workspace, ctrl.Result{})
+
+	require.NoError(t, err)
+	// the second attempt waits twice the base backoff
+	assert.Greater(t, result.RequeueAfter, 30*time.Second)
+	assert.LessOrEqual(t, result.RequeueAfter, 60*time.Second)
+	updated := &workspacev1alpha1.Workspace{}
+	require.NoError(t, fakeClient.Get(context.Background(), client.ObjectKeyFromObject(workspace), updated))
+	assert.Equal(t, DesiredStateStopped, updated.Spec.DesiredStatus)
+}
+
+func TestRestartPreemptedWorkspace_RestartsAfterBackoff(t *testing.T) {
+	preemptedAt := time.Now().Add(-time.Minute)
+	workspace := createPreemptedWorkspace(preemptedAt, 0)
+	workspace.Status.Preemptions = []workspacev1alpha1.PreemptionRecord{{PreemptedAt: metav1.NewTime(preemptedAt)}}
+	sm, fakeClient, recorder := newStartupTestStateMachine(t, workspace)
+
+	result, err := sm.restartPreemptedWorkspace(context.Background(), workspace, ctrl.Result{})
+
+	require.NoError(t, err)
+	assert.Equal(t, MinimalRequeueDelay, result.RequeueAfter)
+	require.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, "PreemptionRestart")
+
+	updated := &workspacev1alpha1.Workspace{}
+	require.NoError(t, fakeClient.Get(context.Background(), client.ObjectKeyFromObject(workspace), updated))
+	assert.Equal(t, DesiredStateRunning, updated.Spec.DesiredStatus)
+	assert.NotContains(t, updated.Annotations, PreemptionReasonAnnotation)
+	assert.NotContains(t, updated.Annotations, PreemptedAtAnnotation)
+	assert.Equal(t, int32(1), updated.Status.PreemptionRestarts)
+	require.Len(t, updated.Status.Preemptions, 1)
+	assert.NotNil(t, updated.Status.Preemptions[0].RestartedAt)
+	condition := meta.FindStatusCondition(updated.Status.Conditions, ConditionTypePreempted)
+	require.NotNil(t, condition)
+	assert.Equal(t, ReasonPreemptionRestarted, condition.Reason)
+}
+
+func TestRecordPreemption_UserCanceledRestart(t *testing.T) {
+	preemptedAt := time.Now().Add(-time.Hour)
+	workspace := createPreemptedWorkspace(preemptedAt, 0)
+	sm, _, _ := newStartupTestStateMachine(t, workspace)
+	sm.recordPreemption(context.Background(), workspace)
+
+	// a user stopping the workspace removes the preemption annotations
+	workspace.Annotations = nil
+	sm.recordPreemption(context.Background(), workspace)
+
+	condition := meta.FindStatusCondition(workspace.Status.Conditions, ConditionTypePreempted)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, ReasonPreemptionRestartCanceled, condition.Reason)
+
+	result, err := sm.restartPreemptedWorkspace(context.Background(), workspace, ctrl.Result{})
+	require.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+	assert.Equal(t, DesiredStateStopped, workspace.Spec.DesiredStatus)
+}
+
+func TestRestartPreemptedWorkspace_DisabledPolicy(t *testing.T) {
+	workspace := createPreemptedWorkspace(time.Now().Add(-time.Hour), 0)
+	workspace.Spec.PreemptionRestart.Enabled = false
+	workspace.Status.Preemptions = []workspacev1alpha1.PreemptionRecord{{PreemptedAt: metav1.Now()}}
+	sm, _, _ := newStartupTestStateMachine(t, workspace)
+
+	result, err := sm.restartPreemptedWorkspace(context.Background(), workspace, ctrl.Result{RequeueAfter: time.Hour})
+
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, result.RequeueAfter)
+	assert.Equal(t, DesiredStateStopped, workspace.Spec.DesiredStatus)
+}
+
+func TestBuildPodSpec_PreemptionPriorityBump(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, workspacev1alpha1.AddToScheme(scheme))
+	builder := NewDeploymentBuilder(scheme, WorkspaceControllerOptions{}, nil)
+	workspace := createPreemptedWorkspace(time.Now(), 0)
+	workspace.Spec.PriorityClassName = "workspace-batch"
+
+	podSpec, err := builder.buildPodSpec(workspace, corev1.ResourceRequirements{}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "workspace-batch", podSpec.PriorityClassName)
+
+	workspace.Status.PreemptionRestarts = 1
+	podSpec, err = builder.buildPodSpec(workspace, corev1.ResourceRequirements{}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "workspace-high", podSpec.PriorityClassName)
+}
+
+func TestBuildPodSpec_PreemptionPriorityBumpEndsWithUserStop(t *testing.T) {
+	preemptedAt := time.Now().Add(-time.Hour)
+	workspace := createPreemptedWorkspace(preemptedAt, 0)
+	workspace.Spec.PriorityClassName = "workspace-batch"
+	workspace.Status.Preemptions = []workspacev1alpha1.PreemptionRecord{{PreemptedAt: metav1.NewTime(preemptedAt)}}
+	sm, _, _ := newStartupTestStateMachine(t, workspace)
+	builder := NewDeploymentBuilder(sm.resourceManager.scheme, WorkspaceControllerOptions{}, nil)
+
+	// the automatic restart after the preemption runs at the restart priority
+	_, err := sm.restartPreemptedWorkspace(context.Background(), workspace, ctrl.Result{})
+	require.NoError(t, err)
+	require.Equal(t, DesiredStateRunning, workspace.Spec.DesiredStatus)
+	podSpec, err := builder.buildPodSpec(workspace, corev1.ResourceRequirements{}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "workspace-high", podSpec.PriorityClassName)
+
+	// a user stops the workspace, then starts it again
+	workspace.Spec.DesiredStatus = DesiredStateStopped
+	sm.recordPreemption(context.Background(), workspace)
+	assert.Equal(t, int32(0), workspace.Status.PreemptionRestarts)
+	workspace.Spec.DesiredStatus = DesiredStateRunning
+	podSpec, err = builder.buildPodSpec(workspace, corev1.ResourceRequirements{}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "workspace-batch", podSpec.PriorityClassName)
+}
@@ -0,0 +1,53 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	workspaceutil "github.com/jupyter-infra/jupyter-k8s/internal/workspace"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// isStartBlockedByQuota checks the quotas of the creator of a stopped workspace before the controller starts it.
+// The webhook does not check updates made by the controller, so scheduled starts and preemption restarts
+// are checked here. When a quota would be exceeded, it sets the QuotaExceeded condition and returns true,
+// the caller must then leave the workspace stopped and persist the status.
+func (sm *StateMachine) isStartBlockedByQuota(ctx context.Context, workspace *workspacev1alpha1.Workspace) (bool, error) {
+	started := workspace.DeepCopy()
+	started.Spec.DesiredStatus = DesiredStateRunning
+	requested := workspaceutil.GetQuotaIncrease(workspace, started)
+
+	err := workspaceutil.CheckQuotas(ctx, sm.resourceManager.client, os.Getenv(ControllerPodNamespaceEnv), started, requested)
+	var exceeded *workspaceutil.QuotaExceededError
+	if !errors.As(err, &exceeded) {
+		if err != nil {
+			return false, fmt.Errorf("failed to check workspace quotas: %w", err)
+		}
+		return false, nil
+	}
+
+	message := fmt.Sprintf("Workspace was not started: %s", exceeded.Error())
+	logf.FromContext(ctx).Info("Not starting workspace over quota", "quota", exceeded.Quota, "subject", exceeded.Subject)
+	if existing := meta.FindStatusCondition(workspace.Status.Conditions, ConditionTypeQuotaExceeded); existing == nil ||
+		existing.Message != message {
+		sm.recorder.Event(workspace, corev1.EventTypeWarning, ReasonStartBlockedByQuota, message)
+	}
+	meta.SetStatusCondition(&workspace.Status.Conditions, NewCondition(
+		ConditionTypeQuotaExceeded,
+		metav1.ConditionTrue,
+		ReasonStartBlockedByQuota,
+		message,
+	))
+	return true, nil
+}
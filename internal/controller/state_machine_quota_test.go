@@ -0,0 +1,97 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+)
+
+// createRunningLimitQuota stores a quota allowing a single running workspace per user, with a running
+// workspace of alice already counted against it
+func createRunningLimitQuota(t *testing.T, k8sClient client.Client) {
+	maxRunning := int32(1)
+	require.NoError(t, k8sClient.Create(context.Background(), &workspacev1alpha1.WorkspaceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "users", Namespace: "default"},
+		Spec: workspacev1alpha1.WorkspaceQuotaSpec{
+			PerUser: &workspacev1alpha1.WorkspaceQuotaLimits{MaxRunningWorkspaces: &maxRunning},
+		},
+	}))
+	require.NoError(t, k8sClient.Create(context.Background(), &workspacev1alpha1.Workspace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "other-workspace",
+			Namespace:   "default",
+			Annotations: map[string]string{AnnotationCreatedBy: "alice"},
+		},
+		Spec: workspacev1alpha1.WorkspaceSpec{DesiredStatus: DesiredStateRunning},
+	}))
+}
+
+func TestReconcileSchedule_QuotaBlocksScheduledStart(t *testing.T) {
+	workspace := &workspacev1alpha1.Workspace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              testWorkspaceName,
+			Namespace:         "default",
+			Annotations:       map[string]string{AnnotationCreatedBy: "alice"},
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-48 * time.Hour)),
+		},
+		Spec: workspacev1alpha1.WorkspaceSpec{
+			DesiredStatus: DesiredStateStopped,
+			Schedule:      &workspacev1alpha1.ScheduleSpec{StartSchedule: "* * * * *"},
+		},
+	}
+	sm, fakeClient, recorder := newStartupTestStateMachine(t, workspace)
+	createRunningLimitQuota(t, fakeClient)
+
+	changed, err := sm.reconcileSchedule(context.Background(), workspace)
+
+	require.NoError(t, err)
+	assert.False(t, changed)
+	require.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, ReasonStartBlockedByQuota)
+
+	updated := &workspacev1alpha1.Workspace{}
+	require.NoError(t, fakeClient.Get(context.Background(), client.ObjectKeyFromObject(workspace), updated))
+	assert.Equal(t, DesiredStateStopped, updated.Spec.DesiredStatus)
+	assert.NotNil(t, updated.Status.LastScheduleTime)
+	condition := meta.FindStatusCondition(updated.Status.Conditions, ConditionTypeQuotaExceeded)
+	require.NotNil(t, condition)
+	assert.Contains(t, condition.Message, "running workspaces: 2 exceeds the limit of 1")
+}
+
+func TestRestartPreemptedWorkspace_QuotaBlocksRestart(t *testing.T) {
+	preemptedAt := time.Now().Add(-time.Minute)
+	workspace := createPreemptedWorkspace(preemptedAt, 0)
+	workspace.Annotations[AnnotationCreatedBy] = "alice"
+	workspace.Status.Preemptions = []workspacev1alpha1.PreemptionRecord{{PreemptedAt: metav1.NewTime(preemptedAt)}}
+	sm, fakeClient, recorder := newStartupTestStateMachine(t, workspace)
+	createRunningLimitQuota(t, fakeClient)
+
+	result, err := sm.restartPreemptedWorkspace(context.Background(), workspace, ctrl.Result{})
+
+	require.NoError(t, err)
+	assert.Equal(t, LongRequeueDelay, result.RequeueAfter)
+	require.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, ReasonStartBlockedByQuota)
+
+	updated := &workspacev1alpha1.Workspace{}
+	require.NoError(t, fakeClient.Get(context.Background(), client.ObjectKeyFromObject(workspace), updated))
+	assert.Equal(t, DesiredStateStopped, updated.Spec.DesiredStatus)
+	// the blocked restart is not counted as an attempt
+	assert.Equal(t, int32(0), updated.Status.PreemptionRestarts)
+	assert.Nil(t, updated.Status.Preemptions[0].RestartedAt)
+	assert.NotNil(t, meta.FindStatusCondition(updated.Status.Conditions, ConditionTypeQuotaExceeded))
+}
@@ -0,0 +1,104 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	workspaceutil "github.com/jupyter-infra/jupyter-k8s/internal/workspace"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// reconcileSchedule applies the most recent start/stop schedule boundary to the workspace desired status.
+// Returns true when DesiredStatus was changed, in which case the caller should requeue.
+func (sm *StateMachine) reconcileSchedule(ctx context.Context, workspace *workspacev1alpha1.Workspace) (bool, error) {
+	logger := logf.FromContext(ctx).WithValues("workspace", workspace.Name)
+
+	schedule, err := workspaceutil.ParseSchedule(workspace.Spec.Schedule)
+	if err != nil {
+		// Invalid schedules are rejected by the webhook, do not block reconciliation on them
+		logger.Error(err, "Ignoring invalid workspace schedule")
+		return false, nil
+	}
+	if schedule == nil {
+		return false, nil
+	}
+
+	// Only consider boundaries that have not been applied yet
+	after := workspace.CreationTimestamp.Time
+	if workspace.Status.LastScheduleTime != nil {
+		after = workspace.Status.LastScheduleTime.Time
+	}
+	boundary := schedule.LastBoundary(after, time.Now())
+	if boundary == nil {
+		return false, nil
+	}
+
+	desiredStatus := DesiredStateRunning
+	if boundary.Action == workspaceutil.ScheduleActionStop {
+		desiredStatus = DesiredStateStopped
+	}
+
+	changed := false
+	blocked := false
+	if sm.getDesiredStatus(workspace) != desiredStatus && desiredStatus == DesiredStateRunning {
+		if blocked, err = sm.isStartBlockedByQuota(ctx, workspace); err != nil {
+			return false, err
+		}
+	}
+	if sm.getDesiredStatus(workspace) != desiredStatus && !blocked {
+		logger.Info("Applying scheduled desired status", "action", boundary.Action, "scheduledAt", boundary.Time)
+		sm.recorder.Event(workspace, corev1.EventTypeNormal, "Scheduled"+boundary.Action,
+			fmt.Sprintf("Setting desired status to %s per schedule at %s", desiredStatus, boundary.Time.Format(time.RFC3339)))
+
+		workspace.Spec.DesiredStatus = desiredStatus
+		if err := sm.resourceManager.client.Update(ctx, workspace); err != nil {
+			return false, fmt.Errorf("failed to update workspace desired status: %w", err)
+		}
+		changed = true
+	}
+
+	// Record the boundary so that it is applied only once, even if the user overrides it afterwards
+	// or a quota blocks the start
+	workspace.Status.LastScheduleTime = &metav1.Time{Time: boundary.Time}
+	if err := sm.resourceManager.client.Status().Update(ctx, workspace); err != nil {
+		return changed, fmt.Errorf("failed to update workspace last schedule time: %w", err)
+	}
+	return changed, nil
+}
+
+// requeueForNextScheduleBoundary shortens the requeue delay so that the next reconciliation
+// happens right after the next schedule boundary
+func (sm *StateMachine) requeueForNextScheduleBoundary(
+	workspace *workspacev1alpha1.Workspace,
+	result ctrl.Result,
+	err error) (ctrl.Result, error) {
+	if err != nil {
+		return result, err
+	}
+
+	schedule, parseErr := workspaceutil.ParseSchedule(workspace.Spec.Schedule)
+	if parseErr != nil || schedule == nil {
+		return result, nil
+	}
+
+	next := schedule.NextBoundary(time.Now())
+	if next == nil || next.Time.IsZero() {
+		return result, nil
+	}
+
+	untilNext := time.Until(next.Time) + ScheduleRequeueSlack
+	if result.RequeueAfter == 0 || untilNext < result.RequeueAfter {
+		result.RequeueAfter = untilNext
+	}
+	return result, nil
+}
@@ -0,0 +1,63 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// snapshotStorageOnStop takes a VolumeSnapshot of the workspace storage when the workspace transitions
+// to Stopped, and prunes old snapshots beyond the retain count.
+// Failures are reported as events and never block the workspace from stopping.
+func (sm *StateMachine) snapshotStorageOnStop(
+	ctx context.Context,
+	workspace *workspacev1alpha1.Workspace,
+	snapshotStatus *workspacev1alpha1.WorkspaceStatus) {
+	logger := logf.FromContext(ctx).WithValues("workspace", workspace.Name)
+
+	storage := workspace.Spec.Storage
+	if storage == nil || storage.Snapshot == nil || !storage.Snapshot.OnStop || IsEphemeralStorage(workspace) {
+		return
+	}
+
+	// Only snapshot on the transition to Stopped
+	stoppedCondition := FindCondition(&snapshotStatus.Conditions, ConditionTypeStopped)
+	if stoppedCondition != nil && stoppedCondition.Status == metav1.ConditionTrue {
+		return
+	}
+
+	now := time.Now()
+	snapshot, err := sm.resourceManager.CreateVolumeSnapshot(ctx, workspace, now)
+	if err != nil {
+		logger.Error(err, "Failed to snapshot workspace storage")
+		sm.recorder.Event(workspace, corev1.EventTypeWarning, "SnapshotFailed",
+			fmt.Sprintf("Failed to snapshot workspace storage: %v", err))
+		return
+	}
+	if snapshot == nil {
+		return
+	}
+
+	sm.recorder.Event(workspace, corev1.EventTypeNormal, "SnapshotCreated",
+		fmt.Sprintf("Created VolumeSnapshot %s of workspace storage", snapshot.GetName()))
+	workspace.Status.LastSnapshotName = snapshot.GetName()
+	workspace.Status.LastSnapshotTime = &metav1.Time{Time: now}
+
+	retain := DefaultSnapshotRetain
+	if storage.Snapshot.Retain > 0 {
+		retain = int(storage.Snapshot.Retain)
+	}
+	if err := sm.resourceManager.PruneVolumeSnapshots(ctx, workspace, retain); err != nil {
+		logger.Error(err, "Failed to prune old workspace snapshots")
+	}
+}
@@ -0,0 +1,179 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// reconcileStartingWorkspace updates the status of a workspace whose resources are not ready yet,
+// enforces its startup timeout and schedules the next readiness check.
+// Deployment status changes trigger a reconciliation on their own, so the readiness checks back off
+// as the startup goes on instead of polling the API server at a fixed short interval.
+func (sm *StateMachine) reconcileStartingWorkspace(
+	ctx context.Context,
+	workspace *workspacev1alpha1.Workspace,
+	snapshotStatus *workspacev1alpha1.WorkspaceStatus,
+	readiness WorkspaceRunningReadiness) (ctrl.Result, error) {
+	now := time.Now()
+	if workspace.Status.StartupStartedAt == nil {
+		// a new start resets the failures of the previous one
+		workspace.Status.StartupStartedAt = &metav1.Time{Time: now}
+		workspace.Status.StartupFailures = 0
+	}
+	if !readiness.computeReady {
+		readiness.podFailure = sm.diagnoseStartingWorkspace(ctx, workspace)
+	}
+
+	startedAt := workspace.Status.StartupStartedAt.Time
+	timeout := startupTimeout(workspace)
+	if timeout > 0 && !now.Before(startedAt.Add(timeout)) {
+		return sm.handleStartupTimeout(ctx, workspace, snapshotStatus, readiness, timeout)
+	}
+
+	if err := sm.statusManager.UpdateStartingStatus(
+		ctx, workspace, readiness, snapshotStatus); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: startupPollDelay(startedAt, now, timeout)}, nil
+}
+
+// handleStartupTimeout marks a workspace that exceeded its startup timeout as StartupFailed,
+// then either restarts its pods after a backoff or stops it, depending on the failure policy
+func (sm *StateMachine) handleStartupTimeout(
+	ctx context.Context,
+	workspace *workspacev1alpha1.Workspace,
+	snapshotStatus *workspacev1alpha1.WorkspaceStatus,
+	readiness WorkspaceRunningReadiness,
+	timeout time.Duration) (ctrl.Result, error) {
+	logger := logf.FromContext(ctx).WithValues("workspace", workspace.Name)
+	now := time.Now()
+	policy := startupFailurePolicy(workspace)
+
+	message := fmt.Sprintf("Workspace did not become available within %s", timeout)
+	if readiness.podFailure != nil {
+		message = fmt.Sprintf("%s: %s", message, readiness.podFailure.Message)
+	}
+
+	// count the failure once per startup attempt
+	degraded := meta.FindStatusCondition(workspace.Status.Conditions, ConditionTypeDegraded)
+	if degraded == nil || degraded.Status != metav1.ConditionTrue || degraded.Reason != ReasonStartupFailed {
+		workspace.Status.StartupFailures++
+		logger.Info("Workspace exceeded its startup timeout",
+			"timeout", timeout, "failures", workspace.Status.StartupFailures, "failurePolicy", policy)
+		sm.recorder.Event(workspace, corev1.EventTypeWarning, ReasonStartupFailed, message)
+	}
+	readiness.podFailure = &PodFailure{Reason: ReasonStartupFailed, Message: message}
+
+	if policy == workspacev1alpha1.StartupFailurePolicyStop {
+		if err := sm.statusManager.UpdateStartingStatus(ctx, workspace, readiness, snapshotStatus); err != nil {
+			return ctrl.Result{}, err
+		}
+		sm.recorder.Event(workspace, corev1.EventTypeNormal, "StartupFailureStop",
+			"Stopping workspace because it failed to start")
+		workspace.Spec.DesiredStatus = DesiredStateStopped
+		if err := sm.resourceManager.client.Update(ctx, workspace); err != nil {
+			logger.Error(err, "Failed to update workspace desired status")
+			return ctrl.Result{}, err
+		}
+		logger.Info("Updated workspace desired status to Stopped after startup failure")
+		return ctrl.Result{RequeueAfter: MinimalRequeueDelay}, nil
+	}
+
+	retryAt := workspace.Status.StartupStartedAt.Add(timeout + startupRetryBackoff(workspace.Status.StartupFailures))
+	if now.Before(retryAt) {
+		if err := sm.statusManager.UpdateStartingStatus(ctx, workspace, readiness, snapshotStatus); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: retryAt.Sub(now)}, nil
+	}
+
+	// the backoff elapsed: restart the pods and begin a new startup attempt
+	if err := sm.restartWorkspacePods(ctx, workspace); err != nil {
+		return ctrl.Result{}, err
+	}
+	sm.recorder.Event(workspace, corev1.EventTypeNormal, "StartupRetry",
+		fmt.Sprintf("Restarting workspace after %d failed startup attempts", workspace.Status.StartupFailures))
+	workspace.Status.StartupStartedAt = &metav1.Time{Time: now}
+	readiness.podFailure = nil
+	if err := sm.statusManager.UpdateStartingStatus(ctx, workspace, readiness, snapshotStatus); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: StartupPollMinDelay}, nil
+}
+
+// restartWorkspacePods deletes the pods of the workspace so that its Deployment recreates them
+func (sm *StateMachine) restartWorkspacePods(ctx context.Context, workspace *workspacev1alpha1.Workspace) error {
+	podList := &corev1.PodList{}
+	if err := sm.resourceManager.client.List(ctx, podList,
+		client.InNamespace(workspace.Namespace),
+		client.MatchingLabels(GenerateLabels(workspace.Name))); err != nil {
+		return fmt.Errorf("failed to list pods: %w", err)
+	}
+	for i := range podList.Items {
+		if err := sm.resourceManager.client.Delete(ctx, &podList.Items[i]); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete pod %s: %w", podList.Items[i].Name, err)
+		}
+	}
+	return nil
+}
+
+// startupTimeout returns the startup timeout of the workspace, zero when it has none
+func startupTimeout(workspace *workspacev1alpha1.Workspace) time.Duration {
+	if workspace.Spec.StartupTimeout == nil {
+		return 0
+	}
+	return time.Duration(workspace.Spec.StartupTimeout.TimeoutInSeconds) * time.Second
+}
+
+// startupFailurePolicy returns the startup failure policy of the workspace, Retry by default
+func startupFailurePolicy(workspace *workspacev1alpha1.Workspace) workspacev1alpha1.StartupFailurePolicy {
+	if workspace.Spec.StartupTimeout == nil || workspace.Spec.StartupTimeout.FailurePolicy == "" {
+		return workspacev1alpha1.StartupFailurePolicyRetry
+	}
+	return workspace.Spec.StartupTimeout.FailurePolicy
+}
+
+// startupPollDelay returns the delay before the next readiness check of a starting workspace.
+// It grows with the time spent starting and never overshoots the startup timeout.
+func startupPollDelay(startedAt, now time.Time, timeout time.Duration) time.Duration {
+	delay := now.Sub(startedAt) / 2
+	if delay < StartupPollMinDelay {
+		delay = StartupPollMinDelay
+	}
+	if delay > StartupPollMaxDelay {
+		delay = StartupPollMaxDelay
+	}
+	if timeout > 0 {
+		if remaining := startedAt.Add(timeout).Sub(now); remaining < delay {
+			delay = remaining
+		}
+	}
+	return delay
+}
+
+// startupRetryBackoff returns the exponential backoff before retrying after the given number of failures
+func startupRetryBackoff(failures int32) time.Duration {
+	delay := StartupRetryBaseDelay
+	for i := int32(1); i < failures; i++ {
+		delay *= 2
+		if delay >= StartupRetryMaxDelay {
+			return StartupRetryMaxDelay
+		}
+	}
+	return delay
+}
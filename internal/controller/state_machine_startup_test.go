@@ -0,0 +1,220 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+)
+
+// newStartupTestStateMachine creates a state machine backed by a fake client holding the workspace and its pod
+func newStartupTestStateMachine(t *testing.T, workspace *workspacev1alpha1.Workspace) (*StateMachine, client.Client, *record.FakeRecorder) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, workspacev1alpha1.AddToScheme(scheme))
+	require.NoError(t, corev1.AddToScheme(scheme))
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "workspace-test-workspace-abc123-xyz789",
+			Namespace: workspace.Namespace,
+			Labels:    GenerateLabels(workspace.Name),
+		},
+	}
+
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(workspace, pod).
+		WithStatusSubresource(workspace).
+		Build()
+	recorder := record.NewFakeRecorder(10)
+
+	sm := NewStateMachine(
+		&ResourceManager{client: fakeClient, scheme: scheme},
+		NewStatusManager(fakeClient),
+		recorder,
+		NewWorkspaceIdleChecker(fakeClient),
+	)
+	return sm, fakeClient, recorder
+}
+
+// createStartingWorkspace returns a workspace starting since the given time with a 5 minutes startup timeout
+func createStartingWorkspace(startedAt time.Time, policy workspacev1alpha1.StartupFailurePolicy) *workspacev1alpha1.Workspace {
+	return &workspacev1alpha1.Workspace{
+		ObjectMeta: metav1.ObjectMeta{Name: testWorkspaceName, Namespace: "default"},
+		Spec: workspacev1alpha1.WorkspaceSpec{
+			DesiredStatus: DesiredStateRunning,
+			StartupTimeout: &workspacev1alpha1.StartupTimeoutSpec{
+				TimeoutInSeconds: 300,
+				FailurePolicy:    policy,
+			},
+		},
+		Status: workspacev1alpha1.WorkspaceStatus{
+			StartupStartedAt: &metav1.Time{Time: startedAt},
+		},
+	}
+}
+
+func countWorkspacePods(t *testing.T, c client.Client) int {
+	podList := &corev1.PodList{}
+	require.NoError(t, c.List(context.Background(), podList, client.MatchingLabels(GenerateLabels(testWorkspaceName))))
+	return len(podList.Items)
+}
+
+func TestStartupPollDelay(t *testing.T) {
+	now := time.Now()
+
+	assert.Equal(t, StartupPollMinDelay, startupPollDelay(now, now, 0))
+	assert.Equal(t, 5*time.Second, startupPollDelay(now.Add(-10*time.Second), now, 0))
+	assert.Equal(t, StartupPollMaxDelay, startupPollDelay(now.Add(-time.Hour), now, 0))
+	// the delay never overshoots the startup timeout
+	assert.Equal(t, 2*time.Second, startupPollDelay(now.Add(-58*time.Second), now, time.Minute))
+}
+
+func TestStartupRetryBackoff(t *testing.T) {
+	assert.Equal(t, StartupRetryBaseDelay, startupRetryBackoff(1))
+	assert.Equal(t, 2*StartupRetryBaseDelay, startupRetryBackoff(2))
+	assert.Equal(t, 4*StartupRetryBaseDelay, startupRetryBackoff(3))
+	assert.Equal(t, StartupRetryMaxDelay, startupRetryBackoff(20))
+}
+
+func TestReconcileStartingWorkspace_StartsTimer(t *testing.T) {
+	workspace := createStartingWorkspace(time.Now(), "")
+	workspace.Status.StartupStartedAt = nil
+	workspace.Status.StartupFailures = 2
+	sm, _, _ := newStartupTestStateMachine(t, workspace)
+
+	snapshot := workspace.Status.DeepCopy()
+	result, err := sm.reconcileStartingWorkspace(context.Background(), workspace, snapshot,
+		WorkspaceRunningReadiness{serviceReady: true})
+
+	require.NoError(t, err)
+	require.NotNil(t, workspace.Status.StartupStartedAt)
+	assert.Equal(t, int32(0), workspace.Status.StartupFailures)
+	assert.Equal(t, StartupPollMinDelay, result.RequeueAfter)
+}
+
+func TestReconcileStartingWorkspace_TimeoutStops(t *testing.T) {
+	workspace := createStartingWorkspace(time.Now().Add(-10*time.Minute), workspacev1alpha1.StartupFailurePolicyStop)
+	sm, fakeClient, recorder := newStartupTestStateMachine(t, workspace)
+
+	snapshot := workspace.Status.DeepCopy()
+	result, err := sm.reconcileStartingWorkspace(context.Background(), workspace, snapshot,
+		WorkspaceRunningReadiness{serviceReady: true})
+
+	require.NoError(t, err)
+	assert.Equal(t, MinimalRequeueDelay, result.RequeueAfter)
+
+	updated := &workspacev1alpha1.Workspace{}
+	require.NoError(t, fakeClient.Get(context.Background(), client.ObjectKeyFromObject(workspace), updated))
+	assert.Equal(t, DesiredStateStopped, updated.Spec.DesiredStatus)
+	assert.Equal(t, int32(1), updated.Status.StartupFailures)
+	degraded := meta.FindStatusCondition(updated.Status.Conditions, ConditionTypeDegraded)
+	require.NotNil(t, degraded)
+	assert.Equal(t, metav1.ConditionTrue, degraded.Status)
+	assert.Equal(t, ReasonStartupFailed, degraded.Reason)
+
+	require.Len(t, recorder.Events, 2)
+	assert.Contains(t, <-recorder.Events, "Warning StartupFailed")
+	assert.Contains(t, <-recorder.Events, "StartupFailureStop")
+
+	// the stopped workspace keeps reporting the startup failure
+	stopped := stoppedDegradedCondition(updated)
+	assert.Equal(t, metav1.ConditionTrue, stopped.Status)
+	assert.Equal(t, ReasonStartupFailed, stopped.Reason)
+}
+
+func TestReconcileStartingWorkspace_TimeoutRetriesAfterBackoff(t *testing.T) {
+	// the timeout was reached 10 seconds ago, within the first backoff
+	workspace := createStartingWorkspace(time.Now().Add(-310*time.Second), workspacev1alpha1.StartupFailurePolicyRetry)
+	sm, fakeClient, recorder := newStartupTestStateMachine(t, workspace)
+
+	snapshot := workspace.Status.DeepCopy()
+	result, err := sm.reconcileStartingWorkspace(context.Background(), workspace, snapshot,
+		WorkspaceRunningReadiness{serviceReady: true})
+
+	require.NoError(t, err)
+	assert.Greater(t, result.RequeueAfter, time.Duration(0))
+	assert.LessOrEqual(t, result.RequeueAfter, StartupRetryBaseDelay)
+	assert.Equal(t, int32(1), workspace.Status.StartupFailures)
+	assert.Equal(t, 1, countWorkspacePods(t, fakeClient))
+	require.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, "Warning StartupFailed")
+
+	// a later reconciliation within the backoff does not count the failure again
+	snapshot = workspace.Status.DeepCopy()
+	_, err = sm.reconcileStartingWorkspace(context.Background(), workspace, snapshot,
+		WorkspaceRunningReadiness{serviceReady: true})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), workspace.Status.StartupFailures)
+	assert.Empty(t, recorder.Events)
+
+	// once the backoff elapsed, the pods are restarted and a new attempt begins
+	workspace.Status.StartupStartedAt = &metav1.Time{Time: time.Now().Add(-time.Hour)}
+	snapshot = workspace.Status.DeepCopy()
+	result, err = sm.reconcileStartingWorkspace(context.Background(), workspace, snapshot,
+		WorkspaceRunningReadiness{serviceReady: true})
+
+	require.NoError(t, err)
+	assert.Equal(t, StartupPollMinDelay, result.RequeueAfter)
+	assert.Equal(t, 0, countWorkspacePods(t, fakeClient))
+	assert.WithinDuration(t, time.Now(), workspace.Status.StartupStartedAt.Time, time.Minute)
+	assert.Equal(t, int32(1), workspace.Status.StartupFailures)
+	require.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, "StartupRetry")
+
+	updated := &workspacev1alpha1.Workspace{}
+	require.NoError(t, fakeClient.Get(context.Background(), client.ObjectKeyFromObject(workspace), updated))
+	assert.Equal(t, DesiredStateRunning, updated.Spec.DesiredStatus)
+	degraded := meta.FindStatusCondition(updated.Status.Conditions, ConditionTypeDegraded)
+	require.NotNil(t, degraded)
+	assert.Equal(t, metav1.ConditionFalse, degraded.Status)
+}
+
+func TestReconcileStartingWorkspace_TimeoutReportsPodFailureOnce(t *testing.T) {
+	// the timeout was reached 10 seconds ago, within the first backoff
+	workspace := createStartingWorkspace(time.Now().Add(-310*time.Second), workspacev1alpha1.StartupFailurePolicyRetry)
+	sm, fakeClient, recorder := newStartupTestStateMachine(t, workspace)
+
+	podList := &corev1.PodList{}
+	require.NoError(t, fakeClient.List(context.Background(), podList))
+	require.Len(t, podList.Items, 1)
+	pod := &podList.Items[0]
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name:                 "workspace",
+		RestartCount:         4,
+		State:                corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+		LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137}},
+	}}
+	require.NoError(t, fakeClient.Status().Update(context.Background(), pod))
+
+	snapshot := workspace.Status.DeepCopy()
+	_, err := sm.reconcileStartingWorkspace(context.Background(), workspace, snapshot,
+		WorkspaceRunningReadiness{serviceReady: true})
+	require.NoError(t, err)
+	require.Len(t, recorder.Events, 2)
+	assert.Contains(t, <-recorder.Events, "Warning OutOfMemory")
+	assert.Contains(t, <-recorder.Events, "Warning StartupFailed")
+
+	// the pod failure is not reported again while waiting for the retry
+	snapshot = workspace.Status.DeepCopy()
+	_, err = sm.reconcileStartingWorkspace(context.Background(), workspace, snapshot,
+		WorkspaceRunningReadiness{serviceReady: true})
+	require.NoError(t, err)
+	assert.Empty(t, recorder.Events)
+}
@@ -0,0 +1,152 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// reconcileStorageResize reports the progress of an expansion of the workspace storage with the
+// StorageResizing condition and the storage capacity, and restarts the workspace pod when the
+// expansion waits for a filesystem resize and the storage spec asks for it.
+// The status is persisted by the status update that follows.
+func (sm *StateMachine) reconcileStorageResize(
+	ctx context.Context,
+	workspace *workspacev1alpha1.Workspace,
+	pvc *corev1.PersistentVolumeClaim) {
+	logger := logf.FromContext(ctx)
+	if pvc == nil {
+		workspace.Status.StorageCapacity = nil
+		meta.RemoveStatusCondition(&workspace.Status.Conditions, ConditionTypeStorageResizing)
+		return
+	}
+
+	capacity, hasCapacity := pvc.Status.Capacity[corev1.ResourceStorage]
+	if hasCapacity {
+		workspace.Status.StorageCapacity = &capacity
+	}
+	requested := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	desired := resolveStorageSize(workspace)
+
+	var condition metav1.Condition
+	if desired.Cmp(requested) > 0 {
+		// the PVC is only expanded once the workspace is available and its storage class allows it
+		allowed, err := sm.resourceManager.IsVolumeExpansionAllowed(ctx, pvc)
+		if err != nil {
+			logger.Error(err, "Failed to check if the storage class allows volume expansion")
+			return
+		}
+		if allowed {
+			condition = NewCondition(ConditionTypeStorageResizing, metav1.ConditionTrue, ReasonResizePending,
+				fmt.Sprintf("Storage expansion from %s to %s is pending", requested.String(), desired.String()))
+		} else {
+			condition = NewCondition(ConditionTypeStorageResizing, metav1.ConditionFalse, ReasonExpansionNotSupported,
+				fmt.Sprintf("Storage class of PVC %s does not allow volume expansion, storage stays at %s",
+					pvc.Name, requested.String()))
+			if existing := meta.FindStatusCondition(workspace.Status.Conditions, ConditionTypeStorageResizing); existing == nil ||
+				existing.Reason != ReasonExpansionNotSupported {
+				sm.recorder.Event(workspace, corev1.EventTypeWarning, ReasonExpansionNotSupported, condition.Message)
+			}
+		}
+	} else if pending := findPVCCondition(pvc, corev1.PersistentVolumeClaimFileSystemResizePending); pending != nil {
+		message := fmt.Sprintf("Storage expansion to %s waits for the filesystem resize on the node", requested.String())
+		if workspace.Spec.Storage.RestartOnResize {
+			message = fmt.Sprintf("Restarting workspace to complete the filesystem resize to %s", requested.String())
+			if err := sm.restartPodsForFileSystemResize(ctx, workspace, pending.LastTransitionTime.Time); err != nil {
+				logger.Error(err, "Failed to restart workspace pods for filesystem resize")
+			}
+		}
+		condition = NewCondition(ConditionTypeStorageResizing, metav1.ConditionTrue, ReasonFileSystemResizePending, message)
+	} else if findPVCCondition(pvc, corev1.PersistentVolumeClaimResizing) != nil ||
+		(hasCapacity && capacity.Cmp(requested) < 0) {
+		condition = NewCondition(ConditionTypeStorageResizing, metav1.ConditionTrue, ReasonResizeInProgress,
+			fmt.Sprintf("Storage is being expanded to %s", requested.String()))
+	} else {
+		// nothing to report for workspaces that were never resized
+		if meta.FindStatusCondition(workspace.Status.Conditions, ConditionTypeStorageResizing) == nil {
+			return
+		}
+		condition = NewCondition(ConditionTypeStorageResizing, metav1.ConditionFalse, ReasonResizeCompleted,
+			fmt.Sprintf("Storage capacity is %s", requested.String()))
+	}
+	meta.SetStatusCondition(&workspace.Status.Conditions, condition)
+}
+
+// restartPodsForFileSystemResize deletes the workspace pods that mounted the volume before its filesystem
+// resize became pending, the resize then happens when the replacement pod mounts the volume
+func (sm *StateMachine) restartPodsForFileSystemResize(
+	ctx context.Context,
+	workspace *workspacev1alpha1.Workspace,
+	pendingSince time.Time) error {
+	podList := &corev1.PodList{}
+	if err := sm.resourceManager.client.List(ctx, podList,
+		client.InNamespace(workspace.Namespace),
+		client.MatchingLabels(GenerateLabels(workspace.Name))); err != nil {
+		return fmt.Errorf("failed to list pods: %w", err)
+	}
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if !pod.DeletionTimestamp.IsZero() || !pod.CreationTimestamp.Time.Before(pendingSince) {
+			continue
+		}
+		if err := sm.resourceManager.client.Delete(ctx, pod); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete pod %s: %w", pod.Name, err)
+		}
+		sm.recorder.Event(workspace, corev1.EventTypeNormal, "FileSystemResizeRestart",
+			fmt.Sprintf("Restarted pod %s to complete the filesystem resize of the workspace storage", pod.Name))
+	}
+	return nil
+}
+
+// findPVCCondition returns the condition of the PVC with the given type if it is true
+func findPVCCondition(
+	pvc *corev1.PersistentVolumeClaim,
+	conditionType corev1.PersistentVolumeClaimConditionType) *corev1.PersistentVolumeClaimCondition {
+	for i := range pvc.Status.Conditions {
+		if pvc.Status.Conditions[i].Type == conditionType && pvc.Status.Conditions[i].Status == corev1.ConditionTrue {
+			return &pvc.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// reconcileSharedVolumeConflicts reports the shared volumes of the namespace that are not mounted in the workspace
+// because their name or mount path conflicts with a volume of the workspace
+func (sm *StateMachine) reconcileSharedVolumeConflicts(ctx context.Context, workspace *workspacev1alpha1.Workspace) {
+	conflicts, err := sm.resourceManager.deploymentBuilder.sharedVolumeConflicts(ctx, workspace)
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to check conflicts of shared volumes")
+		return
+	}
+	if len(conflicts) == 0 {
+		meta.RemoveStatusCondition(&workspace.Status.Conditions, ConditionTypeSharedVolumeConflict)
+		return
+	}
+
+	message := fmt.Sprintf("Shared volumes %s are not mounted, their name or mount path conflicts with a workspace volume",
+		strings.Join(conflicts, ", "))
+	if existing := meta.FindStatusCondition(workspace.Status.Conditions, ConditionTypeSharedVolumeConflict); existing == nil ||
+		existing.Message != message {
+		sm.recorder.Event(workspace, corev1.EventTypeWarning, ReasonMountPathConflict, message)
+	}
+	meta.SetStatusCondition(&workspace.Status.Conditions, NewCondition(
+		ConditionTypeSharedVolumeConflict,
+		metav1.ConditionTrue,
+		ReasonMountPathConflict,
+		message,
+	))
+}
@@ -0,0 +1,236 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+)
+
+func newStorageTestStateMachine(t *testing.T, objs ...client.Object) (*StateMachine, client.Client, *record.FakeRecorder) {
+	s := runtime.NewScheme()
+	require.NoError(t, scheme.AddToScheme(s))
+	require.NoError(t, workspacev1alpha1.AddToScheme(s))
+	fakeClient := fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build()
+	recorder := record.NewFakeRecorder(10)
+	sm := NewStateMachine(
+		&ResourceManager{client: fakeClient, scheme: s, pvcBuilder: NewPVCBuilder(s), statusManager: NewStatusManager(fakeClient)},
+		NewStatusManager(fakeClient),
+		recorder,
+		NewWorkspaceIdleChecker(fakeClient),
+	)
+	return sm, fakeClient, recorder
+}
+
+func resizeTestWorkspace(size string) *workspacev1alpha1.Workspace {
+	return &workspacev1alpha1.Workspace{
+		ObjectMeta: metav1.ObjectMeta{Name: testWorkspaceName, Namespace: "default"},
+		Spec: workspacev1alpha1.WorkspaceSpec{
+			DesiredStatus: DesiredStateRunning,
+			Storage:       &workspacev1alpha1.StorageSpec{Size: resource.MustParse(size)},
+		},
+	}
+}
+
+func resizeTestPVC(requested, capacity string) *corev1.PersistentVolumeClaim {
+	storageClassName := "standard"
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: GeneratePVCName(testWorkspaceName), Namespace: "default"},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			StorageClassName: &storageClassName,
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(requested)},
+			},
+		},
+		Status: corev1.PersistentVolumeClaimStatus{
+			Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(capacity)},
+		},
+	}
+}
+
+func resizeTestStorageClass(allowExpansion bool) *storagev1.StorageClass {
+	return &storagev1.StorageClass{
+		ObjectMeta:           metav1.ObjectMeta{Name: "standard"},
+		Provisioner:          "ebs.csi.aws.com",
+		AllowVolumeExpansion: &allowExpansion,
+	}
+}
+
+func TestReconcileStorageResize_ExpansionNotSupported(t *testing.T) {
+	workspace := resizeTestWorkspace("20Gi")
+	pvc := resizeTestPVC("10Gi", "10Gi")
+	sm, _, recorder := newStorageTestStateMachine(t, resizeTestStorageClass(false))
+
+	sm.reconcileStorageResize(context.Background(), workspace, pvc)
+
+	condition := meta.FindStatusCondition(workspace.Status.Conditions, ConditionTypeStorageResizing)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, ReasonExpansionNotSupported, condition.Reason)
+	assert.True(t, workspace.Status.StorageCapacity.Equal(resource.MustParse("10Gi")))
+	require.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, ReasonExpansionNotSupported)
+
+	// the warning is only emitted once
+	sm.reconcileStorageResize(context.Background(), workspace, pvc)
+	assert.Empty(t, recorder.Events)
+}
+
+func TestReconcileStorageResize_Progress(t *testing.T) {
+	workspace := resizeTestWorkspace("20Gi")
+	sm, _, _ := newStorageTestStateMachine(t, resizeTestStorageClass(true))
+
+	sm.reconcileStorageResize(context.Background(), workspace, resizeTestPVC("10Gi", "10Gi"))
+	condition := meta.FindStatusCondition(workspace.Status.Conditions, ConditionTypeStorageResizing)
+	require.NotNil(t, condition)
+	assert.Equal(t, ReasonResizePending, condition.Reason)
+
+	pvc := resizeTestPVC("20Gi", "10Gi")
+	sm.reconcileStorageResize(context.Background(), workspace, pvc)
+	condition = meta.FindStatusCondition(workspace.Status.Conditions, ConditionTypeStorageResizing)
+	assert.Equal(t, ReasonResizeInProgress, condition.Reason)
+
+	pvc.Status.Conditions = []corev1.PersistentVolumeClaimCondition{
+		{Type: corev1.PersistentVolumeClaimFileSystemResizePending, Status: corev1.ConditionTrue},
+	}
+	sm.reconcileStorageResize(context.Background(), workspace, pvc)
+	condition = meta.FindStatusCondition(workspace.Status.Conditions, ConditionTypeStorageResizing)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, ReasonFileSystemResizePending, condition.Reason)
+
+	sm.reconcileStorageResize(context.Background(), workspace, resizeTestPVC("20Gi", "20Gi"))
+	condition = meta.FindStatusCondition(workspace.Status.Conditions, ConditionTypeStorageResizing)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, ReasonResizeCompleted, condition.Reason)
+	assert.True(t, workspace.Status.StorageCapacity.Equal(resource.MustParse("20Gi")))
+}
+
+func TestReconcileStorageResize_NoResize(t *testing.T) {
+	workspace := resizeTestWorkspace("10Gi")
+	sm, _, _ := newStorageTestStateMachine(t)
+
+	sm.reconcileStorageResize(context.Background(), workspace, resizeTestPVC("10Gi", "10Gi"))
+
+	assert.Nil(t, meta.FindStatusCondition(workspace.Status.Conditions, ConditionTypeStorageResizing))
+	assert.True(t, workspace.Status.StorageCapacity.Equal(resource.MustParse("10Gi")))
+}
+
+func TestReconcileStorageResize_RestartsPodForFileSystemResize(t *testing.T) {
+	pendingSince := time.Now().Truncate(time.Second)
+	workspace := resizeTestWorkspace("20Gi")
+	workspace.Spec.Storage.RestartOnResize = true
+	pvc := resizeTestPVC("20Gi", "10Gi")
+	pvc.Status.Conditions = []corev1.PersistentVolumeClaimCondition{{
+		Type:               corev1.PersistentVolumeClaimFileSystemResizePending,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.NewTime(pendingSince),
+	}}
+	oldPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:              "workspace-test-workspace-old",
+		Namespace:         "default",
+		Labels:            GenerateLabels(workspace.Name),
+		CreationTimestamp: metav1.NewTime(pendingSince.Add(-time.Hour)),
+	}}
+	newPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:              "workspace-test-workspace-new",
+		Namespace:         "default",
+		Labels:            GenerateLabels(workspace.Name),
+		CreationTimestamp: metav1.NewTime(pendingSince.Add(time.Minute)),
+	}}
+	sm, fakeClient, recorder := newStorageTestStateMachine(t, oldPod, newPod)
+
+	sm.reconcileStorageResize(context.Background(), workspace, pvc)
+
+	err := fakeClient.Get(context.Background(), client.ObjectKeyFromObject(oldPod), &corev1.Pod{})
+	assert.True(t, errors.IsNotFound(err))
+	assert.NoError(t, fakeClient.Get(context.Background(), client.ObjectKeyFromObject(newPod), &corev1.Pod{}))
+	require.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, "FileSystemResizeRestart")
+}
+
+func TestEnsurePVCExists_SkipsExpansionNotAllowed(t *testing.T) {
+	workspace := resizeTestWorkspace("20Gi")
+	markWorkspaceAvailable(workspace)
+	pvc := resizeTestPVC("10Gi", "10Gi")
+	pvc.Spec.AccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
+	sm, fakeClient, _ := newStorageTestStateMachine(t, pvc, resizeTestStorageClass(false))
+
+	_, err := sm.resourceManager.EnsurePVCExists(context.Background(), workspace)
+	require.NoError(t, err)
+
+	updated := &corev1.PersistentVolumeClaim{}
+	require.NoError(t, fakeClient.Get(context.Background(), client.ObjectKeyFromObject(pvc), updated))
+	assert.True(t, updated.Spec.Resources.Requests.Storage().Equal(resource.MustParse("10Gi")))
+
+	storageClass := &storagev1.StorageClass{}
+	require.NoError(t, fakeClient.Get(context.Background(), client.ObjectKey{Name: "standard"}, storageClass))
+	allowExpansion := true
+	storageClass.AllowVolumeExpansion = &allowExpansion
+	require.NoError(t, fakeClient.Update(context.Background(), storageClass))
+	_, err = sm.resourceManager.EnsurePVCExists(context.Background(), workspace)
+	require.NoError(t, err)
+	require.NoError(t, fakeClient.Get(context.Background(), client.ObjectKeyFromObject(pvc), updated))
+	assert.True(t, updated.Spec.Resources.Requests.Storage().Equal(resource.MustParse("20Gi")))
+}
+
+func TestEnsurePVCExists_SkipsEphemeralStorage(t *testing.T) {
+	workspace := resizeTestWorkspace("5Gi")
+	workspace.Spec.Storage.Type = workspacev1alpha1.StorageTypeEmptyDir
+	sm, fakeClient, _ := newStorageTestStateMachine(t, workspace)
+
+	pvc, err := sm.resourceManager.EnsurePVCExists(context.Background(), workspace)
+
+	require.NoError(t, err)
+	assert.Nil(t, pvc)
+	pvcs := &corev1.PersistentVolumeClaimList{}
+	require.NoError(t, fakeClient.List(context.Background(), pvcs))
+	assert.Empty(t, pvcs.Items)
+}
+
+func TestBuildPodSpec_EphemeralStorage(t *testing.T) {
+	s := runtime.NewScheme()
+	require.NoError(t, workspacev1alpha1.AddToScheme(s))
+	builder := NewDeploymentBuilder(s, WorkspaceControllerOptions{}, nil)
+	workspace := resizeTestWorkspace("5Gi")
+	workspace.Spec.Storage.MountPath = "/home/student"
+
+	workspace.Spec.Storage.Type = workspacev1alpha1.StorageTypeEmptyDir
+	podSpec, err := builder.buildPodSpec(workspace, corev1.ResourceRequirements{}, nil)
+	require.NoError(t, err)
+	require.Len(t, podSpec.Volumes, 2)
+	require.NotNil(t, podSpec.Volumes[0].EmptyDir)
+	assert.True(t, podSpec.Volumes[0].EmptyDir.SizeLimit.Equal(resource.MustParse("5Gi")))
+	assert.Equal(t, "/home/student", podSpec.Containers[0].VolumeMounts[0].MountPath)
+
+	storageClassName := "local-ssd"
+	workspace.Spec.Storage.Type = workspacev1alpha1.StorageTypeEphemeralVolume
+	workspace.Spec.Storage.StorageClassName = &storageClassName
+	podSpec, err = builder.buildPodSpec(workspace, corev1.ResourceRequirements{}, nil)
+	require.NoError(t, err)
+	require.Len(t, podSpec.Volumes, 2)
+	require.NotNil(t, podSpec.Volumes[0].Ephemeral)
+	claimSpec := podSpec.Volumes[0].Ephemeral.VolumeClaimTemplate.Spec
+	assert.Equal(t, &storageClassName, claimSpec.StorageClassName)
+	assert.True(t, claimSpec.Resources.Requests.Storage().Equal(resource.MustParse("5Gi")))
+	assert.Equal(t, "/home/student", podSpec.Containers[0].VolumeMounts[0].MountPath)
+}
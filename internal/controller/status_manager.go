@@ -9,6 +9,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"time"
 
 	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
 
@@ -41,6 +42,7 @@ func (sm *StatusManager) updateStatus(
 		// requesting to modify condition: overwrite
 		workspace.Status.Conditions = *conditionsToUpdate
 	}
+	workspace.Status.ObservedGeneration = workspace.Generation
 
 	if reflect.DeepEqual(workspace.Status, snapshotStatus) {
 		// no-op: status hasn't changed
@@ -54,6 +56,20 @@ func (sm *StatusManager) updateStatus(
 	return nil
 }
 
+// setPhase sets the workspace phase, recording when it was entered
+// StartedAt is recorded whenever the workspace enters the Running phase
+func setPhase(workspace *workspacev1alpha1.Workspace, phase workspacev1alpha1.WorkspacePhase) {
+	if workspace.Status.Phase == phase && workspace.Status.PhaseTransitionTime != nil {
+		return
+	}
+	now := metav1.Now()
+	workspace.Status.Phase = phase
+	workspace.Status.PhaseTransitionTime = &now
+	if phase == workspacev1alpha1.WorkspacePhaseRunning {
+		workspace.Status.StartedAt = &now
+	}
+}
+
 // IsWorkspaceAvailable checks if the workspace is in Available=True state
 func (sm *StatusManager) IsWorkspaceAvailable(workspace *workspacev1alpha1.Workspace) bool {
 	for _, condition := range workspace.Status.Conditions {
@@ -69,6 +85,8 @@ type WorkspaceRunningReadiness struct {
 	computeReady         bool
 	serviceReady         bool
 	accessResourcesReady bool
+	// podFailure is set when a workspace pod is failing to start
+	podFailure *PodFailure
 }
 
 // UpdateStartingStatus sets Available to false and Progressing to true
@@ -116,13 +134,22 @@ func (sm *StatusManager) UpdateStartingStatus(
 		startingMessage,
 	)
 
-	// ensure DegradedCondition is set to False with ReasonNoError
+	// ensure DegradedCondition is set to False with ReasonNoError,
+	// unless a workspace pod is failing to start
 	degradedCondition := NewCondition(
 		ConditionTypeDegraded,
 		metav1.ConditionFalse,
 		ReasonNoError,
 		"No errors detected",
 	)
+	if readiness.podFailure != nil {
+		degradedCondition = NewCondition(
+			ConditionTypeDegraded,
+			metav1.ConditionTrue,
+			readiness.podFailure.Reason,
+			readiness.podFailure.Message,
+		)
+	}
 
 	// ensure StoppedCondition is set to False with ReasonDesiredStateRunning
 	stoppedCondition := NewCondition(
@@ -140,7 +167,20 @@ func (sm *StatusManager) UpdateStartingStatus(
 		stoppedCondition,
 	}
 
+	// A preempted workspace that runs again has recovered from its preemption
+	if preempted := FindCondition(&workspace.Status.Conditions, ConditionTypePreempted); preempted != nil &&
+		preempted.Status == metav1.ConditionTrue {
+		conditions = append(conditions, NewCondition(
+			ConditionTypePreempted,
+			metav1.ConditionFalse,
+			ReasonPreemptionRecovered,
+			"Workspace is running again after its preemption",
+		))
+	}
+
 	conditionsToUpdate := MergeConditionsIfChanged(ctx, workspace, &conditions)
+	workspace.Status.StoppedAt = nil
+	setPhase(workspace, workspacev1alpha1.WorkspacePhaseStarting)
 	return sm.updateStatus(ctx, workspace, &conditionsToUpdate, snapshotStatus)
 }
 
@@ -207,7 +247,22 @@ func (sm *StatusManager) UpdateRunningStatus(
 		stoppedCondition,
 	}
 
+	// A preempted workspace that runs again has recovered from its preemption
+	if preempted := FindCondition(&workspace.Status.Conditions, ConditionTypePreempted); preempted != nil &&
+		preempted.Status == metav1.ConditionTrue {
+		conditions = append(conditions, NewCondition(
+			ConditionTypePreempted,
+			metav1.ConditionFalse,
+			ReasonPreemptionRecovered,
+			"Workspace is running again after its preemption",
+		))
+	}
+
 	conditionsToUpdate := MergeConditionsIfChanged(ctx, workspace, &conditions)
+	workspace.Status.StoppedAt = nil
+	workspace.Status.StartupStartedAt = nil
+	workspace.Status.StartupFailures = 0
+	setPhase(workspace, workspacev1alpha1.WorkspacePhaseRunning)
 	return sm.updateStatus(ctx, workspace, &conditionsToUpdate, snapshotStatus)
 }
 
@@ -264,13 +319,8 @@ func (sm *StatusManager) UpdateStoppingStatus(
 		stoppingMessage,
 	)
 
-	// ensure DegradedCondition is set to false with ReasonNoError
-	degradedCondition := NewCondition(
-		ConditionTypeDegraded,
-		metav1.ConditionFalse,
-		ReasonNoError,
-		"No errors detected",
-	)
+	// ensure DegradedCondition is set to false with ReasonNoError, unless the last start failed
+	degradedCondition := stoppedDegradedCondition(workspace)
 
 	// ensure StoppedCondition is set to false with appropriate reason
 	stoppedCondition := NewCondition(
@@ -289,9 +339,30 @@ func (sm *StatusManager) UpdateStoppingStatus(
 	}
 
 	conditionsToUpdate := MergeConditionsIfChanged(ctx, workspace, &conditions)
+	workspace.Status.StartupStartedAt = nil
+	setPhase(workspace, workspacev1alpha1.WorkspacePhaseStopping)
 	return sm.updateStatus(ctx, workspace, &conditionsToUpdate, snapshotStatus)
 }
 
+// stoppedDegradedCondition returns the Degraded condition of a stopping or stopped workspace.
+// A workspace stopped after failing to start keeps reporting StartupFailed until it is started again.
+func stoppedDegradedCondition(workspace *workspacev1alpha1.Workspace) metav1.Condition {
+	if workspace.Status.StartupFailures > 0 {
+		return NewCondition(
+			ConditionTypeDegraded,
+			metav1.ConditionTrue,
+			ReasonStartupFailed,
+			fmt.Sprintf("Workspace failed to start %d times", workspace.Status.StartupFailures),
+		)
+	}
+	return NewCondition(
+		ConditionTypeDegraded,
+		metav1.ConditionFalse,
+		ReasonNoError,
+		"No errors detected",
+	)
+}
+
 // UpdateStoppedStatus sets Available and Progressing to false, Stopped to true
 func (sm *StatusManager) UpdateStoppedStatus(
 	ctx context.Context,
@@ -324,13 +395,8 @@ func (sm *StatusManager) UpdateStoppedStatus(
 		"Workspace is stopped",
 	)
 
-	// ensure DegradedCondition is set to false with ReasonNoError
-	degradedCondition := NewCondition(
-		ConditionTypeDegraded,
-		metav1.ConditionFalse,
-		ReasonNoError,
-		"No errors detected",
-	)
+	// ensure DegradedCondition is set to false with ReasonNoError, unless the last start failed
+	degradedCondition := stoppedDegradedCondition(workspace)
 
 	// ensure StoppedCondition is set to True with ReasonDeploymentAndServiceStopped
 	stoppedCondition := NewCondition(
@@ -348,12 +414,28 @@ func (sm *StatusManager) UpdateStoppedStatus(
 		stoppedCondition,
 	}
 
+	// A pending idle shutdown is resolved once the workspace is stopped, whatever stopped it
+	if pending := FindCondition(&workspace.Status.Conditions, ConditionTypeIdleShutdownPending); pending != nil &&
+		pending.Status == metav1.ConditionTrue {
+		conditions = append(conditions, NewCondition(
+			ConditionTypeIdleShutdownPending,
+			metav1.ConditionFalse,
+			ReasonWorkspaceStopped,
+			"Workspace is stopped",
+		))
+	}
+
 	conditionsToUpdate := MergeConditionsIfChanged(ctx, workspace, &conditions)
 
 	// Clear resource names since all workspace resources have been deleted at this point.
 	// This prevents stale references and signals that no active resources exist.
 	workspace.Status.DeploymentName = ""
 	workspace.Status.ServiceName = ""
+	if workspace.Status.StoppedAt == nil {
+		workspace.Status.StoppedAt = &metav1.Time{Time: time.Now()}
+	}
+	workspace.Status.StartupStartedAt = nil
+	setPhase(workspace, workspacev1alpha1.WorkspacePhaseStopped)
 	return sm.updateStatus(ctx, workspace, &conditionsToUpdate, snapshotStatus)
 }
 
@@ -368,5 +450,7 @@ func (sm *StatusManager) UpdateDeletingStatus(ctx context.Context, workspace *wo
 	}
 
 	meta.SetStatusCondition(&workspace.Status.Conditions, condition)
+	setPhase(workspace, workspacev1alpha1.WorkspacePhaseDeleting)
+	workspace.Status.ObservedGeneration = workspace.Generation
 	return sm.client.Status().Update(ctx, workspace)
 }
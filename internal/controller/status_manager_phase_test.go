@@ -0,0 +1,74 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+)
+
+func TestSetPhase(t *testing.T) {
+	workspace := &workspacev1alpha1.Workspace{}
+
+	setPhase(workspace, workspacev1alpha1.WorkspacePhaseStarting)
+	assert.Equal(t, workspacev1alpha1.WorkspacePhaseStarting, workspace.Status.Phase)
+	require.NotNil(t, workspace.Status.PhaseTransitionTime)
+	assert.Nil(t, workspace.Status.StartedAt)
+
+	// the transition time is kept while the phase does not change
+	since := metav1.NewTime(time.Now().Add(-time.Hour))
+	workspace.Status.PhaseTransitionTime = &since
+	setPhase(workspace, workspacev1alpha1.WorkspacePhaseStarting)
+	assert.True(t, workspace.Status.PhaseTransitionTime.Equal(&since))
+
+	setPhase(workspace, workspacev1alpha1.WorkspacePhaseRunning)
+	assert.Equal(t, workspacev1alpha1.WorkspacePhaseRunning, workspace.Status.Phase)
+	assert.WithinDuration(t, time.Now(), workspace.Status.PhaseTransitionTime.Time, time.Minute)
+	require.NotNil(t, workspace.Status.StartedAt)
+	assert.True(t, workspace.Status.StartedAt.Equal(workspace.Status.PhaseTransitionTime))
+}
+
+func TestStatusManager_RecordsPhaseAndObservedGeneration(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, workspacev1alpha1.AddToScheme(scheme))
+	workspace := &workspacev1alpha1.Workspace{
+		ObjectMeta: metav1.ObjectMeta{Name: testWorkspaceName, Namespace: "default", Generation: 3},
+	}
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(workspace).
+		WithStatusSubresource(workspace).
+		Build()
+	statusManager := NewStatusManager(fakeClient)
+
+	snapshot := workspace.Status.DeepCopy()
+	require.NoError(t, statusManager.UpdateRunningStatus(context.Background(), workspace, snapshot))
+
+	updated := &workspacev1alpha1.Workspace{}
+	require.NoError(t, fakeClient.Get(context.Background(), client.ObjectKeyFromObject(workspace), updated))
+	assert.Equal(t, workspacev1alpha1.WorkspacePhaseRunning, updated.Status.Phase)
+	assert.Equal(t, updated.Generation, updated.Status.ObservedGeneration)
+	require.NotNil(t, updated.Status.StartedAt)
+	require.NotNil(t, updated.Status.PhaseTransitionTime)
+
+	snapshot = updated.Status.DeepCopy()
+	require.NoError(t, statusManager.UpdateStoppedStatus(context.Background(), updated, snapshot))
+	require.NoError(t, fakeClient.Get(context.Background(), client.ObjectKeyFromObject(workspace), updated))
+	assert.Equal(t, workspacev1alpha1.WorkspacePhaseStopped, updated.Status.Phase)
+	require.NotNil(t, updated.Status.StoppedAt)
+	// the start time of the stopped session is kept for accounting
+	assert.NotNil(t, updated.Status.StartedAt)
+}
@@ -239,6 +239,27 @@ var _ = Describe("StatusManager", func() {
 				Expect(availableCond).NotTo(BeNil())
 				Expect(availableCond.Reason).To(Equal(ReasonPreempted))
 			})
+
+			It("should resolve a pending idle shutdown", func() {
+				workspace.Status.Conditions = append(workspace.Status.Conditions, NewCondition(
+					ConditionTypeIdleShutdownPending,
+					metav1.ConditionTrue,
+					ReasonIdleTimeoutReached,
+					"Workspace is idle",
+				))
+
+				snapshot := workspace.Status.DeepCopy()
+				err := statusManager.UpdateStoppedStatus(ctx, workspace, snapshot)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(workspace), workspace)).To(Succeed())
+
+				// Verify IdleShutdownPending=False
+				pendingCond := findCondition(workspace.Status.Conditions, ConditionTypeIdleShutdownPending)
+				Expect(pendingCond).NotTo(BeNil())
+				Expect(pendingCond.Status).To(Equal(metav1.ConditionFalse))
+				Expect(pendingCond.Reason).To(Equal(ReasonWorkspaceStopped))
+			})
 		})
 
 		Describe("UpdateStoppingStatus", func() {
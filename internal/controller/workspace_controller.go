@@ -7,7 +7,6 @@ package controller
 
 import (
 	"context"
-	"strings"
 
 	"github.com/jupyter-infra/jupyter-k8s-plugin/plugin"
 	"github.com/jupyter-infra/jupyter-k8s-plugin/pluginclient"
@@ -83,14 +82,18 @@ func (r *WorkspaceReconciler) SetStateMachine(sm StateMachineInterface) {
 // +kubebuilder:rbac:groups=workspace.jupyter.org,resources=*,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=workspace.jupyter.org,resources=workspaces/finalizers,verbs=update
 // +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps,resources=deployments;statefulsets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=pods;serviceaccounts,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=pods,verbs=create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=pods/exec,verbs=create
 // +kubebuilder:rbac:groups="",resources=events,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
 // +kubebuilder:rbac:groups=traefik.io,resources=ingressroutes,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=traefik.io,resources=middlewares,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=snapshot.storage.k8s.io,resources=volumesnapshots,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups=metrics.k8s.io,resources=pods,verbs=get
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
@@ -243,6 +246,9 @@ func (r *WorkspaceReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Named("workspace").
 		// Watch for standard Kubernetes resources
 		Owns(&appsv1.Deployment{}).
+		Owns(&appsv1.StatefulSet{}).
+		// Bare pods of the Pod compute mode are owned by the workspace
+		Owns(&corev1.Pod{}).
 		Owns(&corev1.Service{}).
 		Owns(&corev1.PersistentVolumeClaim{})
 
@@ -253,6 +259,12 @@ func (r *WorkspaceReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		handler.EnqueueRequestsFromMapFunc(r.accessStrategyEventHandler),
 	)
 
+	// Watch for changes to SharedVolume resources to mount them in the Workspaces of their namespace
+	builder.Watches(
+		&workspacev1alpha1.SharedVolume{},
+		handler.EnqueueRequestsFromMapFunc(r.sharedVolumeEventHandler),
+	)
+
 	// Conditionally watch pods based on configuration
 	if r.options.EnableWorkspacePodWatching {
 		builder.Watches(
@@ -265,21 +277,17 @@ func (r *WorkspaceReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			})),
 		)
 
-		// Also watch Events to detect preemption
+		// Also watch Events to detect preemption and pod failures
 		builder.Watches(
 			&corev1.Event{},
 			handler.EnqueueRequestsFromMapFunc(r.podEventHandler.HandleKubernetesEvents),
 			builderPkg.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
-				// Only watch preemption-related events to avoid processing all events
+				// Only watch preemption and pod failure events to avoid processing all events
 				event, ok := obj.(*corev1.Event)
 				if !ok {
 					return false
 				}
-				// Handle both workload preemption events and pod stopped events due to preemption
-				return (event.InvolvedObject.Kind == KindPod &&
-					event.Reason == "Stopped" &&
-					strings.Contains(event.Message, "Preempted")) ||
-					(event.Reason == "Preempted")
+				return isPodPreemptionEvent(event) || isPodFailureEvent(event)
 			})),
 		)
 	}
@@ -371,6 +379,24 @@ func (r *WorkspaceReconciler) getWorkspace(ctx context.Context, req ctrl.Request
 	return workspace, err
 }
 
+// sharedVolumeEventHandler maps SharedVolume events to reconciliation requests of the Workspaces of its namespace
+func (r *WorkspaceReconciler) sharedVolumeEventHandler(ctx context.Context, obj client.Object) []reconcile.Request {
+	logger := logf.FromContext(ctx)
+	workspaces := &workspacev1alpha1.WorkspaceList{}
+	if err := r.List(ctx, workspaces, client.InNamespace(obj.GetNamespace())); err != nil {
+		logger.Error(err, "Failed to list Workspaces of shared volume namespace",
+			"sharedVolume", obj.GetName(),
+			"namespace", obj.GetNamespace())
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(workspaces.Items))
+	for _, workspace := range workspaces.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&workspace)})
+	}
+	return requests
+}
+
 // accessStrategyEventHandler maps AccessStrategy events to Workspace reconciliation requests
 func (r *WorkspaceReconciler) accessStrategyEventHandler(ctx context.Context, obj client.Object) []reconcile.Request {
 	logger := logf.FromContext(ctx)
@@ -0,0 +1,208 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	"github.com/jupyter-infra/jupyter-k8s/internal/workspace"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// WorkspaceQuota condition types and reasons
+const (
+	// QuotaConditionTypeEnforced indicates whether the quota is enforced by the workspace webhook
+	QuotaConditionTypeEnforced = "Enforced"
+	// QuotaConditionTypeExceeded indicates whether a user or a group is over one of the quota limits,
+	// which happens when limits are lowered below the current usage
+	QuotaConditionTypeExceeded = "Exceeded"
+
+	ReasonQuotaEnforced          = "QuotaEnforced"
+	ReasonClusterScopeNotAllowed = "ClusterScopeNotAllowed"
+	ReasonWithinLimits           = "WithinLimits"
+	ReasonLimitsExceeded         = "LimitsExceeded"
+)
+
+// WorkspaceQuotaReconciler reports the usage of WorkspaceQuota objects
+type WorkspaceQuotaReconciler struct {
+	client.Client
+	Scheme              *runtime.Scheme
+	controllerNamespace string
+}
+
+// +kubebuilder:rbac:groups=workspace.jupyter.org,resources=workspacequotas/status,verbs=get;update;patch
+
+// Reconcile computes the usage of each user and group of the quota and updates its status.
+// Enforcement happens in the workspace webhook, the status only reports usage.
+func (r *WorkspaceQuotaReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := logf.FromContext(ctx).WithValues(
+		"workspacequota", req.Name,
+		"namespace", req.Namespace)
+
+	quota := &workspacev1alpha1.WorkspaceQuota{}
+	if err := r.Get(ctx, req.NamespacedName, quota); err != nil {
+		if errors.IsNotFound(err) {
+			logger.V(1).Info("WorkspaceQuota not found, it may have been deleted")
+			return ctrl.Result{}, nil
+		}
+		logger.Error(err, "Failed to get WorkspaceQuota")
+		return ctrl.Result{}, err
+	}
+
+	status := quota.Status.DeepCopy()
+	status.ObservedGeneration = quota.Generation
+
+	if !workspace.IsQuotaEnforced(quota, r.controllerNamespace) {
+		status.Users = nil
+		status.Groups = nil
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:    QuotaConditionTypeEnforced,
+			Status:  metav1.ConditionFalse,
+			Reason:  ReasonClusterScopeNotAllowed,
+			Message: fmt.Sprintf("Cluster quotas are only enforced in namespace %q", r.controllerNamespace),
+		})
+		meta.RemoveStatusCondition(&status.Conditions, QuotaConditionTypeExceeded)
+		return ctrl.Result{}, r.updateStatus(ctx, quota, status)
+	}
+
+	workspaces, err := workspace.ListQuotaWorkspaces(ctx, r.Client, quota)
+	if err != nil {
+		logger.Error(err, "Failed to list workspaces counted against quota")
+		return ctrl.Result{}, err
+	}
+	status.Users, status.Groups = workspace.ComputeQuotaUsage(quota, workspaces)
+
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:    QuotaConditionTypeEnforced,
+		Status:  metav1.ConditionTrue,
+		Reason:  ReasonQuotaEnforced,
+		Message: "Quota is enforced on workspace creation and start",
+	})
+	if exceeded := exceededQuotaUsage(quota, status); len(exceeded) > 0 {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:    QuotaConditionTypeExceeded,
+			Status:  metav1.ConditionTrue,
+			Reason:  ReasonLimitsExceeded,
+			Message: strings.Join(exceeded, "; "),
+		})
+	} else {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:    QuotaConditionTypeExceeded,
+			Status:  metav1.ConditionFalse,
+			Reason:  ReasonWithinLimits,
+			Message: "All users and groups are within the quota limits",
+		})
+	}
+
+	return ctrl.Result{}, r.updateStatus(ctx, quota, status)
+}
+
+// exceededQuotaUsage describes each user and group whose usage is over the quota limits
+func exceededQuotaUsage(quota *workspacev1alpha1.WorkspaceQuota, status *workspacev1alpha1.WorkspaceQuotaStatus) []string {
+	exceeded := []string{}
+	for _, usage := range status.Users {
+		if limits := workspace.ExceededQuotaLimits(quota.Spec.PerUser, usage.WorkspaceQuotaUsage, nil); len(limits) > 0 {
+			exceeded = append(exceeded, fmt.Sprintf("user %s: %s", usage.User, strings.Join(limits, ", ")))
+		}
+	}
+	for i, usage := range status.Groups {
+		limits := workspace.ExceededQuotaLimits(&quota.Spec.PerGroup[i].WorkspaceQuotaLimits, usage.WorkspaceQuotaUsage, nil)
+		if len(limits) > 0 {
+			exceeded = append(exceeded, fmt.Sprintf("group %s: %s", usage.Group, strings.Join(limits, ", ")))
+		}
+	}
+	return exceeded
+}
+
+// updateStatus writes the status of the quota when it changed
+func (r *WorkspaceQuotaReconciler) updateStatus(
+	ctx context.Context,
+	quota *workspacev1alpha1.WorkspaceQuota,
+	status *workspacev1alpha1.WorkspaceQuotaStatus) error {
+	if equality.Semantic.DeepEqual(&quota.Status, status) {
+		return nil
+	}
+	quota.Status = *status
+	if err := r.Status().Update(ctx, quota); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to update WorkspaceQuota status")
+		return err
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+// Quotas are reconciled when the workspaces they count change.
+func (r *WorkspaceQuotaReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	logger := mgr.GetLogger().WithName("workspacequota-setup")
+	logger.Info("Setting up WorkspaceQuota controller")
+
+	err := ctrl.NewControllerManagedBy(mgr).
+		For(&workspacev1alpha1.WorkspaceQuota{}).
+		Watches(
+			&workspacev1alpha1.Workspace{},
+			handler.EnqueueRequestsFromMapFunc(r.findQuotasForWorkspace),
+		).
+		Named("workspacequota").
+		Complete(r)
+
+	if err != nil {
+		logger.Error(err, "Failed to setup WorkspaceQuota controller")
+		return err
+	}
+
+	logger.Info("Successfully registered WorkspaceQuota controller with manager")
+	return nil
+}
+
+// findQuotasForWorkspace maps a Workspace to the enforced quotas that count it
+func (r *WorkspaceQuotaReconciler) findQuotasForWorkspace(ctx context.Context, obj client.Object) []reconcile.Request {
+	ws, ok := obj.(*workspacev1alpha1.Workspace)
+	if !ok {
+		return nil
+	}
+
+	quotas, err := workspace.ListQuotasForNamespace(ctx, r.Client, ws.Namespace, r.controllerNamespace)
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list quotas for workspace",
+			"workspace", ws.Name,
+			"workspaceNamespace", ws.Namespace)
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(quotas))
+	for _, quota := range quotas {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+			Name:      quota.Name,
+			Namespace: quota.Namespace,
+		}})
+	}
+	return requests
+}
+
+// SetupWorkspaceQuotaController sets up the WorkspaceQuota controller with the Manager
+func SetupWorkspaceQuotaController(mgr ctrl.Manager) error {
+	reconciler := &WorkspaceQuotaReconciler{
+		Client:              mgr.GetClient(),
+		Scheme:              mgr.GetScheme(),
+		controllerNamespace: os.Getenv(ControllerPodNamespaceEnv),
+	}
+
+	return reconciler.SetupWithManager(mgr)
+}
@@ -0,0 +1,129 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+)
+
+func newQuotaTestReconciler(t *testing.T, objects ...client.Object) (*WorkspaceQuotaReconciler, client.Client) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, workspacev1alpha1.AddToScheme(scheme))
+	fakeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objects...).
+		WithStatusSubresource(&workspacev1alpha1.WorkspaceQuota{}).
+		Build()
+	return &WorkspaceQuotaReconciler{
+		Client:              fakeClient,
+		Scheme:              scheme,
+		controllerNamespace: "jupyter-k8s-system",
+	}, fakeClient
+}
+
+func newQuotaTestWorkspace(name, owner, desiredStatus string) *workspacev1alpha1.Workspace {
+	return &workspacev1alpha1.Workspace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   "default",
+			Annotations: map[string]string{AnnotationCreatedBy: owner},
+		},
+		Spec: workspacev1alpha1.WorkspaceSpec{
+			DesiredStatus: desiredStatus,
+			Resources: &corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+			},
+		},
+	}
+}
+
+func TestWorkspaceQuotaReconcile_ReportsUsage(t *testing.T) {
+	maxRunning := int32(1)
+	quota := &workspacev1alpha1.WorkspaceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "users", Namespace: "default", Generation: 2},
+		Spec: workspacev1alpha1.WorkspaceQuotaSpec{
+			PerUser: &workspacev1alpha1.WorkspaceQuotaLimits{MaxRunningWorkspaces: &maxRunning},
+		},
+	}
+	reconciler, fakeClient := newQuotaTestReconciler(t, quota,
+		newQuotaTestWorkspace("ws-1", "alice", DesiredStateRunning),
+		newQuotaTestWorkspace("ws-2", "alice", DesiredStateRunning),
+		newQuotaTestWorkspace("ws-3", "bob", DesiredStateStopped))
+
+	_, err := reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(quota)})
+	require.NoError(t, err)
+
+	updated := &workspacev1alpha1.WorkspaceQuota{}
+	require.NoError(t, fakeClient.Get(context.Background(), client.ObjectKeyFromObject(quota), updated))
+	assert.Equal(t, int64(2), updated.Status.ObservedGeneration)
+	require.Len(t, updated.Status.Users, 2)
+	assert.Equal(t, "alice", updated.Status.Users[0].User)
+	assert.Equal(t, int32(2), updated.Status.Users[0].RunningWorkspaces)
+	assert.True(t, updated.Status.Users[0].Resources.Cpu().Equal(resource.MustParse("2")))
+	assert.Equal(t, "bob", updated.Status.Users[1].User)
+	assert.Equal(t, int32(0), updated.Status.Users[1].RunningWorkspaces)
+
+	enforced := meta.FindStatusCondition(updated.Status.Conditions, QuotaConditionTypeEnforced)
+	require.NotNil(t, enforced)
+	assert.Equal(t, metav1.ConditionTrue, enforced.Status)
+	exceeded := meta.FindStatusCondition(updated.Status.Conditions, QuotaConditionTypeExceeded)
+	require.NotNil(t, exceeded)
+	assert.Equal(t, metav1.ConditionTrue, exceeded.Status)
+	assert.Equal(t, "user alice: running workspaces: 2 exceeds the limit of 1", exceeded.Message)
+}
+
+func TestWorkspaceQuotaReconcile_ClusterScopeOutsideControllerNamespace(t *testing.T) {
+	quota := &workspacev1alpha1.WorkspaceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "default"},
+		Spec: workspacev1alpha1.WorkspaceQuotaSpec{
+			Scope:   workspacev1alpha1.WorkspaceQuotaScopeCluster,
+			PerUser: &workspacev1alpha1.WorkspaceQuotaLimits{},
+		},
+	}
+	reconciler, fakeClient := newQuotaTestReconciler(t, quota,
+		newQuotaTestWorkspace("ws-1", "alice", DesiredStateRunning))
+
+	_, err := reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(quota)})
+	require.NoError(t, err)
+
+	updated := &workspacev1alpha1.WorkspaceQuota{}
+	require.NoError(t, fakeClient.Get(context.Background(), client.ObjectKeyFromObject(quota), updated))
+	assert.Empty(t, updated.Status.Users)
+	enforced := meta.FindStatusCondition(updated.Status.Conditions, QuotaConditionTypeEnforced)
+	require.NotNil(t, enforced)
+	assert.Equal(t, metav1.ConditionFalse, enforced.Status)
+	assert.Equal(t, ReasonClusterScopeNotAllowed, enforced.Reason)
+}
+
+func TestFindQuotasForWorkspace(t *testing.T) {
+	namespaced := &workspacev1alpha1.WorkspaceQuota{ObjectMeta: metav1.ObjectMeta{Name: "ns", Namespace: "default"}}
+	other := &workspacev1alpha1.WorkspaceQuota{ObjectMeta: metav1.ObjectMeta{Name: "ns", Namespace: "other"}}
+	cluster := &workspacev1alpha1.WorkspaceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "jupyter-k8s-system"},
+		Spec:       workspacev1alpha1.WorkspaceQuotaSpec{Scope: workspacev1alpha1.WorkspaceQuotaScopeCluster},
+	}
+	reconciler, _ := newQuotaTestReconciler(t, namespaced, other, cluster)
+
+	requests := reconciler.findQuotasForWorkspace(context.Background(), newQuotaTestWorkspace("ws-1", "alice", DesiredStateRunning))
+
+	require.Len(t, requests, 2)
+	names := []string{requests[0].String(), requests[1].String()}
+	assert.ElementsMatch(t, []string{"default/ns", "jupyter-k8s-system/cluster"}, names)
+}
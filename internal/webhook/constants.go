@@ -23,3 +23,10 @@ const (
 	DefaultTemplateLabel       = "workspace.jupyter.org/default-template"
 	DefaultServiceAccountLabel = "workspace.jupyter.org/default-service-account"
 )
+
+// Environment variable constants
+const (
+	// AuthMiddlewareServiceAccountEnv holds the username of the auth middleware service account
+	// (system:serviceaccount:<namespace>:<name>), which starts workspaces on connection
+	AuthMiddlewareServiceAccountEnv = "AUTHMIDDLEWARE_SERVICE_ACCOUNT"
+)
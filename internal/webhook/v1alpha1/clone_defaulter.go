@@ -0,0 +1,142 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+)
+
+// CloneDefaulter copies the spec of a source workspace into workspaces created with cloneFrom
+type CloneDefaulter struct {
+	client client.Client
+}
+
+// NewCloneDefaulter creates a new CloneDefaulter
+func NewCloneDefaulter(k8sClient client.Client) *CloneDefaulter {
+	return &CloneDefaulter{
+		client: k8sClient,
+	}
+}
+
+// ApplyCloneDefaults copies the source workspace spec into fields the workspace does not set
+// Identity and access fields (display name, ownership, access type, service account) and
+// secondary volumes are never copied, since they belong to the source workspace owner
+func (cd *CloneDefaulter) ApplyCloneDefaults(ctx context.Context, workspace *workspacev1alpha1.Workspace) error {
+	if workspace.Spec.CloneFrom == nil {
+		return nil
+	}
+
+	source := &workspacev1alpha1.Workspace{}
+	if err := cd.client.Get(ctx, types.NamespacedName{
+		Name:      workspace.Spec.CloneFrom.Name,
+		Namespace: workspace.Namespace,
+	}, source); err != nil {
+		if errors.IsNotFound(err) {
+			return fmt.Errorf("source workspace %s not found in namespace %s", workspace.Spec.CloneFrom.Name, workspace.Namespace)
+		}
+		return fmt.Errorf("failed to get source workspace %s: %w", workspace.Spec.CloneFrom.Name, err)
+	}
+
+	applyCloneSpec(workspace, source)
+	return nil
+}
+
+// applyCloneSpec copies the source spec into unset workspace spec fields
+func applyCloneSpec(workspace, source *workspacev1alpha1.Workspace) {
+	spec := &workspace.Spec
+	sourceSpec := source.Spec.DeepCopy()
+
+	if spec.Image == "" {
+		spec.Image = sourceSpec.Image
+	}
+	if spec.Resources == nil {
+		spec.Resources = sourceSpec.Resources
+	}
+	if spec.ContainerConfig == nil {
+		spec.ContainerConfig = sourceSpec.ContainerConfig
+	}
+	if spec.Env == nil {
+		spec.Env = sourceSpec.Env
+	}
+	if spec.NodeSelector == nil {
+		spec.NodeSelector = sourceSpec.NodeSelector
+	}
+	if spec.Affinity == nil {
+		spec.Affinity = sourceSpec.Affinity
+	}
+	if spec.Tolerations == nil {
+		spec.Tolerations = sourceSpec.Tolerations
+	}
+	if spec.PriorityClassName == "" {
+		spec.PriorityClassName = sourceSpec.PriorityClassName
+	}
+	if spec.Lifecycle == nil {
+		spec.Lifecycle = sourceSpec.Lifecycle
+	}
+	if spec.Probes == nil {
+		spec.Probes = sourceSpec.Probes
+	}
+	if spec.AccessStrategy == nil {
+		spec.AccessStrategy = sourceSpec.AccessStrategy
+	}
+	if spec.TemplateRef == nil {
+		spec.TemplateRef = sourceSpec.TemplateRef
+	}
+	if spec.IdleShutdown == nil {
+		spec.IdleShutdown = sourceSpec.IdleShutdown
+	}
+	if spec.Schedule == nil {
+		spec.Schedule = sourceSpec.Schedule
+	}
+	if spec.StartupTimeout == nil {
+		spec.StartupTimeout = sourceSpec.StartupTimeout
+	}
+	if spec.PreemptionRestart == nil {
+		spec.PreemptionRestart = sourceSpec.PreemptionRestart
+	}
+	if spec.AppType == "" {
+		spec.AppType = sourceSpec.AppType
+	}
+	if spec.ComputeMode == "" {
+		spec.ComputeMode = sourceSpec.ComputeMode
+	}
+	if spec.PodSecurityContext == nil {
+		spec.PodSecurityContext = sourceSpec.PodSecurityContext
+	}
+	if spec.ContainerSecurityContext == nil {
+		spec.ContainerSecurityContext = sourceSpec.ContainerSecurityContext
+	}
+	// Secondary storages are provisioned empty for the clone, only their declaration is copied
+	if spec.SecondaryStorages == nil {
+		spec.SecondaryStorages = sourceSpec.SecondaryStorages
+	}
+
+	// The primary storage is cloned from the source PVC, so it must be at least as large
+	if sourceSpec.Storage != nil {
+		if spec.Storage == nil {
+			spec.Storage = sourceSpec.Storage
+			spec.Storage.RestoreFromSnapshot = ""
+			spec.Storage.ExistingClaimName = ""
+		} else {
+			if spec.Storage.Size.IsZero() {
+				spec.Storage.Size = sourceSpec.Storage.Size
+			}
+			if spec.Storage.StorageClassName == nil {
+				spec.Storage.StorageClassName = sourceSpec.Storage.StorageClassName
+			}
+			if spec.Storage.MountPath == "" {
+				spec.Storage.MountPath = sourceSpec.Storage.MountPath
+			}
+		}
+	}
+}
@@ -0,0 +1,209 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	"github.com/jupyter-infra/jupyter-k8s/internal/controller"
+	webhookconst "github.com/jupyter-infra/jupyter-k8s/internal/webhook"
+)
+
+var _ = Describe("CloneDefaulter", func() {
+	var (
+		ctx       context.Context
+		scheme    *runtime.Scheme
+		source    *workspacev1alpha1.Workspace
+		workspace *workspacev1alpha1.Workspace
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		_ = workspacev1alpha1.AddToScheme(scheme)
+
+		source = &workspacev1alpha1.Workspace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "instructor",
+				Namespace:   "default",
+				Annotations: map[string]string{controller.AnnotationCreatedBy: "teacher"},
+			},
+			Spec: workspacev1alpha1.WorkspaceSpec{
+				DisplayName:   "Instructor",
+				Image:         "jupyter/scipy-notebook:latest",
+				OwnershipType: webhookconst.OwnershipTypeOwnerOnly,
+				AccessType:    webhookconst.OwnershipTypePublic,
+				Env:           []corev1.EnvVar{{Name: "COURSE", Value: "ml-101"}},
+				Storage: &workspacev1alpha1.StorageSpec{
+					Size:      resource.MustParse("20Gi"),
+					MountPath: "/home/jovyan",
+				},
+				Volumes:            []workspacev1alpha1.VolumeSpec{{Name: "data", PersistentVolumeClaimName: "data", MountPath: "/data"}},
+				ServiceAccountName: "teacher-sa",
+			},
+		}
+
+		workspace = &workspacev1alpha1.Workspace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "student-1",
+				Namespace:   "default",
+				Annotations: map[string]string{controller.AnnotationCreatedBy: "student"},
+			},
+			Spec: workspacev1alpha1.WorkspaceSpec{
+				DisplayName: "Student 1",
+				CloneFrom:   &workspacev1alpha1.CloneSource{Name: "instructor"},
+			},
+		}
+	})
+
+	Context("ApplyCloneDefaults", func() {
+		It("should do nothing without cloneFrom", func() {
+			workspace.Spec.CloneFrom = nil
+			defaulter := NewCloneDefaulter(fake.NewClientBuilder().WithScheme(scheme).Build())
+
+			Expect(defaulter.ApplyCloneDefaults(ctx, workspace)).To(Succeed())
+			Expect(workspace.Spec.Image).To(BeEmpty())
+		})
+
+		It("should fail when the source workspace does not exist", func() {
+			defaulter := NewCloneDefaulter(fake.NewClientBuilder().WithScheme(scheme).Build())
+
+			err := defaulter.ApplyCloneDefaults(ctx, workspace)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("not found"))
+		})
+
+		It("should copy the source spec but not identity fields or volumes", func() {
+			defaulter := NewCloneDefaulter(fake.NewClientBuilder().WithScheme(scheme).WithObjects(source).Build())
+
+			Expect(defaulter.ApplyCloneDefaults(ctx, workspace)).To(Succeed())
+			Expect(workspace.Spec.Image).To(Equal("jupyter/scipy-notebook:latest"))
+			Expect(workspace.Spec.Env).To(Equal(source.Spec.Env))
+			Expect(workspace.Spec.Storage.Size).To(Equal(resource.MustParse("20Gi")))
+			Expect(workspace.Spec.DisplayName).To(Equal("Student 1"))
+			Expect(workspace.Spec.OwnershipType).To(BeEmpty())
+			Expect(workspace.Spec.ServiceAccountName).To(BeEmpty())
+			Expect(workspace.Spec.Volumes).To(BeEmpty())
+		})
+
+		It("should not override fields set on the clone", func() {
+			workspace.Spec.Image = "jupyter/base-notebook:latest"
+			workspace.Spec.Storage = &workspacev1alpha1.StorageSpec{Size: resource.MustParse("30Gi")}
+			defaulter := NewCloneDefaulter(fake.NewClientBuilder().WithScheme(scheme).WithObjects(source).Build())
+
+			Expect(defaulter.ApplyCloneDefaults(ctx, workspace)).To(Succeed())
+			Expect(workspace.Spec.Image).To(Equal("jupyter/base-notebook:latest"))
+			Expect(workspace.Spec.Storage.Size).To(Equal(resource.MustParse("30Gi")))
+			Expect(workspace.Spec.Storage.MountPath).To(Equal("/home/jovyan"))
+		})
+	})
+
+	Context("validateCloneOwnership", func() {
+		It("should reject cloning a workspace created by another user", func() {
+			k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(source).Build()
+			workspace.Spec.Storage = &workspacev1alpha1.StorageSpec{Size: resource.MustParse("20Gi")}
+
+			violation := validateCloneOwnership(ctx, k8sClient, workspace)
+			Expect(violation).NotTo(BeNil())
+			Expect(violation.Type).To(Equal(ViolationTypeCloneSourceOwnedByAnotherUser))
+		})
+
+		It("should allow cloning a workspace created by another user that allows cloning", func() {
+			source.Annotations[controller.AnnotationAllowClone] = "true"
+			k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(source).Build()
+			workspace.Spec.Storage = &workspacev1alpha1.StorageSpec{Size: resource.MustParse("20Gi")}
+
+			Expect(validateCloneOwnership(ctx, k8sClient, workspace)).To(BeNil())
+		})
+
+		It("should allow the creator to clone their own workspace", func() {
+			workspace.Annotations[controller.AnnotationCreatedBy] = "teacher"
+			k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(source).Build()
+			workspace.Spec.Storage = &workspacev1alpha1.StorageSpec{Size: resource.MustParse("20Gi")}
+
+			Expect(validateCloneOwnership(ctx, k8sClient, workspace)).To(BeNil())
+		})
+
+		It("should reject cloning a source without a creator", func() {
+			delete(source.Annotations, controller.AnnotationCreatedBy)
+			delete(workspace.Annotations, controller.AnnotationCreatedBy)
+			k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(source).Build()
+			workspace.Spec.Storage = &workspacev1alpha1.StorageSpec{Size: resource.MustParse("20Gi")}
+
+			violation := validateCloneOwnership(ctx, k8sClient, workspace)
+			Expect(violation).NotTo(BeNil())
+			Expect(violation.Type).To(Equal(ViolationTypeCloneSourceOwnedByAnotherUser))
+		})
+
+		It("should reject the clone when the source workspace cannot be read", func() {
+			k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+				Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+					return errors.New("connection refused")
+				},
+			}).Build()
+			workspace.Spec.Storage = &workspacev1alpha1.StorageSpec{Size: resource.MustParse("20Gi")}
+
+			violation := validateCloneOwnership(ctx, k8sClient, workspace)
+			Expect(violation).NotTo(BeNil())
+			Expect(violation.Type).To(Equal(ViolationTypeCloneSourceOwnedByAnotherUser))
+		})
+
+		It("should reject a clone smaller than the source storage", func() {
+			workspace.Annotations[controller.AnnotationCreatedBy] = "teacher"
+			k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(source).Build()
+			workspace.Spec.Storage = &workspacev1alpha1.StorageSpec{Size: resource.MustParse("10Gi")}
+
+			violation := validateCloneOwnership(ctx, k8sClient, workspace)
+			Expect(violation).NotTo(BeNil())
+			Expect(violation.Type).To(Equal(ViolationTypeInvalidCloneSource))
+		})
+
+		It("should reject combining cloneFrom with restoreFromSnapshot", func() {
+			k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(source).Build()
+			workspace.Spec.Storage = &workspacev1alpha1.StorageSpec{
+				Size:                resource.MustParse("20Gi"),
+				RestoreFromSnapshot: "snap",
+			}
+
+			violation := validateCloneOwnership(ctx, k8sClient, workspace)
+			Expect(violation).NotTo(BeNil())
+			Expect(violation.Type).To(Equal(ViolationTypeInvalidCloneSource))
+		})
+
+		It("should not check the source again when the clone is updated", func() {
+			source.Annotations[controller.AnnotationAllowClone] = "true"
+			workspace.Spec.Storage = &workspacev1alpha1.StorageSpec{Size: resource.MustParse("20Gi")}
+			oldWorkspace := workspace.DeepCopy()
+
+			// the source owner revokes cloning and grows their storage after the clone was created
+			delete(source.Annotations, controller.AnnotationAllowClone)
+			source.Spec.Storage.Size = resource.MustParse("50Gi")
+			validator := NewVolumeValidator(fake.NewClientBuilder().WithScheme(scheme).WithObjects(source).Build())
+
+			workspace.Spec.DesiredStatus = controller.DesiredStateStopped
+			Expect(validator.ValidateVolumeOwnership(ctx, oldWorkspace, workspace)).To(Succeed())
+
+			// changing the source is checked again
+			workspace.Spec.CloneFrom = &workspacev1alpha1.CloneSource{Name: "other"}
+			source.Name = "other"
+			validator = NewVolumeValidator(fake.NewClientBuilder().WithScheme(scheme).WithObjects(source).Build())
+			Expect(validator.ValidateVolumeOwnership(ctx, oldWorkspace, workspace)).To(
+				MatchError(ContainSubstring("does not allow cloning")))
+		})
+	})
+})
@@ -0,0 +1,47 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	"github.com/jupyter-infra/jupyter-k8s/internal/controller"
+)
+
+// validateComputeMode checks the workspace compute mode against the one required by the template
+func validateComputeMode(workspace *workspacev1alpha1.Workspace, template *workspacev1alpha1.WorkspaceTemplate) *TemplateViolation {
+	computeMode := controller.GetComputeMode(workspace)
+	if template.Spec.ComputeMode == "" || computeMode == template.Spec.ComputeMode {
+		return nil
+	}
+	return &TemplateViolation{
+		Type:  ViolationTypeComputeModeNotAllowed,
+		Field: "spec.computeMode",
+		Message: fmt.Sprintf("Compute mode '%s' is not allowed by template '%s', which requires '%s'",
+			computeMode, template.Name, template.Spec.ComputeMode),
+		Allowed: string(template.Spec.ComputeMode),
+		Actual:  string(computeMode),
+	}
+}
+
+// validateComputeModeUpdate rejects compute mode changes unless the workspace is stopped,
+// since the resources of the previous mode would keep running alongside the new ones
+func validateComputeModeUpdate(oldWorkspace, newWorkspace *workspacev1alpha1.Workspace) error {
+	oldMode := controller.GetComputeMode(oldWorkspace)
+	newMode := controller.GetComputeMode(newWorkspace)
+	if oldMode == newMode {
+		return nil
+	}
+
+	status := oldWorkspace.Status
+	if status.Phase == workspacev1alpha1.WorkspacePhaseStopped ||
+		(status.Phase == "" && status.DeploymentName == "") {
+		return nil
+	}
+	return fmt.Errorf("spec.computeMode cannot be changed from %s to %s while the workspace is %s, stop it first",
+		oldMode, newMode, status.Phase)
+}
@@ -0,0 +1,76 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+)
+
+var _ = Describe("ComputeModeValidator", func() {
+	var (
+		workspace *workspacev1alpha1.Workspace
+		template  *workspacev1alpha1.WorkspaceTemplate
+	)
+
+	BeforeEach(func() {
+		workspace = &workspacev1alpha1.Workspace{}
+		template = &workspacev1alpha1.WorkspaceTemplate{}
+		template.Name = "test-template"
+	})
+
+	It("should accept any compute mode when the template sets none", func() {
+		workspace.Spec.ComputeMode = workspacev1alpha1.ComputeModePod
+		Expect(validateComputeMode(workspace, template)).To(BeNil())
+	})
+
+	It("should reject a compute mode other than the template's", func() {
+		template.Spec.ComputeMode = workspacev1alpha1.ComputeModeStatefulSet
+
+		violation := validateComputeMode(workspace, template)
+		Expect(violation).NotTo(BeNil())
+		Expect(violation.Type).To(Equal(ViolationTypeComputeModeNotAllowed))
+		Expect(violation.Actual).To(Equal("Deployment"))
+
+		workspace.Spec.ComputeMode = workspacev1alpha1.ComputeModeStatefulSet
+		Expect(validateComputeMode(workspace, template)).To(BeNil())
+	})
+
+	Context("on update", func() {
+		var oldWorkspace *workspacev1alpha1.Workspace
+
+		BeforeEach(func() {
+			oldWorkspace = workspace.DeepCopy()
+			workspace.Spec.ComputeMode = workspacev1alpha1.ComputeModePod
+		})
+
+		It("should allow changing the compute mode of a stopped workspace", func() {
+			oldWorkspace.Status.Phase = workspacev1alpha1.WorkspacePhaseStopped
+			Expect(validateComputeModeUpdate(oldWorkspace, workspace)).To(Succeed())
+		})
+
+		It("should allow changing the compute mode of a workspace that never started", func() {
+			Expect(validateComputeModeUpdate(oldWorkspace, workspace)).To(Succeed())
+		})
+
+		It("should reject changing the compute mode of a running workspace", func() {
+			oldWorkspace.Status.Phase = workspacev1alpha1.WorkspacePhaseRunning
+			oldWorkspace.Status.DeploymentName = "workspace-test"
+
+			err := validateComputeModeUpdate(oldWorkspace, workspace)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("stop it first"))
+		})
+
+		It("should treat an unset compute mode as Deployment", func() {
+			oldWorkspace.Status.Phase = workspacev1alpha1.WorkspacePhaseRunning
+			workspace.Spec.ComputeMode = workspacev1alpha1.ComputeModeDeployment
+			Expect(validateComputeModeUpdate(oldWorkspace, workspace)).To(Succeed())
+		})
+	})
+})
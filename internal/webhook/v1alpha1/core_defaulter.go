@@ -35,4 +35,9 @@ func applyCoreDefaults(workspace *workspacev1alpha1.Workspace, template *workspa
 	if workspace.Spec.AppType == "" && template.Spec.AppType != "" {
 		workspace.Spec.AppType = template.Spec.AppType
 	}
+
+	// Apply compute mode defaults
+	if workspace.Spec.ComputeMode == "" && template.Spec.ComputeMode != "" {
+		workspace.Spec.ComputeMode = template.Spec.ComputeMode
+	}
 }
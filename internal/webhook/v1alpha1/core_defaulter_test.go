@@ -134,5 +134,13 @@ var _ = Describe("CoreDefaulter", func() {
 
 			Expect(workspace.Spec.AppType).To(Equal("vscode"))
 		})
+
+		It("should apply the template compute mode", func() {
+			template.Spec.ComputeMode = workspacev1alpha1.ComputeModeStatefulSet
+
+			applyCoreDefaults(workspace, template)
+
+			Expect(workspace.Spec.ComputeMode).To(Equal(workspacev1alpha1.ComputeModeStatefulSet))
+		})
 	})
 })
@@ -0,0 +1,148 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	"github.com/jupyter-infra/jupyter-k8s/internal/controller"
+)
+
+// validateIdleShutdownOverrides checks the workspace idle shutdown against the template's IdleShutdownOverrides policy
+func validateIdleShutdownOverrides(workspace *workspacev1alpha1.Workspace, template *workspacev1alpha1.WorkspaceTemplate) []TemplateViolation {
+	policy := template.Spec.IdleShutdownOverrides
+	idleShutdown := workspace.Spec.IdleShutdown
+	if policy == nil || idleShutdown == nil {
+		return nil
+	}
+
+	var violations []TemplateViolation
+
+	// When overrides are not allowed, the workspace must keep the template default idle shutdown
+	if policy.Allow != nil && !*policy.Allow &&
+		!equality.Semantic.DeepEqual(idleShutdown, template.Spec.DefaultIdleShutdown) {
+		violations = append(violations, TemplateViolation{
+			Type:    ViolationTypeIdleShutdownOverrideNotAllowed,
+			Field:   "spec.idleShutdown",
+			Message: fmt.Sprintf("Idle shutdown overrides not allowed by template '%s'", template.Name),
+			Allowed: "template default idle shutdown",
+			Actual:  fmt.Sprintf("enabled=%t idleTimeoutInMinutes=%d", idleShutdown.Enabled, idleShutdown.IdleTimeoutInMinutes),
+		})
+	}
+
+	if idleShutdown.Enabled {
+		if violation := validateIdleTimeoutBounds(idleShutdown.IdleTimeoutInMinutes, policy, template.Name); violation != nil {
+			violations = append(violations, *violation)
+		}
+	}
+
+	if metrics := idleShutdown.Detection.Metrics; metrics != nil {
+		violations = append(violations, validateMetricsDetection(metrics, policy, template.Name)...)
+	}
+
+	return violations
+}
+
+// validatePrometheusURL checks that the Prometheus API used for idle detection is allowed by the operator.
+// The controller queries this URL, so it applies to all users, with or without a template.
+// On update, an unchanged URL is not checked again so that workspaces keep working when the setting changes.
+func validatePrometheusURL(oldWorkspace, workspace *workspacev1alpha1.Workspace) error {
+	prometheusURL := getPrometheusURL(workspace)
+	if prometheusURL == "" || (oldWorkspace != nil && getPrometheusURL(oldWorkspace) == prometheusURL) {
+		return nil
+	}
+	if !controller.IsPrometheusURLAllowed(prometheusURL) {
+		return fmt.Errorf("prometheus URL '%s' is not allowed, allowed URLs: %v",
+			prometheusURL, controller.GetAllowedPrometheusURLs())
+	}
+	return nil
+}
+
+// getPrometheusURL returns the Prometheus API used for idle detection, if any
+func getPrometheusURL(workspace *workspacev1alpha1.Workspace) string {
+	idleShutdown := workspace.Spec.IdleShutdown
+	if idleShutdown == nil || idleShutdown.Detection.Metrics == nil || idleShutdown.Detection.Metrics.Prometheus == nil {
+		return ""
+	}
+	return idleShutdown.Detection.Metrics.Prometheus.URL
+}
+
+// validateIdleTimeoutBounds checks if the idle timeout is within template bounds
+func validateIdleTimeoutBounds(timeout int, policy *workspacev1alpha1.IdleShutdownOverridePolicy, templateName string) *TemplateViolation {
+	if policy.MinIdleTimeoutInMinutes != nil && timeout < *policy.MinIdleTimeoutInMinutes {
+		return &TemplateViolation{
+			Type:    ViolationTypeIdleShutdownTimeoutOutOfBounds,
+			Field:   "spec.idleShutdown.idleTimeoutInMinutes",
+			Message: fmt.Sprintf("Idle timeout %d minutes is below minimum %d required by template '%s'", timeout, *policy.MinIdleTimeoutInMinutes, templateName),
+			Allowed: fmt.Sprintf("min: %d", *policy.MinIdleTimeoutInMinutes),
+			Actual:  fmt.Sprintf("%d", timeout),
+		}
+	}
+
+	if policy.MaxIdleTimeoutInMinutes != nil && timeout > *policy.MaxIdleTimeoutInMinutes {
+		return &TemplateViolation{
+			Type:    ViolationTypeIdleShutdownTimeoutOutOfBounds,
+			Field:   "spec.idleShutdown.idleTimeoutInMinutes",
+			Message: fmt.Sprintf("Idle timeout %d minutes exceeds maximum %d allowed by template '%s'", timeout, *policy.MaxIdleTimeoutInMinutes, templateName),
+			Allowed: fmt.Sprintf("max: %d", *policy.MaxIdleTimeoutInMinutes),
+			Actual:  fmt.Sprintf("%d", timeout),
+		}
+	}
+
+	return nil
+}
+
+// validateMetricsDetection checks metrics-based idle detection against the template policy
+func validateMetricsDetection(metrics *workspacev1alpha1.MetricsIdleDetection, policy *workspacev1alpha1.IdleShutdownOverridePolicy, templateName string) []TemplateViolation {
+	if policy.AllowMetricsDetection != nil && !*policy.AllowMetricsDetection {
+		return []TemplateViolation{{
+			Type:    ViolationTypeMetricsDetectionNotAllowed,
+			Field:   "spec.idleShutdown.detection.metrics",
+			Message: fmt.Sprintf("Template '%s' does not allow metrics-based idle detection", templateName),
+			Allowed: "httpGet or jupyterAPI detection",
+			Actual:  "metrics",
+		}}
+	}
+
+	var violations []TemplateViolation
+
+	threshold := metrics.CPUThreshold
+	if policy.MinCPUThreshold != nil && threshold.Cmp(*policy.MinCPUThreshold) < 0 {
+		violations = append(violations, TemplateViolation{
+			Type:    ViolationTypeMetricsThresholdOutOfBounds,
+			Field:   "spec.idleShutdown.detection.metrics.cpuThreshold",
+			Message: fmt.Sprintf("CPU threshold %s is below minimum %s required by template '%s'", threshold.String(), policy.MinCPUThreshold.String(), templateName),
+			Allowed: fmt.Sprintf("min: %s", policy.MinCPUThreshold.String()),
+			Actual:  threshold.String(),
+		})
+	}
+	if policy.MaxCPUThreshold != nil && threshold.Cmp(*policy.MaxCPUThreshold) > 0 {
+		violations = append(violations, TemplateViolation{
+			Type:    ViolationTypeMetricsThresholdOutOfBounds,
+			Field:   "spec.idleShutdown.detection.metrics.cpuThreshold",
+			Message: fmt.Sprintf("CPU threshold %s exceeds maximum %s allowed by template '%s'", threshold.String(), policy.MaxCPUThreshold.String(), templateName),
+			Allowed: fmt.Sprintf("max: %s", policy.MaxCPUThreshold.String()),
+			Actual:  threshold.String(),
+		})
+	}
+
+	if metrics.Prometheus != nil && len(policy.AllowedPrometheusURLs) > 0 &&
+		!slices.Contains(policy.AllowedPrometheusURLs, metrics.Prometheus.URL) {
+		violations = append(violations, TemplateViolation{
+			Type:    ViolationTypePrometheusURLNotAllowed,
+			Field:   "spec.idleShutdown.detection.metrics.prometheus.url",
+			Message: fmt.Sprintf("Prometheus URL '%s' is not allowed by template '%s'", metrics.Prometheus.URL, templateName),
+			Allowed: fmt.Sprintf("%v", policy.AllowedPrometheusURLs),
+			Actual:  metrics.Prometheus.URL,
+		})
+	}
+
+	return violations
+}
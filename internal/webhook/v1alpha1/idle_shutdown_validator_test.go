@@ -0,0 +1,212 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	"github.com/jupyter-infra/jupyter-k8s/internal/controller"
+)
+
+var _ = Describe("IdleShutdownValidator", func() {
+	var (
+		workspace *workspacev1alpha1.Workspace
+		template  *workspacev1alpha1.WorkspaceTemplate
+	)
+
+	httpGetIdleShutdown := func(timeout int) *workspacev1alpha1.IdleShutdownSpec {
+		return &workspacev1alpha1.IdleShutdownSpec{
+			Enabled:              true,
+			IdleTimeoutInMinutes: timeout,
+			Detection: workspacev1alpha1.IdleDetectionSpec{
+				HTTPGet: &corev1.HTTPGetAction{Path: "/api/idle", Port: intstr.FromInt(8888)},
+			},
+		}
+	}
+
+	metricsIdleShutdown := func(cpuThreshold string) *workspacev1alpha1.IdleShutdownSpec {
+		return &workspacev1alpha1.IdleShutdownSpec{
+			Enabled:              true,
+			IdleTimeoutInMinutes: 30,
+			Detection: workspacev1alpha1.IdleDetectionSpec{
+				Metrics: &workspacev1alpha1.MetricsIdleDetection{
+					Source:       workspacev1alpha1.MetricsIdleSourcePrometheus,
+					CPUThreshold: resource.MustParse(cpuThreshold),
+					Prometheus:   &workspacev1alpha1.PrometheusMetricsSource{URL: "http://prometheus.monitoring.svc:9090"},
+				},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		workspace = &workspacev1alpha1.Workspace{}
+		template = &workspacev1alpha1.WorkspaceTemplate{}
+		template.Name = "test-template"
+	})
+
+	It("should return nil when template has no IdleShutdownOverrides", func() {
+		workspace.Spec.IdleShutdown = httpGetIdleShutdown(10)
+		Expect(validateIdleShutdownOverrides(workspace, template)).To(BeNil())
+	})
+
+	It("should return nil when workspace has no idle shutdown", func() {
+		allow := false
+		template.Spec.IdleShutdownOverrides = &workspacev1alpha1.IdleShutdownOverridePolicy{Allow: &allow}
+		Expect(validateIdleShutdownOverrides(workspace, template)).To(BeNil())
+	})
+
+	Context("Allow", func() {
+		BeforeEach(func() {
+			allow := false
+			template.Spec.IdleShutdownOverrides = &workspacev1alpha1.IdleShutdownOverridePolicy{Allow: &allow}
+			template.Spec.DefaultIdleShutdown = httpGetIdleShutdown(2)
+		})
+
+		It("should reject a custom idle shutdown when overrides are not allowed", func() {
+			workspace.Spec.IdleShutdown = httpGetIdleShutdown(10)
+
+			violations := validateIdleShutdownOverrides(workspace, template)
+			Expect(violations).To(HaveLen(1))
+			Expect(violations[0].Type).To(Equal(ViolationTypeIdleShutdownOverrideNotAllowed))
+			Expect(violations[0].Message).To(ContainSubstring("Idle shutdown overrides not allowed by template"))
+		})
+
+		It("should accept the template default idle shutdown", func() {
+			workspace.Spec.IdleShutdown = template.Spec.DefaultIdleShutdown.DeepCopy()
+			Expect(validateIdleShutdownOverrides(workspace, template)).To(BeEmpty())
+		})
+	})
+
+	Context("Timeout bounds", func() {
+		BeforeEach(func() {
+			minTimeout, maxTimeout := 5, 60
+			template.Spec.IdleShutdownOverrides = &workspacev1alpha1.IdleShutdownOverridePolicy{
+				MinIdleTimeoutInMinutes: &minTimeout,
+				MaxIdleTimeoutInMinutes: &maxTimeout,
+			}
+		})
+
+		It("should reject a timeout below the minimum", func() {
+			workspace.Spec.IdleShutdown = httpGetIdleShutdown(2)
+
+			violations := validateIdleShutdownOverrides(workspace, template)
+			Expect(violations).To(HaveLen(1))
+			Expect(violations[0].Type).To(Equal(ViolationTypeIdleShutdownTimeoutOutOfBounds))
+			Expect(violations[0].Allowed).To(Equal("min: 5"))
+		})
+
+		It("should reject a timeout above the maximum", func() {
+			workspace.Spec.IdleShutdown = httpGetIdleShutdown(120)
+
+			violations := validateIdleShutdownOverrides(workspace, template)
+			Expect(violations).To(HaveLen(1))
+			Expect(violations[0].Allowed).To(Equal("max: 60"))
+		})
+
+		It("should accept a timeout within bounds", func() {
+			workspace.Spec.IdleShutdown = httpGetIdleShutdown(30)
+			Expect(validateIdleShutdownOverrides(workspace, template)).To(BeEmpty())
+		})
+
+		It("should ignore the timeout when idle shutdown is disabled", func() {
+			workspace.Spec.IdleShutdown = httpGetIdleShutdown(120)
+			workspace.Spec.IdleShutdown.Enabled = false
+			Expect(validateIdleShutdownOverrides(workspace, template)).To(BeEmpty())
+		})
+	})
+
+	Context("Metrics detection", func() {
+		It("should reject metrics detection when not allowed", func() {
+			allowMetrics := false
+			template.Spec.IdleShutdownOverrides = &workspacev1alpha1.IdleShutdownOverridePolicy{AllowMetricsDetection: &allowMetrics}
+			workspace.Spec.IdleShutdown = metricsIdleShutdown("50m")
+
+			violations := validateIdleShutdownOverrides(workspace, template)
+			Expect(violations).To(HaveLen(1))
+			Expect(violations[0].Type).To(Equal(ViolationTypeMetricsDetectionNotAllowed))
+		})
+
+		It("should reject a CPU threshold outside the template bounds", func() {
+			minThreshold, maxThreshold := resource.MustParse("20m"), resource.MustParse("200m")
+			template.Spec.IdleShutdownOverrides = &workspacev1alpha1.IdleShutdownOverridePolicy{
+				MinCPUThreshold: &minThreshold,
+				MaxCPUThreshold: &maxThreshold,
+			}
+
+			workspace.Spec.IdleShutdown = metricsIdleShutdown("10m")
+			violations := validateIdleShutdownOverrides(workspace, template)
+			Expect(violations).To(HaveLen(1))
+			Expect(violations[0].Type).To(Equal(ViolationTypeMetricsThresholdOutOfBounds))
+			Expect(violations[0].Allowed).To(Equal("min: 20m"))
+
+			workspace.Spec.IdleShutdown = metricsIdleShutdown("1")
+			violations = validateIdleShutdownOverrides(workspace, template)
+			Expect(violations).To(HaveLen(1))
+			Expect(violations[0].Allowed).To(Equal("max: 200m"))
+
+			workspace.Spec.IdleShutdown = metricsIdleShutdown("100m")
+			Expect(validateIdleShutdownOverrides(workspace, template)).To(BeEmpty())
+		})
+
+		It("should reject a Prometheus URL outside the allowlist", func() {
+			template.Spec.IdleShutdownOverrides = &workspacev1alpha1.IdleShutdownOverridePolicy{
+				AllowedPrometheusURLs: []string{"http://thanos.monitoring.svc:9090"},
+			}
+			workspace.Spec.IdleShutdown = metricsIdleShutdown("50m")
+
+			violations := validateIdleShutdownOverrides(workspace, template)
+			Expect(violations).To(HaveLen(1))
+			Expect(violations[0].Type).To(Equal(ViolationTypePrometheusURLNotAllowed))
+
+			template.Spec.IdleShutdownOverrides.AllowedPrometheusURLs = append(template.Spec.IdleShutdownOverrides.AllowedPrometheusURLs,
+				"http://prometheus.monitoring.svc:9090")
+			Expect(validateIdleShutdownOverrides(workspace, template)).To(BeEmpty())
+		})
+	})
+
+	Context("validatePrometheusURL", func() {
+		It("should reject any Prometheus URL when the operator allows none", func() {
+			workspace.Spec.IdleShutdown = metricsIdleShutdown("50m")
+
+			Expect(validatePrometheusURL(nil, workspace)).To(MatchError(ContainSubstring("is not allowed")))
+		})
+
+		It("should accept a Prometheus URL allowed by the operator", func() {
+			GinkgoT().Setenv(controller.IdlePrometheusAllowedURLsEnv, "http://prometheus.monitoring.svc:9090/")
+			workspace.Spec.IdleShutdown = metricsIdleShutdown("50m")
+
+			Expect(validatePrometheusURL(nil, workspace)).To(Succeed())
+		})
+
+		It("should reject a changed Prometheus URL outside the operator allowlist", func() {
+			GinkgoT().Setenv(controller.IdlePrometheusAllowedURLsEnv, "http://prometheus.monitoring.svc:9090")
+			workspace.Spec.IdleShutdown = metricsIdleShutdown("50m")
+			updated := workspace.DeepCopy()
+			updated.Spec.IdleShutdown.Detection.Metrics.Prometheus.URL = "http://169.254.169.254"
+
+			Expect(validatePrometheusURL(workspace, updated)).To(HaveOccurred())
+		})
+
+		It("should not check an unchanged Prometheus URL on update", func() {
+			workspace.Spec.IdleShutdown = metricsIdleShutdown("50m")
+			updated := workspace.DeepCopy()
+			updated.Spec.DesiredStatus = "Stopped"
+
+			Expect(validatePrometheusURL(workspace, updated)).To(Succeed())
+		})
+
+		It("should ignore workspaces without Prometheus idle detection", func() {
+			workspace.Spec.IdleShutdown = httpGetIdleShutdown(30)
+
+			Expect(validatePrometheusURL(nil, workspace)).To(Succeed())
+		})
+	})
+})
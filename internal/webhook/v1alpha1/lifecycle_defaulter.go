@@ -16,8 +16,33 @@ func applyLifecycleDefaults(workspace *workspacev1alpha1.Workspace, template *wo
 		workspace.Spec.Lifecycle = template.Spec.DefaultLifecycle.DeepCopy()
 	}
 
+	// Apply probe defaults
+	if workspace.Spec.Probes == nil && template.Spec.DefaultProbes != nil {
+		workspace.Spec.Probes = template.Spec.DefaultProbes.DeepCopy()
+	}
+
 	// Apply idle shutdown defaults
 	if workspace.Spec.IdleShutdown == nil && template.Spec.DefaultIdleShutdown != nil {
 		workspace.Spec.IdleShutdown = template.Spec.DefaultIdleShutdown.DeepCopy()
 	}
+
+	// Apply schedule defaults
+	if workspace.Spec.Schedule == nil && template.Spec.DefaultSchedule != nil {
+		workspace.Spec.Schedule = template.Spec.DefaultSchedule.DeepCopy()
+	}
+
+	// Apply lifecycle policy defaults
+	if workspace.Spec.LifecyclePolicy == nil && template.Spec.DefaultLifecyclePolicy != nil {
+		workspace.Spec.LifecyclePolicy = template.Spec.DefaultLifecyclePolicy.DeepCopy()
+	}
+
+	// Apply startup timeout defaults
+	if workspace.Spec.StartupTimeout == nil && template.Spec.DefaultStartupTimeout != nil {
+		workspace.Spec.StartupTimeout = template.Spec.DefaultStartupTimeout.DeepCopy()
+	}
+
+	// Apply preemption restart defaults
+	if workspace.Spec.PreemptionRestart == nil && template.Spec.DefaultPreemptionRestart != nil {
+		workspace.Spec.PreemptionRestart = template.Spec.DefaultPreemptionRestart.DeepCopy()
+	}
 }
@@ -98,5 +98,96 @@ var _ = Describe("LifecycleDefaulter", func() {
 
 			Expect(workspace.Spec.IdleShutdown.Enabled).To(BeFalse())
 		})
+
+		It("should apply schedule defaults", func() {
+			template.Spec.DefaultSchedule = &workspacev1alpha1.ScheduleSpec{
+				StopSchedule: "0 20 * * *",
+				TimeZone:     "Europe/Paris",
+			}
+
+			applyLifecycleDefaults(workspace, template)
+
+			Expect(workspace.Spec.Schedule).ToNot(BeNil())
+			Expect(workspace.Spec.Schedule.StopSchedule).To(Equal("0 20 * * *"))
+			Expect(workspace.Spec.Schedule.TimeZone).To(Equal("Europe/Paris"))
+		})
+
+		It("should not override existing schedule", func() {
+			workspace.Spec.Schedule = &workspacev1alpha1.ScheduleSpec{
+				StartSchedule: "0 8 * * 1-5",
+			}
+			template.Spec.DefaultSchedule = &workspacev1alpha1.ScheduleSpec{
+				StopSchedule: "0 20 * * *",
+			}
+
+			applyLifecycleDefaults(workspace, template)
+
+			Expect(workspace.Spec.Schedule.StartSchedule).To(Equal("0 8 * * 1-5"))
+			Expect(workspace.Spec.Schedule.StopSchedule).To(BeEmpty())
+		})
+
+		It("should apply probe defaults", func() {
+			template.Spec.DefaultProbes = &workspacev1alpha1.ProbesSpec{
+				ReadinessProbe: &corev1.Probe{InitialDelaySeconds: 5},
+			}
+
+			applyLifecycleDefaults(workspace, template)
+
+			Expect(workspace.Spec.Probes).ToNot(BeNil())
+			Expect(workspace.Spec.Probes.ReadinessProbe.InitialDelaySeconds).To(Equal(int32(5)))
+			workspace.Spec.Probes.ReadinessProbe.InitialDelaySeconds = 10
+			Expect(template.Spec.DefaultProbes.ReadinessProbe.InitialDelaySeconds).To(Equal(int32(5)))
+		})
+
+		It("should apply lifecycle policy defaults", func() {
+			days := int32(30)
+			template.Spec.DefaultLifecyclePolicy = &workspacev1alpha1.LifecyclePolicySpec{
+				DeleteAfterStoppedInDays: &days,
+				RetainStorage:            true,
+			}
+
+			applyLifecycleDefaults(workspace, template)
+
+			Expect(workspace.Spec.LifecyclePolicy).ToNot(BeNil())
+			Expect(*workspace.Spec.LifecyclePolicy.DeleteAfterStoppedInDays).To(Equal(int32(30)))
+			Expect(workspace.Spec.LifecyclePolicy.RetainStorage).To(BeTrue())
+		})
+
+		It("should apply startup timeout defaults", func() {
+			template.Spec.DefaultStartupTimeout = &workspacev1alpha1.StartupTimeoutSpec{
+				TimeoutInSeconds: 600,
+				FailurePolicy:    workspacev1alpha1.StartupFailurePolicyStop,
+			}
+
+			applyLifecycleDefaults(workspace, template)
+
+			Expect(workspace.Spec.StartupTimeout).ToNot(BeNil())
+			Expect(workspace.Spec.StartupTimeout.TimeoutInSeconds).To(Equal(int32(600)))
+			Expect(workspace.Spec.StartupTimeout.FailurePolicy).To(Equal(workspacev1alpha1.StartupFailurePolicyStop))
+		})
+
+		It("should not override existing startup timeout", func() {
+			template.Spec.DefaultStartupTimeout = &workspacev1alpha1.StartupTimeoutSpec{TimeoutInSeconds: 600}
+			workspace.Spec.StartupTimeout = &workspacev1alpha1.StartupTimeoutSpec{TimeoutInSeconds: 120}
+
+			applyLifecycleDefaults(workspace, template)
+
+			Expect(workspace.Spec.StartupTimeout.TimeoutInSeconds).To(Equal(int32(120)))
+		})
+
+		It("should apply preemption restart defaults", func() {
+			template.Spec.DefaultPreemptionRestart = &workspacev1alpha1.PreemptionRestartPolicy{
+				Enabled:           true,
+				MaxAttempts:       5,
+				PriorityClassName: "workspace-high",
+			}
+
+			applyLifecycleDefaults(workspace, template)
+
+			Expect(workspace.Spec.PreemptionRestart).ToNot(BeNil())
+			Expect(workspace.Spec.PreemptionRestart.Enabled).To(BeTrue())
+			Expect(workspace.Spec.PreemptionRestart.MaxAttempts).To(Equal(int32(5)))
+			Expect(workspace.Spec.PreemptionRestart.PriorityClassName).To(Equal("workspace-high"))
+		})
 	})
 })
@@ -0,0 +1,66 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+)
+
+// validateLifecyclePolicy checks that the workspace lifecycle policy does not extend or remove
+// the durations of the template default lifecycle policy
+func validateLifecyclePolicy(workspace *workspacev1alpha1.Workspace, template *workspacev1alpha1.WorkspaceTemplate) []TemplateViolation {
+	templatePolicy := template.Spec.DefaultLifecyclePolicy
+	if templatePolicy == nil {
+		return nil
+	}
+
+	var workspaceStopped, workspaceLifetime *int32
+	if policy := workspace.Spec.LifecyclePolicy; policy != nil {
+		workspaceStopped = policy.DeleteAfterStoppedInDays
+		workspaceLifetime = policy.MaxLifetimeInDays
+	}
+
+	var violations []TemplateViolation
+	if violation := validateLifecycleDays(
+		workspaceStopped, templatePolicy.DeleteAfterStoppedInDays,
+		"spec.lifecyclePolicy.deleteAfterStoppedInDays", template.Name); violation != nil {
+		violations = append(violations, *violation)
+	}
+	if violation := validateLifecycleDays(
+		workspaceLifetime, templatePolicy.MaxLifetimeInDays,
+		"spec.lifecyclePolicy.maxLifetimeInDays", template.Name); violation != nil {
+		violations = append(violations, *violation)
+	}
+	return violations
+}
+
+// validateLifecycleDays checks a workspace lifecycle duration against the template maximum
+func validateLifecycleDays(days, maxDays *int32, field, templateName string) *TemplateViolation {
+	if maxDays == nil {
+		return nil
+	}
+	if days == nil {
+		return &TemplateViolation{
+			Type:    ViolationTypeLifecyclePolicyExceeded,
+			Field:   field,
+			Message: fmt.Sprintf("%s is required by template '%s'", field, templateName),
+			Allowed: fmt.Sprintf("max: %d", *maxDays),
+			Actual:  "unset",
+		}
+	}
+	if *days > *maxDays {
+		return &TemplateViolation{
+			Type:    ViolationTypeLifecyclePolicyExceeded,
+			Field:   field,
+			Message: fmt.Sprintf("%s %d days exceeds maximum %d allowed by template '%s'", field, *days, *maxDays, templateName),
+			Allowed: fmt.Sprintf("max: %d", *maxDays),
+			Actual:  fmt.Sprintf("%d", *days),
+		}
+	}
+	return nil
+}
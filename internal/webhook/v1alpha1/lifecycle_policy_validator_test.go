@@ -0,0 +1,67 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+)
+
+var _ = Describe("LifecyclePolicyValidator", func() {
+	var (
+		workspace *workspacev1alpha1.Workspace
+		template  *workspacev1alpha1.WorkspaceTemplate
+	)
+
+	days := func(d int32) *int32 {
+		return &d
+	}
+
+	BeforeEach(func() {
+		workspace = &workspacev1alpha1.Workspace{}
+		template = &workspacev1alpha1.WorkspaceTemplate{}
+		template.Name = "test-template"
+	})
+
+	It("should return nil when template has no DefaultLifecyclePolicy", func() {
+		workspace.Spec.LifecyclePolicy = &workspacev1alpha1.LifecyclePolicySpec{MaxLifetimeInDays: days(365)}
+		Expect(validateLifecyclePolicy(workspace, template)).To(BeNil())
+	})
+
+	It("should accept shorter durations than the template", func() {
+		template.Spec.DefaultLifecyclePolicy = &workspacev1alpha1.LifecyclePolicySpec{
+			DeleteAfterStoppedInDays: days(30),
+			MaxLifetimeInDays:        days(90),
+		}
+		workspace.Spec.LifecyclePolicy = &workspacev1alpha1.LifecyclePolicySpec{
+			DeleteAfterStoppedInDays: days(7),
+			MaxLifetimeInDays:        days(90),
+		}
+		Expect(validateLifecyclePolicy(workspace, template)).To(BeEmpty())
+	})
+
+	It("should reject a longer duration than the template", func() {
+		template.Spec.DefaultLifecyclePolicy = &workspacev1alpha1.LifecyclePolicySpec{DeleteAfterStoppedInDays: days(30)}
+		workspace.Spec.LifecyclePolicy = &workspacev1alpha1.LifecyclePolicySpec{DeleteAfterStoppedInDays: days(60)}
+
+		violations := validateLifecyclePolicy(workspace, template)
+		Expect(violations).To(HaveLen(1))
+		Expect(violations[0].Type).To(Equal(ViolationTypeLifecyclePolicyExceeded))
+		Expect(violations[0].Field).To(Equal("spec.lifecyclePolicy.deleteAfterStoppedInDays"))
+		Expect(violations[0].Allowed).To(Equal("max: 30"))
+	})
+
+	It("should reject removing a duration set by the template", func() {
+		template.Spec.DefaultLifecyclePolicy = &workspacev1alpha1.LifecyclePolicySpec{MaxLifetimeInDays: days(90)}
+
+		violations := validateLifecyclePolicy(workspace, template)
+		Expect(violations).To(HaveLen(1))
+		Expect(violations[0].Field).To(Equal("spec.lifecyclePolicy.maxLifetimeInDays"))
+		Expect(violations[0].Actual).To(Equal("unset"))
+	})
+})
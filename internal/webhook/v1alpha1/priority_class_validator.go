@@ -0,0 +1,60 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"slices"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+)
+
+// validatePriorityClasses checks the workspace priority class, and the one its preemption restart policy
+// bumps the pod to, against the template's allowed priority classes
+func validatePriorityClasses(workspace *workspacev1alpha1.Workspace, template *workspacev1alpha1.WorkspaceTemplate) []TemplateViolation {
+	effectiveAllowed := template.Spec.AllowedPriorityClassNames
+	if len(effectiveAllowed) == 0 && template.Spec.DefaultPriorityClassName != "" {
+		effectiveAllowed = []string{template.Spec.DefaultPriorityClassName}
+	}
+
+	var violations []TemplateViolation
+	checkAllowed := func(field, priorityClassName string) {
+		if priorityClassName == "" || slices.Contains(effectiveAllowed, priorityClassName) {
+			return
+		}
+		violations = append(violations, TemplateViolation{
+			Type:  ViolationTypePriorityClassNotAllowed,
+			Field: field,
+			Message: fmt.Sprintf("Priority class '%s' is not allowed by template '%s'. Allowed priority classes: %v",
+				priorityClassName, template.Name, effectiveAllowed),
+			Allowed: fmt.Sprintf("%v", effectiveAllowed),
+			Actual:  priorityClassName,
+		})
+	}
+
+	checkAllowed("spec.priorityClassName", workspace.Spec.PriorityClassName)
+	if workspace.Spec.PreemptionRestart != nil {
+		checkAllowed("spec.preemptionRestart.priorityClassName", workspace.Spec.PreemptionRestart.PriorityClassName)
+	}
+	return violations
+}
+
+// validateStandalonePriorityClasses rejects priority classes on workspaces without a template,
+// since only templates can allow priority classes
+func validateStandalonePriorityClasses(workspace *workspacev1alpha1.Workspace) error {
+	if workspace.Spec.TemplateRef != nil {
+		return nil
+	}
+	if workspace.Spec.PriorityClassName != "" {
+		return fmt.Errorf("spec.priorityClassName: priority class '%s' requires a template allowing it in allowedPriorityClassNames",
+			workspace.Spec.PriorityClassName)
+	}
+	if workspace.Spec.PreemptionRestart != nil && workspace.Spec.PreemptionRestart.PriorityClassName != "" {
+		return fmt.Errorf("spec.preemptionRestart.priorityClassName: priority class '%s' requires a template allowing it in allowedPriorityClassNames",
+			workspace.Spec.PreemptionRestart.PriorityClassName)
+	}
+	return nil
+}
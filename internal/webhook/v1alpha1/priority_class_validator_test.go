@@ -0,0 +1,100 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+)
+
+var _ = Describe("PriorityClassValidator", func() {
+	var (
+		workspace *workspacev1alpha1.Workspace
+		template  *workspacev1alpha1.WorkspaceTemplate
+	)
+
+	BeforeEach(func() {
+		workspace = &workspacev1alpha1.Workspace{}
+		template = &workspacev1alpha1.WorkspaceTemplate{}
+		template.Name = "test-template"
+	})
+
+	It("should accept workspaces without priority class", func() {
+		Expect(validatePriorityClasses(workspace, template)).To(BeEmpty())
+	})
+
+	It("should reject any priority class when the template allows none", func() {
+		workspace.Spec.PriorityClassName = "workspace-interactive"
+
+		violations := validatePriorityClasses(workspace, template)
+		Expect(violations).To(HaveLen(1))
+		Expect(violations[0].Type).To(Equal(ViolationTypePriorityClassNotAllowed))
+		Expect(violations[0].Field).To(Equal("spec.priorityClassName"))
+	})
+
+	It("should only allow the default priority class when no allowed list is set", func() {
+		template.Spec.DefaultPriorityClassName = "workspace-batch"
+
+		workspace.Spec.PriorityClassName = "workspace-batch"
+		Expect(validatePriorityClasses(workspace, template)).To(BeEmpty())
+
+		workspace.Spec.PriorityClassName = "workspace-interactive"
+		Expect(validatePriorityClasses(workspace, template)).To(HaveLen(1))
+	})
+
+	It("should accept priority classes from the allowed list", func() {
+		template.Spec.DefaultPriorityClassName = "workspace-batch"
+		template.Spec.AllowedPriorityClassNames = []string{"workspace-batch", "workspace-interactive"}
+		workspace.Spec.PriorityClassName = "workspace-interactive"
+
+		Expect(validatePriorityClasses(workspace, template)).To(BeEmpty())
+	})
+
+	It("should check the preemption restart priority class", func() {
+		template.Spec.AllowedPriorityClassNames = []string{"workspace-batch"}
+		workspace.Spec.PriorityClassName = "workspace-batch"
+		workspace.Spec.PreemptionRestart = &workspacev1alpha1.PreemptionRestartPolicy{
+			Enabled:           true,
+			PriorityClassName: "system-cluster-critical",
+		}
+
+		violations := validatePriorityClasses(workspace, template)
+		Expect(violations).To(HaveLen(1))
+		Expect(violations[0].Field).To(Equal("spec.preemptionRestart.priorityClassName"))
+		Expect(violations[0].Actual).To(Equal("system-cluster-critical"))
+	})
+
+	Context("validateStandalonePriorityClasses", func() {
+		It("should reject a workspace priority class without a template", func() {
+			workspace.Spec.PriorityClassName = "workspace-batch"
+
+			Expect(validateStandalonePriorityClasses(workspace)).To(
+				MatchError(ContainSubstring("spec.priorityClassName")))
+		})
+
+		It("should reject a restart priority class without a template", func() {
+			workspace.Spec.PreemptionRestart = &workspacev1alpha1.PreemptionRestartPolicy{
+				Enabled:           true,
+				PriorityClassName: "system-cluster-critical",
+			}
+
+			Expect(validateStandalonePriorityClasses(workspace)).To(
+				MatchError(ContainSubstring("spec.preemptionRestart.priorityClassName")))
+		})
+
+		It("should accept no priority class or a template", func() {
+			workspace.Spec.PreemptionRestart = &workspacev1alpha1.PreemptionRestartPolicy{Enabled: true}
+			Expect(validateStandalonePriorityClasses(workspace)).To(Succeed())
+
+			workspace.Spec.PriorityClassName = "workspace-batch"
+			workspace.Spec.PreemptionRestart.PriorityClassName = "workspace-interactive"
+			workspace.Spec.TemplateRef = &workspacev1alpha1.TemplateRef{Name: "test-template"}
+			Expect(validateStandalonePriorityClasses(workspace)).To(Succeed())
+		})
+	})
+})
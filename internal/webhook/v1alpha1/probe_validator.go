@@ -0,0 +1,72 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+)
+
+// defaultProbePeriodSeconds is the period Kubernetes uses for probes that do not set one
+const defaultProbePeriodSeconds = 10
+
+// validateProbeOverrides checks the workspace probes against the template's ProbeOverrides policy
+func validateProbeOverrides(workspace *workspacev1alpha1.Workspace, template *workspacev1alpha1.WorkspaceTemplate) []TemplateViolation {
+	policy := template.Spec.ProbeOverrides
+	probes := workspace.Spec.Probes
+	if policy == nil || probes == nil {
+		return nil
+	}
+
+	var violations []TemplateViolation
+
+	// When overrides are not allowed, the workspace must keep the template default probes
+	if policy.Allow != nil && !*policy.Allow && !equality.Semantic.DeepEqual(probes, template.Spec.DefaultProbes) {
+		violations = append(violations, TemplateViolation{
+			Type:    ViolationTypeProbeOverrideNotAllowed,
+			Field:   "spec.probes",
+			Message: fmt.Sprintf("Template '%s' does not allow overriding the default probes", template.Name),
+			Allowed: "template default probes",
+			Actual:  "custom probes",
+		})
+	}
+
+	if policy.MinPeriodSeconds != nil {
+		namedProbes := []struct {
+			name  string
+			probe *corev1.Probe
+		}{
+			{"readinessProbe", probes.ReadinessProbe},
+			{"livenessProbe", probes.LivenessProbe},
+			{"startupProbe", probes.StartupProbe},
+		}
+		for _, named := range namedProbes {
+			if named.probe == nil {
+				continue
+			}
+			period := named.probe.PeriodSeconds
+			if period == 0 {
+				period = defaultProbePeriodSeconds
+			}
+			if period < *policy.MinPeriodSeconds {
+				violations = append(violations, TemplateViolation{
+					Type:  ViolationTypeProbePeriodTooShort,
+					Field: fmt.Sprintf("spec.probes.%s.periodSeconds", named.name),
+					Message: fmt.Sprintf("Probe period %d seconds is below minimum %d required by template '%s'",
+						period, *policy.MinPeriodSeconds, template.Name),
+					Allowed: fmt.Sprintf("min: %d", *policy.MinPeriodSeconds),
+					Actual:  fmt.Sprintf("%d", period),
+				})
+			}
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,80 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+)
+
+var _ = Describe("ProbeValidator", func() {
+	var (
+		workspace *workspacev1alpha1.Workspace
+		template  *workspacev1alpha1.WorkspaceTemplate
+	)
+
+	newProbe := func(path string, periodSeconds int32) *corev1.Probe {
+		return &corev1.Probe{
+			ProbeHandler: corev1.ProbeHandler{
+				HTTPGet: &corev1.HTTPGetAction{Path: path, Port: intstr.FromInt(8888)},
+			},
+			PeriodSeconds: periodSeconds,
+		}
+	}
+
+	BeforeEach(func() {
+		workspace = &workspacev1alpha1.Workspace{}
+		template = &workspacev1alpha1.WorkspaceTemplate{}
+		template.Name = "test-template"
+	})
+
+	It("should return nil when template has no ProbeOverrides", func() {
+		workspace.Spec.Probes = &workspacev1alpha1.ProbesSpec{ReadinessProbe: newProbe("/ready", 1)}
+		Expect(validateProbeOverrides(workspace, template)).To(BeNil())
+	})
+
+	It("should reject custom probes when overrides are not allowed", func() {
+		allow := false
+		template.Spec.ProbeOverrides = &workspacev1alpha1.ProbeOverridePolicy{Allow: &allow}
+		template.Spec.DefaultProbes = &workspacev1alpha1.ProbesSpec{ReadinessProbe: newProbe("/api", 10)}
+		workspace.Spec.Probes = &workspacev1alpha1.ProbesSpec{ReadinessProbe: newProbe("/ready", 10)}
+
+		violations := validateProbeOverrides(workspace, template)
+		Expect(violations).To(HaveLen(1))
+		Expect(violations[0].Type).To(Equal(ViolationTypeProbeOverrideNotAllowed))
+	})
+
+	It("should accept the template default probes when overrides are not allowed", func() {
+		allow := false
+		template.Spec.ProbeOverrides = &workspacev1alpha1.ProbeOverridePolicy{Allow: &allow}
+		template.Spec.DefaultProbes = &workspacev1alpha1.ProbesSpec{ReadinessProbe: newProbe("/api", 10)}
+		workspace.Spec.Probes = template.Spec.DefaultProbes.DeepCopy()
+
+		Expect(validateProbeOverrides(workspace, template)).To(BeEmpty())
+	})
+
+	It("should reject probes more frequent than the minimum period", func() {
+		minPeriod := int32(15)
+		template.Spec.ProbeOverrides = &workspacev1alpha1.ProbeOverridePolicy{MinPeriodSeconds: &minPeriod}
+		workspace.Spec.Probes = &workspacev1alpha1.ProbesSpec{
+			ReadinessProbe: newProbe("/ready", 20),
+			LivenessProbe:  newProbe("/alive", 5),
+			// Unset period defaults to 10 seconds
+			StartupProbe: newProbe("/started", 0),
+		}
+
+		violations := validateProbeOverrides(workspace, template)
+		Expect(violations).To(HaveLen(2))
+		Expect(violations[0].Type).To(Equal(ViolationTypeProbePeriodTooShort))
+		Expect(violations[0].Field).To(Equal("spec.probes.livenessProbe.periodSeconds"))
+		Expect(violations[1].Field).To(Equal("spec.probes.startupProbe.periodSeconds"))
+		Expect(violations[1].Actual).To(Equal("10"))
+	})
+})
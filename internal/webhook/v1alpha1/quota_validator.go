@@ -0,0 +1,62 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"errors"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	workspaceutil "github.com/jupyter-infra/jupyter-k8s/internal/workspace"
+)
+
+// QuotaValidator enforces WorkspaceQuotas when workspaces are created or updated
+type QuotaValidator struct {
+	client              client.Client
+	controllerNamespace string
+}
+
+// NewQuotaValidator creates a new QuotaValidator
+func NewQuotaValidator(k8sClient client.Client, controllerNamespace string) *QuotaValidator {
+	return &QuotaValidator{
+		client:              k8sClient,
+		controllerNamespace: controllerNamespace,
+	}
+}
+
+// ValidateCreateWorkspace rejects a new workspace that would exceed a quota of its creator
+func (qv *QuotaValidator) ValidateCreateWorkspace(ctx context.Context, workspace *workspacev1alpha1.Workspace) error {
+	return qv.validateQuotas(ctx, workspace, workspaceutil.GetQuotaConsumption(workspace))
+}
+
+// ValidateUpdateWorkspace rejects an update that would exceed a quota of the workspace creator,
+// such as starting a stopped workspace or growing its resources or storage.
+// Updates that do not increase the consumption of the workspace are not checked against quotas.
+func (qv *QuotaValidator) ValidateUpdateWorkspace(
+	ctx context.Context,
+	oldWorkspace, newWorkspace *workspacev1alpha1.Workspace) error {
+	requested := workspaceutil.GetQuotaIncrease(oldWorkspace, newWorkspace)
+	if !workspaceutil.IsQuotaIncrease(requested) {
+		return nil
+	}
+	return qv.validateQuotas(ctx, newWorkspace, requested)
+}
+
+// validateQuotas checks the usage of the workspace creator and their groups, including the workspace,
+// against the limits of every quota covering the workspace namespace
+func (qv *QuotaValidator) validateQuotas(
+	ctx context.Context,
+	workspace *workspacev1alpha1.Workspace,
+	requested workspacev1alpha1.WorkspaceQuotaUsage) error {
+	err := workspaceutil.CheckQuotas(ctx, qv.client, qv.controllerNamespace, workspace, requested)
+	var exceeded *workspaceutil.QuotaExceededError
+	if errors.As(err, &exceeded) {
+		workspacelog.Info("Workspace exceeds quota", "workspace", workspace.Name, "quota", exceeded.Quota, "subject", exceeded.Subject)
+	}
+	return err
+}
@@ -0,0 +1,233 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	"github.com/jupyter-infra/jupyter-k8s/internal/controller"
+)
+
+var _ = Describe("QuotaValidator", func() {
+	var (
+		ctx    context.Context
+		scheme *runtime.Scheme
+	)
+
+	newQuotaWorkspace := func(name, namespace, owner, desiredStatus, cpu string) *workspacev1alpha1.Workspace {
+		return &workspacev1alpha1.Workspace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Annotations: map[string]string{
+					controller.AnnotationCreatedBy:       owner,
+					controller.AnnotationCreatedByGroups: "system:authenticated,data-science",
+				},
+			},
+			Spec: workspacev1alpha1.WorkspaceSpec{
+				DesiredStatus: desiredStatus,
+				Resources: &corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)},
+				},
+				Storage: &workspacev1alpha1.StorageSpec{Size: resource.MustParse("10Gi")},
+			},
+		}
+	}
+
+	newValidator := func(objects ...client.Object) *QuotaValidator {
+		return NewQuotaValidator(fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build(), "jupyter-k8s-system")
+	}
+
+	int32Ptr := func(i int32) *int32 { return &i }
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme = runtime.NewScheme()
+		_ = workspacev1alpha1.AddToScheme(scheme)
+	})
+
+	Context("ValidateCreateWorkspace", func() {
+		It("should allow workspaces without quotas", func() {
+			validator := newValidator(newQuotaWorkspace("ws-1", "default", "alice", "Running", "1"))
+
+			Expect(validator.ValidateCreateWorkspace(ctx, newQuotaWorkspace("ws-2", "default", "alice", "Running", "1"))).To(Succeed())
+		})
+
+		It("should reject a workspace exceeding the per-user workspace count", func() {
+			quota := &workspacev1alpha1.WorkspaceQuota{
+				ObjectMeta: metav1.ObjectMeta{Name: "users", Namespace: "default"},
+				Spec: workspacev1alpha1.WorkspaceQuotaSpec{
+					PerUser: &workspacev1alpha1.WorkspaceQuotaLimits{MaxWorkspaces: int32Ptr(2)},
+				},
+			}
+			validator := newValidator(quota,
+				newQuotaWorkspace("ws-1", "default", "alice", "Stopped", "1"),
+				newQuotaWorkspace("ws-2", "default", "alice", "Stopped", "1"),
+				newQuotaWorkspace("ws-3", "default", "bob", "Stopped", "1"))
+
+			err := validator.ValidateCreateWorkspace(ctx, newQuotaWorkspace("ws-4", "default", "alice", "Stopped", "1"))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("quota default/users for user alice"))
+			Expect(err.Error()).To(ContainSubstring("workspaces: 3 exceeds the limit of 2"))
+
+			Expect(validator.ValidateCreateWorkspace(ctx, newQuotaWorkspace("ws-4", "default", "bob", "Stopped", "1"))).To(Succeed())
+		})
+
+		It("should only check the limits the workspace consumes", func() {
+			quota := &workspacev1alpha1.WorkspaceQuota{
+				ObjectMeta: metav1.ObjectMeta{Name: "users", Namespace: "default"},
+				Spec: workspacev1alpha1.WorkspaceQuotaSpec{
+					PerUser: &workspacev1alpha1.WorkspaceQuotaLimits{
+						MaxRunningWorkspaces: int32Ptr(1),
+						Resources:            corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+					},
+				},
+			}
+			validator := newValidator(quota,
+				newQuotaWorkspace("ws-1", "default", "alice", "Running", "2"))
+
+			// a stopped workspace does not count against running limits
+			Expect(validator.ValidateCreateWorkspace(ctx, newQuotaWorkspace("ws-2", "default", "alice", "Stopped", "1"))).To(Succeed())
+
+			err := validator.ValidateCreateWorkspace(ctx, newQuotaWorkspace("ws-2", "default", "alice", "Running", "1"))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("running workspaces: 2 exceeds the limit of 1"))
+			Expect(err.Error()).To(ContainSubstring("cpu: 3 exceeds the limit of 2"))
+		})
+
+		It("should reject a workspace exceeding the combined resources of a group", func() {
+			quota := &workspacev1alpha1.WorkspaceQuota{
+				ObjectMeta: metav1.ObjectMeta{Name: "groups", Namespace: "default"},
+				Spec: workspacev1alpha1.WorkspaceQuotaSpec{
+					PerGroup: []workspacev1alpha1.GroupQuota{{
+						Group: "data-science",
+						WorkspaceQuotaLimits: workspacev1alpha1.WorkspaceQuotaLimits{
+							Resources: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("25Gi")},
+						},
+					}},
+				},
+			}
+			validator := newValidator(quota,
+				newQuotaWorkspace("ws-1", "default", "alice", "Stopped", "1"),
+				newQuotaWorkspace("ws-2", "default", "bob", "Stopped", "1"))
+
+			err := validator.ValidateCreateWorkspace(ctx, newQuotaWorkspace("ws-3", "default", "carol", "Stopped", "1"))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("for group data-science: storage: 30Gi exceeds the limit of 25Gi"))
+
+			// members of other groups are not limited
+			other := newQuotaWorkspace("ws-3", "default", "dave", "Stopped", "1")
+			other.Annotations[controller.AnnotationCreatedByGroups] = "system:authenticated"
+			Expect(validator.ValidateCreateWorkspace(ctx, other)).To(Succeed())
+		})
+
+		It("should count workspaces of all namespaces for cluster quotas in the controller namespace", func() {
+			quota := &workspacev1alpha1.WorkspaceQuota{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "jupyter-k8s-system"},
+				Spec: workspacev1alpha1.WorkspaceQuotaSpec{
+					Scope:   workspacev1alpha1.WorkspaceQuotaScopeCluster,
+					PerUser: &workspacev1alpha1.WorkspaceQuotaLimits{MaxWorkspaces: int32Ptr(1)},
+				},
+			}
+			validator := newValidator(quota,
+				newQuotaWorkspace("ws-1", "team-a", "alice", "Stopped", "1"))
+
+			err := validator.ValidateCreateWorkspace(ctx, newQuotaWorkspace("ws-2", "team-b", "alice", "Stopped", "1"))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("quota jupyter-k8s-system/cluster"))
+		})
+
+		It("should ignore cluster quotas outside of the controller namespace", func() {
+			quota := &workspacev1alpha1.WorkspaceQuota{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "team-a"},
+				Spec: workspacev1alpha1.WorkspaceQuotaSpec{
+					Scope:   workspacev1alpha1.WorkspaceQuotaScopeCluster,
+					PerUser: &workspacev1alpha1.WorkspaceQuotaLimits{MaxWorkspaces: int32Ptr(0)},
+				},
+			}
+			validator := newValidator(quota)
+
+			Expect(validator.ValidateCreateWorkspace(ctx, newQuotaWorkspace("ws-1", "team-b", "alice", "Stopped", "1"))).To(Succeed())
+		})
+	})
+
+	Context("ValidateUpdateWorkspace", func() {
+		var quota *workspacev1alpha1.WorkspaceQuota
+
+		BeforeEach(func() {
+			quota = &workspacev1alpha1.WorkspaceQuota{
+				ObjectMeta: metav1.ObjectMeta{Name: "users", Namespace: "default"},
+				Spec: workspacev1alpha1.WorkspaceQuotaSpec{
+					PerUser: &workspacev1alpha1.WorkspaceQuotaLimits{
+						MaxWorkspaces:        int32Ptr(1),
+						MaxRunningWorkspaces: int32Ptr(1),
+					},
+				},
+			}
+		})
+
+		It("should reject starting a workspace over the running limit", func() {
+			stopped := newQuotaWorkspace("ws-2", "default", "alice", "Stopped", "1")
+			validator := newValidator(quota, stopped,
+				newQuotaWorkspace("ws-1", "default", "alice", "Running", "1"))
+
+			started := stopped.DeepCopy()
+			started.Spec.DesiredStatus = "Running"
+			err := validator.ValidateUpdateWorkspace(ctx, stopped, started)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("running workspaces: 2 exceeds the limit of 1"))
+			// the workspace count is already exceeded but is not affected by a start
+			Expect(err.Error()).NotTo(ContainSubstring("alice: workspaces:"))
+		})
+
+		It("should allow updates that do not start the workspace", func() {
+			running := newQuotaWorkspace("ws-2", "default", "alice", "Running", "1")
+			validator := newValidator(quota, running,
+				newQuotaWorkspace("ws-1", "default", "alice", "Running", "1"))
+
+			updated := running.DeepCopy()
+			updated.Spec.DisplayName = "Renamed"
+			Expect(validator.ValidateUpdateWorkspace(ctx, running, updated)).To(Succeed())
+		})
+
+		It("should reject growing the resources of a running workspace over the limit", func() {
+			quota.Spec.PerUser.Resources = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")}
+			running := newQuotaWorkspace("ws-1", "default", "alice", "Running", "1")
+			validator := newValidator(quota, running)
+
+			updated := running.DeepCopy()
+			updated.Spec.Resources.Requests[corev1.ResourceCPU] = resource.MustParse("4")
+			err := validator.ValidateUpdateWorkspace(ctx, running, updated)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("cpu: 4 exceeds the limit of 2"))
+
+			// shrinking is allowed even when the user is over the limit
+			Expect(validator.ValidateUpdateWorkspace(ctx, updated, running)).To(Succeed())
+		})
+
+		It("should reject growing the storage of a stopped workspace over the limit", func() {
+			quota.Spec.PerUser.Resources = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("15Gi")}
+			stopped := newQuotaWorkspace("ws-1", "default", "alice", "Stopped", "1")
+			validator := newValidator(quota, stopped)
+
+			updated := stopped.DeepCopy()
+			updated.Spec.Storage.Size = resource.MustParse("20Gi")
+			err := validator.ValidateUpdateWorkspace(ctx, stopped, updated)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("storage: 20Gi exceeds the limit of 15Gi"))
+		})
+	})
+})
@@ -14,7 +14,7 @@ import (
 )
 
 // validateReservedPrefixOnCreate rejects any workspace.jupyter.org/ prefixed labels or annotations
-// that are not in the system-managed allow-list.
+// that are not in the system-managed allow-list, or that only the controller may set.
 func validateReservedPrefixOnCreate(workspace *workspacev1alpha1.Workspace) error {
 	if err := checkReservedKeys(workspace.Labels, "label"); err != nil {
 		return err
@@ -25,6 +25,8 @@ func validateReservedPrefixOnCreate(workspace *workspacev1alpha1.Workspace) erro
 // validateReservedPrefixOnUpdate rejects user changes to workspace.jupyter.org/ prefixed labels or annotations.
 // For SetOnCreateOnly keys: rejects any value change or removal.
 // For SetAlways keys: allows changes (system will overwrite).
+// For SetByUser keys: allows additions, changes and removals.
+// For SetByController keys: rejects additions and changes, allows removals.
 // For unknown labels/annotations with reserved keys: rejects additions, changes, and removals.
 func validateReservedPrefixOnUpdate(oldWorkspace, newWorkspace *workspacev1alpha1.Workspace) error {
 	if err := checkReservedKeyChanges(oldWorkspace.Labels, newWorkspace.Labels, "label"); err != nil {
@@ -33,13 +35,17 @@ func validateReservedPrefixOnUpdate(oldWorkspace, newWorkspace *workspacev1alpha
 	return checkReservedKeyChanges(oldWorkspace.Annotations, newWorkspace.Annotations, "annotation")
 }
 
-// checkReservedKeys checks if any key with the reserved prefix is not system-managed.
+// checkReservedKeys checks if any key with the reserved prefix is not system-managed or is set by the controller only.
 func checkReservedKeys(metadata map[string]string, kind string) error {
 	for key := range metadata {
 		if strings.HasPrefix(key, controller.ReservedMetadataPrefix) {
-			if _, ok := controller.SystemManagedMetadataKeys[key]; !ok {
+			policy, ok := controller.SystemManagedMetadataKeys[key]
+			if !ok {
 				return fmt.Errorf("%s '%s' uses reserved prefix %s", kind, key, controller.ReservedMetadataPrefix)
 			}
+			if policy == controller.SetByController {
+				return fmt.Errorf("%s '%s' can only be set by the controller", kind, key)
+			}
 		}
 	}
 	return nil
@@ -65,6 +71,11 @@ func checkReservedKeyChanges(oldMeta, newMeta map[string]string, kind string) er
 		if existed && oldVal != newVal && policy == controller.SetOnCreateOnly {
 			return fmt.Errorf("%s '%s' is immutable", kind, key)
 		}
+
+		// Reject if added or changed reserved key is set by the controller only
+		if (!existed || oldVal != newVal) && policy == controller.SetByController {
+			return fmt.Errorf("%s '%s' can only be set by the controller", kind, key)
+		}
 	}
 
 	// Check for removed keys
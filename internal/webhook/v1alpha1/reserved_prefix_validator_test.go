@@ -74,6 +74,15 @@ var _ = Describe("Reserved Prefix Validator", func() {
 		It("should allow workspace with nil labels and annotations", func() {
 			Expect(validateReservedPrefixOnCreate(workspace)).To(Succeed())
 		})
+
+		It("should reject workspace with the SetByController lifecycle-deletion annotation", func() {
+			workspace.Annotations = map[string]string{
+				controller.AnnotationLifecycleDeletion: "stopped for 30 days",
+			}
+			err := validateReservedPrefixOnCreate(workspace)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("annotation 'workspace.jupyter.org/lifecycle-deletion' can only be set by the controller"))
+		})
 	})
 
 	Context("validateReservedPrefixOnUpdate", func() {
@@ -169,6 +178,47 @@ var _ = Describe("Reserved Prefix Validator", func() {
 			Expect(validateReservedPrefixOnUpdate(oldWorkspace, workspace)).To(Succeed())
 		})
 
+		It("should allow adding and removing the SetByUser deletion-protection annotation", func() {
+			workspace.Annotations = map[string]string{
+				controller.AnnotationDeletionProtection: "true",
+			}
+			Expect(validateReservedPrefixOnUpdate(oldWorkspace, workspace)).To(Succeed())
+			Expect(validateReservedPrefixOnUpdate(workspace, oldWorkspace)).To(Succeed())
+		})
+
+		It("should reject adding the SetByController lifecycle-deletion annotation", func() {
+			workspace.Annotations = map[string]string{
+				controller.AnnotationLifecycleDeletion: "stopped for 30 days",
+			}
+			err := validateReservedPrefixOnUpdate(oldWorkspace, workspace)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("annotation 'workspace.jupyter.org/lifecycle-deletion' can only be set by the controller"))
+		})
+
+		It("should reject changing the SetByController lifecycle-deletion annotation", func() {
+			oldWorkspace.Annotations = map[string]string{
+				controller.AnnotationLifecycleDeletion: "stopped for 30 days",
+			}
+			workspace.Annotations = map[string]string{
+				controller.AnnotationLifecycleDeletion: "inactive for 1 day",
+			}
+			err := validateReservedPrefixOnUpdate(oldWorkspace, workspace)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("annotation 'workspace.jupyter.org/lifecycle-deletion' can only be set by the controller"))
+		})
+
+		It("should allow keeping or removing the SetByController lifecycle-deletion annotation", func() {
+			oldWorkspace.Annotations = map[string]string{
+				controller.AnnotationLifecycleDeletion: "stopped for 30 days",
+			}
+			workspace.Annotations = map[string]string{
+				controller.AnnotationLifecycleDeletion: "stopped for 30 days",
+			}
+			Expect(validateReservedPrefixOnUpdate(oldWorkspace, workspace)).To(Succeed())
+			workspace.Annotations = map[string]string{}
+			Expect(validateReservedPrefixOnUpdate(oldWorkspace, workspace)).To(Succeed())
+		})
+
 		It("should reject setting created-by to empty string", func() {
 			oldWorkspace.Annotations = map[string]string{
 				controller.AnnotationCreatedBy: "original-user",
@@ -0,0 +1,57 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	workspaceutil "github.com/jupyter-infra/jupyter-k8s/internal/workspace"
+)
+
+// validateScheduleSyntax checks that the workspace schedule cron expressions and time zone are valid
+func validateScheduleSyntax(workspace *workspacev1alpha1.Workspace) error {
+	if _, err := workspaceutil.ParseSchedule(workspace.Spec.Schedule); err != nil {
+		return fmt.Errorf("invalid spec.schedule: %w", err)
+	}
+	return nil
+}
+
+// validateScheduleOverrides checks the workspace schedule against the template's ScheduleOverrides policy
+func validateScheduleOverrides(workspace *workspacev1alpha1.Workspace, template *workspacev1alpha1.WorkspaceTemplate) []TemplateViolation {
+	policy := template.Spec.ScheduleOverrides
+	if policy == nil || workspace.Spec.Schedule == nil {
+		return nil
+	}
+
+	var violations []TemplateViolation
+
+	// When overrides are not allowed, the workspace must keep the template default schedule
+	if policy.Allow != nil && !*policy.Allow &&
+		!equality.Semantic.DeepEqual(workspace.Spec.Schedule, template.Spec.DefaultSchedule) {
+		violations = append(violations, TemplateViolation{
+			Type:    ViolationTypeScheduleOverrideNotAllowed,
+			Field:   "spec.schedule",
+			Message: fmt.Sprintf("Template '%s' does not allow overriding the default schedule", template.Name),
+			Allowed: "template default schedule",
+			Actual:  fmt.Sprintf("start=%q stop=%q timeZone=%q", workspace.Spec.Schedule.StartSchedule, workspace.Spec.Schedule.StopSchedule, workspace.Spec.Schedule.TimeZone),
+		})
+	}
+
+	if policy.AllowStartSchedule != nil && !*policy.AllowStartSchedule && workspace.Spec.Schedule.StartSchedule != "" {
+		violations = append(violations, TemplateViolation{
+			Type:    ViolationTypeStartScheduleNotAllowed,
+			Field:   "spec.schedule.startSchedule",
+			Message: fmt.Sprintf("Template '%s' does not allow workspaces to be started on a schedule", template.Name),
+			Allowed: "no start schedule",
+			Actual:  workspace.Spec.Schedule.StartSchedule,
+		})
+	}
+
+	return violations
+}
@@ -0,0 +1,92 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+)
+
+var _ = Describe("ScheduleValidator", func() {
+	var (
+		workspace *workspacev1alpha1.Workspace
+		template  *workspacev1alpha1.WorkspaceTemplate
+	)
+
+	BeforeEach(func() {
+		workspace = &workspacev1alpha1.Workspace{}
+		template = &workspacev1alpha1.WorkspaceTemplate{}
+		template.Name = "test-template"
+	})
+
+	Context("validateScheduleSyntax", func() {
+		It("should accept a workspace without schedule", func() {
+			Expect(validateScheduleSyntax(workspace)).To(Succeed())
+		})
+
+		It("should accept a valid schedule", func() {
+			workspace.Spec.Schedule = &workspacev1alpha1.ScheduleSpec{
+				StartSchedule: "0 9 * * 1-5",
+				StopSchedule:  "@midnight",
+				TimeZone:      "Europe/Paris",
+			}
+			Expect(validateScheduleSyntax(workspace)).To(Succeed())
+		})
+
+		It("should reject an invalid cron expression", func() {
+			workspace.Spec.Schedule = &workspacev1alpha1.ScheduleSpec{StopSchedule: "every evening"}
+			err := validateScheduleSyntax(workspace)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("stopSchedule"))
+		})
+
+		It("should reject an unknown time zone", func() {
+			workspace.Spec.Schedule = &workspacev1alpha1.ScheduleSpec{StopSchedule: "0 18 * * *", TimeZone: "Nowhere/Land"}
+			err := validateScheduleSyntax(workspace)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("timeZone"))
+		})
+	})
+
+	Context("validateScheduleOverrides", func() {
+		It("should return nil when template has no ScheduleOverrides", func() {
+			workspace.Spec.Schedule = &workspacev1alpha1.ScheduleSpec{StopSchedule: "0 18 * * *"}
+			Expect(validateScheduleOverrides(workspace, template)).To(BeNil())
+		})
+
+		It("should reject a custom schedule when overrides are not allowed", func() {
+			allow := false
+			template.Spec.ScheduleOverrides = &workspacev1alpha1.ScheduleOverridePolicy{Allow: &allow}
+			template.Spec.DefaultSchedule = &workspacev1alpha1.ScheduleSpec{StopSchedule: "0 18 * * *"}
+			workspace.Spec.Schedule = &workspacev1alpha1.ScheduleSpec{StopSchedule: "0 23 * * *"}
+
+			violations := validateScheduleOverrides(workspace, template)
+			Expect(violations).To(HaveLen(1))
+			Expect(violations[0].Type).To(Equal(ViolationTypeScheduleOverrideNotAllowed))
+		})
+
+		It("should accept the template default schedule when overrides are not allowed", func() {
+			allow := false
+			template.Spec.ScheduleOverrides = &workspacev1alpha1.ScheduleOverridePolicy{Allow: &allow}
+			template.Spec.DefaultSchedule = &workspacev1alpha1.ScheduleSpec{StopSchedule: "0 18 * * *"}
+			workspace.Spec.Schedule = template.Spec.DefaultSchedule.DeepCopy()
+
+			Expect(validateScheduleOverrides(workspace, template)).To(BeEmpty())
+		})
+
+		It("should reject a start schedule when start schedules are not allowed", func() {
+			allowStart := false
+			template.Spec.ScheduleOverrides = &workspacev1alpha1.ScheduleOverridePolicy{AllowStartSchedule: &allowStart}
+			workspace.Spec.Schedule = &workspacev1alpha1.ScheduleSpec{StartSchedule: "0 9 * * *"}
+
+			violations := validateScheduleOverrides(workspace, template)
+			Expect(violations).To(HaveLen(1))
+			Expect(violations[0].Type).To(Equal(ViolationTypeStartScheduleNotAllowed))
+		})
+	})
+})
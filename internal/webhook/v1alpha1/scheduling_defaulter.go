@@ -31,4 +31,9 @@ func applySchedulingDefaults(workspace *workspacev1alpha1.Workspace, template *w
 		workspace.Spec.Tolerations = make([]corev1.Toleration, len(template.Spec.DefaultTolerations))
 		copy(workspace.Spec.Tolerations, template.Spec.DefaultTolerations)
 	}
+
+	// Apply priority class defaults
+	if workspace.Spec.PriorityClassName == "" && template.Spec.DefaultPriorityClassName != "" {
+		workspace.Spec.PriorityClassName = template.Spec.DefaultPriorityClassName
+	}
 }
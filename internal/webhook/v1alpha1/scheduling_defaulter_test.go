@@ -129,6 +129,23 @@ var _ = Describe("SchedulingDefaulter", func() {
 			Expect(workspace.Spec.Tolerations[0].Key).To(Equal("existing"))
 		})
 
+		It("should apply priority class defaults when empty", func() {
+			template.Spec.DefaultPriorityClassName = "workspace-interactive"
+
+			applySchedulingDefaults(workspace, template)
+
+			Expect(workspace.Spec.PriorityClassName).To(Equal("workspace-interactive"))
+		})
+
+		It("should not override existing priority class", func() {
+			template.Spec.DefaultPriorityClassName = "workspace-interactive"
+			workspace.Spec.PriorityClassName = "workspace-batch"
+
+			applySchedulingDefaults(workspace, template)
+
+			Expect(workspace.Spec.PriorityClassName).To(Equal("workspace-batch"))
+		})
+
 		It("should create independent copies (deep copy test)", func() {
 			applySchedulingDefaults(workspace, template)
 
@@ -0,0 +1,98 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	"github.com/jupyter-infra/jupyter-k8s/internal/controller"
+)
+
+// validateSecondaryStorageBounds checks the secondary storages of the workspace against the template caps
+func validateSecondaryStorageBounds(workspace *workspacev1alpha1.Workspace, template *workspacev1alpha1.WorkspaceTemplate) []TemplateViolation {
+	storages := workspace.Spec.SecondaryStorages
+	if len(storages) == 0 {
+		return nil
+	}
+
+	// Check AllowSecondaryStorages setting (default is true if not specified)
+	if template.Spec.AllowSecondaryStorages != nil && !*template.Spec.AllowSecondaryStorages {
+		return []TemplateViolation{{
+			Type:    ViolationTypeSecondaryStorageNotAllowed,
+			Field:   "spec.secondaryStorages",
+			Message: fmt.Sprintf("Template '%s' does not allow secondary storages, but workspace specifies %d storage(s)", template.Name, len(storages)),
+			Allowed: "no secondary storages",
+			Actual:  fmt.Sprintf("%d storage(s)", len(storages)),
+		}}
+	}
+
+	config := template.Spec.SecondaryStorage
+	if config == nil {
+		return nil
+	}
+
+	var violations []TemplateViolation
+	if config.MaxCount != nil && int32(len(storages)) > *config.MaxCount {
+		violations = append(violations, TemplateViolation{
+			Type:    ViolationTypeSecondaryStorageExceeded,
+			Field:   "spec.secondaryStorages",
+			Message: fmt.Sprintf("Workspace specifies %d secondary storages, template '%s' allows at most %d", len(storages), template.Name, *config.MaxCount),
+			Allowed: fmt.Sprintf("max: %d", *config.MaxCount),
+			Actual:  fmt.Sprintf("%d", len(storages)),
+		})
+	}
+
+	for _, storage := range storages {
+		field := fmt.Sprintf("spec.secondaryStorages[%s].size", storage.Name)
+		if config.MinSize != nil && storage.Size.Cmp(*config.MinSize) < 0 {
+			violations = append(violations, TemplateViolation{
+				Type:    ViolationTypeSecondaryStorageExceeded,
+				Field:   field,
+				Message: fmt.Sprintf("Secondary storage '%s' size %s is below minimum %s required by template '%s'", storage.Name, storage.Size.String(), config.MinSize.String(), template.Name),
+				Allowed: fmt.Sprintf("min: %s", config.MinSize.String()),
+				Actual:  storage.Size.String(),
+			})
+		}
+		if config.MaxSize != nil && storage.Size.Cmp(*config.MaxSize) > 0 {
+			violations = append(violations, TemplateViolation{
+				Type:    ViolationTypeSecondaryStorageExceeded,
+				Field:   field,
+				Message: fmt.Sprintf("Secondary storage '%s' size %s exceeds maximum %s allowed by template '%s'", storage.Name, storage.Size.String(), config.MaxSize.String(), template.Name),
+				Allowed: fmt.Sprintf("max: %s", config.MaxSize.String()),
+				Actual:  storage.Size.String(),
+			})
+		}
+	}
+	return violations
+}
+
+// validateSecondaryStorageMounts rejects secondary storages mounted at the path of another volume of the workspace
+func validateSecondaryStorageMounts(workspace *workspacev1alpha1.Workspace) error {
+	if len(workspace.Spec.SecondaryStorages) == 0 {
+		return nil
+	}
+
+	mountPaths := map[string]string{}
+	if workspace.Spec.Storage != nil {
+		mountPaths[workspace.Spec.Storage.MountPath] = "the primary storage"
+		if workspace.Spec.Storage.MountPath == "" {
+			mountPaths[controller.DefaultMountPath] = "the primary storage"
+		}
+	}
+	for _, volume := range workspace.Spec.Volumes {
+		mountPaths[volume.MountPath] = fmt.Sprintf("volume '%s'", volume.Name)
+	}
+
+	for _, storage := range workspace.Spec.SecondaryStorages {
+		if other, exists := mountPaths[storage.MountPath]; exists {
+			return fmt.Errorf("spec.secondaryStorages[%s].mountPath %s is already used by %s",
+				storage.Name, storage.MountPath, other)
+		}
+		mountPaths[storage.MountPath] = fmt.Sprintf("secondary storage '%s'", storage.Name)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package v1alpha1
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+)
+
+var _ = Describe("SecondaryStorageValidator", func() {
+	var (
+		template  *workspacev1alpha1.WorkspaceTemplate
+		workspace *workspacev1alpha1.Workspace
+	)
+
+	BeforeEach(func() {
+		maxCount := int32(2)
+		minSize := resource.MustParse("1Gi")
+		maxSize := resource.MustParse("100Gi")
+		template = &workspacev1alpha1.WorkspaceTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-template"},
+			Spec: workspacev1alpha1.WorkspaceTemplateSpec{
+				SecondaryStorage: &workspacev1alpha1.SecondaryStorageConfig{
+					MaxCount: &maxCount,
+					MinSize:  &minSize,
+					MaxSize:  &maxSize,
+				},
+			},
+		}
+		workspace = &workspacev1alpha1.Workspace{
+			Spec: workspacev1alpha1.WorkspaceSpec{
+				Storage: &workspacev1alpha1.StorageSpec{Size: resource.MustParse("10Gi"), MountPath: "/home/jovyan"},
+				SecondaryStorages: []workspacev1alpha1.SecondaryStorageSpec{
+					{Name: "scratch", Size: resource.MustParse("50Gi"), MountPath: "/scratch"},
+				},
+			},
+		}
+	})
+
+	Context("validateSecondaryStorageBounds", func() {
+		It("should allow secondary storages within the template caps", func() {
+			Expect(validateSecondaryStorageBounds(workspace, template)).To(BeEmpty())
+		})
+
+		It("should reject secondary storages when the template does not allow them", func() {
+			allowed := false
+			template.Spec.AllowSecondaryStorages = &allowed
+
+			violations := validateSecondaryStorageBounds(workspace, template)
+			Expect(violations).To(HaveLen(1))
+			Expect(violations[0].Type).To(Equal(ViolationTypeSecondaryStorageNotAllowed))
+		})
+
+		It("should reject more secondary storages than the template allows", func() {
+			workspace.Spec.SecondaryStorages = append(workspace.Spec.SecondaryStorages,
+				workspacev1alpha1.SecondaryStorageSpec{Name: "data", Size: resource.MustParse("10Gi"), MountPath: "/data"},
+				workspacev1alpha1.SecondaryStorageSpec{Name: "cache", Size: resource.MustParse("10Gi"), MountPath: "/cache"})
+
+			violations := validateSecondaryStorageBounds(workspace, template)
+			Expect(violations).To(HaveLen(1))
+			Expect(violations[0].Type).To(Equal(ViolationTypeSecondaryStorageExceeded))
+			Expect(violations[0].Field).To(Equal("spec.secondaryStorages"))
+		})
+
+		It("should reject secondary storages outside the template size bounds", func() {
+			workspace.Spec.SecondaryStorages[0].Size = resource.MustParse("200Gi")
+			workspace.Spec.SecondaryStorages = append(workspace.Spec.SecondaryStorages,
+				workspacev1alpha1.SecondaryStorageSpec{Name: "data", Size: resource.MustParse("500Mi"), MountPath: "/data"})
+
+			violations := validateSecondaryStorageBounds(workspace, template)
+			Expect(violations).To(HaveLen(2))
+			Expect(violations[0].Field).To(Equal("spec.secondaryStorages[scratch].size"))
+			Expect(violations[0].Allowed).To(Equal("max: 100Gi"))
+			Expect(violations[1].Field).To(Equal("spec.secondaryStorages[data].size"))
+			Expect(violations[1].Allowed).To(Equal("min: 1Gi"))
+		})
+	})
+
+	Context("validateSecondaryStorageMounts", func() {
+		It("should allow distinct mount paths", func() {
+			Expect(validateSecondaryStorageMounts(workspace)).To(Succeed())
+		})
+
+		It("should reject a secondary storage mounted over the primary storage", func() {
+			workspace.Spec.SecondaryStorages[0].MountPath = "/home/jovyan"
+
+			err := validateSecondaryStorageMounts(workspace)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("already used by the primary storage"))
+		})
+
+		It("should reject secondary storages sharing a mount path", func() {
+			workspace.Spec.Volumes = []workspacev1alpha1.VolumeSpec{
+				{Name: "datasets", PersistentVolumeClaimName: "datasets", MountPath: "/datasets"},
+			}
+			workspace.Spec.SecondaryStorages = append(workspace.Spec.SecondaryStorages,
+				workspacev1alpha1.SecondaryStorageSpec{Name: "data", Size: resource.MustParse("10Gi"), MountPath: "/scratch"})
+
+			err := validateSecondaryStorageMounts(workspace)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("already used by secondary storage 'scratch'"))
+		})
+	})
+})
@@ -7,6 +7,7 @@ package v1alpha1
 
 import (
 	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	"github.com/jupyter-infra/jupyter-k8s/internal/controller"
 )
 
 // applyStorageDefaults applies storage defaults from template to workspace
@@ -22,6 +23,12 @@ func applyStorageDefaults(workspace *workspacev1alpha1.Workspace, template *work
 
 	// Apply individual storage defaults if storage exists
 	if workspace.Spec.Storage != nil {
+		// Templates requiring ephemeral storage default to an emptyDir
+		if workspace.Spec.Storage.Type == "" &&
+			template.Spec.PrimaryStorage.EphemeralStorage == workspacev1alpha1.EphemeralStorageRequired {
+			workspace.Spec.Storage.Type = workspacev1alpha1.StorageTypeEmptyDir
+		}
+
 		// Apply default size if not specified
 		if workspace.Spec.Storage.Size.IsZero() && !template.Spec.PrimaryStorage.DefaultSize.IsZero() {
 			workspace.Spec.Storage.Size = template.Spec.PrimaryStorage.DefaultSize
@@ -36,5 +43,37 @@ func applyStorageDefaults(workspace *workspacev1alpha1.Workspace, template *work
 		if workspace.Spec.Storage.MountPath == "" && template.Spec.PrimaryStorage.DefaultMountPath != "" {
 			workspace.Spec.Storage.MountPath = template.Spec.PrimaryStorage.DefaultMountPath
 		}
+
+		// Snapshot and retention policies only apply to persistent storage
+		if controller.IsEphemeralStorage(workspace) {
+			return
+		}
+
+		// Apply default snapshot policy if not specified
+		if workspace.Spec.Storage.Snapshot == nil && template.Spec.PrimaryStorage.DefaultSnapshotPolicy != nil {
+			workspace.Spec.Storage.Snapshot = template.Spec.PrimaryStorage.DefaultSnapshotPolicy.DeepCopy()
+		}
+
+		// Apply default PVC retention policy if not specified
+		if workspace.Spec.Storage.PersistentVolumeClaimRetentionPolicy == nil &&
+			template.Spec.PrimaryStorage.DefaultPersistentVolumeClaimRetentionPolicy != nil {
+			workspace.Spec.Storage.PersistentVolumeClaimRetentionPolicy =
+				template.Spec.PrimaryStorage.DefaultPersistentVolumeClaimRetentionPolicy.DeepCopy()
+		}
+	}
+}
+
+// applySecondaryStorageDefaults applies the default storage class of the template to secondary storages
+func applySecondaryStorageDefaults(workspace *workspacev1alpha1.Workspace, template *workspacev1alpha1.WorkspaceTemplate) {
+	config := template.Spec.SecondaryStorage
+	if config == nil || config.DefaultStorageClassName == nil {
+		return
+	}
+
+	for i := range workspace.Spec.SecondaryStorages {
+		if workspace.Spec.SecondaryStorages[i].StorageClassName == nil {
+			storageClassName := *config.DefaultStorageClassName
+			workspace.Spec.SecondaryStorages[i].StorageClassName = &storageClassName
+		}
 	}
 }
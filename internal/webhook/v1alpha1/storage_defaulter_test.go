@@ -74,6 +74,51 @@ var _ = Describe("StorageDefaulter", func() {
 			Expect(workspace.Spec.Storage.MountPath).To(Equal("/existing"))
 		})
 
+		It("should apply default snapshot policy when not specified", func() {
+			template.Spec.PrimaryStorage.DefaultSnapshotPolicy = &workspacev1alpha1.SnapshotPolicy{OnStop: true, Retain: 2}
+
+			applyStorageDefaults(workspace, template)
+
+			Expect(workspace.Spec.Storage.Snapshot).NotTo(BeNil())
+			Expect(workspace.Spec.Storage.Snapshot.OnStop).To(BeTrue())
+			Expect(workspace.Spec.Storage.Snapshot.Retain).To(Equal(int32(2)))
+		})
+
+		It("should not override existing snapshot policy", func() {
+			template.Spec.PrimaryStorage.DefaultSnapshotPolicy = &workspacev1alpha1.SnapshotPolicy{OnStop: true, Retain: 2}
+			workspace.Spec.Storage = &workspacev1alpha1.StorageSpec{
+				Snapshot: &workspacev1alpha1.SnapshotPolicy{OnStop: false, Retain: 5},
+			}
+
+			applyStorageDefaults(workspace, template)
+
+			Expect(workspace.Spec.Storage.Snapshot.OnStop).To(BeFalse())
+			Expect(workspace.Spec.Storage.Snapshot.Retain).To(Equal(int32(5)))
+		})
+
+		It("should apply default PVC retention policy when not specified", func() {
+			template.Spec.PrimaryStorage.DefaultPersistentVolumeClaimRetentionPolicy = &workspacev1alpha1.PersistentVolumeClaimRetentionPolicy{
+				WhenDeleted: workspacev1alpha1.PersistentVolumeClaimRetentionPolicyRetain,
+			}
+
+			applyStorageDefaults(workspace, template)
+
+			Expect(workspace.Spec.Storage.PersistentVolumeClaimRetentionPolicy).NotTo(BeNil())
+			Expect(workspace.Spec.Storage.PersistentVolumeClaimRetentionPolicy.WhenDeleted).To(
+				Equal(workspacev1alpha1.PersistentVolumeClaimRetentionPolicyRetain))
+		})
+
+		It("should default to emptyDir storage when the template requires ephemeral storage", func() {
+			template.Spec.PrimaryStorage.EphemeralStorage = workspacev1alpha1.EphemeralStorageRequired
+			template.Spec.PrimaryStorage.DefaultSnapshotPolicy = &workspacev1alpha1.SnapshotPolicy{OnStop: true}
+
+			applyStorageDefaults(workspace, template)
+
+			Expect(workspace.Spec.Storage.Type).To(Equal(workspacev1alpha1.StorageTypeEmptyDir))
+			Expect(workspace.Spec.Storage.MountPath).To(Equal("/workspace"))
+			Expect(workspace.Spec.Storage.Snapshot).To(BeNil())
+		})
+
 		It("should do nothing when template has no primary storage", func() {
 			template.Spec.PrimaryStorage = nil
 
@@ -90,4 +135,23 @@ var _ = Describe("StorageDefaulter", func() {
 			Expect(workspace.Spec.Storage).To(BeNil())
 		})
 	})
+
+	Context("applySecondaryStorageDefaults", func() {
+		It("should apply the template storage class to secondary storages without one", func() {
+			defaultClass := "standard"
+			customClass := "fast-ssd"
+			template.Spec.SecondaryStorage = &workspacev1alpha1.SecondaryStorageConfig{
+				DefaultStorageClassName: &defaultClass,
+			}
+			workspace.Spec.SecondaryStorages = []workspacev1alpha1.SecondaryStorageSpec{
+				{Name: "scratch", Size: resource.MustParse("10Gi"), MountPath: "/scratch"},
+				{Name: "data", Size: resource.MustParse("10Gi"), MountPath: "/data", StorageClassName: &customClass},
+			}
+
+			applySecondaryStorageDefaults(workspace, template)
+
+			Expect(*workspace.Spec.SecondaryStorages[0].StorageClassName).To(Equal("standard"))
+			Expect(*workspace.Spec.SecondaryStorages[1].StorageClassName).To(Equal("fast-ssd"))
+		})
+	})
 })
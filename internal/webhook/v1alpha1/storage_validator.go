@@ -7,10 +7,12 @@ package v1alpha1
 
 import (
 	"fmt"
+	"time"
 
 	"k8s.io/apimachinery/pkg/api/resource"
 
 	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	"github.com/jupyter-infra/jupyter-k8s/internal/controller"
 )
 
 // validateStorageSize checks if storage size is within template bounds
@@ -54,3 +56,145 @@ func storageEqual(old, new *workspacev1alpha1.StorageSpec) bool {
 
 	return old.Size.Equal(new.Size) && old.MountPath == new.MountPath
 }
+
+// maxPVCRetainDuration is the longest retainDuration of the RetainFor PVC retention policy
+const maxPVCRetainDuration = 365 * 24 * time.Hour
+
+// validatePVCRetentionPolicy checks that storage retained with the RetainFor policy is eventually deleted
+func validatePVCRetentionPolicy(workspace *workspacev1alpha1.Workspace) error {
+	if workspace.Spec.Storage == nil || workspace.Spec.Storage.PersistentVolumeClaimRetentionPolicy == nil {
+		return nil
+	}
+	policy := workspace.Spec.Storage.PersistentVolumeClaimRetentionPolicy
+	if policy.WhenDeleted != workspacev1alpha1.PersistentVolumeClaimRetentionPolicyRetainFor || policy.RetainDuration == nil {
+		return nil
+	}
+	if duration := policy.RetainDuration.Duration; duration <= 0 || duration > maxPVCRetainDuration {
+		return fmt.Errorf("spec.storage.persistentVolumeClaimRetentionPolicy.retainDuration must be positive and at most %s, got %s",
+			maxPVCRetainDuration, duration)
+	}
+	return nil
+}
+
+// validateStorageShrink rejects decreasing the size of the workspace storage or of its secondary storages,
+// which PVCs do not support
+func validateStorageShrink(oldWorkspace, newWorkspace *workspacev1alpha1.Workspace) error {
+	if err := validateSecondaryStorageShrink(oldWorkspace, newWorkspace); err != nil {
+		return err
+	}
+
+	oldStorage := oldWorkspace.Spec.Storage
+	newStorage := newWorkspace.Spec.Storage
+	if oldStorage == nil || newStorage == nil || oldStorage.Size.IsZero() || newStorage.Size.IsZero() {
+		return nil
+	}
+	// The size limit of ephemeral storage applies to the next pod, it can be lowered
+	if controller.IsEphemeralStorage(newWorkspace) {
+		return nil
+	}
+	if newStorage.Size.Cmp(oldStorage.Size) < 0 {
+		return fmt.Errorf("spec.storage.size cannot be decreased from %s to %s, volumes can only be expanded",
+			oldStorage.Size.String(), newStorage.Size.String())
+	}
+	return nil
+}
+
+// validateStorageTypeChange rejects changing the type of the workspace storage,
+// comparing the effective types so that an unset type counts as Persistent
+func validateStorageTypeChange(oldWorkspace, newWorkspace *workspacev1alpha1.Workspace) error {
+	if oldWorkspace.Spec.Storage == nil || newWorkspace.Spec.Storage == nil {
+		return nil
+	}
+	oldType := controller.GetStorageType(oldWorkspace)
+	newType := controller.GetStorageType(newWorkspace)
+	if oldType != newType {
+		return fmt.Errorf("spec.storage.type is immutable, cannot change from %s to %s", oldType, newType)
+	}
+	return nil
+}
+
+// ephemeralStoragePolicy returns the ephemeral storage policy of the template storage, Allowed when unset
+func ephemeralStoragePolicy(config *workspacev1alpha1.StorageConfig) workspacev1alpha1.EphemeralStoragePolicy {
+	if config == nil || config.EphemeralStorage == "" {
+		return workspacev1alpha1.EphemeralStorageAllowed
+	}
+	return config.EphemeralStorage
+}
+
+// validateStorageType checks the workspace storage type against the ephemeral storage policy of the template
+func validateStorageType(workspace *workspacev1alpha1.Workspace, template *workspacev1alpha1.WorkspaceTemplate) *TemplateViolation {
+	if workspace.Spec.Storage == nil {
+		return nil
+	}
+	storageType := controller.GetStorageType(workspace)
+
+	switch ephemeralStoragePolicy(template.Spec.PrimaryStorage) {
+	case workspacev1alpha1.EphemeralStorageRequired:
+		if controller.IsEphemeralStorage(workspace) {
+			return nil
+		}
+		return &TemplateViolation{
+			Type:    ViolationTypeStorageTypeNotAllowed,
+			Field:   "spec.storage.type",
+			Message: fmt.Sprintf("Storage type '%s' is not allowed by template '%s', which requires ephemeral storage", storageType, template.Name),
+			Allowed: fmt.Sprintf("%s, %s", workspacev1alpha1.StorageTypeEmptyDir, workspacev1alpha1.StorageTypeEphemeralVolume),
+			Actual:  string(storageType),
+		}
+	case workspacev1alpha1.EphemeralStorageForbidden:
+		if !controller.IsEphemeralStorage(workspace) {
+			return nil
+		}
+		return &TemplateViolation{
+			Type:    ViolationTypeStorageTypeNotAllowed,
+			Field:   "spec.storage.type",
+			Message: fmt.Sprintf("Storage type '%s' is not allowed by template '%s', which forbids ephemeral storage", storageType, template.Name),
+			Allowed: string(workspacev1alpha1.StorageTypePersistent),
+			Actual:  string(storageType),
+		}
+	default:
+		return nil
+	}
+}
+
+// validateEphemeralStorage rejects the storage options that need a PVC on ephemeral storage
+func validateEphemeralStorage(workspace *workspacev1alpha1.Workspace) error {
+	if !controller.IsEphemeralStorage(workspace) {
+		return nil
+	}
+	storage := workspace.Spec.Storage
+	storageType := storage.Type
+
+	if storage.Snapshot != nil && storage.Snapshot.OnStop {
+		return fmt.Errorf("spec.storage.snapshot cannot be enabled with %s storage", storageType)
+	}
+	if storage.RestoreFromSnapshot != "" {
+		return fmt.Errorf("spec.storage.restoreFromSnapshot cannot be used with %s storage", storageType)
+	}
+	if storage.ExistingClaimName != "" {
+		return fmt.Errorf("spec.storage.existingClaimName cannot be used with %s storage", storageType)
+	}
+	if policy := storage.PersistentVolumeClaimRetentionPolicy; policy != nil &&
+		policy.WhenDeleted != "" && policy.WhenDeleted != workspacev1alpha1.PersistentVolumeClaimRetentionPolicyDelete {
+		return fmt.Errorf("spec.storage.persistentVolumeClaimRetentionPolicy cannot retain %s storage", storageType)
+	}
+	if workspace.Spec.CloneFrom != nil {
+		return fmt.Errorf("cloneFrom requires persistent storage, storage type is %s", storageType)
+	}
+	return nil
+}
+
+// validateSecondaryStorageShrink rejects decreasing the size of a secondary storage
+func validateSecondaryStorageShrink(oldWorkspace, newWorkspace *workspacev1alpha1.Workspace) error {
+	oldSizes := make(map[string]resource.Quantity, len(oldWorkspace.Spec.SecondaryStorages))
+	for _, storage := range oldWorkspace.Spec.SecondaryStorages {
+		oldSizes[storage.Name] = storage.Size
+	}
+	for _, storage := range newWorkspace.Spec.SecondaryStorages {
+		oldSize, exists := oldSizes[storage.Name]
+		if exists && storage.Size.Cmp(oldSize) < 0 {
+			return fmt.Errorf("spec.secondaryStorages[%s].size cannot be decreased from %s to %s, volumes can only be expanded",
+				storage.Name, oldSize.String(), storage.Size.String())
+		}
+	}
+	return nil
+}
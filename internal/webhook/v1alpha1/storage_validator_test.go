@@ -0,0 +1,322 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	"github.com/jupyter-infra/jupyter-k8s/internal/controller"
+	webhookconst "github.com/jupyter-infra/jupyter-k8s/internal/webhook"
+)
+
+var _ = Describe("StorageValidator", func() {
+	Context("validateStorageShrink", func() {
+		var oldWorkspace, newWorkspace *workspacev1alpha1.Workspace
+
+		BeforeEach(func() {
+			oldWorkspace = &workspacev1alpha1.Workspace{
+				Spec: workspacev1alpha1.WorkspaceSpec{
+					Storage: &workspacev1alpha1.StorageSpec{Size: resource.MustParse("10Gi")},
+				},
+			}
+			newWorkspace = oldWorkspace.DeepCopy()
+		})
+
+		It("should allow expanding the storage", func() {
+			newWorkspace.Spec.Storage.Size = resource.MustParse("20Gi")
+			Expect(validateStorageShrink(oldWorkspace, newWorkspace)).To(Succeed())
+		})
+
+		It("should reject shrinking the storage", func() {
+			newWorkspace.Spec.Storage.Size = resource.MustParse("5Gi")
+
+			err := validateStorageShrink(oldWorkspace, newWorkspace)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("cannot be decreased from 10Gi to 5Gi"))
+		})
+
+		It("should ignore workspaces without storage", func() {
+			oldWorkspace.Spec.Storage = nil
+			newWorkspace.Spec.Storage.Size = resource.MustParse("5Gi")
+			Expect(validateStorageShrink(oldWorkspace, newWorkspace)).To(Succeed())
+		})
+
+		It("should reject shrinking a secondary storage", func() {
+			oldWorkspace.Spec.SecondaryStorages = []workspacev1alpha1.SecondaryStorageSpec{
+				{Name: "scratch", Size: resource.MustParse("50Gi"), MountPath: "/scratch"},
+			}
+			newWorkspace = oldWorkspace.DeepCopy()
+			newWorkspace.Spec.SecondaryStorages[0].Size = resource.MustParse("100Gi")
+			Expect(validateStorageShrink(oldWorkspace, newWorkspace)).To(Succeed())
+
+			newWorkspace.Spec.SecondaryStorages[0].Size = resource.MustParse("20Gi")
+			err := validateStorageShrink(oldWorkspace, newWorkspace)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.secondaryStorages[scratch].size cannot be decreased"))
+		})
+	})
+
+	Context("validateStorageTypeChange", func() {
+		var oldWorkspace, newWorkspace *workspacev1alpha1.Workspace
+
+		BeforeEach(func() {
+			oldWorkspace = &workspacev1alpha1.Workspace{
+				Spec: workspacev1alpha1.WorkspaceSpec{
+					Storage: &workspacev1alpha1.StorageSpec{Size: resource.MustParse("10Gi")},
+				},
+			}
+			newWorkspace = oldWorkspace.DeepCopy()
+		})
+
+		It("should reject setting an ephemeral type on an unset type", func() {
+			newWorkspace.Spec.Storage.Type = workspacev1alpha1.StorageTypeEmptyDir
+
+			err := validateStorageTypeChange(oldWorkspace, newWorkspace)
+			Expect(err).To(MatchError(ContainSubstring("cannot change from Persistent to EmptyDir")))
+		})
+
+		It("should allow setting Persistent on an unset type", func() {
+			newWorkspace.Spec.Storage.Type = workspacev1alpha1.StorageTypePersistent
+			Expect(validateStorageTypeChange(oldWorkspace, newWorkspace)).To(Succeed())
+		})
+
+		It("should reject clearing an ephemeral type", func() {
+			oldWorkspace.Spec.Storage.Type = workspacev1alpha1.StorageTypeEphemeralVolume
+
+			err := validateStorageTypeChange(oldWorkspace, newWorkspace)
+			Expect(err).To(MatchError(ContainSubstring("cannot change from EphemeralVolume to Persistent")))
+		})
+
+		It("should ignore workspaces without storage", func() {
+			oldWorkspace.Spec.Storage = nil
+			newWorkspace.Spec.Storage.Type = workspacev1alpha1.StorageTypeEmptyDir
+			Expect(validateStorageTypeChange(oldWorkspace, newWorkspace)).To(Succeed())
+		})
+	})
+
+	Context("validateExistingClaimOwnership", func() {
+		var (
+			ctx       context.Context
+			scheme    *runtime.Scheme
+			retained  *corev1.PersistentVolumeClaim
+			workspace *workspacev1alpha1.Workspace
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			scheme = runtime.NewScheme()
+			Expect(corev1.AddToScheme(scheme)).To(Succeed())
+			Expect(workspacev1alpha1.AddToScheme(scheme)).To(Succeed())
+			retained = &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "workspace-old-pvc",
+					Namespace:   "default",
+					Labels:      map[string]string{controller.LabelComponent: controller.ComponentRetainedStorage},
+					Annotations: map[string]string{controller.AnnotationCreatedBy: "alice"},
+				},
+			}
+			workspace = &workspacev1alpha1.Workspace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "new",
+					Namespace:   "default",
+					Annotations: map[string]string{controller.AnnotationCreatedBy: "alice"},
+				},
+				Spec: workspacev1alpha1.WorkspaceSpec{
+					Storage: &workspacev1alpha1.StorageSpec{ExistingClaimName: "workspace-old-pvc"},
+				},
+			}
+		})
+
+		It("should allow adopting storage retained from a workspace of the same user", func() {
+			k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(retained).Build()
+			Expect(validateExistingClaimOwnership(ctx, k8sClient, workspace)).To(BeNil())
+		})
+
+		It("should reject adopting storage retained from a workspace of another user", func() {
+			retained.Annotations[controller.AnnotationCreatedBy] = "bob"
+			k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(retained).Build()
+
+			violation := validateExistingClaimOwnership(ctx, k8sClient, workspace)
+			Expect(violation).NotTo(BeNil())
+			Expect(violation.Type).To(Equal(ViolationTypeRetainedStorageOfAnotherUser))
+		})
+
+		It("should reject adopting a PVC that is not retained storage", func() {
+			retained.Labels = nil
+			k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(retained).Build()
+
+			violation := validateExistingClaimOwnership(ctx, k8sClient, workspace)
+			Expect(violation).NotTo(BeNil())
+			Expect(violation.Type).To(Equal(ViolationTypeInvalidExistingClaim))
+		})
+
+		It("should reject adopting retained storage without a recorded creator unless admin", func() {
+			delete(retained.Annotations, controller.AnnotationCreatedBy)
+			k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(retained).Build()
+
+			violation := validateExistingClaimOwnership(ctx, k8sClient, workspace)
+			Expect(violation).NotTo(BeNil())
+			Expect(violation.Type).To(Equal(ViolationTypeRetainedStorageOfAnotherUser))
+
+			adminCtx := createUserContext(ctx, "CREATE", "admin", webhookconst.DefaultAdminGroup)
+			Expect(validateExistingClaimOwnership(adminCtx, k8sClient, workspace)).To(BeNil())
+		})
+
+		It("should reject when the PVC cannot be read", func() {
+			k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+				Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+					return errors.New("connection refused")
+				},
+			}).Build()
+
+			violation := validateExistingClaimOwnership(ctx, k8sClient, workspace)
+			Expect(violation).NotTo(BeNil())
+			Expect(violation.Message).To(ContainSubstring("Unable to verify the owner"))
+		})
+
+		It("should reject combining existingClaimName with restoreFromSnapshot", func() {
+			workspace.Spec.Storage.RestoreFromSnapshot = "snap"
+			k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(retained).Build()
+
+			violation := validateExistingClaimOwnership(ctx, k8sClient, workspace)
+			Expect(violation).NotTo(BeNil())
+			Expect(violation.Type).To(Equal(ViolationTypeInvalidExistingClaim))
+		})
+	})
+
+	Context("validatePVCRetentionPolicy", func() {
+		var workspace *workspacev1alpha1.Workspace
+
+		BeforeEach(func() {
+			workspace = &workspacev1alpha1.Workspace{
+				Spec: workspacev1alpha1.WorkspaceSpec{
+					Storage: &workspacev1alpha1.StorageSpec{
+						PersistentVolumeClaimRetentionPolicy: &workspacev1alpha1.PersistentVolumeClaimRetentionPolicy{
+							WhenDeleted:    workspacev1alpha1.PersistentVolumeClaimRetentionPolicyRetainFor,
+							RetainDuration: &metav1.Duration{Duration: 168 * time.Hour},
+						},
+					},
+				},
+			}
+		})
+
+		It("should allow a bounded retain duration", func() {
+			Expect(validatePVCRetentionPolicy(workspace)).To(Succeed())
+		})
+
+		It("should reject a retain duration above the maximum", func() {
+			workspace.Spec.Storage.PersistentVolumeClaimRetentionPolicy.RetainDuration.Duration = maxPVCRetainDuration + time.Hour
+			Expect(validatePVCRetentionPolicy(workspace)).To(MatchError(ContainSubstring("at most")))
+		})
+
+		It("should reject a negative retain duration", func() {
+			workspace.Spec.Storage.PersistentVolumeClaimRetentionPolicy.RetainDuration.Duration = -time.Hour
+			Expect(validatePVCRetentionPolicy(workspace)).To(MatchError(ContainSubstring("must be positive")))
+		})
+	})
+
+	Context("validateStorageType", func() {
+		var (
+			template  *workspacev1alpha1.WorkspaceTemplate
+			workspace *workspacev1alpha1.Workspace
+		)
+
+		BeforeEach(func() {
+			template = &workspacev1alpha1.WorkspaceTemplate{
+				ObjectMeta: metav1.ObjectMeta{Name: "workshop"},
+				Spec: workspacev1alpha1.WorkspaceTemplateSpec{
+					PrimaryStorage: &workspacev1alpha1.StorageConfig{},
+				},
+			}
+			workspace = &workspacev1alpha1.Workspace{
+				Spec: workspacev1alpha1.WorkspaceSpec{
+					Storage: &workspacev1alpha1.StorageSpec{Size: resource.MustParse("5Gi")},
+				},
+			}
+		})
+
+		It("should allow any storage type by default", func() {
+			Expect(validateStorageType(workspace, template)).To(BeNil())
+			workspace.Spec.Storage.Type = workspacev1alpha1.StorageTypeEmptyDir
+			Expect(validateStorageType(workspace, template)).To(BeNil())
+		})
+
+		It("should reject persistent storage when the template requires ephemeral storage", func() {
+			template.Spec.PrimaryStorage.EphemeralStorage = workspacev1alpha1.EphemeralStorageRequired
+
+			violation := validateStorageType(workspace, template)
+			Expect(violation).NotTo(BeNil())
+			Expect(violation.Type).To(Equal(ViolationTypeStorageTypeNotAllowed))
+			Expect(violation.Actual).To(Equal("Persistent"))
+
+			workspace.Spec.Storage.Type = workspacev1alpha1.StorageTypeEphemeralVolume
+			Expect(validateStorageType(workspace, template)).To(BeNil())
+		})
+
+		It("should reject ephemeral storage when the template forbids it", func() {
+			template.Spec.PrimaryStorage.EphemeralStorage = workspacev1alpha1.EphemeralStorageForbidden
+			Expect(validateStorageType(workspace, template)).To(BeNil())
+
+			workspace.Spec.Storage.Type = workspacev1alpha1.StorageTypeEmptyDir
+			violation := validateStorageType(workspace, template)
+			Expect(violation).NotTo(BeNil())
+			Expect(violation.Type).To(Equal(ViolationTypeStorageTypeNotAllowed))
+		})
+	})
+
+	Context("validateEphemeralStorage", func() {
+		var workspace *workspacev1alpha1.Workspace
+
+		BeforeEach(func() {
+			workspace = &workspacev1alpha1.Workspace{
+				Spec: workspacev1alpha1.WorkspaceSpec{
+					Storage: &workspacev1alpha1.StorageSpec{
+						Type: workspacev1alpha1.StorageTypeEmptyDir,
+						Size: resource.MustParse("5Gi"),
+					},
+				},
+			}
+		})
+
+		It("should allow ephemeral storage without PVC options", func() {
+			Expect(validateEphemeralStorage(workspace)).To(Succeed())
+		})
+
+		It("should reject snapshots of ephemeral storage", func() {
+			workspace.Spec.Storage.Snapshot = &workspacev1alpha1.SnapshotPolicy{OnStop: true}
+
+			err := validateEphemeralStorage(workspace)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.storage.snapshot"))
+		})
+
+		It("should reject retaining ephemeral storage", func() {
+			workspace.Spec.Storage.PersistentVolumeClaimRetentionPolicy = &workspacev1alpha1.PersistentVolumeClaimRetentionPolicy{
+				WhenDeleted: workspacev1alpha1.PersistentVolumeClaimRetentionPolicyRetain,
+			}
+			Expect(validateEphemeralStorage(workspace)).NotTo(Succeed())
+		})
+
+		It("should allow shrinking the size limit of ephemeral storage", func() {
+			oldWorkspace := workspace.DeepCopy()
+			workspace.Spec.Storage.Size = resource.MustParse("1Gi")
+			Expect(validateStorageShrink(oldWorkspace, workspace)).To(Succeed())
+		})
+	})
+})
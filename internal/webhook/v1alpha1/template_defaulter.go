@@ -34,6 +34,7 @@ var defaultApplicators = []DefaultApplicator{
 	applyCoreDefaults,
 	applyResourceDefaults,
 	applyStorageDefaults,
+	applySecondaryStorageDefaults,
 	applyVolumeDefaults,
 	applySchedulingDefaults,
 	applyMetadataDefaults,
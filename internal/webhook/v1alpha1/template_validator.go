@@ -101,11 +101,26 @@ func (tv *TemplateValidator) ValidateCreateWorkspace(ctx context.Context, worksp
 		}
 	}
 
+	// Validate storage type
+	if violation := validateStorageType(workspace, template); violation != nil {
+		violations = append(violations, *violation)
+	}
+
 	// Validate secondary storage volumes
 	if violation := validateSecondaryStorages(workspace.Spec.Volumes, template); violation != nil {
 		violations = append(violations, *violation)
 	}
 
+	// Validate volume sources
+	if sourceViolations := validateVolumeSourcePolicy(workspace, template); len(sourceViolations) > 0 {
+		violations = append(violations, sourceViolations...)
+	}
+
+	// Validate secondary storages
+	if secondaryViolations := validateSecondaryStorageBounds(workspace, template); len(secondaryViolations) > 0 {
+		violations = append(violations, secondaryViolations...)
+	}
+
 	// Validate label requirements
 	if labelViolations := validateLabelRequirements(workspace, template); len(labelViolations) > 0 {
 		violations = append(violations, labelViolations...)
@@ -116,6 +131,36 @@ func (tv *TemplateValidator) ValidateCreateWorkspace(ctx context.Context, worksp
 		violations = append(violations, envViolations...)
 	}
 
+	// Validate idle shutdown overrides
+	if idleShutdownViolations := validateIdleShutdownOverrides(workspace, template); len(idleShutdownViolations) > 0 {
+		violations = append(violations, idleShutdownViolations...)
+	}
+
+	// Validate schedule overrides
+	if scheduleViolations := validateScheduleOverrides(workspace, template); len(scheduleViolations) > 0 {
+		violations = append(violations, scheduleViolations...)
+	}
+
+	// Validate probe overrides
+	if probeViolations := validateProbeOverrides(workspace, template); len(probeViolations) > 0 {
+		violations = append(violations, probeViolations...)
+	}
+
+	// Validate priority classes
+	if priorityClassViolations := validatePriorityClasses(workspace, template); len(priorityClassViolations) > 0 {
+		violations = append(violations, priorityClassViolations...)
+	}
+
+	// Validate compute mode
+	if violation := validateComputeMode(workspace, template); violation != nil {
+		violations = append(violations, *violation)
+	}
+
+	// Validate lifecycle policy
+	if lifecycleViolations := validateLifecyclePolicy(workspace, template); len(lifecycleViolations) > 0 {
+		violations = append(violations, lifecycleViolations...)
+	}
+
 	if len(violations) > 0 {
 		return fmt.Errorf("workspace violates template '%s' constraints: %s", workspace.Spec.TemplateRef.Name, formatViolations(violations))
 	}
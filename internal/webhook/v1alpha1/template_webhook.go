@@ -99,6 +99,16 @@ func constraintsChanged(oldTemplate, newTemplate *workspacev1alpha1.WorkspaceTem
 		return true
 	}
 
+	// Check AllowedPriorityClassNames changes
+	if !equality.Semantic.DeepEqual(oldSpec.AllowedPriorityClassNames, newSpec.AllowedPriorityClassNames) {
+		return true
+	}
+
+	// Check ComputeMode changes
+	if oldSpec.ComputeMode != newSpec.ComputeMode {
+		return true
+	}
+
 	// Check ResourceBounds changes
 	if resourceBoundsChanged(oldSpec.ResourceBounds, newSpec.ResourceBounds) {
 		return true
@@ -109,6 +119,21 @@ func constraintsChanged(oldTemplate, newTemplate *workspacev1alpha1.WorkspaceTem
 		return true
 	}
 
+	// Check VolumeSources changes
+	if !equality.Semantic.DeepEqual(oldSpec.VolumeSources, newSpec.VolumeSources) {
+		return true
+	}
+
+	// Check SecondaryStorage changes
+	if !equality.Semantic.DeepEqual(oldSpec.SecondaryStorage, newSpec.SecondaryStorage) {
+		return true
+	}
+
+	// Check PrimaryStorage.EphemeralStorage changes
+	if ephemeralStoragePolicy(oldSpec.PrimaryStorage) != ephemeralStoragePolicy(newSpec.PrimaryStorage) {
+		return true
+	}
+
 	// Check IdleShutdownOverrides.Allow changes
 	if idleShutdownAllowOverrideChanged(oldSpec.IdleShutdownOverrides, newSpec.IdleShutdownOverrides) {
 		return true
@@ -119,11 +144,31 @@ func constraintsChanged(oldTemplate, newTemplate *workspacev1alpha1.WorkspaceTem
 		return true
 	}
 
+	// Check IdleShutdownOverrides metrics detection bounds changes
+	if idleShutdownMetricsBoundsChanged(oldSpec.IdleShutdownOverrides, newSpec.IdleShutdownOverrides) {
+		return true
+	}
+
 	// Check EnvRequirements changes
 	if !equality.Semantic.DeepEqual(oldSpec.EnvRequirements, newSpec.EnvRequirements) {
 		return true
 	}
 
+	// Check ScheduleOverrides changes
+	if !equality.Semantic.DeepEqual(oldSpec.ScheduleOverrides, newSpec.ScheduleOverrides) {
+		return true
+	}
+
+	// Check ProbeOverrides changes
+	if !equality.Semantic.DeepEqual(oldSpec.ProbeOverrides, newSpec.ProbeOverrides) {
+		return true
+	}
+
+	// Check DefaultLifecyclePolicy changes, its durations are maximums for workspaces
+	if !equality.Semantic.DeepEqual(oldSpec.DefaultLifecyclePolicy, newSpec.DefaultLifecyclePolicy) {
+		return true
+	}
+
 	return false
 }
 
@@ -233,3 +278,21 @@ func idleShutdownTimeoutBoundsChanged(oldOverrides, newOverrides *workspacev1alp
 
 	return false
 }
+
+// idleShutdownMetricsBoundsChanged checks if metrics-based idle detection bounds changed
+func idleShutdownMetricsBoundsChanged(oldOverrides, newOverrides *workspacev1alpha1.IdleShutdownOverridePolicy) bool {
+	// If one is nil and the other isn't, they're different
+	if (oldOverrides == nil) != (newOverrides == nil) {
+		return true
+	}
+
+	// Both nil means no change
+	if oldOverrides == nil {
+		return false
+	}
+
+	return !equality.Semantic.DeepEqual(oldOverrides.AllowMetricsDetection, newOverrides.AllowMetricsDetection) ||
+		!equality.Semantic.DeepEqual(oldOverrides.MinCPUThreshold, newOverrides.MinCPUThreshold) ||
+		!equality.Semantic.DeepEqual(oldOverrides.MaxCPUThreshold, newOverrides.MaxCPUThreshold) ||
+		!equality.Semantic.DeepEqual(oldOverrides.AllowedPrometheusURLs, newOverrides.AllowedPrometheusURLs)
+}
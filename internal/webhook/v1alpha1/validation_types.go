@@ -31,6 +31,9 @@ const (
 	ViolationTypeStorageExceeded                = "StorageExceeded"
 	ViolationTypeSecondaryStorageNotAllowed     = "SecondaryStorageNotAllowed"
 	ViolationTypeVolumeOwnedByAnotherWorkspace  = "VolumeOwnedByAnotherWorkspace"
+	ViolationTypeSnapshotOwnedByAnotherUser     = "SnapshotOwnedByAnotherUser"
+	ViolationTypeCloneSourceOwnedByAnotherUser  = "CloneSourceOwnedByAnotherUser"
+	ViolationTypeInvalidCloneSource             = "InvalidCloneSource"
 	ViolationTypeInvalidTemplate                = "InvalidTemplate"
 	ViolationTypeIdleShutdownOverrideNotAllowed = "IdleShutdownOverrideNotAllowed"
 	ViolationTypeIdleShutdownTimeoutOutOfBounds = "IdleShutdownTimeoutOutOfBounds"
@@ -38,4 +41,20 @@ const (
 	ViolationTypeLabelRegexMismatch             = "LabelRegexMismatch"
 	ViolationTypeEnvRequired                    = "EnvRequired"
 	ViolationTypeEnvRegexMismatch               = "EnvRegexMismatch"
+	ViolationTypeScheduleOverrideNotAllowed     = "ScheduleOverrideNotAllowed"
+	ViolationTypeStartScheduleNotAllowed        = "StartScheduleNotAllowed"
+	ViolationTypeMetricsDetectionNotAllowed     = "MetricsDetectionNotAllowed"
+	ViolationTypeMetricsThresholdOutOfBounds    = "MetricsThresholdOutOfBounds"
+	ViolationTypePrometheusURLNotAllowed        = "PrometheusURLNotAllowed"
+	ViolationTypeLifecyclePolicyExceeded        = "LifecyclePolicyExceeded"
+	ViolationTypeProbeOverrideNotAllowed        = "ProbeOverrideNotAllowed"
+	ViolationTypeProbePeriodTooShort            = "ProbePeriodTooShort"
+	ViolationTypePriorityClassNotAllowed        = "PriorityClassNotAllowed"
+	ViolationTypeComputeModeNotAllowed          = "ComputeModeNotAllowed"
+	ViolationTypeRetainedStorageOfAnotherUser   = "RetainedStorageOfAnotherUser"
+	ViolationTypeInvalidExistingClaim           = "InvalidExistingClaim"
+	ViolationTypeStorageTypeNotAllowed          = "StorageTypeNotAllowed"
+	ViolationTypeSharedVolumeReference          = "SharedVolumeReference"
+	ViolationTypeSecondaryStorageExceeded       = "SecondaryStorageExceeded"
+	ViolationTypeVolumeSourceNotAllowed         = "VolumeSourceNotAllowed"
 )
@@ -8,14 +8,28 @@ package v1alpha1
 import (
 	"context"
 	"fmt"
+	"path"
+	"slices"
+	"strings"
 
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	"github.com/jupyter-infra/jupyter-k8s/internal/controller"
+	workspaceutil "github.com/jupyter-infra/jupyter-k8s/internal/workspace"
 )
 
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
 // VolumeValidator handles volume validation for webhooks
 type VolumeValidator struct {
 	client client.Client
@@ -28,14 +42,37 @@ func NewVolumeValidator(k8sClient client.Client) *VolumeValidator {
 	}
 }
 
-// ValidateVolumeOwnership checks that volumes don't reference PVCs owned by other workspaces
-func (vv *VolumeValidator) ValidateVolumeOwnership(ctx context.Context, workspace *workspacev1alpha1.Workspace) error {
+// ValidateVolumeOwnership checks that volumes don't reference PVCs owned by other workspaces.
+// oldWorkspace is nil on creation.
+func (vv *VolumeValidator) ValidateVolumeOwnership(ctx context.Context, oldWorkspace, workspace *workspacev1alpha1.Workspace) error {
 	if violation := validateVolumeOwnership(ctx, vv.client, workspace); violation != nil {
 		return fmt.Errorf("workspace violates volume ownership constraints: %s", violation.Message)
 	}
+	// The snapshot is only checked when the restore is requested, it may be deleted once restored
+	if oldWorkspace == nil || getRestoreFromSnapshot(oldWorkspace) != getRestoreFromSnapshot(workspace) {
+		if violation := validateSnapshotOwnership(ctx, vv.client, workspace); violation != nil {
+			return fmt.Errorf("workspace violates volume ownership constraints: %s", violation.Message)
+		}
+	}
+	// The source is only checked when the clone is requested, later changes of the source must not
+	// prevent the user from updating the clone
+	if oldWorkspace == nil || !equality.Semantic.DeepEqual(oldWorkspace.Spec.CloneFrom, workspace.Spec.CloneFrom) {
+		if violation := validateCloneOwnership(ctx, vv.client, workspace); violation != nil {
+			return fmt.Errorf("workspace violates volume ownership constraints: %s", violation.Message)
+		}
+	}
+	if violation := validateExistingClaimOwnership(ctx, vv.client, workspace); violation != nil {
+		return fmt.Errorf("workspace violates volume ownership constraints: %s", violation.Message)
+	}
 	return nil
 }
 
+// ValidateVolumeObjectAccess checks that the requesting user may read the Secrets and ConfigMaps mounted
+// by the workspace. oldWorkspace is nil on creation.
+func (vv *VolumeValidator) ValidateVolumeObjectAccess(ctx context.Context, oldWorkspace, workspace *workspacev1alpha1.Workspace) error {
+	return validateVolumeObjectAccess(ctx, vv.client, oldWorkspace, workspace)
+}
+
 // validateSecondaryStorages checks if secondary storage volumes are allowed by template
 func validateSecondaryStorages(volumes []workspacev1alpha1.VolumeSpec, template *workspacev1alpha1.WorkspaceTemplate) *TemplateViolation {
 	// Skip validation if no volumes specified
@@ -57,36 +94,531 @@ func validateSecondaryStorages(volumes []workspacev1alpha1.VolumeSpec, template
 	return nil
 }
 
+// validateVolumeSources checks that each volume sets exactly one source and mounts a relative sub-path,
+// and that its name does not collide with the pod volumes of secondary storages and shared volumes
+func validateVolumeSources(workspace *workspacev1alpha1.Workspace) error {
+	for i := range workspace.Spec.Volumes {
+		volume := &workspace.Spec.Volumes[i]
+		for _, prefix := range []string{controller.SecondaryVolumeNamePrefix, controller.SharedVolumeNamePrefix} {
+			if strings.HasPrefix(volume.Name, prefix) {
+				return fmt.Errorf("spec.volumes[%s] name must not start with %s, which is reserved for generated volumes",
+					volume.Name, prefix)
+			}
+		}
+		if sourceTypes := workspaceutil.GetVolumeSourceTypes(volume); len(sourceTypes) != 1 {
+			return fmt.Errorf("spec.volumes[%s] must set exactly one of persistentVolumeClaimName, configMap, secret or csi, got %d",
+				volume.Name, len(sourceTypes))
+		}
+		if volume.SubPath == "" {
+			continue
+		}
+		if path.IsAbs(volume.SubPath) || slices.Contains(strings.Split(volume.SubPath, "/"), "..") {
+			return fmt.Errorf("spec.volumes[%s].subPath %s must be a relative path without '..'", volume.Name, volume.SubPath)
+		}
+	}
+	return nil
+}
+
+// validateVolumeSourcePolicy checks the volume sources against the allowlist of the template.
+// Secret and CSI sources are denied unless the template lists them in its allowed types.
+func validateVolumeSourcePolicy(workspace *workspacev1alpha1.Workspace, template *workspacev1alpha1.WorkspaceTemplate) []TemplateViolation {
+	policy := template.Spec.VolumeSources
+	if policy == nil {
+		policy = &workspacev1alpha1.VolumeSourcePolicy{}
+	}
+
+	var violations []TemplateViolation
+	for i := range workspace.Spec.Volumes {
+		volume := &workspace.Spec.Volumes[i]
+		sourceType := workspaceutil.GetVolumeSourceType(volume)
+		sourceName := workspaceutil.GetVolumeSourceName(volume)
+
+		// Secret and CSI sources must be listed explicitly, other types are allowed by an empty list
+		typeAllowed := slices.Contains(policy.AllowedTypes, sourceType) ||
+			(len(policy.AllowedTypes) == 0 && !isRestrictedVolumeSourceType(sourceType))
+		if !typeAllowed {
+			violations = append(violations, TemplateViolation{
+				Type:    ViolationTypeVolumeSourceNotAllowed,
+				Field:   fmt.Sprintf("spec.volumes[%s]", volume.Name),
+				Message: fmt.Sprintf("Volume '%s' of type %s is not allowed by template '%s'", volume.Name, sourceType, template.Name),
+				Allowed: fmt.Sprintf("%v", policy.AllowedTypes),
+				Actual:  string(sourceType),
+			})
+			continue
+		}
+
+		var allowedNames []string
+		switch sourceType {
+		case workspacev1alpha1.VolumeSourceTypePersistentVolumeClaim:
+			allowedNames = policy.AllowedPersistentVolumeClaims
+		case workspacev1alpha1.VolumeSourceTypeConfigMap:
+			allowedNames = policy.AllowedConfigMaps
+		case workspacev1alpha1.VolumeSourceTypeSecret:
+			allowedNames = policy.AllowedSecrets
+		case workspacev1alpha1.VolumeSourceTypeCSI:
+			allowedNames = policy.AllowedCSIDrivers
+		}
+		if len(allowedNames) > 0 && !slices.Contains(allowedNames, sourceName) {
+			violations = append(violations, TemplateViolation{
+				Type:    ViolationTypeVolumeSourceNotAllowed,
+				Field:   fmt.Sprintf("spec.volumes[%s]", volume.Name),
+				Message: fmt.Sprintf("Volume '%s' mounts %s '%s' which is not allowed by template '%s'", volume.Name, sourceType, sourceName, template.Name),
+				Allowed: fmt.Sprintf("%v", allowedNames),
+				Actual:  sourceName,
+			})
+		}
+	}
+	return violations
+}
+
 // validateVolumeOwnership checks that volumes don't reference PVCs owned by other workspaces
 func validateVolumeOwnership(ctx context.Context, k8sClient client.Client, workspace *workspacev1alpha1.Workspace) *TemplateViolation {
-	for _, volume := range workspace.Spec.Volumes {
-		// Get the PVC
-		pvc := &corev1.PersistentVolumeClaim{}
-		err := k8sClient.Get(ctx, types.NamespacedName{
-			Name:      volume.PersistentVolumeClaimName,
-			Namespace: workspace.Namespace,
-		}, pvc)
-
-		// If PVC doesn't exist, skip validation (let other validation handle it)
-		if err != nil {
+	for i := range workspace.Spec.Volumes {
+		volume := &workspace.Spec.Volumes[i]
+		if workspaceutil.GetVolumeSourceType(volume) != workspacev1alpha1.VolumeSourceTypePersistentVolumeClaim {
 			continue
 		}
+		if violation := validatePVCVolumeOwnership(ctx, k8sClient, workspace, volume); violation != nil {
+			return violation
+		}
+	}
+
+	return nil
+}
+
+// validatePVCVolumeOwnership checks that a volume doesn't reference a PVC owned by another workspace
+func validatePVCVolumeOwnership(
+	ctx context.Context,
+	k8sClient client.Client,
+	workspace *workspacev1alpha1.Workspace,
+	volume *workspacev1alpha1.VolumeSpec) *TemplateViolation {
+	field := fmt.Sprintf("spec.volumes[%s].persistentVolumeClaimName", volume.Name)
+
+	// Get the PVC
+	pvc := &corev1.PersistentVolumeClaim{}
+	err := k8sClient.Get(ctx, types.NamespacedName{
+		Name:      volume.PersistentVolumeClaimName,
+		Namespace: workspace.Namespace,
+	}, pvc)
+
+	// If PVC doesn't exist, skip validation (the pod does not start until it does)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return &TemplateViolation{
+			Type:    ViolationTypeVolumeOwnedByAnotherWorkspace,
+			Field:   field,
+			Message: fmt.Sprintf("Unable to verify the owner of PVC '%s': %v", volume.PersistentVolumeClaimName, err),
+			Allowed: "PVCs whose owner can be verified",
+			Actual:  "unverified PVC",
+		}
+	}
 
-		// Check if PVC is owned by another workspace
-		for _, ownerRef := range pvc.OwnerReferences {
-			if ownerRef.APIVersion == "workspace.jupyter.org/v1alpha1" &&
-				ownerRef.Kind == "Workspace" &&
-				ownerRef.UID != workspace.UID {
-				return &TemplateViolation{
-					Type:    ViolationTypeVolumeOwnedByAnotherWorkspace,
-					Field:   fmt.Sprintf("spec.volumes[%s].persistentVolumeClaimName", volume.Name),
-					Message: fmt.Sprintf("Volume '%s' references PVC '%s' which is owned by another workspace '%s'", volume.Name, volume.PersistentVolumeClaimName, ownerRef.Name),
-					Allowed: "PVCs not owned by other workspaces",
-					Actual:  fmt.Sprintf("PVC owned by workspace '%s'", ownerRef.Name),
+	// Check if PVC is owned by another workspace
+	if violation := validateNotOwnedByAnotherWorkspace(pvc, workspace, "PVC", field); violation != nil {
+		return violation
+	}
+
+	// Shared volumes are mounted by the controller with the access of the workspace creator
+	if pvc.Labels[controller.LabelComponent] == controller.ComponentSharedVolume {
+		return &TemplateViolation{
+			Type:  ViolationTypeSharedVolumeReference,
+			Field: field,
+			Message: fmt.Sprintf("Volume '%s' references PVC '%s' of shared volume '%s', which is mounted automatically",
+				volume.Name, volume.PersistentVolumeClaimName, pvc.Labels[controller.LabelSharedVolumeName]),
+			Allowed: "PVCs not provisioned for shared volumes",
+			Actual:  "PVC of a shared volume",
+		}
+	}
+
+	// Retained PVCs of deleted workspaces keep belonging to their creator
+	return validateRetainedPVCOwner(ctx, pvc, workspace, field)
+}
+
+// volumeObjectReference is a Secret or ConfigMap referenced by a volume
+type volumeObjectReference struct {
+	resource string
+	name     string
+	field    string
+}
+
+// getVolumeObjectReferences returns the Secrets and ConfigMaps referenced by the volume,
+// including the secret passed to its CSI driver
+func getVolumeObjectReferences(volume *workspacev1alpha1.VolumeSpec) []volumeObjectReference {
+	var references []volumeObjectReference
+	if volume.ConfigMap != nil {
+		references = append(references, volumeObjectReference{
+			resource: "configmaps",
+			name:     volume.ConfigMap.Name,
+			field:    fmt.Sprintf("spec.volumes[%s].configMap.name", volume.Name),
+		})
+	}
+	if volume.Secret != nil {
+		references = append(references, volumeObjectReference{
+			resource: "secrets",
+			name:     volume.Secret.SecretName,
+			field:    fmt.Sprintf("spec.volumes[%s].secret.secretName", volume.Name),
+		})
+	}
+	if volume.CSI != nil && volume.CSI.NodePublishSecretRef != nil {
+		references = append(references, volumeObjectReference{
+			resource: "secrets",
+			name:     volume.CSI.NodePublishSecretRef.Name,
+			field:    fmt.Sprintf("spec.volumes[%s].csi.nodePublishSecretRef.name", volume.Name),
+		})
+	}
+	return references
+}
+
+// validateVolumeObjectAccess checks that the requesting user may get the Secrets and ConfigMaps mounted
+// by the volumes, so that the workspace pod does not expose objects the user cannot read.
+// References already present on the old workspace are not checked again.
+func validateVolumeObjectAccess(
+	ctx context.Context,
+	k8sClient client.Client,
+	oldWorkspace, workspace *workspacev1alpha1.Workspace) error {
+	existing := map[volumeObjectReference]bool{}
+	if oldWorkspace != nil {
+		for i := range oldWorkspace.Spec.Volumes {
+			for _, reference := range getVolumeObjectReferences(&oldWorkspace.Spec.Volumes[i]) {
+				existing[volumeObjectReference{resource: reference.resource, name: reference.name}] = true
+			}
+		}
+	}
+
+	var userInfo *authenticationv1.UserInfo
+	for i := range workspace.Spec.Volumes {
+		for _, reference := range getVolumeObjectReferences(&workspace.Spec.Volumes[i]) {
+			if existing[volumeObjectReference{resource: reference.resource, name: reference.name}] {
+				continue
+			}
+			if userInfo == nil {
+				req, err := admission.RequestFromContext(ctx)
+				if err != nil {
+					return fmt.Errorf("unable to extract user information from request context: %w", err)
 				}
+				userInfo = &req.UserInfo
+			}
+
+			allowed, err := canGetObject(ctx, k8sClient, userInfo, workspace.Namespace, reference)
+			if err != nil {
+				return fmt.Errorf("%s: unable to verify access to %s %s: %w", reference.field, reference.resource, reference.name, err)
+			}
+			if !allowed {
+				return fmt.Errorf("%s: access denied: user cannot get %s %s in namespace %s",
+					reference.field, reference.resource, reference.name, workspace.Namespace)
 			}
 		}
 	}
+	return nil
+}
+
+// canGetObject runs a SubjectAccessReview checking that the user may get the object
+func canGetObject(
+	ctx context.Context,
+	k8sClient client.Client,
+	userInfo *authenticationv1.UserInfo,
+	namespace string,
+	reference volumeObjectReference) (bool, error) {
+	extra := make(map[string]authorizationv1.ExtraValue, len(userInfo.Extra))
+	for key, value := range userInfo.Extra {
+		extra[key] = authorizationv1.ExtraValue(value)
+	}
+	review := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: namespace,
+				Verb:      "get",
+				Resource:  reference.resource,
+				Name:      reference.name,
+			},
+			User:   userInfo.Username,
+			Groups: userInfo.Groups,
+			UID:    userInfo.UID,
+			Extra:  extra,
+		},
+	}
+	if err := k8sClient.Create(ctx, review); err != nil {
+		return false, err
+	}
+	return review.Status.Allowed, nil
+}
+
+// validateStandaloneVolumeSources rejects Secret and CSI volumes on workspaces without a template,
+// since only templates can allow these sources
+func validateStandaloneVolumeSources(workspace *workspacev1alpha1.Workspace) error {
+	if workspace.Spec.TemplateRef != nil {
+		return nil
+	}
+	for i := range workspace.Spec.Volumes {
+		volume := &workspace.Spec.Volumes[i]
+		if sourceType := workspaceutil.GetVolumeSourceType(volume); isRestrictedVolumeSourceType(sourceType) {
+			return fmt.Errorf("spec.volumes[%s]: %s volumes require a template allowing them in volumeSources.allowedTypes",
+				volume.Name, sourceType)
+		}
+	}
+	return nil
+}
+
+// isRestrictedVolumeSourceType checks if the source type is denied unless a template explicitly allows it
+func isRestrictedVolumeSourceType(sourceType workspacev1alpha1.VolumeSourceType) bool {
+	return sourceType == workspacev1alpha1.VolumeSourceTypeSecret || sourceType == workspacev1alpha1.VolumeSourceTypeCSI
+}
+
+// validateNotOwnedByAnotherWorkspace checks that an object referenced by a volume is not owned by another workspace
+func validateNotOwnedByAnotherWorkspace(
+	obj metav1.Object,
+	workspace *workspacev1alpha1.Workspace,
+	kind, field string) *TemplateViolation {
+	for _, ownerRef := range obj.GetOwnerReferences() {
+		if ownerRef.APIVersion == "workspace.jupyter.org/v1alpha1" &&
+			ownerRef.Kind == "Workspace" &&
+			ownerRef.UID != workspace.UID {
+			return &TemplateViolation{
+				Type:    ViolationTypeVolumeOwnedByAnotherWorkspace,
+				Field:   field,
+				Message: fmt.Sprintf("%s '%s' is owned by another workspace '%s'", kind, obj.GetName(), ownerRef.Name),
+				Allowed: fmt.Sprintf("%ss not owned by other workspaces", kind),
+				Actual:  fmt.Sprintf("%s owned by workspace '%s'", kind, ownerRef.Name),
+			}
+		}
+	}
+	return nil
+}
+
+// validateSnapshotOwnership checks that the workspace doesn't restore a snapshot taken of another user's workspace
+func validateSnapshotOwnership(ctx context.Context, k8sClient client.Client, workspace *workspacev1alpha1.Workspace) *TemplateViolation {
+	if workspace.Spec.Storage == nil || workspace.Spec.Storage.RestoreFromSnapshot == "" {
+		return nil
+	}
+
+	snapshot := &unstructured.Unstructured{}
+	snapshot.SetAPIVersion(controller.VolumeSnapshotAPIGroup + "/" + controller.VolumeSnapshotAPIVersion)
+	snapshot.SetKind(controller.KindVolumeSnapshot)
+	err := k8sClient.Get(ctx, types.NamespacedName{
+		Name:      workspace.Spec.Storage.RestoreFromSnapshot,
+		Namespace: workspace.Namespace,
+	}, snapshot)
+
+	// A restore needs an existing snapshot, the PVC would otherwise bind to any snapshot created later with that name
+	if apierrors.IsNotFound(err) {
+		return &TemplateViolation{
+			Type:    ViolationTypeSnapshotOwnedByAnotherUser,
+			Field:   "spec.storage.restoreFromSnapshot",
+			Message: fmt.Sprintf("Snapshot '%s' does not exist", workspace.Spec.Storage.RestoreFromSnapshot),
+			Allowed: "existing snapshots",
+			Actual:  "missing snapshot",
+		}
+	}
+	if err != nil {
+		return &TemplateViolation{
+			Type:    ViolationTypeSnapshotOwnedByAnotherUser,
+			Field:   "spec.storage.restoreFromSnapshot",
+			Message: fmt.Sprintf("Unable to verify the owner of snapshot '%s': %v", workspace.Spec.Storage.RestoreFromSnapshot, err),
+			Allowed: "snapshots whose owner can be verified",
+			Actual:  "unverified snapshot",
+		}
+	}
+
+	// Snapshots taken by the controller carry the creator of the source workspace,
+	// only admins may restore other snapshots
+	snapshotOwner := snapshot.GetAnnotations()[controller.AnnotationCreatedBy]
+	if snapshotOwner == "" {
+		if isControllerOrAdminUser(ctx) {
+			return nil
+		}
+		return &TemplateViolation{
+			Type:    ViolationTypeSnapshotOwnedByAnotherUser,
+			Field:   "spec.storage.restoreFromSnapshot",
+			Message: fmt.Sprintf("Snapshot '%s' has no creator and can only be restored by an admin", snapshot.GetName()),
+			Allowed: "snapshots of workspaces created by the same user",
+			Actual:  "snapshot without creator",
+		}
+	}
+
+	if workspaceOwner := workspace.Annotations[controller.AnnotationCreatedBy]; workspaceOwner != snapshotOwner {
+		return &TemplateViolation{
+			Type:    ViolationTypeSnapshotOwnedByAnotherUser,
+			Field:   "spec.storage.restoreFromSnapshot",
+			Message: fmt.Sprintf("Snapshot '%s' belongs to a workspace created by another user", snapshot.GetName()),
+			Allowed: "snapshots of workspaces created by the same user",
+			Actual:  fmt.Sprintf("snapshot of workspace created by '%s'", snapshotOwner),
+		}
+	}
 
 	return nil
 }
+
+// getRestoreFromSnapshot returns the snapshot restored by the workspace storage, empty if none
+func getRestoreFromSnapshot(workspace *workspacev1alpha1.Workspace) string {
+	if workspace.Spec.Storage == nil {
+		return ""
+	}
+	return workspace.Spec.Storage.RestoreFromSnapshot
+}
+
+// validateCloneOwnership checks that the workspace only clones the storage of a workspace the user may access
+func validateCloneOwnership(ctx context.Context, k8sClient client.Client, workspace *workspacev1alpha1.Workspace) *TemplateViolation {
+	if workspace.Spec.CloneFrom == nil {
+		return nil
+	}
+
+	if workspace.Spec.Storage != nil && workspace.Spec.Storage.RestoreFromSnapshot != "" {
+		return &TemplateViolation{
+			Type:    ViolationTypeInvalidCloneSource,
+			Field:   "spec.cloneFrom",
+			Message: "cloneFrom cannot be combined with storage.restoreFromSnapshot",
+			Allowed: "either cloneFrom or storage.restoreFromSnapshot",
+			Actual:  "both",
+		}
+	}
+
+	source := &workspacev1alpha1.Workspace{}
+	err := k8sClient.Get(ctx, types.NamespacedName{
+		Name:      workspace.Spec.CloneFrom.Name,
+		Namespace: workspace.Namespace,
+	}, source)
+
+	// If source workspace doesn't exist, skip validation (it may be deleted after the clone was created)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return &TemplateViolation{
+			Type:    ViolationTypeCloneSourceOwnedByAnotherUser,
+			Field:   "spec.cloneFrom.name",
+			Message: fmt.Sprintf("Unable to verify the owner of source workspace '%s': %v", workspace.Spec.CloneFrom.Name, err),
+			Allowed: "source workspace with a verifiable owner",
+			Actual:  "lookup failed",
+		}
+	}
+
+	if source.Spec.Storage == nil {
+		return &TemplateViolation{
+			Type:    ViolationTypeInvalidCloneSource,
+			Field:   "spec.cloneFrom.name",
+			Message: fmt.Sprintf("Source workspace '%s' has no primary storage to clone", source.Name),
+			Allowed: "source workspace with primary storage",
+			Actual:  "no primary storage",
+		}
+	}
+
+	// The clone gets a copy of the source home volume: only the creator of the source may clone it,
+	// unless the source explicitly opts in to sharing
+	sourceOwner := source.Annotations[controller.AnnotationCreatedBy]
+	if source.Annotations[controller.AnnotationAllowClone] != "true" &&
+		(sourceOwner == "" || sourceOwner != workspace.Annotations[controller.AnnotationCreatedBy]) {
+		return &TemplateViolation{
+			Type:    ViolationTypeCloneSourceOwnedByAnotherUser,
+			Field:   "spec.cloneFrom.name",
+			Message: fmt.Sprintf("Source workspace '%s' was created by another user and does not allow cloning", source.Name),
+			Allowed: fmt.Sprintf("workspaces created by the same user or annotated with %s=true", controller.AnnotationAllowClone),
+			Actual:  fmt.Sprintf("workspace created by '%s'", sourceOwner),
+		}
+	}
+
+	// CSI clones cannot be smaller than their source volume
+	if workspace.Spec.Storage != nil && workspace.Spec.Storage.Size.Cmp(source.Spec.Storage.Size) < 0 {
+		return &TemplateViolation{
+			Type:    ViolationTypeInvalidCloneSource,
+			Field:   "spec.storage.size",
+			Message: fmt.Sprintf("Storage size must be at least the size of source workspace '%s'", source.Name),
+			Allowed: fmt.Sprintf(">= %s", source.Spec.Storage.Size.String()),
+			Actual:  workspace.Spec.Storage.Size.String(),
+		}
+	}
+
+	return nil
+}
+
+// validateExistingClaimOwnership checks that the workspace only adopts a PVC retained from a workspace
+// of the same user
+func validateExistingClaimOwnership(ctx context.Context, k8sClient client.Client, workspace *workspacev1alpha1.Workspace) *TemplateViolation {
+	if workspace.Spec.Storage == nil || workspace.Spec.Storage.ExistingClaimName == "" {
+		return nil
+	}
+	claimName := workspace.Spec.Storage.ExistingClaimName
+
+	if workspace.Spec.CloneFrom != nil || workspace.Spec.Storage.RestoreFromSnapshot != "" {
+		return &TemplateViolation{
+			Type:    ViolationTypeInvalidExistingClaim,
+			Field:   "spec.storage.existingClaimName",
+			Message: "storage.existingClaimName cannot be combined with cloneFrom or storage.restoreFromSnapshot",
+			Allowed: "one source for the workspace storage",
+			Actual:  "several sources",
+		}
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{}
+	err := k8sClient.Get(ctx, types.NamespacedName{
+		Name:      claimName,
+		Namespace: workspace.Namespace,
+	}, pvc)
+
+	// If PVC doesn't exist, skip validation (the controller reports it)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return &TemplateViolation{
+			Type:    ViolationTypeInvalidExistingClaim,
+			Field:   "spec.storage.existingClaimName",
+			Message: fmt.Sprintf("Unable to verify the owner of PVC '%s': %v", claimName, err),
+			Allowed: "PVCs whose owner can be verified",
+			Actual:  "unverified PVC",
+		}
+	}
+
+	// Once adopted, the PVC is owned by the workspace
+	if metav1.IsControlledBy(pvc, workspace) {
+		return nil
+	}
+	if !controller.IsRetainedPVC(pvc) {
+		return &TemplateViolation{
+			Type:    ViolationTypeInvalidExistingClaim,
+			Field:   "spec.storage.existingClaimName",
+			Message: fmt.Sprintf("PVC '%s' is not storage retained from a deleted workspace", claimName),
+			Allowed: "PVCs retained from deleted workspaces",
+			Actual:  "PVC not retained from a workspace",
+		}
+	}
+
+	return validateRetainedPVCOwner(ctx, pvc, workspace, "spec.storage.existingClaimName")
+}
+
+// validateRetainedPVCOwner checks that a PVC retained from a deleted workspace is only used by the
+// creator of that workspace. Retained PVCs without a recorded creator are only available to admins.
+func validateRetainedPVCOwner(
+	ctx context.Context,
+	pvc *corev1.PersistentVolumeClaim,
+	workspace *workspacev1alpha1.Workspace,
+	field string) *TemplateViolation {
+	if !controller.IsRetainedPVC(pvc) {
+		return nil
+	}
+
+	retainedOwner := pvc.Annotations[controller.AnnotationCreatedBy]
+	if retainedOwner == "" {
+		if isControllerOrAdminUser(ctx) {
+			return nil
+		}
+		return &TemplateViolation{
+			Type:    ViolationTypeRetainedStorageOfAnotherUser,
+			Field:   field,
+			Message: fmt.Sprintf("PVC '%s' was retained from a workspace without a recorded creator", pvc.Name),
+			Allowed: "storage retained from workspaces created by the same user",
+			Actual:  "storage retained from a workspace without a creator",
+		}
+	}
+	if retainedOwner == workspace.Annotations[controller.AnnotationCreatedBy] {
+		return nil
+	}
+	return &TemplateViolation{
+		Type:    ViolationTypeRetainedStorageOfAnotherUser,
+		Field:   field,
+		Message: fmt.Sprintf("PVC '%s' was retained from a workspace created by another user", pvc.Name),
+		Allowed: "storage retained from workspaces created by the same user",
+		Actual:  fmt.Sprintf("storage retained from workspace created by '%s'", retainedOwner),
+	}
+}
@@ -0,0 +1,405 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	"github.com/jupyter-infra/jupyter-k8s/internal/controller"
+	webhookconst "github.com/jupyter-infra/jupyter-k8s/internal/webhook"
+)
+
+var _ = Describe("VolumeValidator", func() {
+	Context("validateVolumeOwnership", func() {
+		var (
+			ctx       context.Context
+			scheme    *runtime.Scheme
+			workspace *workspacev1alpha1.Workspace
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			scheme = runtime.NewScheme()
+			Expect(corev1.AddToScheme(scheme)).To(Succeed())
+			workspace = &workspacev1alpha1.Workspace{
+				ObjectMeta: metav1.ObjectMeta{Name: "student", Namespace: "default"},
+				Spec: workspacev1alpha1.WorkspaceSpec{
+					Volumes: []workspacev1alpha1.VolumeSpec{
+						{Name: "data", PersistentVolumeClaimName: "data-pvc", MountPath: "/data"},
+					},
+				},
+			}
+		})
+
+		It("should allow PVCs not owned by workspaces", func() {
+			pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data-pvc", Namespace: "default"}}
+			k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pvc).Build()
+
+			Expect(validateVolumeOwnership(ctx, k8sClient, workspace)).To(BeNil())
+		})
+
+		It("should reject the PVC of a shared volume", func() {
+			pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+				Name:      "data-pvc",
+				Namespace: "default",
+				Labels: map[string]string{
+					controller.LabelComponent:        controller.ComponentSharedVolume,
+					controller.LabelSharedVolumeName: "datasets",
+				},
+			}}
+			k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pvc).Build()
+
+			violation := validateVolumeOwnership(ctx, k8sClient, workspace)
+			Expect(violation).NotTo(BeNil())
+			Expect(violation.Type).To(Equal(ViolationTypeSharedVolumeReference))
+			Expect(violation.Message).To(ContainSubstring("shared volume 'datasets'"))
+		})
+
+		It("should fail closed when the PVC cannot be read", func() {
+			k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+				Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+					return errors.New("connection refused")
+				},
+			}).Build()
+
+			violation := validateVolumeOwnership(ctx, k8sClient, workspace)
+			Expect(violation).NotTo(BeNil())
+			Expect(violation.Message).To(ContainSubstring("Unable to verify the owner"))
+		})
+	})
+
+	Context("validateSnapshotOwnership", func() {
+		It("should reject when the snapshot cannot be read", func() {
+			k8sClient := fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+				Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+					return errors.New("connection refused")
+				},
+			}).Build()
+			workspace := &workspacev1alpha1.Workspace{
+				ObjectMeta: metav1.ObjectMeta{Name: "student", Namespace: "default"},
+				Spec: workspacev1alpha1.WorkspaceSpec{
+					Storage: &workspacev1alpha1.StorageSpec{RestoreFromSnapshot: "snap"},
+				},
+			}
+
+			violation := validateSnapshotOwnership(context.Background(), k8sClient, workspace)
+			Expect(violation).NotTo(BeNil())
+			Expect(violation.Type).To(Equal(ViolationTypeSnapshotOwnedByAnotherUser))
+		})
+
+		// newSnapshotClient returns a client holding the snapshot with the given annotations, or no snapshot when nil
+		newSnapshotClient := func(annotations map[string]string) client.Client {
+			return fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+				Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+					if annotations == nil {
+						return apierrors.NewNotFound(schema.GroupResource{Group: controller.VolumeSnapshotAPIGroup, Resource: "volumesnapshots"}, key.Name)
+					}
+					obj.SetName(key.Name)
+					obj.SetNamespace(key.Namespace)
+					obj.SetAnnotations(annotations)
+					return nil
+				},
+			}).Build()
+		}
+
+		newRestoringWorkspace := func() *workspacev1alpha1.Workspace {
+			return &workspacev1alpha1.Workspace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "student",
+					Namespace:   "default",
+					Annotations: map[string]string{controller.AnnotationCreatedBy: "student"},
+				},
+				Spec: workspacev1alpha1.WorkspaceSpec{
+					Storage: &workspacev1alpha1.StorageSpec{RestoreFromSnapshot: "snap"},
+				},
+			}
+		}
+
+		It("should reject a snapshot that does not exist", func() {
+			ctx := createUserContext(context.Background(), "CREATE", "student")
+
+			violation := validateSnapshotOwnership(ctx, newSnapshotClient(nil), newRestoringWorkspace())
+			Expect(violation).NotTo(BeNil())
+			Expect(violation.Message).To(ContainSubstring("does not exist"))
+		})
+
+		It("should only let admins restore a snapshot without creator", func() {
+			k8sClient := newSnapshotClient(map[string]string{})
+
+			userCtx := createUserContext(context.Background(), "CREATE", "student")
+			violation := validateSnapshotOwnership(userCtx, k8sClient, newRestoringWorkspace())
+			Expect(violation).NotTo(BeNil())
+			Expect(violation.Type).To(Equal(ViolationTypeSnapshotOwnedByAnotherUser))
+
+			adminCtx := createUserContext(context.Background(), "CREATE", "admin", webhookconst.DefaultAdminGroup)
+			Expect(validateSnapshotOwnership(adminCtx, k8sClient, newRestoringWorkspace())).To(BeNil())
+		})
+
+		It("should allow restoring a snapshot of the same user", func() {
+			ctx := createUserContext(context.Background(), "CREATE", "student")
+			k8sClient := newSnapshotClient(map[string]string{controller.AnnotationCreatedBy: "student"})
+
+			Expect(validateSnapshotOwnership(ctx, k8sClient, newRestoringWorkspace())).To(BeNil())
+		})
+
+		It("should not check the snapshot again once restored", func() {
+			validator := NewVolumeValidator(newSnapshotClient(nil))
+			workspace := newRestoringWorkspace()
+			ctx := createUserContext(context.Background(), "UPDATE", "student")
+
+			Expect(validator.ValidateVolumeOwnership(ctx, workspace.DeepCopy(), workspace)).To(Succeed())
+		})
+	})
+
+	Context("validateVolumeObjectAccess", func() {
+		var (
+			ctx       context.Context
+			scheme    *runtime.Scheme
+			workspace *workspacev1alpha1.Workspace
+			reviews   []*authorizationv1.SubjectAccessReview
+		)
+
+		// newReviewClient returns a client answering access reviews with the allowed secrets and config maps
+		newReviewClient := func(allowed ...string) client.Client {
+			return fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+				Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+					review, ok := obj.(*authorizationv1.SubjectAccessReview)
+					if !ok {
+						return c.Create(ctx, obj, opts...)
+					}
+					reviews = append(reviews, review)
+					attributes := review.Spec.ResourceAttributes
+					review.Status.Allowed = slices.Contains(allowed, attributes.Resource+"/"+attributes.Name)
+					return nil
+				},
+			}).Build()
+		}
+
+		BeforeEach(func() {
+			ctx = createUserContext(context.Background(), "CREATE", "student", "students")
+			scheme = runtime.NewScheme()
+			Expect(corev1.AddToScheme(scheme)).To(Succeed())
+			Expect(authorizationv1.AddToScheme(scheme)).To(Succeed())
+			reviews = nil
+			workspace = &workspacev1alpha1.Workspace{
+				ObjectMeta: metav1.ObjectMeta{Name: "student", Namespace: "default"},
+				Spec: workspacev1alpha1.WorkspaceSpec{
+					Volumes: []workspacev1alpha1.VolumeSpec{
+						{
+							Name: "config",
+							ConfigMap: &corev1.ConfigMapVolumeSource{
+								LocalObjectReference: corev1.LocalObjectReference{Name: "team-config"},
+							},
+							MountPath: "/etc/team",
+						},
+						{
+							Name: "s3",
+							CSI: &corev1.CSIVolumeSource{
+								Driver:               "s3.csi.aws.com",
+								NodePublishSecretRef: &corev1.LocalObjectReference{Name: "s3-creds"},
+							},
+							MountPath: "/s3",
+						},
+					},
+				},
+			}
+		})
+
+		It("should allow objects the user can get", func() {
+			k8sClient := newReviewClient("configmaps/team-config", "secrets/s3-creds")
+
+			Expect(validateVolumeObjectAccess(ctx, k8sClient, nil, workspace)).To(Succeed())
+			Expect(reviews).To(HaveLen(2))
+			Expect(reviews[0].Spec.User).To(Equal("student"))
+			Expect(reviews[0].Spec.Groups).To(Equal([]string{"students"}))
+			Expect(reviews[0].Spec.ResourceAttributes.Verb).To(Equal("get"))
+			Expect(reviews[0].Spec.ResourceAttributes.Namespace).To(Equal("default"))
+		})
+
+		It("should reject a secret passed to a CSI driver the user cannot get", func() {
+			k8sClient := newReviewClient("configmaps/team-config")
+
+			err := validateVolumeObjectAccess(ctx, k8sClient, nil, workspace)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.volumes[s3].csi.nodePublishSecretRef.name: access denied"))
+		})
+
+		It("should fail closed when the access review fails", func() {
+			k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+				Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+					return errors.New("connection refused")
+				},
+			}).Build()
+
+			err := validateVolumeObjectAccess(ctx, k8sClient, nil, workspace)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("unable to verify access"))
+		})
+
+		It("should not review the objects already mounted by the old workspace", func() {
+			k8sClient := newReviewClient()
+			oldWorkspace := workspace.DeepCopy()
+
+			Expect(validateVolumeObjectAccess(ctx, k8sClient, oldWorkspace, workspace)).To(Succeed())
+			Expect(reviews).To(BeEmpty())
+		})
+	})
+
+	Context("validateStandaloneVolumeSources", func() {
+		It("should reject Secret and CSI volumes without a template", func() {
+			workspace := &workspacev1alpha1.Workspace{
+				Spec: workspacev1alpha1.WorkspaceSpec{
+					Volumes: []workspacev1alpha1.VolumeSpec{
+						{Name: "creds", Secret: &corev1.SecretVolumeSource{SecretName: "creds"}, MountPath: "/creds"},
+					},
+				},
+			}
+			Expect(validateStandaloneVolumeSources(workspace)).To(MatchError(ContainSubstring("require a template")))
+
+			workspace.Spec.TemplateRef = &workspacev1alpha1.TemplateRef{Name: "team"}
+			Expect(validateStandaloneVolumeSources(workspace)).To(Succeed())
+		})
+	})
+
+	Context("validateVolumeSources", func() {
+		var workspace *workspacev1alpha1.Workspace
+
+		BeforeEach(func() {
+			workspace = &workspacev1alpha1.Workspace{
+				Spec: workspacev1alpha1.WorkspaceSpec{
+					Volumes: []workspacev1alpha1.VolumeSpec{
+						{Name: "data", PersistentVolumeClaimName: "data-pvc", MountPath: "/data", ReadOnly: true, SubPath: "team/a"},
+					},
+				},
+			}
+		})
+
+		It("should allow a volume with one source and a relative sub-path", func() {
+			Expect(validateVolumeSources(workspace)).To(Succeed())
+		})
+
+		It("should reject a volume with several sources or none", func() {
+			workspace.Spec.Volumes[0].Secret = &corev1.SecretVolumeSource{SecretName: "creds"}
+			Expect(validateVolumeSources(workspace)).To(MatchError(ContainSubstring("exactly one")))
+
+			workspace.Spec.Volumes[0] = workspacev1alpha1.VolumeSpec{Name: "empty", MountPath: "/empty"}
+			Expect(validateVolumeSources(workspace)).To(MatchError(ContainSubstring("exactly one")))
+		})
+
+		It("should reject names reserved for generated volumes", func() {
+			workspace.Spec.Volumes[0].Name = "secondary-scratch"
+			Expect(validateVolumeSources(workspace)).To(MatchError(ContainSubstring("reserved")))
+
+			workspace.Spec.Volumes[0].Name = "shared-team"
+			Expect(validateVolumeSources(workspace)).To(MatchError(ContainSubstring("reserved")))
+		})
+
+		It("should reject sub-paths escaping the volume", func() {
+			workspace.Spec.Volumes[0].SubPath = "team/../../etc"
+			Expect(validateVolumeSources(workspace)).To(MatchError(ContainSubstring("relative path")))
+
+			workspace.Spec.Volumes[0].SubPath = "/etc"
+			Expect(validateVolumeSources(workspace)).To(MatchError(ContainSubstring("relative path")))
+		})
+	})
+
+	Context("validateVolumeSourcePolicy", func() {
+		var (
+			template  *workspacev1alpha1.WorkspaceTemplate
+			workspace *workspacev1alpha1.Workspace
+		)
+
+		BeforeEach(func() {
+			template = &workspacev1alpha1.WorkspaceTemplate{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-template"},
+				Spec: workspacev1alpha1.WorkspaceTemplateSpec{
+					VolumeSources: &workspacev1alpha1.VolumeSourcePolicy{
+						AllowedTypes: []workspacev1alpha1.VolumeSourceType{
+							workspacev1alpha1.VolumeSourceTypePersistentVolumeClaim,
+							workspacev1alpha1.VolumeSourceTypeConfigMap,
+						},
+						AllowedConfigMaps: []string{"team-config"},
+					},
+				},
+			}
+			workspace = &workspacev1alpha1.Workspace{
+				Spec: workspacev1alpha1.WorkspaceSpec{
+					Volumes: []workspacev1alpha1.VolumeSpec{
+						{Name: "data", PersistentVolumeClaimName: "data-pvc", MountPath: "/data"},
+						{
+							Name: "config",
+							ConfigMap: &corev1.ConfigMapVolumeSource{
+								LocalObjectReference: corev1.LocalObjectReference{Name: "team-config"},
+							},
+							MountPath: "/etc/team",
+						},
+					},
+				},
+			}
+		})
+
+		It("should allow the sources of the allowlist", func() {
+			Expect(validateVolumeSourcePolicy(workspace, template)).To(BeEmpty())
+		})
+
+		It("should deny Secret and CSI sources unless the template lists them", func() {
+			template.Spec.VolumeSources = nil
+			workspace.Spec.Volumes = append(workspace.Spec.Volumes, workspacev1alpha1.VolumeSpec{
+				Name:      "creds",
+				Secret:    &corev1.SecretVolumeSource{SecretName: "creds"},
+				MountPath: "/creds",
+			})
+			violations := validateVolumeSourcePolicy(workspace, template)
+			Expect(violations).To(HaveLen(1))
+			Expect(violations[0].Actual).To(Equal("Secret"))
+
+			template.Spec.VolumeSources = &workspacev1alpha1.VolumeSourcePolicy{
+				AllowedTypes: []workspacev1alpha1.VolumeSourceType{
+					workspacev1alpha1.VolumeSourceTypePersistentVolumeClaim,
+					workspacev1alpha1.VolumeSourceTypeConfigMap,
+					workspacev1alpha1.VolumeSourceTypeSecret,
+				},
+			}
+			Expect(validateVolumeSourcePolicy(workspace, template)).To(BeEmpty())
+		})
+
+		It("should allow PVC and ConfigMap sources without a policy", func() {
+			template.Spec.VolumeSources = nil
+			workspace.Spec.Volumes[1].ConfigMap.Name = "other-config"
+			Expect(validateVolumeSourcePolicy(workspace, template)).To(BeEmpty())
+		})
+
+		It("should reject source types and names outside of the allowlist", func() {
+			workspace.Spec.Volumes[1].ConfigMap.Name = "other-config"
+			workspace.Spec.Volumes = append(workspace.Spec.Volumes, workspacev1alpha1.VolumeSpec{
+				Name:      "creds",
+				Secret:    &corev1.SecretVolumeSource{SecretName: "creds"},
+				MountPath: "/creds",
+			})
+
+			violations := validateVolumeSourcePolicy(workspace, template)
+			Expect(violations).To(HaveLen(2))
+			Expect(violations[0].Type).To(Equal(ViolationTypeVolumeSourceNotAllowed))
+			Expect(violations[0].Actual).To(Equal("other-config"))
+			Expect(violations[1].Actual).To(Equal("Secret"))
+		})
+	})
+})
@@ -9,7 +9,9 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -149,6 +151,22 @@ func getEffectiveOwnershipType(ownershipType string) string {
 	return ownershipType
 }
 
+// isControllerServiceAccount checks if the user is the controller service account
+func isControllerServiceAccount(ctx context.Context) bool {
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil {
+		return false
+	}
+	controllerServiceAccount := os.Getenv(controller.ControllerPodServiceAccountEnv)
+	controllerNamespace := os.Getenv(controller.ControllerPodNamespaceEnv)
+	if controllerServiceAccount == "" || controllerNamespace == "" {
+		return false
+	}
+	// Build the full service account name: system:serviceaccount:namespace:name
+	fullControllerSA := fmt.Sprintf("system:serviceaccount:%s:%s", controllerNamespace, controllerServiceAccount)
+	return req.UserInfo.Username == fullControllerSA
+}
+
 // isControllerOrAdminUser checks if the user is the controller service account or has admin privileges
 func isControllerOrAdminUser(ctx context.Context) bool {
 	req, err := admission.RequestFromContext(ctx)
@@ -157,14 +175,8 @@ func isControllerOrAdminUser(ctx context.Context) bool {
 	}
 
 	// Check if user is controller
-	controllerServiceAccount := os.Getenv(controller.ControllerPodServiceAccountEnv)
-	controllerNamespace := os.Getenv(controller.ControllerPodNamespaceEnv)
-	if controllerServiceAccount != "" && controllerNamespace != "" {
-		// Build the full service account name: system:serviceaccount:namespace:name
-		fullControllerSA := fmt.Sprintf("system:serviceaccount:%s:%s", controllerNamespace, controllerServiceAccount)
-		if req.UserInfo.Username == fullControllerSA {
-			return true
-		}
+	if isControllerServiceAccount(ctx) {
+		return true
 	}
 
 	// Check if user is admin
@@ -183,6 +195,42 @@ func isControllerOrAdminUser(ctx context.Context) bool {
 	return false
 }
 
+// cancelPreemptionRestart removes the preemption annotations of a stopped workspace, so that the controller
+// does not restart it automatically
+func cancelPreemptionRestart(workspace *workspacev1alpha1.Workspace) {
+	if workspace.Spec.DesiredStatus != controller.DesiredStateStopped ||
+		workspace.Annotations[controller.PreemptionReasonAnnotation] != controller.PreemptedReason {
+		return
+	}
+	delete(workspace.Annotations, controller.PreemptionReasonAnnotation)
+	delete(workspace.Annotations, controller.PreemptedAtAnnotation)
+	workspacelog.Info("Canceled the automatic restart of the preempted workspace",
+		"workspace", workspace.GetName(), "namespace", workspace.GetNamespace())
+}
+
+// isStartOnConnectUpdate checks if the update is made by the auth middleware to start a stopped workspace.
+// The middleware authorizes the connection of the user with an access review before the start,
+// and may change nothing but the desired status.
+func isStartOnConnectUpdate(ctx context.Context, oldWorkspace, newWorkspace *workspacev1alpha1.Workspace) bool {
+	serviceAccount := os.Getenv(webhookconst.AuthMiddlewareServiceAccountEnv)
+	if serviceAccount == "" {
+		return false
+	}
+	req, err := admission.RequestFromContext(ctx)
+	if err != nil || req.UserInfo.Username != serviceAccount {
+		return false
+	}
+
+	if oldWorkspace.Spec.DesiredStatus != controller.DesiredStateStopped ||
+		newWorkspace.Spec.DesiredStatus != controller.DesiredStateRunning {
+		return false
+	}
+	oldSpec := oldWorkspace.Spec.DeepCopy()
+	oldSpec.DesiredStatus = newWorkspace.Spec.DesiredStatus
+	return equality.Semantic.DeepEqual(*oldSpec, newWorkspace.Spec) &&
+		equality.Semantic.DeepEqual(oldWorkspace.Labels, newWorkspace.Labels)
+}
+
 // validateOwnershipPermission checks if the user has permission to modify/delete an OwnerOnly workspace
 func validateOwnershipPermission(ctx context.Context, workspace *workspacev1alpha1.Workspace) error {
 	req, err := admission.RequestFromContext(ctx)
@@ -216,7 +264,9 @@ func SetupWorkspaceWebhookWithManager(mgr ctrl.Manager, defaultTemplateNamespace
 	templateGetter := NewTemplateGetter(mgr.GetClient(), defaultTemplateNamespace)
 	serviceAccountValidator := NewServiceAccountValidator(mgr.GetClient())
 	serviceAccountDefaulter := NewServiceAccountDefaulter(mgr.GetClient())
+	cloneDefaulter := NewCloneDefaulter(mgr.GetClient())
 	volumeValidator := NewVolumeValidator(mgr.GetClient())
+	quotaValidator := NewQuotaValidator(mgr.GetClient(), os.Getenv(controller.ControllerPodNamespaceEnv))
 
 	return ctrl.NewWebhookManagedBy(mgr).For(&workspacev1alpha1.Workspace{}).
 		WithValidator(&WorkspaceCustomValidator{
@@ -224,10 +274,12 @@ func SetupWorkspaceWebhookWithManager(mgr ctrl.Manager, defaultTemplateNamespace
 			accessStrategyValidator: accessStrategyValidator,
 			serviceAccountValidator: serviceAccountValidator,
 			volumeValidator:         volumeValidator,
+			quotaValidator:          quotaValidator,
 		}).
 		WithDefaulter(&WorkspaceCustomDefaulter{
 			templateDefaulter:       templateDefaulter,
 			serviceAccountDefaulter: serviceAccountDefaulter,
+			cloneDefaulter:          cloneDefaulter,
 			templateGetter:          templateGetter,
 			client:                  mgr.GetClient(),
 		}).
@@ -244,6 +296,7 @@ func SetupWorkspaceWebhookWithManager(mgr ctrl.Manager, defaultTemplateNamespace
 type WorkspaceCustomDefaulter struct {
 	templateDefaulter       *TemplateDefaulter
 	serviceAccountDefaulter *ServiceAccountDefaulter
+	cloneDefaulter          *CloneDefaulter
 	templateGetter          *TemplateGetter
 	client                  client.Client
 }
@@ -273,18 +326,41 @@ func (d *WorkspaceCustomDefaulter) Default(ctx context.Context, obj runtime.Obje
 	}
 
 	// Extract user info from request context
+	isCreate := false
 	if req, err := admission.RequestFromContext(ctx); err == nil {
 		sanitizedUsername := stringutil.SanitizeUsername(req.UserInfo.Username)
 
 		// Always set created-by on CREATE operations
 		if req.Operation == "CREATE" {
+			isCreate = true
 			workspace.Annotations[controller.AnnotationCreatedBy] = sanitizedUsername
 			workspacelog.Info("Added created-by annotation", "workspace", workspace.GetName(), "user", sanitizedUsername, "namespace", workspace.GetNamespace())
+
+			// Record the creator groups, which per-group workspace quotas are keyed by
+			if len(req.UserInfo.Groups) > 0 {
+				workspace.Annotations[controller.AnnotationCreatedByGroups] = strings.Join(req.UserInfo.Groups, ",")
+			} else {
+				delete(workspace.Annotations, controller.AnnotationCreatedByGroups)
+			}
 		}
 
 		// Always set last-updated-by (CREATE and UPDATE operations)
 		workspace.Annotations[controller.AnnotationLastUpdatedBy] = sanitizedUsername
 		workspacelog.Info("Added last-updated-by annotation", "workspace", workspace.GetName(), "user", sanitizedUsername, "namespace", workspace.GetNamespace())
+
+		// A user keeping a preempted workspace stopped cancels its pending automatic restart
+		if req.Operation == "UPDATE" && !isControllerServiceAccount(ctx) {
+			cancelPreemptionRestart(workspace)
+		}
+	}
+
+	// Copy the source workspace spec before template defaults, so that the source template is reused
+	// Only done on creation, later updates must not re-apply fields the user removed
+	if isCreate {
+		if err := d.cloneDefaulter.ApplyCloneDefaults(ctx, workspace); err != nil {
+			workspacelog.Error(err, "Failed to apply clone defaults", "workspace", workspace.GetName())
+			return fmt.Errorf("failed to apply clone defaults: %w", err)
+		}
 	}
 
 	// Apply template getter
@@ -340,6 +416,7 @@ type WorkspaceCustomValidator struct {
 	accessStrategyValidator *AccessStrategyValidator
 	serviceAccountValidator *ServiceAccountValidator
 	volumeValidator         *VolumeValidator
+	quotaValidator          *QuotaValidator
 }
 
 var _ webhook.CustomValidator = &WorkspaceCustomValidator{}
@@ -363,7 +440,42 @@ func (v *WorkspaceCustomValidator) ValidateCreate(ctx context.Context, obj runti
 	}
 
 	// Validate volume ownership (security check - applies to all users)
-	if err := v.volumeValidator.ValidateVolumeOwnership(ctx, workspace); err != nil {
+	if err := v.volumeValidator.ValidateVolumeOwnership(ctx, nil, workspace); err != nil {
+		return nil, err
+	}
+
+	// Validate the user may read the Secrets and ConfigMaps mounted by the workspace (applies to all users)
+	if err := v.volumeValidator.ValidateVolumeObjectAccess(ctx, nil, workspace); err != nil {
+		return nil, err
+	}
+
+	// Validate the Prometheus API used for idle detection is allowed by the operator (applies to all users)
+	if err := validatePrometheusURL(nil, workspace); err != nil {
+		return nil, err
+	}
+
+	// Validate schedule syntax (applies to all users)
+	if err := validateScheduleSyntax(workspace); err != nil {
+		return nil, err
+	}
+
+	// Validate ephemeral storage options (applies to all users)
+	if err := validateEphemeralStorage(workspace); err != nil {
+		return nil, err
+	}
+
+	// Validate the retention of the storage is bounded (applies to all users)
+	if err := validatePVCRetentionPolicy(workspace); err != nil {
+		return nil, err
+	}
+
+	// Validate secondary storage mount paths (applies to all users)
+	if err := validateSecondaryStorageMounts(workspace); err != nil {
+		return nil, err
+	}
+
+	// Validate volume sources and sub-paths (applies to all users)
+	if err := validateVolumeSources(workspace); err != nil {
 		return nil, err
 	}
 
@@ -372,6 +484,16 @@ func (v *WorkspaceCustomValidator) ValidateCreate(ctx context.Context, obj runti
 		return nil, nil
 	}
 
+	// Validate Secret and CSI volumes are allowed by a template
+	if err := validateStandaloneVolumeSources(workspace); err != nil {
+		return nil, err
+	}
+
+	// Validate priority classes are allowed by a template
+	if err := validateStandalonePriorityClasses(workspace); err != nil {
+		return nil, err
+	}
+
 	// Validate no user-submitted reserved prefix labels/annotations
 	if err := validateReservedPrefixOnCreate(workspace); err != nil {
 		return nil, err
@@ -382,6 +504,11 @@ func (v *WorkspaceCustomValidator) ValidateCreate(ctx context.Context, obj runti
 		return nil, err
 	}
 
+	// Validate workspace quotas of the creator
+	if err := v.quotaValidator.ValidateCreateWorkspace(ctx, workspace); err != nil {
+		return nil, err
+	}
+
 	return nil, nil
 }
 
@@ -403,6 +530,51 @@ func (v *WorkspaceCustomValidator) ValidateUpdate(ctx context.Context, oldObj, n
 		return nil, nil
 	}
 
+	// Validate the Prometheus API used for idle detection is allowed by the operator (applies to all users)
+	if err := validatePrometheusURL(oldWorkspace, newWorkspace); err != nil {
+		return nil, err
+	}
+
+	// Validate schedule syntax (applies to all users)
+	if err := validateScheduleSyntax(newWorkspace); err != nil {
+		return nil, err
+	}
+
+	// Validate the compute mode is only changed while stopped (applies to all users)
+	if err := validateComputeModeUpdate(oldWorkspace, newWorkspace); err != nil {
+		return nil, err
+	}
+
+	// Validate the storage is not shrunk (applies to all users)
+	if err := validateStorageShrink(oldWorkspace, newWorkspace); err != nil {
+		return nil, err
+	}
+
+	// Validate the storage type is not changed (applies to all users)
+	if err := validateStorageTypeChange(oldWorkspace, newWorkspace); err != nil {
+		return nil, err
+	}
+
+	// Validate ephemeral storage options (applies to all users)
+	if err := validateEphemeralStorage(newWorkspace); err != nil {
+		return nil, err
+	}
+
+	// Validate the retention of the storage is bounded (applies to all users)
+	if err := validatePVCRetentionPolicy(newWorkspace); err != nil {
+		return nil, err
+	}
+
+	// Validate secondary storage mount paths (applies to all users)
+	if err := validateSecondaryStorageMounts(newWorkspace); err != nil {
+		return nil, err
+	}
+
+	// Validate volume sources and sub-paths (applies to all users)
+	if err := validateVolumeSources(newWorkspace); err != nil {
+		return nil, err
+	}
+
 	// Controller or admin users bypass validation
 	isAdmin := isControllerOrAdminUser(ctx)
 
@@ -414,6 +586,14 @@ func (v *WorkspaceCustomValidator) ValidateUpdate(ctx context.Context, oldObj, n
 		return nil, nil
 	}
 
+	// Starts on connection are authorized by the auth middleware, only the quotas of the creator apply
+	if isStartOnConnectUpdate(ctx, oldWorkspace, newWorkspace) {
+		if err := v.quotaValidator.ValidateUpdateWorkspace(ctx, oldWorkspace, newWorkspace); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+
 	// Validate no user modifications to reserved prefix labels/annotations
 	if err := validateReservedPrefixOnUpdate(oldWorkspace, newWorkspace); err != nil {
 		return nil, err
@@ -451,7 +631,27 @@ func (v *WorkspaceCustomValidator) ValidateUpdate(ctx context.Context, oldObj, n
 	}
 
 	// Validate volume ownership (security check - applies to all users)
-	if err := v.volumeValidator.ValidateVolumeOwnership(ctx, newWorkspace); err != nil {
+	if err := v.volumeValidator.ValidateVolumeOwnership(ctx, oldWorkspace, newWorkspace); err != nil {
+		return nil, err
+	}
+
+	// Validate the user may read the Secrets and ConfigMaps newly mounted by the workspace
+	if err := v.volumeValidator.ValidateVolumeObjectAccess(ctx, oldWorkspace, newWorkspace); err != nil {
+		return nil, err
+	}
+
+	// Validate Secret and CSI volumes are allowed by a template
+	if err := validateStandaloneVolumeSources(newWorkspace); err != nil {
+		return nil, err
+	}
+
+	// Validate priority classes are allowed by a template
+	if err := validateStandalonePriorityClasses(newWorkspace); err != nil {
+		return nil, err
+	}
+
+	// Validate workspace quotas of the creator when the workspace is started
+	if err := v.quotaValidator.ValidateUpdateWorkspace(ctx, oldWorkspace, newWorkspace); err != nil {
 		return nil, err
 	}
 
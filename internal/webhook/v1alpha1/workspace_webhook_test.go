@@ -70,6 +70,7 @@ var _ = Describe("Workspace Webhook", func() {
 		defaulter = WorkspaceCustomDefaulter{
 			templateDefaulter:       NewTemplateDefaulter(mockClient, ""),
 			serviceAccountDefaulter: NewServiceAccountDefaulter(mockClient),
+			cloneDefaulter:          NewCloneDefaulter(mockClient),
 			templateGetter:          NewTemplateGetter(mockClient, ""),
 			client:                  mockClient, // Add client field for testing
 		}
@@ -77,6 +78,7 @@ var _ = Describe("Workspace Webhook", func() {
 			templateValidator:       NewTemplateValidator(mockClient, ""),
 			serviceAccountValidator: NewServiceAccountValidator(mockClient),
 			volumeValidator:         NewVolumeValidator(mockClient),
+			quotaValidator:          NewQuotaValidator(mockClient, ""),
 		}
 		ctx = context.Background()
 	})
@@ -93,6 +95,15 @@ var _ = Describe("Workspace Webhook", func() {
 			Expect(workspace.Annotations[controller.AnnotationLastUpdatedBy]).To(Equal("test-user"))
 		})
 
+		It("should record the creator groups on create", func() {
+			workspace.Annotations = map[string]string{controller.AnnotationCreatedByGroups: "forged"}
+			ctx = createUserContext(ctx, "CREATE", "test-user", "system:authenticated", "data-science")
+
+			err := defaulter.Default(ctx, workspace)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(workspace.Annotations[controller.AnnotationCreatedByGroups]).To(Equal("system:authenticated,data-science"))
+		})
+
 		It("should not overwrite existing created-by annotation", func() {
 			workspace.Annotations = map[string]string{controller.AnnotationCreatedBy: "original-user"}
 			ctx = createUserContext(ctx, "UPDATE", "new-user")
@@ -141,6 +152,38 @@ var _ = Describe("Workspace Webhook", func() {
 			Expect(workspace.Annotations[controller.AnnotationLastUpdatedBy]).To(Equal("update-user"))
 		})
 
+		It("should cancel the automatic restart of a preempted workspace kept stopped by a user", func() {
+			workspace.Spec.DesiredStatus = controller.DesiredStateStopped
+			workspace.Annotations = map[string]string{
+				controller.PreemptionReasonAnnotation: controller.PreemptedReason,
+				controller.PreemptedAtAnnotation:      "2026-01-01T00:00:00Z",
+			}
+			ctx = createUserContext(ctx, "UPDATE", "update-user")
+
+			Expect(defaulter.Default(ctx, workspace)).To(Succeed())
+			Expect(workspace.Annotations).NotTo(HaveKey(controller.PreemptionReasonAnnotation))
+			Expect(workspace.Annotations).NotTo(HaveKey(controller.PreemptedAtAnnotation))
+		})
+
+		It("should keep the preemption of a workspace stopped by the controller", func() {
+			Expect(os.Setenv(controller.ControllerPodNamespaceEnv, "default")).To(Succeed())
+			Expect(os.Setenv(controller.ControllerPodServiceAccountEnv, "controller")).To(Succeed())
+			defer func() {
+				_ = os.Unsetenv(controller.ControllerPodNamespaceEnv)
+				_ = os.Unsetenv(controller.ControllerPodServiceAccountEnv)
+			}()
+			workspace.Spec.DesiredStatus = controller.DesiredStateStopped
+			workspace.Annotations = map[string]string{
+				controller.PreemptionReasonAnnotation: controller.PreemptedReason,
+				controller.PreemptedAtAnnotation:      "2026-01-01T00:00:00Z",
+			}
+			ctx = createUserContext(ctx, "UPDATE", "system:serviceaccount:default:controller")
+
+			Expect(defaulter.Default(ctx, workspace)).To(Succeed())
+			Expect(workspace.Annotations).To(HaveKeyWithValue(controller.PreemptionReasonAnnotation, controller.PreemptedReason))
+			Expect(workspace.Annotations).To(HaveKey(controller.PreemptedAtAnnotation))
+		})
+
 		It("should call Get(AccessStrategy) and Update(AccessStrategy) with finalizer", func() {
 			// Create a test workspace with AccessStrategy reference
 			workspace.Spec.AccessStrategy = &workspacev1alpha1.AccessStrategyRef{
@@ -433,6 +476,31 @@ var _ = Describe("Workspace Webhook", func() {
 			Expect(warnings).To(BeEmpty())
 		})
 
+		It("should allow the auth middleware to start an OwnerOnly workspace on connection", func() {
+			GinkgoT().Setenv(webhookconst.AuthMiddlewareServiceAccountEnv,
+				"system:serviceaccount:jupyter-k8s-router:jupyter-k8s-authmiddleware")
+			middlewareCtx := createUserContext(ctx, "UPDATE",
+				"system:serviceaccount:jupyter-k8s-router:jupyter-k8s-authmiddleware")
+
+			oldWorkspace := workspace.DeepCopy()
+			oldWorkspace.Spec.OwnershipType = webhookconst.OwnershipTypeOwnerOnly
+			oldWorkspace.Spec.DesiredStatus = controller.DesiredStateStopped
+			oldWorkspace.Annotations = map[string]string{
+				controller.AnnotationCreatedBy: "original-user",
+			}
+			newWorkspace := oldWorkspace.DeepCopy()
+			newWorkspace.Spec.DesiredStatus = controller.DesiredStateRunning
+
+			_, err := validator.ValidateUpdate(middlewareCtx, oldWorkspace, newWorkspace)
+			Expect(err).NotTo(HaveOccurred())
+
+			// the middleware may not change anything else
+			newWorkspace.Spec.Image = "other-image:latest"
+			_, err = validator.ValidateUpdate(middlewareCtx, oldWorkspace, newWorkspace)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("access denied"))
+		})
+
 		It("should allow changing ownershipType from Public to OwnerOnly by admin", func() {
 			adminCtx := createUserContext(ctx, "UPDATE", "admin-user", "system:masters")
 
@@ -902,6 +970,7 @@ var _ = Describe("Workspace Webhook", func() {
 			validatorWithTemplate = &WorkspaceCustomValidator{
 				templateValidator: NewTemplateValidator(k8sClient, "default"),
 				volumeValidator:   NewVolumeValidator(k8sClient),
+				quotaValidator:    NewQuotaValidator(k8sClient, ""),
 			}
 		})
 
@@ -21,6 +21,12 @@ const (
 	// LabelAccessStrategyNamespace is the label key for access strategy namespace in the Workspace labels
 	LabelAccessStrategyNamespace = "workspace.jupyter.org/access-strategy-namespace"
 
+	// AnnotationCreatedBy is the annotation key for the sanitized name of the user who created the workspace
+	AnnotationCreatedBy = "workspace.jupyter.org/created-by"
+
+	// AnnotationCreatedByGroups is the annotation key for the comma-separated groups of the user who created the workspace
+	AnnotationCreatedByGroups = "workspace.jupyter.org/created-by-groups"
+
 	// TemplateFinalizerName is the name of the finalizer placed on a template that is referenced by workspaces
 	TemplateFinalizerName = "workspace.jupyter.org/template-protection"
 
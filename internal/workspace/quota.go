@@ -0,0 +1,360 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+)
+
+// desiredStatusStopped mirrors the controller desired status of a stopped workspace
+const desiredStatusStopped = "Stopped"
+
+// quotaResources lists the resources that WorkspaceQuota limits
+var quotaResources = []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory, corev1.ResourceStorage}
+
+// GetQuotaScope returns the scope of the quota, Namespace by default
+func GetQuotaScope(quota *workspacev1alpha1.WorkspaceQuota) workspacev1alpha1.WorkspaceQuotaScope {
+	if quota.Spec.Scope == "" {
+		return workspacev1alpha1.WorkspaceQuotaScopeNamespace
+	}
+	return quota.Spec.Scope
+}
+
+// IsQuotaEnforced reports whether the quota is enforced.
+// Cluster quotas are only enforced in the controller namespace, so that namespace owners
+// cannot restrict the workspaces of other namespaces.
+func IsQuotaEnforced(quota *workspacev1alpha1.WorkspaceQuota, controllerNamespace string) bool {
+	if GetQuotaScope(quota) != workspacev1alpha1.WorkspaceQuotaScopeCluster {
+		return true
+	}
+	return controllerNamespace != "" && quota.Namespace == controllerNamespace
+}
+
+// QuotaCoversNamespace reports whether workspaces of the namespace are counted against the quota
+func QuotaCoversNamespace(quota *workspacev1alpha1.WorkspaceQuota, namespace string) bool {
+	return GetQuotaScope(quota) == workspacev1alpha1.WorkspaceQuotaScopeCluster || quota.Namespace == namespace
+}
+
+// ListQuotasForNamespace returns the enforced quotas that count the workspaces of the namespace
+func ListQuotasForNamespace(
+	ctx context.Context,
+	k8sClient client.Client,
+	namespace string,
+	controllerNamespace string) ([]workspacev1alpha1.WorkspaceQuota, error) {
+	quotaList := &workspacev1alpha1.WorkspaceQuotaList{}
+	if err := k8sClient.List(ctx, quotaList); err != nil {
+		return nil, fmt.Errorf("failed to list workspace quotas: %w", err)
+	}
+
+	quotas := []workspacev1alpha1.WorkspaceQuota{}
+	for _, quota := range quotaList.Items {
+		if IsQuotaEnforced(&quota, controllerNamespace) && QuotaCoversNamespace(&quota, namespace) {
+			quotas = append(quotas, quota)
+		}
+	}
+	return quotas, nil
+}
+
+// ListQuotaWorkspaces returns the active (non-deleted) workspaces counted against the quota
+func ListQuotaWorkspaces(
+	ctx context.Context,
+	k8sClient client.Client,
+	quota *workspacev1alpha1.WorkspaceQuota) ([]workspacev1alpha1.Workspace, error) {
+	workspaceList := &workspacev1alpha1.WorkspaceList{}
+	listOptions := []client.ListOption{}
+	if GetQuotaScope(quota) == workspacev1alpha1.WorkspaceQuotaScopeNamespace {
+		listOptions = append(listOptions, client.InNamespace(quota.Namespace))
+	}
+	if err := k8sClient.List(ctx, workspaceList, listOptions...); err != nil {
+		return nil, fmt.Errorf("failed to list workspaces for quota %s/%s: %w", quota.Namespace, quota.Name, err)
+	}
+
+	activeWorkspaces := []workspacev1alpha1.Workspace{}
+	for _, ws := range workspaceList.Items {
+		if ws.DeletionTimestamp.IsZero() {
+			activeWorkspaces = append(activeWorkspaces, ws)
+		}
+	}
+	return activeWorkspaces, nil
+}
+
+// GetWorkspaceOwner returns the sanitized name of the user who created the workspace
+func GetWorkspaceOwner(ws *workspacev1alpha1.Workspace) string {
+	return ws.Annotations[AnnotationCreatedBy]
+}
+
+// GetWorkspaceOwnerGroups returns the groups of the user who created the workspace
+func GetWorkspaceOwnerGroups(ws *workspacev1alpha1.Workspace) []string {
+	value := ws.Annotations[AnnotationCreatedByGroups]
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+// IsQuotaRunning reports whether the workspace counts as running against quotas
+func IsQuotaRunning(ws *workspacev1alpha1.Workspace) bool {
+	return ws.Spec.DesiredStatus != desiredStatusStopped
+}
+
+// GetQuotaConsumption returns what a single workspace counts against quotas:
+// the workspace itself, and when running its CPU and memory requests, plus the size of its primary
+// and secondary storages.
+func GetQuotaConsumption(ws *workspacev1alpha1.Workspace) workspacev1alpha1.WorkspaceQuotaUsage {
+	consumption := workspacev1alpha1.WorkspaceQuotaUsage{
+		Workspaces: 1,
+		Resources:  corev1.ResourceList{},
+	}
+	if IsQuotaRunning(ws) {
+		consumption.RunningWorkspaces = 1
+		if ws.Spec.Resources != nil {
+			for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+				// the API server defaults missing requests to the limits
+				if quantity, ok := ws.Spec.Resources.Requests[name]; ok {
+					consumption.Resources[name] = quantity.DeepCopy()
+				} else if quantity, ok := ws.Spec.Resources.Limits[name]; ok {
+					consumption.Resources[name] = quantity.DeepCopy()
+				}
+			}
+		}
+	}
+	storage := resource.Quantity{}
+	if ws.Spec.Storage != nil {
+		storage.Add(ws.Spec.Storage.Size)
+	}
+	for _, secondary := range ws.Spec.SecondaryStorages {
+		storage.Add(secondary.Size)
+	}
+	if !storage.IsZero() {
+		consumption.Resources[corev1.ResourceStorage] = storage
+	}
+	return consumption
+}
+
+// AddQuotaUsage adds the usage of b to a
+func AddQuotaUsage(a *workspacev1alpha1.WorkspaceQuotaUsage, b workspacev1alpha1.WorkspaceQuotaUsage) {
+	a.Workspaces += b.Workspaces
+	a.RunningWorkspaces += b.RunningWorkspaces
+	if a.Resources == nil {
+		a.Resources = corev1.ResourceList{}
+	}
+	for name, quantity := range b.Resources {
+		total := a.Resources[name]
+		total.Add(quantity)
+		a.Resources[name] = total
+	}
+}
+
+// ComputeQuotaUsage returns the usage of each user and each limited group of the quota.
+// Users are only reported when the quota has per-user limits.
+func ComputeQuotaUsage(
+	quota *workspacev1alpha1.WorkspaceQuota,
+	workspaces []workspacev1alpha1.Workspace) ([]workspacev1alpha1.UserQuotaUsage, []workspacev1alpha1.GroupQuotaUsage) {
+	userUsage := map[string]*workspacev1alpha1.WorkspaceQuotaUsage{}
+	groupUsage := map[string]*workspacev1alpha1.WorkspaceQuotaUsage{}
+	for _, group := range quota.Spec.PerGroup {
+		groupUsage[group.Group] = &workspacev1alpha1.WorkspaceQuotaUsage{}
+	}
+
+	for i := range workspaces {
+		ws := &workspaces[i]
+		consumption := GetQuotaConsumption(ws)
+		if owner := GetWorkspaceOwner(ws); owner != "" && quota.Spec.PerUser != nil {
+			if userUsage[owner] == nil {
+				userUsage[owner] = &workspacev1alpha1.WorkspaceQuotaUsage{}
+			}
+			AddQuotaUsage(userUsage[owner], consumption)
+		}
+		for _, group := range GetWorkspaceOwnerGroups(ws) {
+			if usage, ok := groupUsage[group]; ok {
+				AddQuotaUsage(usage, consumption)
+			}
+		}
+	}
+
+	users := make([]workspacev1alpha1.UserQuotaUsage, 0, len(userUsage))
+	for user, usage := range userUsage {
+		users = append(users, workspacev1alpha1.UserQuotaUsage{User: user, WorkspaceQuotaUsage: *usage})
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].User < users[j].User })
+
+	groups := make([]workspacev1alpha1.GroupQuotaUsage, 0, len(quota.Spec.PerGroup))
+	for _, group := range quota.Spec.PerGroup {
+		groups = append(groups, workspacev1alpha1.GroupQuotaUsage{Group: group.Group, WorkspaceQuotaUsage: *groupUsage[group.Group]})
+	}
+	return users, groups
+}
+
+// ExceededQuotaLimits returns a description of each limit exceeded by the usage.
+// When requested is not nil, only the limits that the requested usage counts against are checked,
+// so that a workspace is not rejected for a limit it does not consume.
+func ExceededQuotaLimits(
+	limits *workspacev1alpha1.WorkspaceQuotaLimits,
+	usage workspacev1alpha1.WorkspaceQuotaUsage,
+	requested *workspacev1alpha1.WorkspaceQuotaUsage) []string {
+	if limits == nil {
+		return nil
+	}
+
+	exceeded := []string{}
+	if limits.MaxWorkspaces != nil && (requested == nil || requested.Workspaces > 0) &&
+		usage.Workspaces > *limits.MaxWorkspaces {
+		exceeded = append(exceeded, fmt.Sprintf("workspaces: %d exceeds the limit of %d",
+			usage.Workspaces, *limits.MaxWorkspaces))
+	}
+	if limits.MaxRunningWorkspaces != nil && (requested == nil || requested.RunningWorkspaces > 0) &&
+		usage.RunningWorkspaces > *limits.MaxRunningWorkspaces {
+		exceeded = append(exceeded, fmt.Sprintf("running workspaces: %d exceeds the limit of %d",
+			usage.RunningWorkspaces, *limits.MaxRunningWorkspaces))
+	}
+	for _, name := range quotaResources {
+		limit, ok := limits.Resources[name]
+		if !ok {
+			continue
+		}
+		if requested != nil {
+			if quantity, ok := requested.Resources[name]; !ok || quantity.Sign() <= 0 {
+				continue
+			}
+		}
+		used := usage.Resources[name]
+		if used.Cmp(limit) > 0 {
+			exceeded = append(exceeded, fmt.Sprintf("%s: %s exceeds the limit of %s",
+				name, used.String(), limit.String()))
+		}
+	}
+	return exceeded
+}
+
+// QuotaExceededError reports the limits of a quota that a workspace would exceed
+type QuotaExceededError struct {
+	// Quota is the namespace/name of the exceeded quota
+	Quota string
+	// Subject is the user or group whose limits are exceeded, e.g. "user alice"
+	Subject string
+	// Limits describes each exceeded limit
+	Limits []string
+}
+
+// Error implements the error interface
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("workspace exceeds quota %s for %s: %s", e.Quota, e.Subject, strings.Join(e.Limits, ", "))
+}
+
+// GetQuotaIncrease returns what an update of the workspace adds to the quotas of its creator:
+// the running workspace when it starts, and the resources whose consumption grows by the growth only.
+func GetQuotaIncrease(oldWs, newWs *workspacev1alpha1.Workspace) workspacev1alpha1.WorkspaceQuotaUsage {
+	oldConsumption := GetQuotaConsumption(oldWs)
+	newConsumption := GetQuotaConsumption(newWs)
+
+	increase := workspacev1alpha1.WorkspaceQuotaUsage{Resources: corev1.ResourceList{}}
+	if newConsumption.RunningWorkspaces > oldConsumption.RunningWorkspaces {
+		increase.RunningWorkspaces = newConsumption.RunningWorkspaces - oldConsumption.RunningWorkspaces
+	}
+	for name, quantity := range newConsumption.Resources {
+		delta := quantity.DeepCopy()
+		delta.Sub(oldConsumption.Resources[name])
+		if delta.Sign() > 0 {
+			increase.Resources[name] = delta
+		}
+	}
+	return increase
+}
+
+// IsQuotaIncrease reports whether the usage counts against any quota limit
+func IsQuotaIncrease(usage workspacev1alpha1.WorkspaceQuotaUsage) bool {
+	return usage.Workspaces > 0 || usage.RunningWorkspaces > 0 || len(usage.Resources) > 0
+}
+
+// CheckQuotas checks the usage of the workspace creator and their groups, including the workspace as given,
+// against the limits of every quota covering the workspace namespace that the requested usage counts against.
+// It returns a *QuotaExceededError when a limit is exceeded.
+func CheckQuotas(
+	ctx context.Context,
+	k8sClient client.Client,
+	controllerNamespace string,
+	workspace *workspacev1alpha1.Workspace,
+	requested workspacev1alpha1.WorkspaceQuotaUsage) error {
+	owner := GetWorkspaceOwner(workspace)
+	ownerGroups := GetWorkspaceOwnerGroups(workspace)
+	if owner == "" && len(ownerGroups) == 0 {
+		return nil
+	}
+
+	quotas, err := ListQuotasForNamespace(ctx, k8sClient, workspace.Namespace, controllerNamespace)
+	if err != nil {
+		return err
+	}
+
+	for i := range quotas {
+		quota := &quotas[i]
+		groupLimits := matchingGroupQuotas(quota, ownerGroups)
+		if (quota.Spec.PerUser == nil || owner == "") && len(groupLimits) == 0 {
+			continue
+		}
+
+		workspaces, err := ListQuotaWorkspaces(ctx, k8sClient, quota)
+		if err != nil {
+			return err
+		}
+		// count the workspace as given rather than as currently stored
+		counted := []workspacev1alpha1.Workspace{*workspace}
+		for _, ws := range workspaces {
+			if ws.Namespace != workspace.Namespace || ws.Name != workspace.Name {
+				counted = append(counted, ws)
+			}
+		}
+		users, groups := ComputeQuotaUsage(quota, counted)
+		quotaName := fmt.Sprintf("%s/%s", quota.Namespace, quota.Name)
+
+		if quota.Spec.PerUser != nil && owner != "" {
+			for _, usage := range users {
+				if usage.User != owner {
+					continue
+				}
+				if exceeded := ExceededQuotaLimits(quota.Spec.PerUser, usage.WorkspaceQuotaUsage, &requested); len(exceeded) > 0 {
+					return &QuotaExceededError{Quota: quotaName, Subject: "user " + owner, Limits: exceeded}
+				}
+			}
+		}
+
+		for _, usage := range groups {
+			limits, ok := groupLimits[usage.Group]
+			if !ok {
+				continue
+			}
+			if exceeded := ExceededQuotaLimits(limits, usage.WorkspaceQuotaUsage, &requested); len(exceeded) > 0 {
+				return &QuotaExceededError{Quota: quotaName, Subject: "group " + usage.Group, Limits: exceeded}
+			}
+		}
+	}
+	return nil
+}
+
+// matchingGroupQuotas returns the group limits of the quota that apply to the given groups
+func matchingGroupQuotas(
+	quota *workspacev1alpha1.WorkspaceQuota,
+	groups []string) map[string]*workspacev1alpha1.WorkspaceQuotaLimits {
+	limits := map[string]*workspacev1alpha1.WorkspaceQuotaLimits{}
+	for i := range quota.Spec.PerGroup {
+		groupQuota := &quota.Spec.PerGroup[i]
+		for _, group := range groups {
+			if group == groupQuota.Group {
+				limits[group] = &groupQuota.WorkspaceQuotaLimits
+			}
+		}
+	}
+	return limits
+}
@@ -0,0 +1,159 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package workspace
+
+import (
+	"testing"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newQuotaTestWorkspace(owner, groups, desiredStatus string) workspacev1alpha1.Workspace {
+	return workspacev1alpha1.Workspace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      owner + "-" + desiredStatus,
+			Namespace: "default",
+			Annotations: map[string]string{
+				AnnotationCreatedBy:       owner,
+				AnnotationCreatedByGroups: groups,
+			},
+		},
+		Spec: workspacev1alpha1.WorkspaceSpec{
+			DesiredStatus: desiredStatus,
+			Resources: &corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")},
+				Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+			},
+			Storage: &workspacev1alpha1.StorageSpec{Size: resource.MustParse("5Gi")},
+		},
+	}
+}
+
+func TestGetQuotaConsumption(t *testing.T) {
+	running := newQuotaTestWorkspace("alice", "", "Running")
+	consumption := GetQuotaConsumption(&running)
+	assert.Equal(t, int32(1), consumption.Workspaces)
+	assert.Equal(t, int32(1), consumption.RunningWorkspaces)
+	assert.True(t, consumption.Resources.Cpu().Equal(resource.MustParse("500m")))
+	// memory falls back to the limit when no request is set
+	assert.True(t, consumption.Resources.Memory().Equal(resource.MustParse("1Gi")))
+	assert.True(t, consumption.Resources.Storage().Equal(resource.MustParse("5Gi")))
+
+	stopped := newQuotaTestWorkspace("alice", "", "Stopped")
+	consumption = GetQuotaConsumption(&stopped)
+	assert.Equal(t, int32(0), consumption.RunningWorkspaces)
+	assert.NotContains(t, consumption.Resources, corev1.ResourceCPU)
+	assert.True(t, consumption.Resources.Storage().Equal(resource.MustParse("5Gi")))
+
+	// secondary storages count against the storage limit
+	stopped.Spec.SecondaryStorages = []workspacev1alpha1.SecondaryStorageSpec{
+		{Name: "scratch", Size: resource.MustParse("20Gi"), MountPath: "/scratch"},
+	}
+	consumption = GetQuotaConsumption(&stopped)
+	assert.True(t, consumption.Resources.Storage().Equal(resource.MustParse("25Gi")))
+}
+
+func TestGetQuotaIncrease(t *testing.T) {
+	stopped := newQuotaTestWorkspace("alice", "", "Stopped")
+	running := newQuotaTestWorkspace("alice", "", "Running")
+
+	increase := GetQuotaIncrease(&stopped, &running)
+	assert.True(t, IsQuotaIncrease(increase))
+	assert.Equal(t, int32(0), increase.Workspaces)
+	assert.Equal(t, int32(1), increase.RunningWorkspaces)
+	assert.True(t, increase.Resources.Cpu().Equal(resource.MustParse("500m")))
+	assert.NotContains(t, increase.Resources, corev1.ResourceStorage)
+
+	// stopping releases usage and is not an increase
+	assert.False(t, IsQuotaIncrease(GetQuotaIncrease(&running, &stopped)))
+
+	grown := running.DeepCopy()
+	grown.Spec.Storage.Size = resource.MustParse("8Gi")
+	increase = GetQuotaIncrease(&running, grown)
+	assert.Equal(t, int32(0), increase.RunningWorkspaces)
+	assert.NotContains(t, increase.Resources, corev1.ResourceCPU)
+	assert.True(t, increase.Resources.Storage().Equal(resource.MustParse("3Gi")))
+}
+
+func TestComputeQuotaUsage(t *testing.T) {
+	quota := &workspacev1alpha1.WorkspaceQuota{
+		Spec: workspacev1alpha1.WorkspaceQuotaSpec{
+			PerUser:  &workspacev1alpha1.WorkspaceQuotaLimits{},
+			PerGroup: []workspacev1alpha1.GroupQuota{{Group: "ml"}, {Group: "empty"}},
+		},
+	}
+	workspaces := []workspacev1alpha1.Workspace{
+		newQuotaTestWorkspace("bob", "ml", "Running"),
+		newQuotaTestWorkspace("alice", "ml,other", "Running"),
+		newQuotaTestWorkspace("alice", "ml,other", "Stopped"),
+	}
+
+	users, groups := ComputeQuotaUsage(quota, workspaces)
+
+	require.Len(t, users, 2)
+	assert.Equal(t, "alice", users[0].User)
+	assert.Equal(t, int32(2), users[0].Workspaces)
+	assert.Equal(t, int32(1), users[0].RunningWorkspaces)
+	assert.True(t, users[0].Resources.Storage().Equal(resource.MustParse("10Gi")))
+	assert.Equal(t, "bob", users[1].User)
+
+	require.Len(t, groups, 2)
+	assert.Equal(t, "ml", groups[0].Group)
+	assert.Equal(t, int32(3), groups[0].Workspaces)
+	assert.True(t, groups[0].Resources.Cpu().Equal(resource.MustParse("1")))
+	assert.Equal(t, "empty", groups[1].Group)
+	assert.Equal(t, int32(0), groups[1].Workspaces)
+
+	// users are not reported without per-user limits
+	quota.Spec.PerUser = nil
+	users, _ = ComputeQuotaUsage(quota, workspaces)
+	assert.Empty(t, users)
+}
+
+func TestExceededQuotaLimits(t *testing.T) {
+	maxWorkspaces := int32(1)
+	limits := &workspacev1alpha1.WorkspaceQuotaLimits{
+		MaxWorkspaces: &maxWorkspaces,
+		Resources:     corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+	}
+	usage := workspacev1alpha1.WorkspaceQuotaUsage{
+		Workspaces: 2,
+		Resources:  corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2Gi")},
+	}
+
+	assert.Equal(t, []string{
+		"workspaces: 2 exceeds the limit of 1",
+		"memory: 2Gi exceeds the limit of 1Gi",
+	}, ExceededQuotaLimits(limits, usage, nil))
+
+	// only the limits consumed by the request are checked
+	requested := &workspacev1alpha1.WorkspaceQuotaUsage{
+		Resources: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+	}
+	assert.Equal(t, []string{"memory: 2Gi exceeds the limit of 1Gi"}, ExceededQuotaLimits(limits, usage, requested))
+
+	assert.Empty(t, ExceededQuotaLimits(nil, usage, nil))
+}
+
+func TestIsQuotaEnforced(t *testing.T) {
+	quota := &workspacev1alpha1.WorkspaceQuota{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a"}}
+	assert.True(t, IsQuotaEnforced(quota, "jupyter-k8s-system"))
+	assert.True(t, QuotaCoversNamespace(quota, "team-a"))
+	assert.False(t, QuotaCoversNamespace(quota, "team-b"))
+
+	quota.Spec.Scope = workspacev1alpha1.WorkspaceQuotaScopeCluster
+	assert.False(t, IsQuotaEnforced(quota, "jupyter-k8s-system"))
+	assert.False(t, IsQuotaEnforced(quota, ""))
+	assert.True(t, QuotaCoversNamespace(quota, "team-b"))
+
+	quota.Namespace = "jupyter-k8s-system"
+	assert.True(t, IsQuotaEnforced(quota, "jupyter-k8s-system"))
+}
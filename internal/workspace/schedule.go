@@ -0,0 +1,126 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package workspace
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+)
+
+const (
+	// ScheduleActionStart indicates a start schedule boundary
+	ScheduleActionStart = "Start"
+
+	// ScheduleActionStop indicates a stop schedule boundary
+	ScheduleActionStop = "Stop"
+
+	// MaxMissedScheduleWindow bounds how far back the controller looks for a missed schedule boundary
+	MaxMissedScheduleWindow = 7 * 24 * time.Hour
+)
+
+// cronParser accepts standard 5-field cron expressions and descriptors such as @daily
+var cronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// ScheduleBoundary is a point in time at which a schedule fires
+type ScheduleBoundary struct {
+	Time   time.Time
+	Action string
+}
+
+// ResolvedSchedule holds the parsed start and stop schedules of a ScheduleSpec
+type ResolvedSchedule struct {
+	start    cron.Schedule
+	stop     cron.Schedule
+	location *time.Location
+}
+
+// ParseSchedule parses and validates a ScheduleSpec
+// Returns nil without error when the spec is nil or defines no schedule
+func ParseSchedule(spec *workspacev1alpha1.ScheduleSpec) (*ResolvedSchedule, error) {
+	if spec == nil || (spec.StartSchedule == "" && spec.StopSchedule == "") {
+		return nil, nil
+	}
+
+	location := time.UTC
+	if spec.TimeZone != "" {
+		loc, err := time.LoadLocation(spec.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeZone %q: %w", spec.TimeZone, err)
+		}
+		location = loc
+	}
+
+	resolved := &ResolvedSchedule{location: location}
+	if spec.StartSchedule != "" {
+		start, err := cronParser.Parse(spec.StartSchedule)
+		if err != nil {
+			return nil, fmt.Errorf("invalid startSchedule %q: %w", spec.StartSchedule, err)
+		}
+		resolved.start = start
+	}
+	if spec.StopSchedule != "" {
+		stop, err := cronParser.Parse(spec.StopSchedule)
+		if err != nil {
+			return nil, fmt.Errorf("invalid stopSchedule %q: %w", spec.StopSchedule, err)
+		}
+		resolved.stop = stop
+	}
+	return resolved, nil
+}
+
+// LastBoundary returns the most recent boundary in (after, now], or nil if no schedule fired.
+// When start and stop fire at the same time, stop wins.
+func (rs *ResolvedSchedule) LastBoundary(after, now time.Time) *ScheduleBoundary {
+	if earliest := now.Add(-MaxMissedScheduleWindow); after.Before(earliest) {
+		after = earliest
+	}
+
+	var last *ScheduleBoundary
+	if t, ok := lastFireTime(rs.start, after.In(rs.location), now); ok {
+		last = &ScheduleBoundary{Time: t, Action: ScheduleActionStart}
+	}
+	if t, ok := lastFireTime(rs.stop, after.In(rs.location), now); ok {
+		if last == nil || !t.Before(last.Time) {
+			last = &ScheduleBoundary{Time: t, Action: ScheduleActionStop}
+		}
+	}
+	return last
+}
+
+// NextBoundary returns the first boundary strictly after now, or nil if no schedule is defined
+func (rs *ResolvedSchedule) NextBoundary(now time.Time) *ScheduleBoundary {
+	var next *ScheduleBoundary
+	local := now.In(rs.location)
+	if rs.start != nil {
+		next = &ScheduleBoundary{Time: rs.start.Next(local), Action: ScheduleActionStart}
+	}
+	if rs.stop != nil {
+		t := rs.stop.Next(local)
+		if next == nil || !t.After(next.Time) {
+			next = &ScheduleBoundary{Time: t, Action: ScheduleActionStop}
+		}
+	}
+	return next
+}
+
+// lastFireTime walks the schedule forward from after and returns the last fire time not after now
+func lastFireTime(schedule cron.Schedule, after, now time.Time) (time.Time, bool) {
+	if schedule == nil {
+		return time.Time{}, false
+	}
+
+	var last time.Time
+	found := false
+	for t := schedule.Next(after); !t.IsZero() && !t.After(now); t = schedule.Next(t) {
+		last = t
+		found = true
+	}
+	return last, found
+}
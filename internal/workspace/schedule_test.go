@@ -0,0 +1,128 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package workspace
+
+import (
+	"testing"
+	"time"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSchedule_NilOrEmpty(t *testing.T) {
+	resolved, err := ParseSchedule(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, resolved)
+
+	resolved, err = ParseSchedule(&workspacev1alpha1.ScheduleSpec{TimeZone: "UTC"})
+	assert.NoError(t, err)
+	assert.Nil(t, resolved)
+}
+
+func TestParseSchedule_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		spec workspacev1alpha1.ScheduleSpec
+		want string
+	}{
+		{"bad start", workspacev1alpha1.ScheduleSpec{StartSchedule: "not a cron"}, "invalid startSchedule"},
+		{"bad stop", workspacev1alpha1.ScheduleSpec{StopSchedule: "0 25 * * *"}, "invalid stopSchedule"},
+		{"bad time zone", workspacev1alpha1.ScheduleSpec{StopSchedule: "0 18 * * *", TimeZone: "Mars/Olympus"}, "invalid timeZone"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSchedule(&tt.spec)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestResolvedSchedule_LastBoundary(t *testing.T) {
+	resolved, err := ParseSchedule(&workspacev1alpha1.ScheduleSpec{
+		StartSchedule: "0 9 * * 1-5",
+		StopSchedule:  "0 18 * * 1-5",
+	})
+	require.NoError(t, err)
+
+	// Monday 2025-01-06
+	monday := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+
+	// Before any boundary
+	assert.Nil(t, resolved.LastBoundary(monday, monday.Add(8*time.Hour)))
+
+	// After the start boundary
+	b := resolved.LastBoundary(monday, monday.Add(10*time.Hour))
+	require.NotNil(t, b)
+	assert.Equal(t, ScheduleActionStart, b.Action)
+	assert.Equal(t, monday.Add(9*time.Hour), b.Time.UTC())
+
+	// After the stop boundary
+	b = resolved.LastBoundary(monday, monday.Add(19*time.Hour))
+	require.NotNil(t, b)
+	assert.Equal(t, ScheduleActionStop, b.Action)
+
+	// A boundary equal to after has already been applied
+	assert.Nil(t, resolved.LastBoundary(monday.Add(18*time.Hour), monday.Add(19*time.Hour)))
+}
+
+func TestResolvedSchedule_LastBoundary_StopWinsTies(t *testing.T) {
+	resolved, err := ParseSchedule(&workspacev1alpha1.ScheduleSpec{
+		StartSchedule: "0 12 * * *",
+		StopSchedule:  "0 12 * * *",
+	})
+	require.NoError(t, err)
+
+	day := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	b := resolved.LastBoundary(day, day.Add(13*time.Hour))
+	require.NotNil(t, b)
+	assert.Equal(t, ScheduleActionStop, b.Action)
+}
+
+func TestResolvedSchedule_LastBoundary_BoundedLookback(t *testing.T) {
+	resolved, err := ParseSchedule(&workspacev1alpha1.ScheduleSpec{StopSchedule: "0 0 1 1 *"})
+	require.NoError(t, err)
+
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	// January 1st is outside of the missed schedule window
+	assert.Nil(t, resolved.LastBoundary(time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC), now))
+}
+
+func TestResolvedSchedule_TimeZone(t *testing.T) {
+	resolved, err := ParseSchedule(&workspacev1alpha1.ScheduleSpec{
+		StopSchedule: "0 18 * * *",
+		TimeZone:     "America/New_York",
+	})
+	require.NoError(t, err)
+
+	// 2025-01-06 18:00 in New York is 23:00 UTC
+	now := time.Date(2025, 1, 6, 12, 0, 0, 0, time.UTC)
+	next := resolved.NextBoundary(now)
+	require.NotNil(t, next)
+	assert.Equal(t, ScheduleActionStop, next.Action)
+	assert.Equal(t, time.Date(2025, 1, 6, 23, 0, 0, 0, time.UTC), next.Time.UTC())
+}
+
+func TestResolvedSchedule_NextBoundary(t *testing.T) {
+	resolved, err := ParseSchedule(&workspacev1alpha1.ScheduleSpec{
+		StartSchedule: "0 9 * * *",
+		StopSchedule:  "0 18 * * *",
+	})
+	require.NoError(t, err)
+
+	day := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	next := resolved.NextBoundary(day.Add(10 * time.Hour))
+	require.NotNil(t, next)
+	assert.Equal(t, ScheduleActionStop, next.Action)
+	assert.Equal(t, day.Add(18*time.Hour), next.Time.UTC())
+
+	next = resolved.NextBoundary(day.Add(19 * time.Hour))
+	require.NotNil(t, next)
+	assert.Equal(t, ScheduleActionStart, next.Action)
+	assert.Equal(t, day.Add(33*time.Hour), next.Time.UTC())
+}
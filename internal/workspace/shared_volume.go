@@ -0,0 +1,39 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package workspace
+
+import (
+	"slices"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+)
+
+// SharedVolumeAccess reports whether the shared volume is mounted in the workspace, and whether it is
+// mounted read-only. Access is decided by the groups of the user who created the workspace.
+func SharedVolumeAccess(sharedVolume *workspacev1alpha1.SharedVolume, ws *workspacev1alpha1.Workspace) (mounted bool, readOnly bool) {
+	if sharedVolume.Namespace != ws.Namespace {
+		return false, false
+	}
+
+	groups := GetWorkspaceOwnerGroups(ws)
+	if len(sharedVolume.Spec.Groups) > 0 && !hasAnyGroup(groups, sharedVolume.Spec.Groups) {
+		return false, false
+	}
+	if len(sharedVolume.Spec.ReadWriteGroups) == 0 {
+		return true, false
+	}
+	return true, !hasAnyGroup(groups, sharedVolume.Spec.ReadWriteGroups)
+}
+
+// hasAnyGroup checks if one of the groups is in the candidate groups
+func hasAnyGroup(groups []string, candidates []string) bool {
+	for _, group := range groups {
+		if slices.Contains(candidates, group) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package workspace
+
+import (
+	"testing"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSharedVolumeAccess(t *testing.T) {
+	sharedVolume := &workspacev1alpha1.SharedVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "datasets", Namespace: "default"},
+	}
+	student := newQuotaTestWorkspace("alice", "students", "Running")
+	teacher := newQuotaTestWorkspace("bob", "teachers,staff", "Running")
+
+	// all the workspaces of the namespace mount the volume read-write by default
+	mounted, readOnly := SharedVolumeAccess(sharedVolume, &student)
+	assert.True(t, mounted)
+	assert.False(t, readOnly)
+
+	// members of the read-write groups write, other users read
+	sharedVolume.Spec.ReadWriteGroups = []string{"teachers"}
+	mounted, readOnly = SharedVolumeAccess(sharedVolume, &student)
+	assert.True(t, mounted)
+	assert.True(t, readOnly)
+	mounted, readOnly = SharedVolumeAccess(sharedVolume, &teacher)
+	assert.True(t, mounted)
+	assert.False(t, readOnly)
+
+	// the volume is restricted to the members of its groups
+	sharedVolume.Spec.Groups = []string{"staff"}
+	mounted, _ = SharedVolumeAccess(sharedVolume, &student)
+	assert.False(t, mounted)
+	mounted, _ = SharedVolumeAccess(sharedVolume, &teacher)
+	assert.True(t, mounted)
+
+	// shared volumes are only mounted in their namespace
+	teacher.Namespace = "other"
+	mounted, _ = SharedVolumeAccess(sharedVolume, &teacher)
+	assert.False(t, mounted)
+}
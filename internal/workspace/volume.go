@@ -0,0 +1,53 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package workspace
+
+import (
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+)
+
+// GetVolumeSourceTypes returns the types of the sources set on the volume, which is valid with exactly one
+func GetVolumeSourceTypes(volume *workspacev1alpha1.VolumeSpec) []workspacev1alpha1.VolumeSourceType {
+	var sourceTypes []workspacev1alpha1.VolumeSourceType
+	if volume.PersistentVolumeClaimName != "" {
+		sourceTypes = append(sourceTypes, workspacev1alpha1.VolumeSourceTypePersistentVolumeClaim)
+	}
+	if volume.ConfigMap != nil {
+		sourceTypes = append(sourceTypes, workspacev1alpha1.VolumeSourceTypeConfigMap)
+	}
+	if volume.Secret != nil {
+		sourceTypes = append(sourceTypes, workspacev1alpha1.VolumeSourceTypeSecret)
+	}
+	if volume.CSI != nil {
+		sourceTypes = append(sourceTypes, workspacev1alpha1.VolumeSourceTypeCSI)
+	}
+	return sourceTypes
+}
+
+// GetVolumeSourceType returns the type of the source of the volume, or an empty type when it sets none
+func GetVolumeSourceType(volume *workspacev1alpha1.VolumeSpec) workspacev1alpha1.VolumeSourceType {
+	sourceTypes := GetVolumeSourceTypes(volume)
+	if len(sourceTypes) == 0 {
+		return ""
+	}
+	return sourceTypes[0]
+}
+
+// GetVolumeSourceName returns the name of the PVC, ConfigMap or Secret mounted by the volume,
+// or the driver of its CSI source
+func GetVolumeSourceName(volume *workspacev1alpha1.VolumeSpec) string {
+	switch GetVolumeSourceType(volume) {
+	case workspacev1alpha1.VolumeSourceTypePersistentVolumeClaim:
+		return volume.PersistentVolumeClaimName
+	case workspacev1alpha1.VolumeSourceTypeConfigMap:
+		return volume.ConfigMap.Name
+	case workspacev1alpha1.VolumeSourceTypeSecret:
+		return volume.Secret.SecretName
+	case workspacev1alpha1.VolumeSourceTypeCSI:
+		return volume.CSI.Driver
+	}
+	return ""
+}
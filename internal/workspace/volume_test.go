@@ -0,0 +1,39 @@
+/*
+Copyright (c) Amazon Web Services
+Distributed under the terms of the MIT license
+*/
+
+package workspace
+
+import (
+	"testing"
+
+	workspacev1alpha1 "github.com/jupyter-infra/jupyter-k8s/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestGetVolumeSource(t *testing.T) {
+	volume := &workspacev1alpha1.VolumeSpec{Name: "data", PersistentVolumeClaimName: "datasets", MountPath: "/data"}
+	assert.Equal(t, workspacev1alpha1.VolumeSourceTypePersistentVolumeClaim, GetVolumeSourceType(volume))
+	assert.Equal(t, "datasets", GetVolumeSourceName(volume))
+
+	volume = &workspacev1alpha1.VolumeSpec{
+		Name:      "config",
+		ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "team-config"}},
+	}
+	assert.Equal(t, workspacev1alpha1.VolumeSourceTypeConfigMap, GetVolumeSourceType(volume))
+	assert.Equal(t, "team-config", GetVolumeSourceName(volume))
+
+	volume = &workspacev1alpha1.VolumeSpec{Name: "creds", Secret: &corev1.SecretVolumeSource{SecretName: "team-creds"}}
+	assert.Equal(t, "team-creds", GetVolumeSourceName(volume))
+
+	volume = &workspacev1alpha1.VolumeSpec{Name: "s3", CSI: &corev1.CSIVolumeSource{Driver: "s3.csi.aws.com"}}
+	assert.Equal(t, workspacev1alpha1.VolumeSourceTypeCSI, GetVolumeSourceType(volume))
+	assert.Equal(t, "s3.csi.aws.com", GetVolumeSourceName(volume))
+
+	// a volume must set exactly one source
+	volume.PersistentVolumeClaimName = "datasets"
+	assert.Len(t, GetVolumeSourceTypes(volume), 2)
+	assert.Empty(t, GetVolumeSourceType(&workspacev1alpha1.VolumeSpec{Name: "none"}))
+}